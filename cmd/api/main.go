@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"os"
 	"time"
+	// Embedded zone data, so user timezones resolve in minimal images
+	_ "time/tzdata"
 
 	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
 	"github.com/darron08/todolist-demo/internal/infrastructure/config"
 	"github.com/darron08/todolist-demo/internal/infrastructure/database"
+	"github.com/darron08/todolist-demo/internal/infrastructure/eventbus"
+	"github.com/darron08/todolist-demo/internal/infrastructure/jobs"
+	"github.com/darron08/todolist-demo/internal/infrastructure/mail"
+	"github.com/darron08/todolist-demo/internal/infrastructure/outbox"
+	"github.com/darron08/todolist-demo/internal/infrastructure/realtime"
 	"github.com/darron08/todolist-demo/internal/infrastructure/redis"
 	"github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/webhook"
+	"github.com/darron08/todolist-demo/internal/interfaces/graphql"
+	grpcServer "github.com/darron08/todolist-demo/internal/interfaces/grpc"
 	"github.com/darron08/todolist-demo/internal/interfaces/http"
 	httpHandler "github.com/darron08/todolist-demo/internal/interfaces/http/handler"
 	"github.com/darron08/todolist-demo/internal/usecase"
@@ -55,11 +67,25 @@ func main() {
 	}
 	defer closeDatabases(databases)
 
+	// Background workers are stopped when main returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(databases.MySQL.GetDB())
+	orgRepo := repository.NewOrganizationRepository(databases.MySQL.GetDB())
 	todoRepo := repository.NewTodoRepository(databases.MySQL.GetDB())
 	tagRepo := repository.NewTagRepository(databases.MySQL.GetDB())
 	todoTagRepo := repository.NewTodoTagRepository(databases.MySQL.GetDB())
+	todoAssigneeRepo := repository.NewTodoAssigneeRepository(databases.MySQL.GetDB())
+	webhookRepo := repository.NewWebhookRepository(databases.MySQL.GetDB())
+	webhookDeliveryRepo := repository.NewWebhookDeliveryRepository(databases.MySQL.GetDB())
+	templateRepo := repository.NewTemplateRepository(databases.MySQL.GetDB())
+	workflowRepo := repository.NewWorkflowRepository(databases.MySQL.GetDB())
+	outboxRepo := repository.NewOutboxRepository(databases.MySQL.GetDB())
+	notificationRepo := repository.NewNotificationRepository(databases.MySQL.GetDB())
+	notificationPreferenceRepo := repository.NewNotificationPreferenceRepository(databases.MySQL.GetDB())
+	txManager := repository.NewTransactionManager(databases.MySQL.GetDB())
 
 	// Initialize token store
 	tokenStore := redis.NewTokenStore(databases.Redis)
@@ -68,6 +94,9 @@ func main() {
 	todoCache := cache.NewTodoCache(
 		databases.Redis,
 		todoRepo,
+		workflowRepo,
+		todoAssigneeRepo,
+		todoTagRepo,
 		time.Duration(cfg.Cache.Todo.HashTTL)*time.Second,
 		time.Duration(cfg.Cache.Todo.SortedSetTTL)*time.Second,
 		time.Duration(cfg.Cache.Todo.QueryTTL)*time.Second,
@@ -78,25 +107,235 @@ func main() {
 		time.Duration(cfg.Cache.Tag.TTL)*time.Second,
 	)
 
+	// Rank tag suggestions by usage, recorded whenever tags are attached to todos
+	tagUsage := cache.NewTagUsage(databases.Redis, time.Duration(cfg.Cache.Tag.UsageHalfLife)*time.Second)
+	todoTagRepo = cache.NewUsageTrackingTodoTagRepository(todoTagRepo, tagRepo, tagUsage)
+
+	// Initialize event bus
+	eventBus := eventbus.NewBus(1024)
+	defer eventBus.Close()
+
+	// Initialize webhook dispatcher
+	webhookDispatcher := webhook.NewDispatcher(
+		webhookRepo,
+		webhookDeliveryRepo,
+		webhook.NewQueue(databases.Redis),
+		webhook.Config{
+			MaxAttempts:          cfg.Webhook.MaxAttempts,
+			InitialBackoff:       time.Duration(cfg.Webhook.InitialBackoff) * time.Second,
+			MaxBackoff:           time.Duration(cfg.Webhook.MaxBackoff) * time.Second,
+			Timeout:              time.Duration(cfg.Webhook.Timeout) * time.Second,
+			PollInterval:         time.Duration(cfg.Webhook.PollInterval) * time.Second,
+			BatchSize:            int64(cfg.Webhook.BatchSize),
+			Workers:              cfg.Webhook.Workers,
+			SweepInterval:        time.Duration(cfg.Webhook.SweepInterval) * time.Second,
+			AllowPrivateNetworks: cfg.Webhook.AllowPrivateNetworks,
+		},
+	)
+	if cfg.Webhook.Enabled {
+		eventBus.Subscribe(webhookDispatcher.HandleEvent)
+		go webhookDispatcher.Start(ctx)
+	}
+
+	// Initialize realtime hub
+	realtimeHub := realtime.NewHub(databases.Redis, realtime.Config{
+		StreamMaxLen: int64(cfg.Realtime.StreamMaxLen),
+		StreamTTL:    time.Duration(cfg.Realtime.StreamTTL) * time.Second,
+		ClientBuffer: cfg.Realtime.ClientBuffer,
+	})
+	if cfg.Realtime.Enabled {
+		eventBus.Subscribe(realtimeHub.HandleEvent)
+		go realtimeHub.Start(ctx)
+	}
+
+	// Initialize outbox relay; events recorded by use cases reach the bus and
+	// the other sinks only through the relay
+	if cfg.Outbox.Enabled {
+		var sinks outbox.MultiSink
+		for _, name := range cfg.Outbox.Sinks {
+			switch name {
+			case "bus":
+				sinks = append(sinks, outbox.NewBusSink(eventBus))
+			case "redis_stream":
+				sinks = append(sinks, outbox.NewRedisStreamSink(databases.Redis, cfg.Outbox.StreamKey, int64(cfg.Outbox.StreamMaxLen)))
+			default:
+				log.Fatalf("Unknown outbox sink: %s", name)
+			}
+		}
+
+		outboxRelay := outbox.NewRelay(outboxRepo, sinks, databases.Redis, outbox.Config{
+			PollInterval: time.Duration(cfg.Outbox.PollInterval) * time.Second,
+			BatchSize:    cfg.Outbox.BatchSize,
+			LockTTL:      time.Duration(cfg.Outbox.LockTTL) * time.Second,
+			Retention:    time.Duration(cfg.Outbox.Retention) * time.Second,
+		})
+		go outboxRelay.Start(ctx)
+	}
+
 	// Initialize JWT manager
 	accessTokenExpiry := 15 * time.Minute
 	refreshTokenExpiry := 7 * 24 * time.Hour
 	jwtManager := utils.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Issuer, accessTokenExpiry, refreshTokenExpiry)
 
+	mailSender := mail.NewSMTPSender(mail.Config{
+		Host:     cfg.Mail.Host,
+		Port:     cfg.Mail.Port,
+		Username: cfg.Mail.Username,
+		Password: cfg.Mail.Password,
+		From:     cfg.Mail.From,
+	})
+
 	// Initialize use cases
-	userUseCase := usecase.NewUserUseCase(userRepo, jwtManager, tokenStore)
-	todoUseCase := usecase.NewTodoUseCase(todoRepo, tagRepo, todoTagRepo, todoCache)
-	adminUseCase := usecase.NewAdminUseCase(userRepo, todoRepo)
-	tagUseCase := usecase.NewTagUseCase(tagRepo, todoTagRepo, tagCache)
+	userUseCase := usecase.NewUserUseCase(
+		userRepo,
+		orgRepo,
+		todoRepo,
+		todoCache,
+		jwtManager,
+		tokenStore,
+		txManager,
+		outboxRepo,
+		mailSender,
+		cfg.PasswordReset.URL,
+		time.Duration(cfg.PasswordReset.TTL)*time.Second,
+	)
+	todoUseCase := usecase.NewTodoUseCase(todoRepo, tagRepo, todoTagRepo, todoAssigneeRepo, workflowRepo, userRepo, todoCache, txManager, outboxRepo, time.Duration(cfg.Matrix.UrgentWithin)*time.Second)
+	adminUseCase := usecase.NewAdminUseCase(userRepo, orgRepo, todoRepo, txManager, outboxRepo)
+	tagUseCase := usecase.NewTagUseCase(tagRepo, todoTagRepo, tagCache, todoCache, tagUsage, txManager, outboxRepo)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo, webhookDeliveryRepo, webhookDispatcher)
+	templateUseCase := usecase.NewTemplateUseCase(templateRepo, todoRepo, tagRepo, todoTagRepo, workflowRepo, todoCache, txManager, outboxRepo)
+
+	var statsCache *cache.StatsCache
+	if cfg.Cache.Stats.Enabled {
+		statsCache = cache.NewStatsCache(databases.Redis, time.Duration(cfg.Cache.Stats.TTL)*time.Second)
+	}
+	statsUseCase := usecase.NewStatsUseCase(todoRepo, workflowRepo, statsCache)
+	workflowUseCase := usecase.NewWorkflowUseCase(workflowRepo, todoRepo, todoCache, txManager, orgRepo)
+	agendaUseCase := usecase.NewAgendaUseCase(todoRepo, userRepo, todoCache)
+	organizationUseCase := usecase.NewOrganizationUseCase(orgRepo, userRepo)
+
+	// Start overdue detection
+	if cfg.Overdue.Enabled {
+		overdueUseCase := usecase.NewOverdueUseCase(
+			todoRepo,
+			todoCache,
+			txManager,
+			outboxRepo,
+			time.Duration(cfg.Overdue.EscalationInterval)*time.Second,
+			cfg.Overdue.BatchSize,
+		)
+		overdueJob := jobs.NewPeriodic("overdue", time.Duration(cfg.Overdue.CheckInterval)*time.Second, databases.Redis, func(ctx context.Context) error {
+			_, err := overdueUseCase.ProcessOverdue(ctx, time.Now())
+			return err
+		})
+		go overdueJob.Start(ctx)
+	}
+
+	// Start archiving of completed todos
+	if cfg.Archive.Enabled {
+		archiveUseCase := usecase.NewArchiveUseCase(
+			todoRepo,
+			todoCache,
+			txManager,
+			outboxRepo,
+			time.Duration(cfg.Archive.AfterDays)*24*time.Hour,
+			cfg.Archive.BatchSize,
+		)
+		archiveJob := jobs.NewPeriodic("archive", time.Duration(cfg.Archive.CheckInterval)*time.Second, databases.Redis, func(ctx context.Context) error {
+			_, err := archiveUseCase.ArchiveCompleted(ctx, time.Now())
+			return err
+		})
+		go archiveJob.Start(ctx)
+	}
+
+	// Start digest emails; one instance sends per interval, each user at
+	// their digest hour in their own timezone
+	digestSecret := cfg.Digest.Secret
+	if digestSecret == "" {
+		digestSecret = cfg.JWT.Secret
+	}
+	digestUseCase := usecase.NewDigestUseCase(
+		userRepo,
+		todoRepo,
+		mailSender,
+		cfg.Digest.UnsubscribeURL,
+		digestSecret,
+		cfg.Digest.BatchSize,
+	)
+	if cfg.Digest.Enabled {
+		digestJob := jobs.NewPeriodic("digest", time.Duration(cfg.Digest.CheckInterval)*time.Second, databases.Redis, func(ctx context.Context) error {
+			_, err := digestUseCase.SendDue(ctx, time.Now())
+			return err
+		})
+		go digestJob.Start(ctx)
+	}
+
+	// Deliver events addressed to users to their inbox and email; the bus is
+	// fed by the outbox relay, which runs on one instance at a time
+	var notificationSender mail.Sender
+	if cfg.Notifications.Email {
+		notificationSender = mailSender
+	}
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, notificationPreferenceRepo, userRepo, notificationSender)
+	if cfg.Notifications.Enabled {
+		eventBus.Subscribe(notificationUseCase.HandleEvent)
+	}
+
+	// Initialize GraphQL schema
+	graphqlSchema, err := graphql.NewSchema(todoUseCase, tagUseCase, userUseCase, graphql.Limits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	})
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
 
 	// Initialize handlers
 	userHandler := httpHandler.NewUserHandler(userUseCase)
 	todoHandler := httpHandler.NewTodoHandler(todoUseCase)
 	adminHandler := httpHandler.NewAdminHandler(adminUseCase)
 	tagHandler := httpHandler.NewTagHandler(tagUseCase)
+	webhookHandler := httpHandler.NewWebhookHandler(webhookUseCase)
+	eventHandler := httpHandler.NewEventHandler(
+		realtimeHub,
+		time.Duration(cfg.Realtime.HeartbeatInterval)*time.Second,
+		cfg.CORS.AllowedOrigins,
+	)
+	graphqlHandler := httpHandler.NewGraphQLHandler(graphqlSchema)
+	statsHandler := httpHandler.NewStatsHandler(statsUseCase)
+	templateHandler := httpHandler.NewTemplateHandler(templateUseCase)
+	workflowHandler := httpHandler.NewWorkflowHandler(workflowUseCase)
+	agendaHandler := httpHandler.NewAgendaHandler(agendaUseCase)
+	digestHandler := httpHandler.NewDigestHandler(digestUseCase)
+	organizationHandler := httpHandler.NewOrganizationHandler(organizationUseCase, userUseCase)
+	notificationHandler := httpHandler.NewNotificationHandler(notificationUseCase)
 
 	// Initialize router
-	router := http.SetupRouter(cfg, jwtManager, tokenStore, userHandler, todoHandler, adminHandler, tagHandler)
+	router := http.SetupRouter(cfg, jwtManager, tokenStore, userHandler, todoHandler, adminHandler, tagHandler, webhookHandler, eventHandler, graphqlHandler, statsHandler, templateHandler, workflowHandler, agendaHandler, digestHandler, organizationHandler, notificationHandler)
+
+	// Start gRPC server alongside the HTTP server
+	if cfg.GRPC.Enabled {
+		listener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port %s: %v", cfg.GRPC.Port, err)
+		}
+
+		// WatchTodos needs the realtime hub to be running
+		var watchHub *realtime.Hub
+		if cfg.Realtime.Enabled {
+			watchHub = realtimeHub
+		}
+
+		server := grpcServer.NewServer(jwtManager, todoUseCase, tagUseCase, userUseCase, watchHub)
+		defer server.GracefulStop()
+
+		go func() {
+			log.Printf("gRPC server starting on port %s", cfg.GRPC.Port)
+			if err := server.Serve(listener); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
 
 	// Get port from environment or config
 	port := os.Getenv("PORT")
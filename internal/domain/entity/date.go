@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayout is the layout of dates in JSON and in the database
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date without a time of day or timezone
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in t's location
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// ParseDate parses a date in YYYY-MM-DD form
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// String formats the date as YYYY-MM-DD
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// StartIn returns the first instant of the date in loc
+func (d Date) StartIn(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// EndIn returns the last second of the date in loc. Days are not always
+// 24 hours long, so this is computed from the start of the next day.
+func (d Date) EndIn(loc *time.Location) time.Time {
+	return d.AddDays(1).StartIn(loc).Add(-time.Second)
+}
+
+// AddDays returns the date n days later
+func (d Date) AddDays(n int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+n, 0, 0, 0, 0, time.UTC))
+}
+
+// MarshalJSON encodes the date as a YYYY-MM-DD string
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a YYYY-MM-DD string
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores the date in a DATE column
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a DATE column. The driver may return it as a time at midnight
+// in the connection's location, whose calendar date is the stored one.
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case []byte:
+		return d.scanString(string(v))
+	case string:
+		return d.scanString(v)
+	}
+	return fmt.Errorf("cannot scan %T into Date", value)
+}
+
+func (d *Date) scanString(value string) error {
+	if len(value) > len(dateLayout) {
+		value = value[:len(dateLayout)]
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Notification is an entry in a user's inbox, created from a domain event
+// addressed to the user
+type Notification struct {
+	ID int64 `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	// OrganizationID is the tenant the event happened in
+	OrganizationID int64  `json:"organization_id" gorm:"type:bigint;not null;index:idx_organization_user"`
+	UserID         int64  `json:"user_id" gorm:"type:bigint;not null;index:idx_organization_user;uniqueIndex:idx_user_event"`
+	EventID        string `json:"event_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_user_event"`
+	EventType      string `json:"event_type" gorm:"type:varchar(64);not null"`
+	// ActorID is the user whose change caused the notification, if any
+	ActorID       *int64     `json:"actor_id,omitempty" gorm:"type:bigint"`
+	AggregateType string     `json:"aggregate_type" gorm:"type:varchar(32);not null"`
+	AggregateID   int64      `json:"aggregate_id" gorm:"type:bigint;not null"`
+	Message       string     `json:"message" gorm:"type:varchar(512);not null"`
+	ReadAt        *time.Time `json:"read_at,omitempty" gorm:"type:datetime"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// IsRead reports whether the user has read the notification
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationPreference sets whether events of a type land in the user's
+// inbox and whether they are also emailed
+type NotificationPreference struct {
+	UserID    int64     `json:"user_id" gorm:"type:bigint;primaryKey"`
+	EventType string    `json:"event_type" gorm:"type:varchar(64);primaryKey"`
+	Inbox     bool      `json:"inbox" gorm:"not null"`
+	Email     bool      `json:"email" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// mentionPattern matches @username at the start of the text or after a
+// character that cannot be part of a word, so that email addresses are not
+// taken for mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@(\w[\w.-]*)`)
+
+// Mentions returns the usernames mentioned in text as @username, in order of
+// first mention and without duplicates. Trailing dots and dashes, as at the
+// end of a sentence, are not part of the username.
+func Mentions(text string) []string {
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if !containsFold(usernames, username) {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+// NewMentions returns the usernames mentioned in text but not in previous,
+// the text it replaces
+func NewMentions(text, previous string) []string {
+	mentionedBefore := Mentions(previous)
+
+	var usernames []string
+	for _, username := range Mentions(text) {
+		if !containsFold(mentionedBefore, username) {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+// containsFold reports whether values contains value, ignoring case like
+// username lookups do
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
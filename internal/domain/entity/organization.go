@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"time"
+)
+
+// Organization is a tenant: a workspace whose todos and tags are isolated
+// from those of every other organization. Users take part through
+// memberships and act in one organization at a time.
+type Organization struct {
+	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	Name      string     `json:"name" gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName returns the table name for GORM
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// OrganizationRole represents the role of a member in an organization
+type OrganizationRole string
+
+const (
+	// OrganizationRoleOwner members manage members, owners included
+	OrganizationRoleOwner OrganizationRole = "owner"
+	// OrganizationRoleAdmin members manage members other than owners
+	OrganizationRoleAdmin OrganizationRole = "admin"
+	// OrganizationRoleMember members work on their own todos and tags
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+// IsValid reports whether the role is a known organization role
+func (r OrganizationRole) IsValid() bool {
+	switch r {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+// CanManageMembers reports whether members with the role may add, change
+// and remove members
+func (r OrganizationRole) CanManageMembers() bool {
+	return r == OrganizationRoleOwner || r == OrganizationRoleAdmin
+}
+
+// OrganizationMember represents the membership of a user in an organization
+type OrganizationMember struct {
+	OrganizationID int64            `json:"organization_id" gorm:"type:bigint;not null;primaryKey"`
+	UserID         int64            `json:"user_id" gorm:"type:bigint;not null;primaryKey;index"`
+	Role           OrganizationRole `json:"role" gorm:"type:varchar(20);not null;default:'member'"`
+	CreatedAt      time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Organization *Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+	User         *User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TableName returns the table name for GORM
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
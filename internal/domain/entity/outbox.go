@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"strconv"
+	"time"
+)
+
+// OutboxMessage is a domain event stored in the same transaction as the
+// change it describes, waiting to be relayed to event consumers
+type OutboxMessage struct {
+	ID            int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	EventID       string     `json:"event_id" gorm:"type:varchar(36);not null;uniqueIndex"`
+	EventType     string     `json:"event_type" gorm:"type:varchar(64);not null"`
+	AggregateType string     `json:"aggregate_type" gorm:"type:varchar(32);not null;index:idx_outbox_aggregate"`
+	AggregateID   int64      `json:"aggregate_id" gorm:"type:bigint;not null;index:idx_outbox_aggregate"`
+	UserID        int64      `json:"user_id" gorm:"type:bigint;not null"`
+	Payload       string     `json:"payload" gorm:"type:mediumtext;not null"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	LastError     string     `json:"last_error,omitempty" gorm:"type:varchar(1024)"`
+	PublishedAt   *time.Time `json:"published_at,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+// AggregateKey identifies the aggregate whose events must stay in order
+func (m *OutboxMessage) AggregateKey() string {
+	return m.AggregateType + ":" + strconv.FormatInt(m.AggregateID, 10)
+}
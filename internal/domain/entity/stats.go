@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// DailyCount is the number of todos completed on one calendar day
+type DailyCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// TagUsage is the number of a user's todos labelled with a tag
+type TagUsage struct {
+	TagID int64  `json:"tag_id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// TodoStats aggregates a user's todos. Completion figures cover the period
+// starting at Since; the distributions cover all of the user's todos.
+type TodoStats struct {
+	Since           time.Time              `json:"since"`
+	Total           int64                  `json:"total"`
+	CompletedPerDay []DailyCount           `json:"completed_per_day"`
+	CompletedCount  int64                  `json:"completed_count"`
+	AvgLeadTime     float64                `json:"avg_lead_time_seconds"`
+	OverdueCount    int64                  `json:"overdue_count"`
+	StatusCounts    map[TodoStatus]int64   `json:"status_counts"`
+	PriorityCounts  map[TodoPriority]int64 `json:"priority_counts"`
+	TopTags         []TagUsage             `json:"top_tags"`
+}
@@ -1,16 +1,56 @@
 package entity
 
 import (
+	"strings"
 	"time"
 )
 
-// Tag represents a tag entity in the domain layer
+// TagPathSeparator separates the segments of a hierarchical tag name
+const TagPathSeparator = "/"
+
+// Tag represents a tag entity in the domain layer. Tags belong to the user
+// who created them, in one organization; names are unique per user within
+// the organization. Hierarchical tags are named
+// by their full path, such as "work/backend/db", and point to their parent.
+// Color is a hex color such as "#1e90ff" and Icon names an icon of the
+// client's icon set, such as "briefcase".
 type Tag struct {
-	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
-	Name      string     `json:"name" gorm:"type:varchar(100);uniqueIndex"`
-	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID             int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	UserID         int64      `json:"user_id" gorm:"type:bigint;not null;uniqueIndex:idx_user_organization_name"`
+	OrganizationID int64      `json:"organization_id" gorm:"type:bigint;not null;uniqueIndex:idx_user_organization_name"`
+	ParentID       *int64     `json:"parent_id,omitempty" gorm:"type:bigint;index"`
+	Name           string     `json:"name" gorm:"type:varchar(100);uniqueIndex:idx_user_organization_name"`
+	Color          string     `json:"color,omitempty" gorm:"type:varchar(7)"`
+	Icon           string     `json:"icon,omitempty" gorm:"type:varchar(50)"`
+	Description    string     `json:"description,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// ParentPath returns the name of the tag's parent, or "" for a top-level tag
+func (t *Tag) ParentPath() string {
+	i := strings.LastIndex(t.Name, TagPathSeparator)
+	if i < 0 {
+		return ""
+	}
+	return t.Name[:i]
+}
+
+// LeafName returns the last segment of the tag's name
+func (t *Tag) LeafName() string {
+	return t.Name[strings.LastIndex(t.Name, TagPathSeparator)+1:]
+}
+
+// TagAlias is a former name of a tag, such as the name of a tag merged into
+// it, that still resolves to the tag
+type TagAlias struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	UserID         int64     `json:"user_id" gorm:"type:bigint;not null;uniqueIndex:idx_user_organization_name"`
+	OrganizationID int64     `json:"organization_id" gorm:"type:bigint;not null;uniqueIndex:idx_user_organization_name"`
+	TagID          int64     `json:"tag_id" gorm:"type:bigint;not null;index"`
+	Name           string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_user_organization_name"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
 // TodoTag represents the many-to-many relationship between todos and tags
@@ -25,7 +65,23 @@ func (Tag) TableName() string {
 	return "tags"
 }
 
+// TableName returns the table name for GORM
+func (TagAlias) TableName() string {
+	return "tag_aliases"
+}
+
 // TableName returns the table name for GORM
 func (TodoTag) TableName() string {
 	return "todo_tags"
 }
+
+// ArchivedTodoTag links an archived todo to a tag; it describes the table
+// for migration
+type ArchivedTodoTag struct {
+	TodoTag
+}
+
+// TableName returns the table name for GORM
+func (ArchivedTodoTag) TableName() string {
+	return "archived_todo_tags"
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Template is a named, reusable set of todo blueprints owned by a user
+type Template struct {
+	ID          int64          `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	UserID      int64          `json:"user_id" gorm:"type:bigint;not null;index"`
+	Name        string         `json:"name" gorm:"type:varchar(100);not null"`
+	Description string         `json:"description,omitempty" gorm:"type:varchar(1000)"`
+	Items       []TemplateItem `json:"items" gorm:"foreignKey:TemplateID"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt   *time.Time     `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName returns the table name for GORM
+func (Template) TableName() string {
+	return "templates"
+}
+
+// TemplateItem is the blueprint of a single todo in a template. Items are
+// ordered by Position; an item with a ParentPosition becomes a subtask of
+// the todo created from the item at that position.
+type TemplateItem struct {
+	ID             int64        `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	TemplateID     int64        `json:"template_id" gorm:"type:bigint;not null;index"`
+	Position       int          `json:"position" gorm:"not null"`
+	ParentPosition *int         `json:"parent_position,omitempty"`
+	Title          string       `json:"title" gorm:"type:varchar(255);not null"`
+	Description    string       `json:"description,omitempty" gorm:"type:text"`
+	Priority       TodoPriority `json:"priority" gorm:"type:varchar(20);not null;default:'p2'"`
+	Tags           string       `json:"tags" gorm:"type:varchar(1024)"`
+	// DueOffsetDays is the number of days after the instantiation date the
+	// todo is due; nil leaves the todo without a due date
+	DueOffsetDays *int `json:"due_offset_days,omitempty"`
+}
+
+// TableName returns the table name for GORM
+func (TemplateItem) TableName() string {
+	return "template_items"
+}
+
+// TagList returns the names of the tags applied to the todo
+func (i *TemplateItem) TagList() []string {
+	if i.Tags == "" {
+		return nil
+	}
+	return strings.Split(i.Tags, ",")
+}
+
+// SetTagList stores the names of the tags applied to the todo
+func (i *TemplateItem) SetTagList(tags []string) {
+	i.Tags = strings.Join(tags, ",")
+}
@@ -1,10 +1,12 @@
 package entity
 
 import (
+	"strings"
 	"time"
 )
 
-// TodoStatus represents the status of a todo item
+// TodoStatus represents the status of a todo item. Statuses are the states
+// of the owner's workflow; the constants are the states of the default one.
 type TodoStatus string
 
 const (
@@ -13,30 +15,296 @@ const (
 	TodoStatusCompleted  TodoStatus = "completed"
 )
 
-// TodoPriority represents the priority of a todo item
+// TodoPriority represents the priority of a todo item, on a scale from p0
+// (most important) to p4
 type TodoPriority string
 
 const (
-	TodoPriorityLow    TodoPriority = "low"
-	TodoPriorityMedium TodoPriority = "medium"
-	TodoPriorityHigh   TodoPriority = "high"
+	TodoPriorityP0 TodoPriority = "p0"
+	TodoPriorityP1 TodoPriority = "p1"
+	TodoPriorityP2 TodoPriority = "p2"
+	TodoPriorityP3 TodoPriority = "p3"
+	TodoPriorityP4 TodoPriority = "p4"
+
+	// TodoPriorityDefault is the priority of todos created without one
+	TodoPriorityDefault = TodoPriorityP2
+)
+
+// priorityLabels are the display names of the priority levels, in order
+var priorityLabels = []string{"Critical", "High", "Medium", "Low", "Minimal"}
+
+// legacyPriorities maps the former low/medium/high priorities onto the scale
+var legacyPriorities = map[string]TodoPriority{
+	"high":   TodoPriorityP1,
+	"medium": TodoPriorityP2,
+	"low":    TodoPriorityP3,
+}
+
+// Priorities returns the priority levels from most to least important
+func Priorities() []TodoPriority {
+	return []TodoPriority{TodoPriorityP0, TodoPriorityP1, TodoPriorityP2, TodoPriorityP3, TodoPriorityP4}
+}
+
+// ParsePriority parses a priority level such as "p1" or "P1". The former
+// low, medium and high priorities are accepted as p3, p2 and p1.
+func ParsePriority(value string) (TodoPriority, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if priority, ok := legacyPriorities[value]; ok {
+		return priority, true
+	}
+	priority := TodoPriority(value)
+	return priority, priority.Level() >= 0
+}
+
+// Level returns the position of the priority on the scale, 0 being the most
+// important, or -1 for an unknown priority
+func (p TodoPriority) Level() int {
+	for level, priority := range Priorities() {
+		if p == priority {
+			return level
+		}
+	}
+	return -1
+}
+
+// Label returns the display name of the priority
+func (p TodoPriority) Label() string {
+	level := p.Level()
+	if level < 0 {
+		return string(p)
+	}
+	return priorityLabels[level]
+}
+
+// IsImportant reports whether the priority counts as important when the
+// todo does not say so itself
+func (p TodoPriority) IsImportant() bool {
+	level := p.Level()
+	return level >= 0 && level <= 1
+}
+
+// EisenhowerQuadrant is a quadrant of the urgent/important matrix
+type EisenhowerQuadrant string
+
+const (
+	QuadrantDo        EisenhowerQuadrant = "do"
+	QuadrantSchedule  EisenhowerQuadrant = "schedule"
+	QuadrantDelegate  EisenhowerQuadrant = "delegate"
+	QuadrantEliminate EisenhowerQuadrant = "eliminate"
 )
 
 // Todo represents a todo entity in the domain layer
 type Todo struct {
-	ID          int64        `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
-	UserID      int64        `json:"user_id" gorm:"type:bigint;not null;index"`
-	Title       string       `json:"title" gorm:"type:varchar(255);not null"`
-	Description string       `json:"description,omitempty" gorm:"type:text"`
-	DueDate     *time.Time   `json:"due_date,omitempty" gorm:"type:datetime"`
-	Status      TodoStatus   `json:"status" gorm:"type:varchar(20);not null;default:'not_started'"`
-	Priority    TodoPriority `json:"priority" gorm:"type:varchar(20);not null;default:'medium'"`
-	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt   *time.Time   `json:"deleted_at,omitempty" gorm:"index"`
+	ID     int64 `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	UserID int64 `json:"user_id" gorm:"type:bigint;not null;index"`
+	// OrganizationID is the tenant the todo belongs to
+	OrganizationID int64      `json:"organization_id" gorm:"type:bigint;not null;index"`
+	ParentID       *int64     `json:"parent_id,omitempty" gorm:"type:bigint;index"`
+	Title          string     `json:"title" gorm:"type:varchar(255);not null"`
+	Description    string     `json:"description,omitempty" gorm:"type:text"`
+	DueDate        *time.Time `json:"due_date,omitempty" gorm:"type:datetime"`
+	// AllDay todos are due on DueOn, a date in the owner's timezone;
+	// DueDate is then the last second of that day
+	AllDay bool  `json:"all_day" gorm:"not null;default:false"`
+	DueOn  *Date `json:"due_on,omitempty" gorm:"type:date"`
+	// StartDate is when work on the todo can begin, and ScheduledFor when
+	// the owner plans to do it; neither may be after DueDate
+	StartDate    *time.Time `json:"start_date,omitempty" gorm:"type:datetime"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty" gorm:"type:datetime"`
+	Status       TodoStatus `json:"status" gorm:"type:varchar(20);not null;default:'not_started'"`
+	// StatusCategory is the category of Status in the owner's workflow
+	StatusCategory StatusCategory `json:"status_category" gorm:"type:varchar(20);not null;default:'open'"`
+	Priority       TodoPriority   `json:"priority" gorm:"type:varchar(20);not null;default:'p2'"`
+	// Urgent and Important override what the Eisenhower matrix derives
+	// from the due date and the priority
+	Urgent       *bool      `json:"urgent,omitempty"`
+	Important    *bool      `json:"important,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" gorm:"type:datetime;index"`
+	OverdueAt    *time.Time `json:"overdue_at,omitempty" gorm:"type:datetime"`
+	EscalatedAt  *time.Time `json:"escalated_at,omitempty" gorm:"type:datetime"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty" gorm:"type:datetime;index"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	// ArchivedAt is only set on todos read from the archive
+	ArchivedAt *time.Time `json:"archived_at,omitempty" gorm:"->"`
+	// AssigneeIDs are the users the todo is assigned to, when loaded; nil
+	// means they were not
+	AssigneeIDs []int64 `json:"assignee_ids,omitempty" gorm:"-"`
+	// TagIDs are the tags the todo counts towards, its own tags and the tags
+	// they are nested under, when loaded; nil means they were not
+	TagIDs []int64 `json:"-" gorm:"-"`
 }
 
 // TableName returns the table name for GORM
 func (Todo) TableName() string {
 	return "todos"
 }
+
+// ArchivedTodo is the archive table's row of a todo, kept under the same ID.
+// It describes the table for migration; archived todos are read as Todos
+// with ArchivedAt set.
+type ArchivedTodo struct {
+	Todo
+	ArchivedAt time.Time `json:"archived_at" gorm:"type:datetime;not null;index"`
+}
+
+// TableName returns the table name for GORM
+func (ArchivedTodo) TableName() string {
+	return "archived_todos"
+}
+
+// TodoAssignee assigns a todo to a member of its organization. Assignees
+// see the todo and move it through its owner's workflow; only the owner
+// edits it otherwise.
+type TodoAssignee struct {
+	TodoID int64 `json:"todo_id" gorm:"type:bigint;not null;primaryKey"`
+	UserID int64 `json:"user_id" gorm:"type:bigint;not null;primaryKey"`
+	// AssignedBy is the user who made the assignment
+	AssignedBy int64     `json:"assigned_by" gorm:"type:bigint;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (TodoAssignee) TableName() string {
+	return "todo_assignees"
+}
+
+// ArchivedTodoAssignee assigns an archived todo to a user; it describes the
+// table for migration
+type ArchivedTodoAssignee struct {
+	TodoAssignee
+}
+
+// TableName returns the table name for GORM
+func (ArchivedTodoAssignee) TableName() string {
+	return "archived_todo_assignees"
+}
+
+// IsAssignedTo reports whether the todo's loaded assignees include the user
+func (t *Todo) IsAssignedTo(userID int64) bool {
+	for _, assigneeID := range t.AssigneeIDs {
+		if assigneeID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDelegated reports whether the todo's loaded assignees include someone
+// other than its owner
+func (t *Todo) IsDelegated() bool {
+	for _, assigneeID := range t.AssigneeIDs {
+		if assigneeID != t.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOverdue reports whether the todo is still open after its due date
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return !t.IsDone() && t.DueDate != nil && t.DueDate.Before(now)
+}
+
+// IsDone reports whether the todo is in a done state of its workflow
+func (t *Todo) IsDone() bool {
+	return t.StatusCategory == StatusCategoryDone
+}
+
+// IsArchived reports whether the todo was read from the archive
+func (t *Todo) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+// IsAvailable reports whether work on the todo can begin at now
+func (t *Todo) IsAvailable(now time.Time) bool {
+	return t.StartDate == nil || !t.StartDate.After(now)
+}
+
+// IsSnoozed reports whether the todo is hidden from listings at now
+func (t *Todo) IsSnoozed(now time.Time) bool {
+	return t.SnoozedUntil != nil && t.SnoozedUntil.After(now)
+}
+
+// EscalatePriority raises the priority by one level. It reports false when
+// the priority is already the highest.
+func (t *Todo) EscalatePriority() bool {
+	level := t.Priority.Level()
+	if level <= 0 {
+		return false
+	}
+	t.Priority = Priorities()[level-1]
+	return true
+}
+
+// IsImportant reports whether the todo is important: as flagged, or else
+// when its priority is p0 or p1
+func (t *Todo) IsImportant() bool {
+	if t.Important != nil {
+		return *t.Important
+	}
+	return t.Priority.IsImportant()
+}
+
+// IsUrgent reports whether the todo is urgent at now: as flagged, or else
+// when it is due before now plus within
+func (t *Todo) IsUrgent(now time.Time, within time.Duration) bool {
+	if t.Urgent != nil {
+		return *t.Urgent
+	}
+	return t.DueDate != nil && t.DueDate.Before(now.Add(within))
+}
+
+// Quadrant returns the Eisenhower quadrant of the todo at now
+func (t *Todo) Quadrant(now time.Time, urgentWithin time.Duration) EisenhowerQuadrant {
+	urgent := t.IsUrgent(now, urgentWithin)
+	switch {
+	case t.IsImportant() && urgent:
+		return QuadrantDo
+	case t.IsImportant():
+		return QuadrantSchedule
+	case urgent:
+		return QuadrantDelegate
+	default:
+		return QuadrantEliminate
+	}
+}
+
+// SetDueDate sets when the todo is due. All-day todos are due on the date
+// of dueDate as given, until the end of that day in loc.
+func (t *Todo) SetDueDate(dueDate time.Time, allDay bool, loc *time.Location) {
+	t.AllDay = allDay
+	if !allDay {
+		t.DueOn = nil
+		t.DueDate = &dueDate
+		return
+	}
+
+	day := DateOf(dueDate)
+	t.DueOn = &day
+	t.Rezone(loc)
+}
+
+// Rezone recomputes the due date of an all-day todo for the owner's timezone
+func (t *Todo) Rezone(loc *time.Location) {
+	if !t.AllDay || t.DueOn == nil {
+		return
+	}
+	end := t.DueOn.EndIn(loc)
+	t.DueDate = &end
+}
+
+// SetStatus moves the todo to a workflow state, stamping CompletedAt when
+// the todo enters a done state and clearing it when it is reopened
+func (t *Todo) SetStatus(state *WorkflowState, now time.Time) {
+	if state.Category == StatusCategoryDone {
+		if !t.IsDone() || t.CompletedAt == nil {
+			t.CompletedAt = &now
+		}
+	} else {
+		t.CompletedAt = nil
+	}
+	t.Status = state.Name
+	t.StatusCategory = state.Category
+}
@@ -6,14 +6,22 @@ import (
 
 // User represents a user entity in the domain layer
 type User struct {
-	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
-	Username     string     `json:"username" gorm:"type:varchar(255);uniqueIndex"`
-	Email        string     `json:"email" gorm:"type:varchar(255);uniqueIndex"`
-	PasswordHash string     `json:"-" gorm:"type:varchar(255);not null"`
-	Role         UserRole   `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
-	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID           int64    `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	Username     string   `json:"username" gorm:"type:varchar(255);uniqueIndex"`
+	Email        string   `json:"email" gorm:"type:varchar(255);uniqueIndex"`
+	PasswordHash string   `json:"-" gorm:"type:varchar(255);not null"`
+	Role         UserRole `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
+	// Timezone is the IANA zone due dates and days are interpreted in
+	Timezone string `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	// DigestFrequency opts the user into the digest email, sent at
+	// DigestHour local time every day or, when weekly, on DigestWeekday
+	DigestFrequency DigestFrequency `json:"digest_frequency" gorm:"type:varchar(10);not null;default:'off'"`
+	DigestHour      int             `json:"digest_hour" gorm:"type:tinyint;not null;default:7"`
+	DigestWeekday   time.Weekday    `json:"digest_weekday" gorm:"type:tinyint;not null;default:1"`
+	DigestSentAt    *time.Time      `json:"digest_sent_at,omitempty" gorm:"type:datetime"`
+	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       *time.Time      `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // UserRole represents the role of a user
@@ -24,7 +32,50 @@ const (
 	UserRoleUser  UserRole = "user"
 )
 
+// DigestFrequency represents how often a user receives the digest email
+type DigestFrequency string
+
+const (
+	DigestFrequencyOff    DigestFrequency = "off"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
 // TableName returns the table name for GORM
 func (User) TableName() string {
 	return "users"
 }
+
+// Location returns the user's timezone, or UTC when it is unset or unknown
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// DigestDue reports whether the user's digest should be sent at now: the
+// digest hour has been reached in the user's timezone, on the digest weekday
+// for weekly digests, and no digest was sent earlier that local day
+func (u *User) DigestDue(now time.Time) bool {
+	if u.DigestFrequency != DigestFrequencyDaily && u.DigestFrequency != DigestFrequencyWeekly {
+		return false
+	}
+
+	loc := u.Location()
+	local := now.In(loc)
+	if local.Hour() < u.DigestHour {
+		return false
+	}
+	if u.DigestFrequency == DigestFrequencyWeekly && local.Weekday() != u.DigestWeekday {
+		return false
+	}
+	if u.DigestSentAt != nil && DateOf(u.DigestSentAt.In(loc)) == DateOf(local) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook represents an outgoing webhook subscription owned by a user
+type Webhook struct {
+	ID     int64 `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	UserID int64 `json:"user_id" gorm:"type:bigint;not null;index"`
+	// OrganizationID is the tenant whose events the webhook receives
+	OrganizationID int64      `json:"organization_id" gorm:"type:bigint;not null;index"`
+	URL            string     `json:"url" gorm:"type:varchar(2048);not null"`
+	Secret         string     `json:"-" gorm:"type:varchar(255);not null"`
+	Events         string     `json:"events" gorm:"type:varchar(1024);not null"`
+	Description    string     `json:"description,omitempty" gorm:"type:varchar(255)"`
+	Active         bool       `json:"active" gorm:"not null;default:true"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// EventList returns the subscribed event patterns
+func (w *Webhook) EventList() []string {
+	if w.Events == "" {
+		return nil
+	}
+	return strings.Split(w.Events, ",")
+}
+
+// SetEventList stores the subscribed event patterns
+func (w *Webhook) SetEventList(events []string) {
+	w.Events = strings.Join(events, ",")
+}
+
+// WebhookDeliveryStatus represents the status of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery represents a single attempt history for delivering an event to a webhook
+type WebhookDelivery struct {
+	ID             int64                 `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	WebhookID      int64                 `json:"webhook_id" gorm:"type:bigint;not null;index"`
+	EventID        string                `json:"event_id" gorm:"type:varchar(36);not null;index"`
+	EventType      string                `json:"event_type" gorm:"type:varchar(50);not null"`
+	Payload        string                `json:"payload" gorm:"type:mediumtext;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	ResponseBody   string                `json:"response_body,omitempty" gorm:"type:text"`
+	LastError      string                `json:"last_error,omitempty" gorm:"type:text"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty" gorm:"type:datetime"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" gorm:"type:datetime"`
+	CreatedAt      time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// TableName returns the table name for GORM
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
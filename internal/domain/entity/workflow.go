@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// StatusCategory groups workflow states by how far along the todos in them are
+type StatusCategory string
+
+const (
+	StatusCategoryOpen   StatusCategory = "open"
+	StatusCategoryActive StatusCategory = "active"
+	StatusCategoryDone   StatusCategory = "done"
+)
+
+// IsValid reports whether the category is one of the known categories
+func (c StatusCategory) IsValid() bool {
+	switch c {
+	case StatusCategoryOpen, StatusCategoryActive, StatusCategoryDone:
+		return true
+	}
+	return false
+}
+
+// Workflow defines the statuses a user's todos move through. States are
+// ordered by Position; new todos start in the first state.
+type Workflow struct {
+	ID        int64           `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	UserID    int64           `json:"user_id" gorm:"type:bigint;not null;uniqueIndex"`
+	States    []WorkflowState `json:"states" gorm:"foreignKey:WorkflowID"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (Workflow) TableName() string {
+	return "workflows"
+}
+
+// WorkflowState is a status of a workflow. Transitions lists the states a
+// todo in this state may move to; a state without transitions is final.
+type WorkflowState struct {
+	ID          int64          `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	WorkflowID  int64          `json:"workflow_id" gorm:"type:bigint;not null;index"`
+	Position    int            `json:"position" gorm:"not null"`
+	Name        TodoStatus     `json:"name" gorm:"type:varchar(20);not null"`
+	Category    StatusCategory `json:"category" gorm:"type:varchar(20);not null"`
+	Transitions string         `json:"transitions" gorm:"type:varchar(1024)"`
+}
+
+// TableName returns the table name for GORM
+func (WorkflowState) TableName() string {
+	return "workflow_states"
+}
+
+// TransitionList returns the states a todo in this state may move to
+func (s *WorkflowState) TransitionList() []TodoStatus {
+	if s.Transitions == "" {
+		return nil
+	}
+	names := strings.Split(s.Transitions, ",")
+	statuses := make([]TodoStatus, len(names))
+	for i, name := range names {
+		statuses[i] = TodoStatus(name)
+	}
+	return statuses
+}
+
+// SetTransitionList stores the states a todo in this state may move to
+func (s *WorkflowState) SetTransitionList(statuses []TodoStatus) {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+	s.Transitions = strings.Join(names, ",")
+}
+
+// DefaultWorkflow returns the workflow of users who have not defined their
+// own: not_started, in_progress and completed, each reachable from the others
+func DefaultWorkflow(userID int64) *Workflow {
+	states := []WorkflowState{
+		{Position: 0, Name: TodoStatusNotStarted, Category: StatusCategoryOpen},
+		{Position: 1, Name: TodoStatusInProgress, Category: StatusCategoryActive},
+		{Position: 2, Name: TodoStatusCompleted, Category: StatusCategoryDone},
+	}
+	states[0].SetTransitionList([]TodoStatus{TodoStatusInProgress, TodoStatusCompleted})
+	states[1].SetTransitionList([]TodoStatus{TodoStatusNotStarted, TodoStatusCompleted})
+	states[2].SetTransitionList([]TodoStatus{TodoStatusNotStarted, TodoStatusInProgress})
+
+	return &Workflow{UserID: userID, States: states}
+}
+
+// InitialState returns the state new todos start in
+func (w *Workflow) InitialState() *WorkflowState {
+	if len(w.States) == 0 {
+		return nil
+	}
+	return &w.States[0]
+}
+
+// State returns the state with the given name
+func (w *Workflow) State(status TodoStatus) (*WorkflowState, bool) {
+	for i := range w.States {
+		if w.States[i].Name == status {
+			return &w.States[i], true
+		}
+	}
+	return nil, false
+}
+
+// Statuses returns the names of the workflow's states in order
+func (w *Workflow) Statuses() []TodoStatus {
+	statuses := make([]TodoStatus, len(w.States))
+	for i, state := range w.States {
+		statuses[i] = state.Name
+	}
+	return statuses
+}
+
+// CanTransition reports whether a todo may move from one status to another.
+// Staying in the same status is always allowed, and todos left in a status
+// that is no longer part of the workflow may move to any state.
+func (w *Workflow) CanTransition(from, to TodoStatus) bool {
+	if from == to {
+		return true
+	}
+
+	state, ok := w.State(from)
+	if !ok {
+		return true
+	}
+	for _, next := range state.TransitionList() {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
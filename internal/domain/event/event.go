@@ -0,0 +1,108 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type represents the type of a domain event
+type Type string
+
+const (
+	TodoCreated   Type = "todo.created"
+	TodoUpdated   Type = "todo.updated"
+	TodoCompleted Type = "todo.completed"
+	TodoDeleted   Type = "todo.deleted"
+	TodoOverdue   Type = "todo.overdue"
+	TodoArchived  Type = "todo.archived"
+	// TodoAssigned and TodoUnassigned are recorded for the assignee, so
+	// that they reach the assignee's webhooks and event stream
+	TodoAssigned   Type = "todo.assigned"
+	TodoUnassigned Type = "todo.unassigned"
+	// TodoMentioned is recorded for each user newly mentioned in a todo
+	TodoMentioned Type = "todo.mentioned"
+
+	TagCreated Type = "tag.created"
+	TagUpdated Type = "tag.updated"
+	TagDeleted Type = "tag.deleted"
+
+	UserCreated Type = "user.created"
+	UserDeleted Type = "user.deleted"
+)
+
+// AllTypes returns every event type that can be subscribed to
+func AllTypes() []Type {
+	return []Type{
+		TodoCreated,
+		TodoUpdated,
+		TodoCompleted,
+		TodoDeleted,
+		TodoOverdue,
+		TodoArchived,
+		TodoAssigned,
+		TodoUnassigned,
+		TodoMentioned,
+		TagCreated,
+		TagUpdated,
+		TagDeleted,
+		UserCreated,
+		UserDeleted,
+	}
+}
+
+// Event represents something that happened in the domain layer
+type Event struct {
+	ID     string `json:"id"`
+	Type   Type   `json:"type"`
+	UserID int64  `json:"user_id"`
+	// OrganizationID is the tenant the event happened in, zero for events
+	// outside of any organization
+	OrganizationID int64           `json:"organization_id,omitempty"`
+	AggregateType  string          `json:"aggregate_type"`
+	AggregateID    int64           `json:"aggregate_id"`
+	Data           json.RawMessage `json:"data"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+}
+
+// New creates a new event with a generated ID and the given payload
+func New(eventType Type, userID int64, aggregateType string, aggregateID int64, data interface{}) (*Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		ID:            uuid.New().String(),
+		Type:          eventType,
+		UserID:        userID,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Data:          payload,
+		OccurredAt:    time.Now().UTC(),
+	}, nil
+}
+
+// Matches reports whether the event type matches a subscription pattern.
+// Patterns may be an exact type ("todo.created"), a namespace wildcard
+// ("tag.*") or a global wildcard ("*").
+func (t Type) Matches(pattern string) bool {
+	if pattern == "*" || pattern == string(t) {
+		return true
+	}
+	if strings.HasSuffix(pattern, ".*") {
+		return strings.HasPrefix(string(t), strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// Publisher publishes domain events to interested consumers
+type Publisher interface {
+	Publish(ctx context.Context, evt *Event)
+}
+
+// Handler handles a published domain event
+type Handler func(ctx context.Context, evt *Event)
@@ -16,6 +16,27 @@ type UserRepository interface {
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, offset, limit int) ([]*entity.User, error)
+	// FindDigestSubscribers finds users opted into the digest email with an
+	// ID above afterID, in ID order
+	FindDigestSubscribers(ctx context.Context, afterID int64, limit int) ([]*entity.User, error)
+	MarkDigestSent(ctx context.Context, userID int64, sentAt time.Time) error
+}
+
+// OrganizationRepository defines the interface for organization and membership operations
+type OrganizationRepository interface {
+	// Create creates an organization together with its first member
+	Create(ctx context.Context, org *entity.Organization, owner *entity.OrganizationMember) error
+	FindByID(ctx context.Context, id int64) (*entity.Organization, error)
+	// FindMemberships finds a user's memberships with their organization,
+	// oldest first
+	FindMemberships(ctx context.Context, userID int64) ([]*entity.OrganizationMember, error)
+	FindMember(ctx context.Context, orgID, userID int64) (*entity.OrganizationMember, error)
+	// ListMembers finds an organization's members with their user
+	ListMembers(ctx context.Context, orgID int64) ([]*entity.OrganizationMember, error)
+	AddMember(ctx context.Context, member *entity.OrganizationMember) error
+	UpdateMember(ctx context.Context, member *entity.OrganizationMember) error
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+	CountMembersByRole(ctx context.Context, orgID int64, role entity.OrganizationRole) (int64, error)
 }
 
 // TodoRepository defines the interface for todo repository operations
@@ -28,18 +49,74 @@ type TodoRepository interface {
 	List(ctx context.Context, offset, limit int) ([]*entity.Todo, error)
 	FindByStatus(ctx context.Context, status entity.TodoStatus, offset, limit int) ([]*entity.Todo, error)
 	FindByDueDate(ctx context.Context, startDate, endDate *time.Time, offset, limit int) ([]*entity.Todo, error)
-	FindByUserIDAndFilters(ctx context.Context, userID int64, status *string, priority *string, dueDateFrom, dueDateTo *time.Time, sortBy, sortOrder string, offset, limit int) ([]*entity.Todo, int64, error)
+	FindByUserIDAndFilters(ctx context.Context, userID int64, filter TodoFilter, sortBy, sortOrder string, offset, limit int) ([]*entity.Todo, int64, error)
 	FindByFilters(ctx context.Context, status *string, priority *string, offset, limit int) ([]*entity.Todo, error)
+	GetStatsByUserID(ctx context.Context, userID int64, since, now time.Time, topTags int) (*entity.TodoStats, error)
+	FindOverdue(ctx context.Context, now time.Time, escalateBefore *time.Time, limit int) ([]*entity.Todo, error)
+	FindSnoozedByUserID(ctx context.Context, userID int64, now time.Time) ([]*entity.Todo, error)
+	// FindPlannedByUserID finds a user's open todos that have a due date or
+	// are scheduled
+	FindPlannedByUserID(ctx context.Context, userID int64) ([]*entity.Todo, error)
+	UpdateOverdueState(ctx context.Context, todo *entity.Todo) error
+	FindCompletedBefore(ctx context.Context, before time.Time, limit int) ([]*entity.Todo, error)
+	// FindCompletedBetween finds a user's todos completed in [from, to)
+	FindCompletedBetween(ctx context.Context, userID int64, from, to time.Time) ([]*entity.Todo, error)
+	Archive(ctx context.Context, ids []int64, archivedAt time.Time) error
+	Restore(ctx context.Context, id int64) error
+	FindArchivedByID(ctx context.Context, id int64) (*entity.Todo, error)
+	FindArchivedByUserID(ctx context.Context, userID int64, offset, limit int) ([]*entity.Todo, int64, error)
+	// CountByStatuses counts a user's todos, archived ones included, in any of the statuses
+	CountByStatuses(ctx context.Context, userID int64, statuses []entity.TodoStatus) (int64, error)
+	// UpdateStatusCategory moves a user's todos in a status to another
+	// category, stamping or clearing their completion time accordingly
+	UpdateStatusCategory(ctx context.Context, userID int64, status entity.TodoStatus, category entity.StatusCategory, now time.Time) error
+	// RezoneAllDay moves the due dates of a user's all-day todos to the end
+	// of their day in loc
+	RezoneAllDay(ctx context.Context, userID int64, loc *time.Location) error
+}
+
+// TodoFilter narrows a user's todo list. Nil fields are not applied.
+type TodoFilter struct {
+	Status      *string
+	Priority    *string
+	DueDateFrom *time.Time
+	DueDateTo   *time.Time
+	// OverdueAt keeps only open todos that were due before it
+	OverdueAt *time.Time
+	// ExcludeDone drops todos in a done state of their workflow
+	ExcludeDone bool
+	// VisibleAt hides todos snoozed beyond it
+	VisibleAt *time.Time
+	// AvailableAt hides todos whose start date is after it
+	AvailableAt *time.Time
+	// IncludeArchived also lists todos moved to the archive
+	IncludeArchived bool
+	// TagIDs keeps todos tagged with any of the tags, or with all of them
+	// when MatchAllTags is set. Tags nested under a tag count as the tag.
+	TagIDs       []int64
+	MatchAllTags bool
+	// ExcludeTagIDs drops todos tagged with any of the tags or their nested tags
+	ExcludeTagIDs []int64
+	// AssignedToUser lists the todos assigned to the user, whoever owns
+	// them, instead of the todos the user owns
+	AssignedToUser bool
+	// DelegatedByUser keeps the user's todos assigned to someone else
+	DelegatedByUser bool
 }
 
-// TagRepository defines the interface for tag repository operations
+// TagRepository defines the interface for tag repository operations.
+// Tag names are unique per owning user; nested tags are named by their path.
 type TagRepository interface {
 	Create(ctx context.Context, tag *entity.Tag) error
 	FindByID(ctx context.Context, id int64) (*entity.Tag, error)
-	FindByName(ctx context.Context, name string) (*entity.Tag, error)
+	FindByName(ctx context.Context, userID int64, name string) (*entity.Tag, error)
 	Update(ctx context.Context, tag *entity.Tag) error
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, offset, limit int) ([]*entity.Tag, error)
+	FindByUserID(ctx context.Context, userID int64, offset, limit int) ([]*entity.Tag, int64, error)
+	HasChildren(ctx context.Context, id int64) (bool, error)
+	FindByAlias(ctx context.Context, userID int64, name string) (*entity.Tag, error)
+	AddAliases(ctx context.Context, aliases []*entity.TagAlias) error
+	MoveAliases(ctx context.Context, fromTagIDs []int64, toTagID int64) error
 }
 
 // TodoTagRepository defines the interface for todo-tag relationship operations
@@ -47,7 +124,99 @@ type TodoTagRepository interface {
 	AddTagsToTodo(ctx context.Context, todoID int64, tagIDs []int64) error
 	RemoveTagsFromTodo(ctx context.Context, todoID int64, tagIDs []int64) error
 	ReplaceTagsForTodo(ctx context.Context, todoID int64, tagIDs []int64) error
+	MoveTags(ctx context.Context, fromTagIDs []int64, toTagID int64) error
 	GetTagsByTodoID(ctx context.Context, todoID int64) ([]*entity.Tag, error)
+	GetTagsByTodoIDs(ctx context.Context, todoIDs []int64) (map[int64][]*entity.Tag, error)
+	// GetTodosByTagID and GetTagStatsByUserID count a todo towards a tag
+	// when it is tagged with the tag or any tag nested under it
 	GetTodosByTagID(ctx context.Context, tagID int64, offset, limit int) ([]*entity.Todo, int64, error)
 	GetTagStatsByUserID(ctx context.Context, userID int64) (map[int64]int64, error)
+	// GetTagLineageIDsByTodoID gets the tags a todo, archived or not, counts
+	// towards in the same way
+	GetTagLineageIDsByTodoID(ctx context.Context, todoID int64) ([]int64, error)
+}
+
+// TodoAssigneeRepository defines the interface for todo assignment operations
+type TodoAssigneeRepository interface {
+	AddAssignees(ctx context.Context, assignees []*entity.TodoAssignee) error
+	RemoveAssignees(ctx context.Context, todoID int64, userIDs []int64) error
+	// FindUserIDsByTodoID finds the users a todo is assigned to, in the
+	// order they were assigned, whether the todo is archived or not
+	FindUserIDsByTodoID(ctx context.Context, todoID int64) ([]int64, error)
+}
+
+// NotificationRepository defines the interface for inbox operations. Users
+// only see the notifications of the current organization.
+type NotificationRepository interface {
+	// Create stores a notification, or does nothing when the user was already
+	// notified of the event, reporting whether it was stored
+	Create(ctx context.Context, notification *entity.Notification) (bool, error)
+	FindByUserID(ctx context.Context, userID int64, unreadOnly bool, offset, limit int) ([]*entity.Notification, int64, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID, id int64, readAt time.Time) error
+	// MarkAllRead marks every unread notification of the user as read,
+	// returning how many were
+	MarkAllRead(ctx context.Context, userID int64, readAt time.Time) (int64, error)
+}
+
+// NotificationPreferenceRepository defines the interface for notification
+// preference operations
+type NotificationPreferenceRepository interface {
+	FindByUserID(ctx context.Context, userID int64) ([]*entity.NotificationPreference, error)
+	// Save creates or replaces the preferences for their event types
+	Save(ctx context.Context, preferences []*entity.NotificationPreference) error
+}
+
+// WebhookRepository defines the interface for webhook subscription operations
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *entity.Webhook) error
+	FindByID(ctx context.Context, id int64) (*entity.Webhook, error)
+	FindByUserID(ctx context.Context, userID int64) ([]*entity.Webhook, error)
+	FindActiveByUserID(ctx context.Context, userID int64) ([]*entity.Webhook, error)
+	Update(ctx context.Context, webhook *entity.Webhook) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// WebhookDeliveryRepository defines the interface for webhook delivery log operations
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *entity.WebhookDelivery) error
+	FindByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+	FindByWebhookID(ctx context.Context, webhookID int64, offset, limit int) ([]*entity.WebhookDelivery, int64, error)
+	// FindStalled finds pending deliveries whose next attempt was due before the given time
+	FindStalled(ctx context.Context, before time.Time, limit int) ([]*entity.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+// TemplateRepository defines the interface for todo template operations.
+// Templates are always loaded with their items ordered by position.
+type TemplateRepository interface {
+	Create(ctx context.Context, template *entity.Template) error
+	FindByID(ctx context.Context, id int64) (*entity.Template, error)
+	FindByUserID(ctx context.Context, userID int64) ([]*entity.Template, error)
+	Update(ctx context.Context, template *entity.Template) error
+	ReplaceItems(ctx context.Context, templateID int64, items []entity.TemplateItem) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// WorkflowRepository defines the interface for status workflow operations.
+// Workflows are always loaded with their states ordered by position.
+type WorkflowRepository interface {
+	FindByUserID(ctx context.Context, userID int64) (*entity.Workflow, error)
+	Save(ctx context.Context, workflow *entity.Workflow) error
+	DeleteByUserID(ctx context.Context, userID int64) error
+}
+
+// TransactionManager runs a unit of work inside a database transaction.
+// Repository calls made with the context passed to fn join the transaction.
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OutboxRepository defines the interface for transactional outbox operations
+type OutboxRepository interface {
+	Create(ctx context.Context, message *entity.OutboxMessage) error
+	FindUnpublished(ctx context.Context, limit int) ([]*entity.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, lastError string) error
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
 }
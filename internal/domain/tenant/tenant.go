@@ -0,0 +1,37 @@
+// Package tenant carries the organization a request acts in. Repositories
+// restrict their queries to the tenant bound to the context and refuse
+// contexts bound to none, so that a missing binding cannot expose every
+// tenant. Code that must cross tenants says so with Unscoped.
+package tenant
+
+import "context"
+
+type contextKey struct{}
+
+// allTenants is bound to contexts that may cross tenants
+type allTenants struct{}
+
+// WithID returns a copy of ctx bound to the organization tenantID
+func WithID(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenantID)
+}
+
+// FromContext returns the organization ctx is bound to
+func FromContext(ctx context.Context) (int64, bool) {
+	tenantID, ok := ctx.Value(contextKey{}).(int64)
+	return tenantID, ok
+}
+
+// Unscoped returns a copy of ctx that sees every organization, for the few
+// lookups that must cross tenants, such as finding a user to invite, and for
+// the background jobs that work through all tenants: overdue detection,
+// archiving, digests, workflow changes and notifications
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, allTenants{})
+}
+
+// IsUnscoped reports whether ctx was returned by Unscoped
+func IsUnscoped(ctx context.Context) bool {
+	_, ok := ctx.Value(contextKey{}).(allTenants)
+	return ok
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	redisv8 "github.com/go-redis/redis/v8"
+)
+
+const (
+	// AgendaSetSuffix is the suffix of a user's agenda sorted set
+	AgendaSetSuffix = ":agenda"
+
+	// Agenda members are a todo ID prefixed by the date the member is
+	// scored by
+	agendaDuePrefix       = "due:"
+	agendaScheduledPrefix = "scheduled:"
+)
+
+// BuildAgendaSetKey builds the key of a user's agenda: their open todos
+// scored by the time they are due, and again by the time they are scheduled
+// for. Scores are instants, so the set does not depend on the user's timezone.
+func BuildAgendaSetKey(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d%s", TodoSortedSetPrefix, userID, AgendaSetSuffix))
+}
+
+// agendaMembers returns the agenda members of a todo, empty for todos that
+// are done or have neither a due date nor a scheduled date
+func agendaMembers(todo *entity.Todo) []*redisv8.Z {
+	if todo.IsDone() || todo.DeletedAt != nil {
+		return nil
+	}
+
+	var members []*redisv8.Z
+	if todo.DueDate != nil {
+		members = append(members, &redisv8.Z{Score: float64(todo.DueDate.Unix()), Member: agendaDuePrefix + strconv.FormatInt(todo.ID, 10)})
+	}
+	if todo.ScheduledFor != nil {
+		members = append(members, &redisv8.Z{Score: float64(todo.ScheduledFor.Unix()), Member: agendaScheduledPrefix + strconv.FormatInt(todo.ID, 10)})
+	}
+	return members
+}
+
+// removeFromAgendaWithPipeline removes a todo from its owner's agenda
+func removeFromAgendaWithPipeline(ctx context.Context, pipe redisv8.Pipeliner, todoID, userID int64) {
+	id := strconv.FormatInt(todoID, 10)
+	pipe.ZRem(ctx, BuildAgendaSetKey(tenantOf(ctx), userID), agendaDuePrefix+id, agendaScheduledPrefix+id)
+}
+
+// updateAgendaWithPipeline moves a todo to its current dates in its owner's
+// agenda. An agenda that is not cached is left to be rebuilt on its next read.
+func (tc *TodoCache) updateAgendaWithPipeline(ctx context.Context, pipe redisv8.Pipeliner, todo *entity.Todo) {
+	key := BuildAgendaSetKey(tenantOf(ctx), todo.UserID)
+	exists, err := tc.redisClient.Exists(ctx, key)
+	if err != nil || exists == 0 {
+		return
+	}
+
+	removeFromAgendaWithPipeline(ctx, pipe, todo.ID, todo.UserID)
+	if members := agendaMembers(todo); len(members) > 0 {
+		pipe.ZAdd(ctx, key, members...)
+	}
+}
+
+// GetAgenda returns a user's open todos that are due by to, overdue ones
+// included, or scheduled between from and to
+func (tc *TodoCache) GetAgenda(ctx context.Context, userID int64, from, to time.Time) ([]*entity.Todo, error) {
+	key := BuildAgendaSetKey(tenantOf(ctx), userID)
+
+	exists, err := tc.redisClient.Exists(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		if err := tc.rebuildAgenda(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := tc.redisClient.GetClient().ZRangeByScoreWithScores(ctx, key, &redisv8.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(to.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []*entity.Todo
+	seen := make(map[int64]bool, len(members))
+	for _, member := range members {
+		name, _ := member.Member.(string)
+		idStr := strings.TrimPrefix(name, agendaDuePrefix)
+		if strings.HasPrefix(name, agendaScheduledPrefix) {
+			// Only due dates carry forward from before the range
+			if member.Score < float64(from.Unix()) {
+				continue
+			}
+			idStr = strings.TrimPrefix(name, agendaScheduledPrefix)
+		}
+
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		todo, err := tc.getTodoFromCacheOrDB(ctx, id)
+		if err != nil {
+			// Deleted in the meantime
+			log.Printf("Warning: failed to load agenda todo %d: %v", id, err)
+			continue
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, nil
+}
+
+// rebuildAgenda reloads a user's agenda from the database
+func (tc *TodoCache) rebuildAgenda(ctx context.Context, userID int64) error {
+	key := BuildAgendaSetKey(tenantOf(ctx), userID)
+
+	_, err, _ := tc.rebuildSortedSetFlight.Do(key, func() (interface{}, error) {
+		todos, err := tc.todoRepo.FindPlannedByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		pipe := tc.redisClient.Pipeline()
+		pipe.Del(ctx, key)
+		for _, todo := range todos {
+			if members := agendaMembers(todo); len(members) > 0 {
+				pipe.ZAdd(ctx, key, members...)
+			}
+		}
+		pipe.Expire(ctx, key, tc.sortedSetTTL)
+
+		_, err = tc.redisClient.ExecPipeline(pipe)
+		return nil, err
+	})
+	return err
+}
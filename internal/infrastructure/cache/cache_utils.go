@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -11,6 +12,8 @@ import (
 	"time"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
 )
 
 // ListFilter represents filter parameters for todo list queries
@@ -20,15 +23,65 @@ type ListFilter struct {
 	Search      string
 	DueDateFrom *time.Time
 	DueDateTo   *time.Time
+	Overdue     bool
+	// IncludeSnoozed also lists todos snoozed into the future
+	IncludeSnoozed bool
+	// Available keeps only todos whose start date has passed
+	Available bool
+	// IncludeArchived also lists todos moved to the archive
+	IncludeArchived bool
+	// TagIDs keeps todos tagged with any, or all when MatchAllTags is set,
+	// of the tags or their nested tags
+	TagIDs       []int64
+	MatchAllTags bool
+	// ExcludeTagIDs drops todos tagged with any of the tags or their nested tags
+	ExcludeTagIDs []int64
+	// AssignedToMe lists the todos assigned to the user instead of their own
+	AssignedToMe bool
+	// DelegatedByMe keeps the user's todos assigned to someone else
+	DelegatedByMe bool
 }
 
-// Key prefix constants
+// RepositoryFilter converts the filter for a database query evaluated at now
+func (f *ListFilter) RepositoryFilter(now time.Time) repository.TodoFilter {
+	filter := repository.TodoFilter{
+		Status:          f.Status,
+		Priority:        f.Priority,
+		DueDateFrom:     f.DueDateFrom,
+		DueDateTo:       f.DueDateTo,
+		IncludeArchived: f.IncludeArchived,
+		TagIDs:          f.TagIDs,
+		MatchAllTags:    f.MatchAllTags,
+		ExcludeTagIDs:   f.ExcludeTagIDs,
+		AssignedToUser:  f.AssignedToMe,
+		DelegatedByUser: f.DelegatedByMe,
+	}
+	if f.Overdue {
+		filter.OverdueAt = &now
+	}
+	if !f.IncludeSnoozed {
+		filter.VisibleAt = &now
+	}
+	if f.Available {
+		filter.AvailableAt = &now
+	}
+	return filter
+}
+
+// Key prefix constants. Every cache key is further prefixed by its tenant,
+// see TenantKeyPrefix.
 const (
+	// TenantKeyPrefix scopes cache keys to an organization, so that a tenant
+	// never reads what was cached for another one
+	TenantKeyPrefix = "tenant:"
+
 	// Todo cache keys
 	TodoHashKeyPrefix    = "cache:todo:"
 	TodoSortedSetPrefix  = "cache:todos:user:"
 	TodoQueryCachePrefix = "cache:todos:user:"
 	QueryCacheSuffix     = ":query:"
+	SnoozedSetSuffix     = ":snoozed"
+	WorkflowSuffix       = ":workflow"
 
 	// Tag cache keys
 	TagStringKeyPrefix   = "cache:tag:"
@@ -39,11 +92,25 @@ const (
 	LockKeyPrefix = "lock:"
 )
 
+// tenantOf returns the tenant bound to ctx, 0 when there is none
+func tenantOf(ctx context.Context) int64 {
+	tenantID, _ := tenant.FromContext(ctx)
+	return tenantID
+}
+
+// tenantKey prefixes a cache key by its tenant
+func tenantKey(tenantID int64, key string) string {
+	return fmt.Sprintf("%s%d:%s", TenantKeyPrefix, tenantID, key)
+}
+
 // buildSortedSetKey builds a sorted set key for todos
-func BuildSortedSetKey(userID int64, filters *ListFilter, sortBy, sortOrder string) string {
-	base := fmt.Sprintf("%s%d:sorted:", TodoSortedSetPrefix, userID)
+func BuildSortedSetKey(tenantID, userID int64, filters *ListFilter, sortBy, sortOrder string) string {
+	base := tenantKey(tenantID, fmt.Sprintf("%s%d:sorted:", TodoSortedSetPrefix, userID))
 
 	if filters != nil {
+		if len(filters.TagIDs) == 1 {
+			base += fmt.Sprintf("tag:%d:", filters.TagIDs[0])
+		}
 		if filters.Status != nil {
 			base += fmt.Sprintf("status:%s:", *filters.Status)
 		}
@@ -57,7 +124,7 @@ func BuildSortedSetKey(userID int64, filters *ListFilter, sortBy, sortOrder stri
 }
 
 // buildQueryCacheKey builds a query cache key for complex todo queries
-func BuildQueryCacheKey(userID int64, filters *ListFilter, sortBy, sortOrder string, page, limit int) string {
+func BuildQueryCacheKey(tenantID, userID int64, filters *ListFilter, sortBy, sortOrder string, page, limit int) string {
 	queryData := map[string]interface{}{
 		"user_id":    userID,
 		"status":     filters,
@@ -72,27 +139,56 @@ func BuildQueryCacheKey(userID int64, filters *ListFilter, sortBy, sortOrder str
 	}
 
 	hash := CalculateHash(queryData)
-	return fmt.Sprintf("%s%d%s%s", TodoQueryCachePrefix, userID, QueryCacheSuffix, hash)
+	return tenantKey(tenantID, fmt.Sprintf("%s%d%s%s", TodoQueryCachePrefix, userID, QueryCacheSuffix, hash))
+}
+
+// BuildQueryCachePattern matches all of a user's query caches
+func BuildQueryCachePattern(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d%s*", TodoQueryCachePrefix, userID, QueryCacheSuffix))
+}
+
+// BuildUserTodoListsPattern matches all of a user's sorted sets, query
+// caches, snoozed set, agenda and workflow statuses, which share the user's
+// prefix
+func BuildUserTodoListsPattern(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d:*", TodoSortedSetPrefix, userID))
+}
+
+// BuildSnoozedSetKey builds the key of a user's snoozed todos, scored by
+// the time they wake up
+func BuildSnoozedSetKey(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d%s", TodoSortedSetPrefix, userID, SnoozedSetSuffix))
+}
+
+// BuildWorkflowKey builds the key of the list of the statuses of a user's
+// workflow, in order
+func BuildWorkflowKey(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d%s", TodoSortedSetPrefix, userID, WorkflowSuffix))
 }
 
 // buildTodoHashKey builds a hash key for a single todo
-func BuildTodoHashKey(todoID int64) string {
-	return fmt.Sprintf("%s%d", TodoHashKeyPrefix, todoID)
+func BuildTodoHashKey(tenantID, todoID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d", TodoHashKeyPrefix, todoID))
 }
 
 // buildTagStringKey builds a string key for a single tag
-func BuildTagStringKey(tagID int64) string {
-	return fmt.Sprintf("%s%d", TagStringKeyPrefix, tagID)
+func BuildTagStringKey(tenantID, tagID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d", TagStringKeyPrefix, tagID))
 }
 
-// buildTagListKey builds a string key for tag list
-func BuildTagListKey(page, limit int) string {
-	return fmt.Sprintf("%spage:%d:limit:%d", TagListKeyPrefix, page, limit)
+// buildTagListKey builds a string key for a page of a user's tag list
+func BuildTagListKey(tenantID, userID int64, page, limit int) string {
+	return tenantKey(tenantID, fmt.Sprintf("%suser:%d:page:%d:limit:%d", TagListKeyPrefix, userID, page, limit))
+}
+
+// BuildTagListPattern matches all pages of a user's tag list
+func BuildTagListPattern(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%suser:%d:page:*:limit:*", TagListKeyPrefix, userID))
 }
 
 // buildUserTagsKey builds a string key for user tags
-func BuildUserTagsKey(userID int64) string {
-	return fmt.Sprintf("%s%d", TagUserTagsKeyPrefix, userID)
+func BuildUserTagsKey(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d", TagUserTagsKeyPrefix, userID))
 }
 
 // buildLockKey builds a lock key
@@ -112,6 +208,12 @@ func GetTodoScore(todo *entity.Todo, sortBy, sortOrder string) float64 {
 	case "title":
 		return getTitleScore(todo.Title)
 
+	case "priority":
+		return getPriorityScore(todo.Priority, sortOrder)
+
+	case "scheduled_for":
+		return getScheduledForScore(todo.ScheduledFor, sortOrder)
+
 	default:
 		// Default to created_at descending
 		return getCreatedAtScore(todo.CreatedAt, "desc")
@@ -135,6 +237,12 @@ func getDueDateScore(dueDate *time.Time, sortOrder string) float64 {
 	return float64(timestamp)
 }
 
+// getScheduledForScore calculates score for scheduled_for sorting; todos
+// that are not scheduled come last in ascending order
+func getScheduledForScore(scheduledFor *time.Time, sortOrder string) float64 {
+	return getDueDateScore(scheduledFor, sortOrder)
+}
+
 // getCreatedAtScore calculates score for created_at sorting
 func getCreatedAtScore(createdAt time.Time, sortOrder string) float64 {
 	timestamp := createdAt.Unix()
@@ -144,6 +252,16 @@ func getCreatedAtScore(createdAt time.Time, sortOrder string) float64 {
 	return float64(timestamp)
 }
 
+// getPriorityScore calculates score for priority sorting; ascending order
+// lists the most important level (p0) first
+func getPriorityScore(priority entity.TodoPriority, sortOrder string) float64 {
+	level := float64(priority.Level())
+	if sortOrder == "desc" {
+		return -level
+	}
+	return level
+}
+
 // getTitleScore converts title to a numeric score using FNV hash
 func getTitleScore(title string) float64 {
 	h := fnv.New32a()
@@ -151,22 +269,78 @@ func getTitleScore(title string) float64 {
 	return float64(h.Sum32())
 }
 
-// getAllSortedSetKeys returns all sorted set keys for a user
-func GetAllSortedSetKeys(userID int64) []string {
-	base := fmt.Sprintf("%s%d:sorted:", TodoSortedSetPrefix, userID)
+// getAllSortedSetKeys returns all sorted set keys for a user whose workflow
+// has the given statuses
+func GetAllSortedSetKeys(tenantID, userID int64, statuses []entity.TodoStatus) []string {
+	configs := sortedSetConfigs(statuses)
+	keys := make([]string, len(configs))
+	for i, config := range configs {
+		keys[i] = BuildSortedSetKey(tenantID, userID, config.filters, config.sortBy, config.sortOrder)
+	}
+	return keys
+}
+
+// sortedSetConfig describes a sorted set kept up to date on writes
+type sortedSetConfig struct {
+	filters   *ListFilter
+	sortBy    string
+	sortOrder string
+}
 
-	keys := []string{
-		base + "due_date:asc",
-		base + "due_date:desc",
-		base + "created_at:desc",
-		base + "title:asc",
-		base + "status:not_started:due_date:asc",
-		base + "status:in_progress:due_date:asc",
-		base + "status:completed:due_date:asc",
-		base + "priority:high:due_date:asc",
+// sortedSetConfigs lists the sorted sets of a user whose workflow has the
+// given statuses: the base sorted sets, one per status and one per
+// priority level
+func sortedSetConfigs(statuses []entity.TodoStatus) []sortedSetConfig {
+	configs := []sortedSetConfig{
+		// Base sorted sets
+		{nil, "due_date", "asc"},
+		{nil, "due_date", "desc"},
+		{nil, "created_at", "desc"},
+		{nil, "title", "asc"},
+		{nil, "priority", "asc"},
+		{nil, "scheduled_for", "asc"},
 	}
 
-	return keys
+	// Status filtered sorted sets
+	for _, status := range statuses {
+		configs = append(configs, sortedSetConfig{&ListFilter{Status: strPtr(string(status))}, "due_date", "asc"})
+	}
+
+	// Priority filtered sorted sets
+	for _, priority := range entity.Priorities() {
+		configs = append(configs, sortedSetConfig{&ListFilter{Priority: strPtr(string(priority))}, "due_date", "asc"})
+	}
+
+	return configs
+}
+
+// tagSortedSetConfigs lists the sorted sets of the todos counting towards a
+// tag, one for each way they can be sorted. They are only kept up to date
+// while cached.
+func tagSortedSetConfigs(tagID int64) []sortedSetConfig {
+	filters := &ListFilter{TagIDs: []int64{tagID}}
+	var configs []sortedSetConfig
+	for sortBy := range sortedSetSortFields {
+		for _, sortOrder := range []string{"asc", "desc"} {
+			configs = append(configs, sortedSetConfig{filters, sortBy, sortOrder})
+		}
+	}
+	return configs
+}
+
+// matchesSortedSet reports whether a todo belongs in a sorted set with the
+// given filters
+func matchesSortedSet(todo *entity.Todo, filters *ListFilter) bool {
+	if filters == nil {
+		return true
+	}
+	if filters.Status != nil && string(todo.Status) != *filters.Status {
+		return false
+	}
+	if filters.Priority != nil && string(todo.Priority) != *filters.Priority {
+		return false
+	}
+	return true
 }
 
 // parseTodoFromHash parses a todo entity from hash fields
@@ -195,6 +369,23 @@ func ParseTodoFromHash(fields map[string]string) (*entity.Todo, error) {
 		todo.UserID = userID
 	}
 
+	// Parse OrganizationID
+	if organizationIDStr, ok := fields["organization_id"]; ok {
+		organizationID, err := strconv.ParseInt(organizationIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse organization_id: %w", err)
+		}
+		todo.OrganizationID = organizationID
+	}
+
+	// Parse ParentID
+	if parentIDStr, ok := fields["parent_id"]; ok && parentIDStr != "" {
+		parentID, err := strconv.ParseInt(parentIDStr, 10, 64)
+		if err == nil {
+			todo.ParentID = &parentID
+		}
+	}
+
 	// Parse basic fields
 	if title, ok := fields["title"]; ok {
 		todo.Title = title
@@ -208,10 +399,24 @@ func ParseTodoFromHash(fields map[string]string) (*entity.Todo, error) {
 		todo.Status = entity.TodoStatus(status)
 	}
 
+	if category, ok := fields["status_category"]; ok {
+		todo.StatusCategory = entity.StatusCategory(category)
+	}
+
 	if priority, ok := fields["priority"]; ok {
 		todo.Priority = entity.TodoPriority(priority)
 	}
 
+	if urgent, ok := fields["urgent"]; ok && urgent != "" {
+		value := urgent == "1"
+		todo.Urgent = &value
+	}
+
+	if important, ok := fields["important"]; ok && important != "" {
+		value := important == "1"
+		todo.Important = &value
+	}
+
 	// Parse DueDate
 	if dueDateStr, ok := fields["due_date"]; ok && dueDateStr != "" {
 		timestamp, err := strconv.ParseInt(dueDateStr, 10, 64)
@@ -221,6 +426,53 @@ func ParseTodoFromHash(fields map[string]string) (*entity.Todo, error) {
 		}
 	}
 
+	if allDay, ok := fields["all_day"]; ok {
+		todo.AllDay = allDay == "1"
+	}
+
+	if dueOnStr, ok := fields["due_on"]; ok && dueOnStr != "" {
+		dueOn, err := entity.ParseDate(dueOnStr)
+		if err == nil {
+			todo.DueOn = &dueOn
+		}
+	}
+
+	// Parse StartDate
+	if startDateStr, ok := fields["start_date"]; ok && startDateStr != "" {
+		timestamp, err := strconv.ParseInt(startDateStr, 10, 64)
+		if err == nil {
+			startDate := time.Unix(timestamp, 0)
+			todo.StartDate = &startDate
+		}
+	}
+
+	// Parse ScheduledFor
+	if scheduledForStr, ok := fields["scheduled_for"]; ok && scheduledForStr != "" {
+		timestamp, err := strconv.ParseInt(scheduledForStr, 10, 64)
+		if err == nil {
+			scheduledFor := time.Unix(timestamp, 0)
+			todo.ScheduledFor = &scheduledFor
+		}
+	}
+
+	// Parse SnoozedUntil
+	if snoozedUntilStr, ok := fields["snoozed_until"]; ok && snoozedUntilStr != "" {
+		timestamp, err := strconv.ParseInt(snoozedUntilStr, 10, 64)
+		if err == nil {
+			snoozedUntil := time.Unix(timestamp, 0)
+			todo.SnoozedUntil = &snoozedUntil
+		}
+	}
+
+	// Parse CompletedAt
+	if completedAtStr, ok := fields["completed_at"]; ok && completedAtStr != "" {
+		timestamp, err := strconv.ParseInt(completedAtStr, 10, 64)
+		if err == nil {
+			completedAt := time.Unix(timestamp, 0)
+			todo.CompletedAt = &completedAt
+		}
+	}
+
 	// Parse CreatedAt
 	if createdAtStr, ok := fields["created_at"]; ok && createdAtStr != "" {
 		timestamp, err := strconv.ParseInt(createdAtStr, 10, 64)
@@ -243,26 +495,69 @@ func ParseTodoFromHash(fields map[string]string) (*entity.Todo, error) {
 // buildPipelineTodoHash builds a hash fields map from a todo entity
 func BuildPipelineTodoHash(todo *entity.Todo) map[string]interface{} {
 	fields := map[string]interface{}{
-		"id":         todo.ID,
-		"user_id":    todo.UserID,
-		"title":      todo.Title,
-		"status":     string(todo.Status),
-		"priority":   string(todo.Priority),
-		"created_at": todo.CreatedAt.Unix(),
-		"updated_at": todo.UpdatedAt.Unix(),
+		"id":              todo.ID,
+		"user_id":         todo.UserID,
+		"organization_id": todo.OrganizationID,
+		"title":           todo.Title,
+		"status":          string(todo.Status),
+		"status_category": string(todo.StatusCategory),
+		"priority":        string(todo.Priority),
+		"urgent":          optionalBoolField(todo.Urgent),
+		"important":       optionalBoolField(todo.Important),
+		"all_day":         optionalBoolField(&todo.AllDay),
+		"due_on":          "",
+		"created_at":      todo.CreatedAt.Unix(),
+		"updated_at":      todo.UpdatedAt.Unix(),
 	}
 
 	if todo.Description != "" {
 		fields["description"] = todo.Description
 	}
 
+	if todo.ParentID != nil {
+		fields["parent_id"] = *todo.ParentID
+	}
+
 	if todo.DueDate != nil {
 		fields["due_date"] = todo.DueDate.Unix()
 	}
 
+	if todo.DueOn != nil {
+		fields["due_on"] = todo.DueOn.String()
+	}
+
+	if todo.StartDate != nil {
+		fields["start_date"] = todo.StartDate.Unix()
+	}
+
+	if todo.ScheduledFor != nil {
+		fields["scheduled_for"] = todo.ScheduledFor.Unix()
+	}
+
+	if todo.CompletedAt != nil {
+		fields["completed_at"] = todo.CompletedAt.Unix()
+	}
+
+	if todo.SnoozedUntil != nil {
+		fields["snoozed_until"] = todo.SnoozedUntil.Unix()
+	}
+
 	return fields
 }
 
+// optionalBoolField encodes an optional flag as a hash field, so that
+// clearing the flag overwrites the previous value
+func optionalBoolField(value *bool) string {
+	switch {
+	case value == nil:
+		return ""
+	case *value:
+		return "1"
+	default:
+		return "0"
+	}
+}
+
 // parseTagFromHash parses a tag entity from hash fields
 func ParseTagFromHash(fields map[string]string) (*entity.Tag, error) {
 	if len(fields) == 0 {
@@ -328,20 +623,52 @@ func ShouldUseSortedSet(filters *ListFilter, sortBy string) bool {
 			return false
 		}
 
+		// Overdue and availability depend on the current time
+		if filters.Overdue || filters.Available {
+			return false
+		}
+
+		// Sorted sets only hold todos that are not snoozed
+		if filters.IncludeSnoozed {
+			return false
+		}
+
+		// Archived todos are never cached in sorted sets
+		if filters.IncludeArchived {
+			return false
+		}
+
+		// Sorted sets only hold the user's own todos, whoever they are
+		// assigned to; assignment views are query cached instead
+		if filters.AssignedToMe || filters.DelegatedByMe {
+			return false
+		}
+
 		// Multiple filters (status + priority together)
 		if filters.Status != nil && filters.Priority != nil {
 			return false
 		}
-	}
 
-	// Valid sort fields
-	validSortFields := map[string]bool{
-		"due_date":   true,
-		"created_at": true,
-		"title":      true,
+		// Only single tag views without other filters get their own sorted set
+		if len(filters.TagIDs) > 1 || len(filters.ExcludeTagIDs) > 0 {
+			return false
+		}
+		if len(filters.TagIDs) == 1 && (filters.Status != nil || filters.Priority != nil) {
+			return false
+		}
 	}
 
-	return validSortFields[sortBy]
+	return sortedSetSortFields[sortBy]
+}
+
+// sortedSetSortFields are the fields todo lists can be sorted by in sorted sets
+var sortedSetSortFields = map[string]bool{
+	"due_date":   true,
+	"created_at": true,
+	"title":      true,
+	"priority":   true,
+	// Scheduled dates only change on writes, which rescore the sorted set
+	"scheduled_for": true,
 }
 
 // calculateHash calculates MD5 hash of query parameters
@@ -356,15 +683,15 @@ func CalculateHash(data interface{}) string {
 }
 
 // buildStatusChangeKeys returns old and new sorted set keys for status change
-func BuildStatusChangeKeys(userID int64, oldStatus, newStatus string) []string {
-	oldKey := fmt.Sprintf("%s%d:sorted:status:%s:due_date:asc", TodoSortedSetPrefix, userID, oldStatus)
-	newKey := fmt.Sprintf("%s%d:sorted:status:%s:due_date:asc", TodoSortedSetPrefix, userID, newStatus)
+func BuildStatusChangeKeys(tenantID, userID int64, oldStatus, newStatus string) []string {
+	oldKey := tenantKey(tenantID, fmt.Sprintf("%s%d:sorted:status:%s:due_date:asc", TodoSortedSetPrefix, userID, oldStatus))
+	newKey := tenantKey(tenantID, fmt.Sprintf("%s%d:sorted:status:%s:due_date:asc", TodoSortedSetPrefix, userID, newStatus))
 
 	return []string{oldKey, newKey}
 }
 
 // buildPrioritySortedSetKey builds a sorted set key with priority filter
-func BuildPrioritySortedSetKey(userID int64, priority, sortBy, sortOrder string) string {
-	base := fmt.Sprintf("%s%d:sorted:priority:%s:", TodoSortedSetPrefix, userID, priority)
+func BuildPrioritySortedSetKey(tenantID, userID int64, priority, sortBy, sortOrder string) string {
+	base := tenantKey(tenantID, fmt.Sprintf("%s%d:sorted:priority:%s:", TodoSortedSetPrefix, userID, priority))
 	return base + fmt.Sprintf("%s:%s", sortBy, sortOrder)
 }
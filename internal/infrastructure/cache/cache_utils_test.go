@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheKeys_ScopedByTenant(t *testing.T) {
+	assert.Equal(t, "tenant:1:cache:todo:42", BuildTodoHashKey(1, 42))
+	assert.NotEqual(t, BuildTodoHashKey(1, 42), BuildTodoHashKey(2, 42))
+	assert.NotEqual(t, BuildTagStringKey(1, 5), BuildTagStringKey(2, 5))
+	assert.NotEqual(t, BuildStatsKey(1, 7, 30), BuildStatsKey(2, 7, 30))
+	assert.NotEqual(t, BuildTagUsageKey(1, 7), BuildTagUsageKey(2, 7))
+	assert.NotEqual(t,
+		BuildQueryCacheKey(1, 7, &ListFilter{}, "due_date", "asc", 1, 20),
+		BuildQueryCacheKey(2, 7, &ListFilter{}, "due_date", "asc", 1, 20))
+
+	// A user's patterns only match their keys in the same tenant
+	pattern := strings.TrimSuffix(BuildUserTodoListsPattern(1, 7), "*")
+	for _, key := range GetAllSortedSetKeys(1, 7, []entity.TodoStatus{entity.TodoStatusNotStarted}) {
+		assert.True(t, strings.HasPrefix(key, pattern), key)
+	}
+	assert.True(t, strings.HasPrefix(BuildAgendaSetKey(1, 7), pattern))
+	assert.False(t, strings.HasPrefix(BuildSortedSetKey(2, 7, nil, "due_date", "asc"), pattern))
+}
+
+func TestTenantOf(t *testing.T) {
+	assert.Equal(t, int64(0), tenantOf(context.Background()))
+	assert.Equal(t, int64(3), tenantOf(tenant.WithID(context.Background(), 3)))
+}
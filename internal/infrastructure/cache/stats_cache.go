@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+// StatsKeyPrefix is the prefix of cached per-user stats
+const StatsKeyPrefix = "cache:stats:user:"
+
+// BuildStatsKey builds the cache key for a user's stats over a number of days
+func BuildStatsKey(tenantID, userID int64, days int) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d:days:%d", StatsKeyPrefix, userID, days))
+}
+
+// StatsCache caches computed stats for a short time. Entries are not
+// invalidated on writes; they simply expire.
+type StatsCache struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+// NewStatsCache creates a new stats cache instance
+func NewStatsCache(redisClient *redis.Client, ttl time.Duration) *StatsCache {
+	return &StatsCache{
+		redisClient: redisClient,
+		ttl:         ttl,
+	}
+}
+
+// Get returns cached stats, or false on a miss
+func (sc *StatsCache) Get(ctx context.Context, userID int64, days int) (*dto.StatsResponse, bool) {
+	cached, err := sc.redisClient.Get(ctx, BuildStatsKey(tenantOf(ctx), userID, days))
+	if err != nil || cached == "" {
+		return nil, false
+	}
+
+	var stats dto.StatsResponse
+	if err := json.Unmarshal([]byte(cached), &stats); err != nil {
+		return nil, false
+	}
+	return &stats, true
+}
+
+// Set caches stats
+func (sc *StatsCache) Set(ctx context.Context, userID int64, days int, stats *dto.StatsResponse) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return sc.redisClient.Set(ctx, BuildStatsKey(tenantOf(ctx), userID, days), data, sc.ttl)
+}
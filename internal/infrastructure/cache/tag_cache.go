@@ -44,32 +44,36 @@ func NewTagCache(redisClient *redis.Client, tagRepo repository.TagRepository, ta
 	}
 }
 
-// CreateTag creates a tag and deletes tag list caches
+// CreateTag creates a tag and deletes the owner's tag list caches
 func (tc *TagCache) CreateTag(ctx context.Context, tag *entity.Tag) error {
-	// Delete all tag list caches
-	return tc.deleteAllTagCaches(ctx)
+	// Delete the owner's tag list caches
+	return tc.deleteUserTagCaches(ctx, tag.UserID)
 }
 
-// UpdateTag updates a tag and deletes tag list caches
+// UpdateTag updates a tag and deletes the owner's tag list caches
 func (tc *TagCache) UpdateTag(ctx context.Context, tag *entity.Tag) error {
-	// Delete all tag list caches
-	return tc.deleteAllTagCaches(ctx)
+	// Delete tag cache
+	tagKey := BuildTagStringKey(tenantOf(ctx), tag.ID)
+	_ = tc.redisClient.Del(ctx, tagKey)
+
+	// Delete the owner's tag list caches
+	return tc.deleteUserTagCaches(ctx, tag.UserID)
 }
 
-// DeleteTag deletes a tag and deletes tag list caches
-func (tc *TagCache) DeleteTag(ctx context.Context, tagID int64) error {
+// DeleteTag deletes a tag and deletes the owner's tag list caches
+func (tc *TagCache) DeleteTag(ctx context.Context, tag *entity.Tag) error {
 	// Delete tag cache
-	tagKey := BuildTagStringKey(tagID)
+	tagKey := BuildTagStringKey(tenantOf(ctx), tag.ID)
 	_ = tc.redisClient.Del(ctx, tagKey)
 
-	// Delete all tag list caches
-	return tc.deleteAllTagCaches(ctx)
+	// Delete the owner's tag list caches
+	return tc.deleteUserTagCaches(ctx, tag.UserID)
 }
 
 // GetTag retrieves a single tag from string cache or database
 func (tc *TagCache) GetTag(ctx context.Context, tagID int64) (*entity.Tag, error) {
 	// 1. Try to get from string cache
-	tagKey := BuildTagStringKey(tagID)
+	tagKey := BuildTagStringKey(tenantOf(ctx), tagID)
 	cached, err := tc.redisClient.Get(ctx, tagKey)
 	if err == nil && cached != "" {
 		var cachedTag entity.Tag
@@ -79,7 +83,7 @@ func (tc *TagCache) GetTag(ctx context.Context, tagID int64) (*entity.Tag, error
 	}
 
 	// 2. Use singleflight to prevent thundering herd
-	result, err, _ := tc.tagFlight.Do(fmt.Sprintf("get-tag:%d", tagID), func() (interface{}, error) {
+	result, err, _ := tc.tagFlight.Do(fmt.Sprintf("get-tag:%d:%d", tenantOf(ctx), tagID), func() (interface{}, error) {
 		// Cache miss, get from database
 		tagResult, err := tc.tagRepo.FindByID(ctx, tagID)
 		if err != nil {
@@ -99,9 +103,9 @@ func (tc *TagCache) GetTag(ctx context.Context, tagID int64) (*entity.Tag, error
 	return result.(*entity.Tag), nil
 }
 
-// GetTagList retrieves a paginated list of tags from string cache or database
-func (tc *TagCache) GetTagList(ctx context.Context, page, limit int) ([]*entity.Tag, int64, error) {
-	cacheKey := BuildTagListKey(page, limit)
+// GetTagList retrieves a paginated list of a user's tags from string cache or database
+func (tc *TagCache) GetTagList(ctx context.Context, userID int64, page, limit int) ([]*entity.Tag, int64, error) {
+	cacheKey := BuildTagListKey(tenantOf(ctx), userID, page, limit)
 
 	// 1. Try to get from cache
 	cached, err := tc.redisClient.Get(ctx, cacheKey)
@@ -116,20 +120,13 @@ func (tc *TagCache) GetTagList(ctx context.Context, page, limit int) ([]*entity.
 	}
 
 	// 2. Use singleflight to prevent thundering herd
-	result, err, _ := tc.tagListFlight.Do(fmt.Sprintf("taglist:%d:%d", page, limit), func() (interface{}, error) {
+	result, err, _ := tc.tagListFlight.Do(fmt.Sprintf("taglist:%d:%d:%d:%d", tenantOf(ctx), userID, page, limit), func() (interface{}, error) {
 		// Cache miss, query database
 		offset := (page - 1) * limit
-		tags, err := tc.tagRepo.List(ctx, offset, limit)
-		if err != nil {
-			return nil, err
-		}
-
-		// Get total count
-		allTags, err := tc.tagRepo.List(ctx, 0, 10000)
+		tags, total, err := tc.tagRepo.FindByUserID(ctx, userID, offset, limit)
 		if err != nil {
 			return nil, err
 		}
-		total := int64(len(allTags))
 
 		// Cache result (synchronous)
 		response := struct {
@@ -161,9 +158,9 @@ type tagListResult struct {
 	total int64
 }
 
-// GetUserTags retrieves user tags with todo counts from string cache or database
+// GetUserTags retrieves all tags owned by a user from string cache or database
 func (tc *TagCache) GetUserTags(ctx context.Context, userID int64) ([]*entity.Tag, error) {
-	cacheKey := BuildUserTagsKey(userID)
+	cacheKey := BuildUserTagsKey(tenantOf(ctx), userID)
 
 	// 1. Try to get from cache
 	cached, err := tc.redisClient.Get(ctx, cacheKey)
@@ -175,9 +172,9 @@ func (tc *TagCache) GetUserTags(ctx context.Context, userID int64) ([]*entity.Ta
 	}
 
 	// 2. Use singleflight to prevent thundering herd
-	result, err, _ := tc.userTagsFlight.Do(fmt.Sprintf("usertags:%d", userID), func() (interface{}, error) {
+	result, err, _ := tc.userTagsFlight.Do(fmt.Sprintf("usertags:%d:%d", tenantOf(ctx), userID), func() (interface{}, error) {
 		// Cache miss, query database
-		allTags, err := tc.tagRepo.List(ctx, 0, 10000)
+		allTags, _, err := tc.tagRepo.FindByUserID(ctx, userID, 0, 10000)
 		if err != nil {
 			return nil, err
 		}
@@ -202,7 +199,7 @@ func (tc *TagCache) GetUserTags(ctx context.Context, userID int64) ([]*entity.Ta
 
 // updateTagCache updates string cache for a tag
 func (tc *TagCache) updateTagCache(ctx context.Context, tag *entity.Tag) error {
-	tagKey := BuildTagStringKey(tag.ID)
+	tagKey := BuildTagStringKey(tenantOf(ctx), tag.ID)
 
 	jsonBytes, err := json.Marshal(tag)
 	if err != nil {
@@ -212,23 +209,21 @@ func (tc *TagCache) updateTagCache(ctx context.Context, tag *entity.Tag) error {
 	return tc.redisClient.Set(ctx, tagKey, string(jsonBytes), tc.tagTTL)
 }
 
-// deleteAllTagCaches deletes all tag-related caches
-func (tc *TagCache) deleteAllTagCaches(ctx context.Context) error {
-	lock := NewLock(tc.redisClient, "tags:all")
+// deleteUserTagCaches deletes the tag list caches of a user
+func (tc *TagCache) deleteUserTagCaches(ctx context.Context, userID int64) error {
+	lock := NewLock(tc.redisClient, tenantKey(tenantOf(ctx), fmt.Sprintf("tags:user:%d", userID)))
 
 	return lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
-		// Delete tag list caches (pattern: cache:tags:page:*:limit:*)
-		pattern1 := fmt.Sprintf("%spage:*:limit:*", TagListKeyPrefix)
-		_, err := tc.redisClient.DelPattern(ctx, pattern1)
+		// Delete tag list caches (pattern: cache:tags:user:<id>:page:*:limit:*)
+		pattern := BuildTagListPattern(tenantOf(ctx), userID)
+		_, err := tc.redisClient.DelPattern(ctx, pattern)
 		if err != nil {
 			log.Printf("Warning: failed to delete tag list caches: %v", err)
 		}
 
-		// Delete user tags caches (pattern: cache:tags:my-tags:*)
-		pattern2 := fmt.Sprintf("%s*", TagUserTagsKeyPrefix)
-		_, err = tc.redisClient.DelPattern(ctx, pattern2)
-		if err != nil {
-			log.Printf("Warning: failed to delete user tags caches: %v", err)
+		// Delete user tags cache
+		if err := tc.redisClient.Del(ctx, BuildUserTagsKey(tenantOf(ctx), userID)); err != nil {
+			log.Printf("Warning: failed to delete user tags cache: %v", err)
 		}
 
 		return nil
@@ -237,6 +232,5 @@ func (tc *TagCache) deleteAllTagCaches(ctx context.Context) error {
 
 // InvalidateByUserID invalidates caches for a specific user
 func (tc *TagCache) InvalidateByUserID(ctx context.Context, userID int64) error {
-	userTagsKey := BuildUserTagsKey(userID)
-	return tc.redisClient.Del(ctx, userTagsKey)
+	return tc.deleteUserTagCaches(ctx, userID)
 }
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+)
+
+// TagUsageKeyPrefix is the prefix of the per-user tag usage sorted sets
+const TagUsageKeyPrefix = "tags:usage:user:"
+
+// tagUsageEpoch is the time usage weights are measured from; it keeps the
+// scores small
+var tagUsageEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// BuildTagUsageKey builds the key of a user's tag usage sorted set
+func BuildTagUsageKey(tenantID, userID int64) string {
+	return tenantKey(tenantID, fmt.Sprintf("%s%d", TagUsageKeyPrefix, userID))
+}
+
+// TagUsage ranks a user's tags by how often and how recently they were
+// attached to todos. Each use adds a weight that doubles every half-life,
+// so a use counts half as much as one made a half-life later. Unlike the
+// caches, the sorted sets cannot be rebuilt and do not expire.
+type TagUsage struct {
+	redisClient *redis.Client
+	halfLife    time.Duration
+}
+
+// NewTagUsage creates a new tag usage tracker
+func NewTagUsage(redisClient *redis.Client, halfLife time.Duration) *TagUsage {
+	return &TagUsage{
+		redisClient: redisClient,
+		halfLife:    halfLife,
+	}
+}
+
+// Record counts a use of each of a user's tags at the given time
+func (tu *TagUsage) Record(ctx context.Context, userID int64, tagIDs []int64, at time.Time) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	weight := math.Exp2(float64(at.Sub(tagUsageEpoch)) / float64(tu.halfLife))
+	key := BuildTagUsageKey(tenantOf(ctx), userID)
+
+	pipe := tu.redisClient.Pipeline()
+	for _, tagID := range tagIDs {
+		pipe.ZIncrBy(ctx, key, weight, strconv.FormatInt(tagID, 10))
+	}
+	_, err := tu.redisClient.ExecPipeline(pipe)
+	return err
+}
+
+// Scores returns the usage score of each tag a user has used, keyed by tag ID
+func (tu *TagUsage) Scores(ctx context.Context, userID int64) (map[int64]float64, error) {
+	members, err := tu.redisClient.GetClient().ZRangeWithScores(ctx, BuildTagUsageKey(tenantOf(ctx), userID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int64]float64, len(members))
+	for _, member := range members {
+		idStr, ok := member.Member.(string)
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		scores[id] = member.Score
+	}
+	return scores, nil
+}
+
+// Remove forgets the usage of a user's tags, such as deleted ones
+func (tu *TagUsage) Remove(ctx context.Context, userID int64, tagIDs []int64) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, len(tagIDs))
+	for i, tagID := range tagIDs {
+		members[i] = strconv.FormatInt(tagID, 10)
+	}
+	return tu.redisClient.GetClient().ZRem(ctx, BuildTagUsageKey(tenantOf(ctx), userID), members...).Err()
+}
+
+// Move adds the usage of a user's merged tags to the tag they were merged
+// into and forgets the merged tags
+func (tu *TagUsage) Move(ctx context.Context, userID int64, fromTagIDs []int64, toTagID int64) error {
+	if len(fromTagIDs) == 0 {
+		return nil
+	}
+
+	scores, err := tu.Scores(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var moved float64
+	for _, tagID := range fromTagIDs {
+		moved += scores[tagID]
+	}
+
+	key := BuildTagUsageKey(tenantOf(ctx), userID)
+	members := make([]interface{}, len(fromTagIDs))
+	for i, tagID := range fromTagIDs {
+		members[i] = strconv.FormatInt(tagID, 10)
+	}
+
+	pipe := tu.redisClient.Pipeline()
+	if moved > 0 {
+		pipe.ZIncrBy(ctx, key, moved, strconv.FormatInt(toTagID, 10))
+	}
+	pipe.ZRem(ctx, key, members...)
+	_, err = tu.redisClient.ExecPipeline(pipe)
+	return err
+}
+
+// usageTrackingTodoTagRepository records tag usage whenever tags are
+// attached to a todo
+type usageTrackingTodoTagRepository struct {
+	repository.TodoTagRepository
+	tagRepo repository.TagRepository
+	usage   *TagUsage
+}
+
+// NewUsageTrackingTodoTagRepository wraps a todo-tag repository so that
+// attaching tags records their use for autocomplete ranking
+func NewUsageTrackingTodoTagRepository(todoTagRepo repository.TodoTagRepository, tagRepo repository.TagRepository, usage *TagUsage) repository.TodoTagRepository {
+	return &usageTrackingTodoTagRepository{
+		TodoTagRepository: todoTagRepo,
+		tagRepo:           tagRepo,
+		usage:             usage,
+	}
+}
+
+// AddTagsToTodo adds tags to a todo and records their use
+func (r *usageTrackingTodoTagRepository) AddTagsToTodo(ctx context.Context, todoID int64, tagIDs []int64) error {
+	if err := r.TodoTagRepository.AddTagsToTodo(ctx, todoID, tagIDs); err != nil {
+		return err
+	}
+	r.recordUsage(ctx, tagIDs)
+	return nil
+}
+
+// ReplaceTagsForTodo replaces all tags for a todo and records the use of the new tags
+func (r *usageTrackingTodoTagRepository) ReplaceTagsForTodo(ctx context.Context, todoID int64, tagIDs []int64) error {
+	if err := r.TodoTagRepository.ReplaceTagsForTodo(ctx, todoID, tagIDs); err != nil {
+		return err
+	}
+	r.recordUsage(ctx, tagIDs)
+	return nil
+}
+
+// recordUsage records the use of tags, which all belong to the same user.
+// Usage only affects ranking, so failures are logged rather than failing
+// the write.
+func (r *usageTrackingTodoTagRepository) recordUsage(ctx context.Context, tagIDs []int64) {
+	if len(tagIDs) == 0 {
+		return
+	}
+
+	tag, err := r.tagRepo.FindByID(ctx, tagIDs[0])
+	if err != nil {
+		log.Printf("Warning: failed to look up tag owner: %v", err)
+		return
+	}
+
+	if err := r.usage.Record(ctx, tag.UserID, tagIDs, time.Now()); err != nil {
+		log.Printf("Warning: failed to record tag usage: %v", err)
+	}
+}
@@ -3,22 +3,39 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
 	"github.com/darron08/todolist-demo/internal/domain/repository"
 	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
 	redisv8 "github.com/go-redis/redis/v8"
 	"golang.org/x/sync/singleflight"
 )
 
+// addIfCachedScript adds the todo ID in ARGV[1] to the sorted sets in KEYS
+// that exist, scored by the following ARGV in the same order
+var addIfCachedScript = redisv8.NewScript(`
+for i, key in ipairs(KEYS) do
+	if redis.call('EXISTS', key) == 1 then
+		redis.call('ZADD', key, ARGV[i + 1], ARGV[1])
+	end
+end
+return 0
+`)
+
 // TodoCache manages caching for todos using Sorted Set + Hash
 type TodoCache struct {
-	redisClient *redis.Client
-	todoRepo    repository.TodoRepository
-	lockManager *LockManager
+	redisClient  *redis.Client
+	todoRepo     repository.TodoRepository
+	workflowRepo repository.WorkflowRepository
+	assigneeRepo repository.TodoAssigneeRepository
+	todoTagRepo  repository.TodoTagRepository
+	lockManager  *LockManager
 
 	// Singleflight groups
 	todoFlight             singleflight.Group
@@ -35,10 +52,13 @@ type TodoCache struct {
 }
 
 // NewTodoCache creates a new todo cache instance
-func NewTodoCache(redisClient *redis.Client, todoRepo repository.TodoRepository, hashTTL, sortedSetTTL, queryCacheTTL time.Duration) *TodoCache {
+func NewTodoCache(redisClient *redis.Client, todoRepo repository.TodoRepository, workflowRepo repository.WorkflowRepository, assigneeRepo repository.TodoAssigneeRepository, todoTagRepo repository.TodoTagRepository, hashTTL, sortedSetTTL, queryCacheTTL time.Duration) *TodoCache {
 	return &TodoCache{
 		redisClient:    redisClient,
 		todoRepo:       todoRepo,
+		workflowRepo:   workflowRepo,
+		assigneeRepo:   assigneeRepo,
+		todoTagRepo:    todoTagRepo,
 		lockManager:    NewLockManager(redisClient),
 		hashTTL:        hashTTL,
 		sortedSetTTL:   sortedSetTTL,
@@ -51,29 +71,34 @@ func NewTodoCache(redisClient *redis.Client, todoRepo repository.TodoRepository,
 
 // CreateTodo creates a todo and updates cache using pipeline
 func (tc *TodoCache) CreateTodo(ctx context.Context, todo *entity.Todo) error {
-	lock := NewLock(tc.redisClient, fmt.Sprintf("todo:user:%d", todo.UserID))
+	lock := tc.userLock(ctx, todo.UserID)
+
+	err := lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
+		tagIDs, err := tc.tagLineage(ctx, todo)
+		if err != nil {
+			return err
+		}
 
-	return lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
 		// Use pipeline for atomic operations
 		pipe := tc.redisClient.Pipeline()
 
 		// 1. Create/update hash cache
-		hashKey := BuildTodoHashKey(todo.ID)
+		hashKey := BuildTodoHashKey(tenantOf(ctx), todo.ID)
 		hashFields := BuildPipelineTodoHash(todo)
 		pipe.HSet(ctx, hashKey, hashFields)
 		pipe.Expire(ctx, hashKey, tc.hashTTL)
 
 		// 2. Try to update all sorted sets (if they exist)
-		tc.updateSortedSetsWithPipeline(ctx, pipe, todo)
+		tc.updateSortedSetsWithPipeline(ctx, pipe, todo, tagIDs)
 
 		// 3. Execute pipeline
-		_, err := tc.redisClient.ExecPipeline(pipe)
+		_, err = tc.redisClient.ExecPipeline(pipe)
 		if err != nil {
 			return fmt.Errorf("failed to execute pipeline: %w", err)
 		}
 
 		// 4. Delete query caches separately (pattern deletion)
-		pattern := fmt.Sprintf("%s%d%s*", TodoQueryCachePrefix, todo.UserID, QueryCacheSuffix)
+		pattern := BuildQueryCachePattern(tenantOf(ctx), todo.UserID)
 		_, err = tc.redisClient.DelPattern(ctx, pattern)
 		if err != nil {
 			log.Printf("Warning: failed to delete query caches: %v", err)
@@ -81,30 +106,46 @@ func (tc *TodoCache) CreateTodo(ctx context.Context, todo *entity.Todo) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Assignees cache their own views of the todo
+	return tc.invalidateAssignees(ctx, todo)
 }
 
 // UpdateTodo updates a todo and updates cache using pipeline
 func (tc *TodoCache) UpdateTodo(ctx context.Context, todo *entity.Todo) error {
-	lock := NewLock(tc.redisClient, fmt.Sprintf("todo:user:%d", todo.UserID))
+	lock := tc.userLock(ctx, todo.UserID)
 
-	return lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
+	err := lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
 		// Get old todo for comparison
 		oldTodo, err := tc.todoRepo.FindByID(ctx, todo.ID)
 		if err != nil {
 			return err
 		}
+		tagIDs, err := tc.tagLineage(ctx, todo)
+		if err != nil {
+			return err
+		}
 
 		// Use pipeline for atomic operations
 		pipe := tc.redisClient.Pipeline()
 
 		// 1. Update hash cache
-		hashKey := BuildTodoHashKey(todo.ID)
+		hashKey := BuildTodoHashKey(tenantOf(ctx), todo.ID)
 		hashFields := BuildPipelineTodoHash(todo)
 		pipe.HSet(ctx, hashKey, hashFields)
+		if todo.CompletedAt == nil {
+			pipe.HDel(ctx, hashKey, "completed_at")
+		}
+		if todo.SnoozedUntil == nil {
+			pipe.HDel(ctx, hashKey, "snoozed_until")
+		}
 		pipe.Expire(ctx, hashKey, tc.hashTTL)
 
 		// 2. Update sorted sets (remove from old positions, add to new positions)
-		tc.updateSortedSetsWithPipeline(ctx, pipe, todo)
+		tc.updateSortedSetsWithPipeline(ctx, pipe, todo, tagIDs)
 
 		// 3. If status changed, handle status-specific sorted sets
 		if oldTodo.Status != todo.Status {
@@ -118,7 +159,7 @@ func (tc *TodoCache) UpdateTodo(ctx context.Context, todo *entity.Todo) error {
 		}
 
 		// 4. Delete query caches separately (pattern deletion)
-		pattern := fmt.Sprintf("%s%d%s*", TodoQueryCachePrefix, todo.UserID, QueryCacheSuffix)
+		pattern := BuildQueryCachePattern(tenantOf(ctx), todo.UserID)
 		_, err = tc.redisClient.DelPattern(ctx, pattern)
 		if err != nil {
 			log.Printf("Warning: failed to delete query caches: %v", err)
@@ -126,34 +167,50 @@ func (tc *TodoCache) UpdateTodo(ctx context.Context, todo *entity.Todo) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Assignees cache their own views of the todo
+	return tc.invalidateAssignees(ctx, todo)
 }
 
-// DeleteTodo deletes a todo and cleans up cache using pipeline
-func (tc *TodoCache) DeleteTodo(ctx context.Context, todoID, userID int64) error {
-	lock := NewLock(tc.redisClient, fmt.Sprintf("todo:user:%d", userID))
+// DeleteTodo deletes a todo and cleans up cache using pipeline. The todo's
+// assignees and tags must be loaded when it is gone from the database.
+func (tc *TodoCache) DeleteTodo(ctx context.Context, todo *entity.Todo) error {
+	todoID, userID := todo.ID, todo.UserID
+	lock := tc.userLock(ctx, userID)
+
+	err := lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
+		tagIDs, err := tc.tagLineage(ctx, todo)
+		if err != nil {
+			return err
+		}
 
-	return lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
 		// Use pipeline for atomic operations
 		pipe := tc.redisClient.Pipeline()
 
 		// 1. Delete hash cache
-		hashKey := BuildTodoHashKey(todoID)
+		hashKey := BuildTodoHashKey(tenantOf(ctx), todoID)
 		pipe.Del(ctx, hashKey)
 
 		// 2. Remove from all sorted sets
-		sortedSetKeys := GetAllSortedSetKeys(userID)
+		sortedSetKeys := GetAllSortedSetKeys(tenantOf(ctx), userID, tc.workflowStatuses(ctx, userID))
 		for _, key := range sortedSetKeys {
 			pipe.ZRem(ctx, key, todoID)
 		}
+		tc.removeFromTagSortedSetsWithPipeline(ctx, pipe, todo, tagIDs)
+		pipe.ZRem(ctx, BuildSnoozedSetKey(tenantOf(ctx), userID), todoID)
+		removeFromAgendaWithPipeline(ctx, pipe, todoID, userID)
 
 		// Execute pipeline
-		_, err := tc.redisClient.ExecPipeline(pipe)
+		_, err = tc.redisClient.ExecPipeline(pipe)
 		if err != nil {
 			return fmt.Errorf("failed to execute pipeline: %w", err)
 		}
 
 		// 3. Delete query caches separately (pattern deletion)
-		pattern := fmt.Sprintf("%s%d%s*", TodoQueryCachePrefix, userID, QueryCacheSuffix)
+		pattern := BuildQueryCachePattern(tenantOf(ctx), userID)
 		_, err = tc.redisClient.DelPattern(ctx, pattern)
 		if err != nil {
 			log.Printf("Warning: failed to delete query caches: %v", err)
@@ -161,15 +218,23 @@ func (tc *TodoCache) DeleteTodo(ctx context.Context, todoID, userID int64) error
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Assignees cache their own views of the todo
+	return tc.invalidateAssignees(ctx, todo)
 }
 
 // UpdateTodoStatus updates a todo's status and updates cache
 func (tc *TodoCache) UpdateTodoStatus(ctx context.Context, todoID, userID int64, newStatus string) error {
-	lock := NewLock(tc.redisClient, fmt.Sprintf("todo:user:%d", userID))
+	lock := tc.userLock(ctx, userID)
 
-	return lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
+	var todo *entity.Todo
+	err := lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
 		// Get current todo
-		todo, err := tc.todoRepo.FindByID(ctx, todoID)
+		var err error
+		todo, err = tc.todoRepo.FindByID(ctx, todoID)
 		if err != nil {
 			return err
 		}
@@ -180,15 +245,22 @@ func (tc *TodoCache) UpdateTodoStatus(ctx context.Context, todoID, userID int64,
 		pipe := tc.redisClient.Pipeline()
 
 		// 1. Update hash cache
-		hashKey := BuildTodoHashKey(todoID)
+		hashKey := BuildTodoHashKey(tenantOf(ctx), todoID)
 		pipe.HSet(ctx, hashKey, "status", newStatus)
+		pipe.HSet(ctx, hashKey, "status_category", string(todo.StatusCategory))
+		if todo.CompletedAt != nil {
+			pipe.HSet(ctx, hashKey, "completed_at", todo.CompletedAt.Unix())
+		} else {
+			pipe.HDel(ctx, hashKey, "completed_at")
+		}
 
 		// 2. Handle status change in sorted sets
 		tc.handleStatusChangeWithPipeline(ctx, pipe, todoID, userID, oldStatus, newStatus)
 
-		// 3. Update other sorted sets (if other fields changed)
+		// 3. Update other sorted sets (if other fields changed). Tag sorted
+		// sets list todos of every status, so they are left as they are.
 		todo.Status = entity.TodoStatus(newStatus)
-		tc.updateSortedSetsWithPipeline(ctx, pipe, todo)
+		tc.updateSortedSetsWithPipeline(ctx, pipe, todo, nil)
 
 		// Execute pipeline
 		_, err = tc.redisClient.ExecPipeline(pipe)
@@ -197,7 +269,7 @@ func (tc *TodoCache) UpdateTodoStatus(ctx context.Context, todoID, userID int64,
 		}
 
 		// 4. Delete query caches separately (pattern deletion)
-		pattern := fmt.Sprintf("%s%d%s*", TodoQueryCachePrefix, userID, QueryCacheSuffix)
+		pattern := BuildQueryCachePattern(tenantOf(ctx), userID)
 		_, err = tc.redisClient.DelPattern(ctx, pattern)
 		if err != nil {
 			log.Printf("Warning: failed to delete query caches: %v", err)
@@ -205,12 +277,110 @@ func (tc *TodoCache) UpdateTodoStatus(ctx context.Context, todoID, userID int64,
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Assignees cache their own views of the todo
+	return tc.invalidateAssignees(ctx, todo)
+}
+
+// InvalidateTagged drops a user's cached todo lists that depend on the
+// todos counting towards the given tags, after that changed in bulk. Tags
+// whose nested tags changed must be given too.
+func (tc *TodoCache) InvalidateTagged(ctx context.Context, userID int64, tagIDs []int64) error {
+	lock := tc.userLock(ctx, userID)
+
+	return lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
+		// Delete query caches (pattern deletion)
+		pattern := BuildQueryCachePattern(tenantOf(ctx), userID)
+		_, err := tc.redisClient.DelPattern(ctx, pattern)
+		if err != nil {
+			log.Printf("Warning: failed to delete query caches: %v", err)
+		}
+
+		// Drop the sorted sets of the tags, which are rebuilt on their next read
+		var keys []string
+		for _, tagID := range tagIDs {
+			for _, config := range tagSortedSetConfigs(tagID) {
+				keys = append(keys, BuildSortedSetKey(tenantOf(ctx), userID, config.filters, config.sortBy, config.sortOrder))
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		return tc.redisClient.Del(ctx, keys...)
+	})
+}
+
+// InvalidateUser drops all of a user's cached todo lists, and the cached
+// todos they list, after the user's workflow or timezone changed
+func (tc *TodoCache) InvalidateUser(ctx context.Context, userID int64) error {
+	lock := tc.userLock(ctx, userID)
+
+	return lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
+		// The base sorted set and the snoozed set together list every cached todo
+		for _, key := range []string{BuildSortedSetKey(tenantOf(ctx), userID, nil, "due_date", "asc"), BuildSnoozedSetKey(tenantOf(ctx), userID)} {
+			ids, err := tc.redisClient.ZRangeByID(ctx, key, 0, -1)
+			if err != nil {
+				log.Printf("Warning: failed to list cached todos: %v", err)
+				continue
+			}
+			for _, id := range ids {
+				if err := tc.redisClient.Del(ctx, BuildTodoHashKey(tenantOf(ctx), id)); err != nil {
+					log.Printf("Warning: failed to delete todo hash: %v", err)
+				}
+			}
+		}
+
+		// Sorted sets, query caches and the snoozed set share the user's prefix
+		pattern := BuildUserTodoListsPattern(tenantOf(ctx), userID)
+		_, err := tc.redisClient.DelPattern(ctx, pattern)
+		return err
+	})
+}
+
+// InvalidateAssignees drops the query caches of the users a todo of ownerID
+// is assigned to, which hold their assignment views of the todo, after the
+// todo or its assignees changed. The owner's are dropped with the owner's
+// other lists.
+func (tc *TodoCache) InvalidateAssignees(ctx context.Context, ownerID int64, assigneeIDs []int64) error {
+	for _, assigneeID := range assigneeIDs {
+		if assigneeID == ownerID {
+			continue
+		}
+
+		// Taken after the owner's lock is released, so that owners assigning
+		// todos to each other do not wait on each other
+		lock := tc.userLock(ctx, assigneeID)
+		err := lock.WithLockRetry(ctx, tc.lockTimeout, tc.lockRetryDelay, tc.lockRetry, func() error {
+			_, err := tc.redisClient.DelPattern(ctx, BuildQueryCachePattern(tenantOf(ctx), assigneeID))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidateAssignees drops the assignment views of a todo's assignees,
+// looking the assignees up when they are not loaded
+func (tc *TodoCache) invalidateAssignees(ctx context.Context, todo *entity.Todo) error {
+	assigneeIDs := todo.AssigneeIDs
+	if assigneeIDs == nil && tc.assigneeRepo != nil {
+		var err error
+		if assigneeIDs, err = tc.assigneeRepo.FindUserIDsByTodoID(ctx, todo.ID); err != nil {
+			return err
+		}
+	}
+	return tc.InvalidateAssignees(ctx, todo.UserID, assigneeIDs)
 }
 
 // GetTodo retrieves a single todo from cache or database
 func (tc *TodoCache) GetTodo(ctx context.Context, todoID int64) (*entity.Todo, error) {
 	// 1. Try to get from hash cache
-	hashKey := BuildTodoHashKey(todoID)
+	hashKey := BuildTodoHashKey(tenantOf(ctx), todoID)
 	hashFields, err := tc.redisClient.HGetAll(ctx, hashKey)
 	if err == nil && len(hashFields) > 0 {
 		todo, err := ParseTodoFromHash(hashFields)
@@ -220,7 +390,7 @@ func (tc *TodoCache) GetTodo(ctx context.Context, todoID int64) (*entity.Todo, e
 	}
 
 	// 2. Use singleflight to prevent thundering herd
-	result, err, _ := tc.todoFlight.Do(fmt.Sprintf("get-todo:%d", todoID), func() (interface{}, error) {
+	result, err, _ := tc.todoFlight.Do(fmt.Sprintf("get-todo:%d:%d", tenantOf(ctx), todoID), func() (interface{}, error) {
 		// Cache miss, get from database
 		todo, err := tc.todoRepo.FindByID(ctx, todoID)
 		if err != nil {
@@ -258,7 +428,7 @@ func (tc *TodoCache) getTodoListFromSortedSet(ctx context.Context, userID int64,
 	offset := (page - 1) * limit
 
 	// Build sorted set key
-	sortedSetKey := BuildSortedSetKey(userID, filters, sortBy, sortOrder)
+	sortedSetKey := BuildSortedSetKey(tenantOf(ctx), userID, filters, sortBy, sortOrder)
 
 	// Use singleflight to prevent concurrent rebuild
 	result, err, _ := tc.todoListFlight.Do(sortedSetKey, func() (interface{}, error) {
@@ -268,12 +438,15 @@ func (tc *TodoCache) getTodoListFromSortedSet(ctx context.Context, userID int64,
 			return nil, err
 		}
 
-		// Rebuild sorted set if it doesn't exist (lazy loading)
+		// Rebuild sorted set if it doesn't exist (lazy loading); otherwise
+		// move todos whose snooze has ended back into the sorted sets
 		if exists == 0 {
 			_, _, err = tc.rebuildSortedSetWithFlight(ctx, userID, filters, sortBy, sortOrder)
 			if err != nil {
 				return nil, err
 			}
+		} else if err := tc.wakeSnoozed(ctx, userID, time.Now()); err != nil {
+			log.Printf("Warning: failed to wake snoozed todos: %v", err)
 		}
 
 		// Get IDs from sorted set with pagination
@@ -313,7 +486,7 @@ func (tc *TodoCache) getTodoListFromSortedSet(ctx context.Context, userID int64,
 // getTodoListFromQueryCache retrieves todos using query cache (for complex queries)
 func (tc *TodoCache) getTodoListFromQueryCache(ctx context.Context, userID int64, filters *ListFilter, sortBy, sortOrder string, page, limit int) ([]*entity.Todo, int64, error) {
 	// Build query cache key
-	cacheKey := BuildQueryCacheKey(userID, filters, sortBy, sortOrder, page, limit)
+	cacheKey := BuildQueryCacheKey(tenantOf(ctx), userID, filters, sortBy, sortOrder, page, limit)
 
 	// Try to get from cache
 	cached, err := tc.redisClient.Get(ctx, cacheKey)
@@ -334,10 +507,7 @@ func (tc *TodoCache) getTodoListFromQueryCache(ctx context.Context, userID int64
 		todos, total, err := tc.todoRepo.FindByUserIDAndFilters(
 			ctx,
 			userID,
-			filters.Status,
-			filters.Priority,
-			filters.DueDateFrom,
-			filters.DueDateTo,
+			filters.RepositoryFilter(time.Now()),
 			sortBy,
 			sortOrder,
 			offset,
@@ -359,7 +529,7 @@ func (tc *TodoCache) getTodoListFromQueryCache(ctx context.Context, userID int64
 
 		jsonBytes, err := json.Marshal(response)
 		if err == nil {
-			_ = tc.redisClient.Set(ctx, cacheKey, string(jsonBytes), tc.queryCacheTTL)
+			_ = tc.redisClient.Set(ctx, cacheKey, string(jsonBytes), tc.queryCacheTTLFor(ctx, userID))
 		}
 
 		return &todoListResult{todos, total}, nil
@@ -382,16 +552,21 @@ type todoListResult struct {
 
 // rebuildSortedSetWithFlight rebuilds a single sorted set from database with singleflight
 func (tc *TodoCache) rebuildSortedSetWithFlight(ctx context.Context, userID int64, filters *ListFilter, sortBy, sortOrder string) ([]*entity.Todo, int64, error) {
-	key := BuildSortedSetKey(userID, filters, sortBy, sortOrder)
+	key := BuildSortedSetKey(tenantOf(ctx), userID, filters, sortBy, sortOrder)
 
 	result, err, _ := tc.rebuildSortedSetFlight.Do(key, func() (interface{}, error) {
+		now := time.Now()
+
+		// Track snoozed todos so they reappear once their snooze ends
+		if err := tc.rebuildSnoozedSet(ctx, userID, now); err != nil {
+			return nil, err
+		}
+
 		// Load all todos for this user (or with filters)
 		todos, _, err := tc.todoRepo.FindByUserIDAndFilters(
 			ctx,
 			userID,
-			filters.Status,
-			filters.Priority,
-			nil, nil,
+			repository.TodoFilter{Status: filters.Status, Priority: filters.Priority, VisibleAt: &now, TagIDs: filters.TagIDs},
 			sortBy,
 			sortOrder,
 			0, 10000,
@@ -437,7 +612,7 @@ func (tc *TodoCache) rebuildSortedSetWithFlight(ctx context.Context, userID int6
 
 // getTodoFromCacheOrDB retrieves a todo from hash cache or database
 func (tc *TodoCache) getTodoFromCacheOrDB(ctx context.Context, todoID int64) (*entity.Todo, error) {
-	hashKey := BuildTodoHashKey(todoID)
+	hashKey := BuildTodoHashKey(tenantOf(ctx), todoID)
 	hashFields, err := tc.redisClient.HGetAll(ctx, hashKey)
 
 	if err == nil && len(hashFields) > 0 {
@@ -448,7 +623,7 @@ func (tc *TodoCache) getTodoFromCacheOrDB(ctx context.Context, todoID int64) (*e
 	}
 
 	// Use singleflight to prevent thundering herd
-	result, err, _ := tc.todoFlight.Do(fmt.Sprintf("get-todo:%d", todoID), func() (interface{}, error) {
+	result, err, _ := tc.todoFlight.Do(fmt.Sprintf("get-todo:%d:%d", tenantOf(ctx), todoID), func() (interface{}, error) {
 		// Get from database
 		todo, err := tc.todoRepo.FindByID(ctx, todoID)
 		if err != nil {
@@ -470,7 +645,7 @@ func (tc *TodoCache) getTodoFromCacheOrDB(ctx context.Context, todoID int64) (*e
 
 // updateHashCache updates the hash cache for a todo
 func (tc *TodoCache) updateHashCache(ctx context.Context, todo *entity.Todo) error {
-	hashKey := BuildTodoHashKey(todo.ID)
+	hashKey := BuildTodoHashKey(tenantOf(ctx), todo.ID)
 	hashFields := BuildPipelineTodoHash(todo)
 
 	err := tc.redisClient.HSetAll(ctx, hashKey, hashFields)
@@ -481,35 +656,40 @@ func (tc *TodoCache) updateHashCache(ctx context.Context, todo *entity.Todo) err
 	return tc.redisClient.Expire(ctx, hashKey, tc.hashTTL)
 }
 
-// updateSortedSetsWithPipeline updates all relevant sorted sets in a pipeline
-func (tc *TodoCache) updateSortedSetsWithPipeline(ctx context.Context, pipe redisv8.Pipeliner, todo *entity.Todo) {
-	// Define all sorted set variations
-	sortedSetConfigs := []struct {
-		filters   *ListFilter
-		sortBy    string
-		sortOrder string
-	}{
-		// Base sorted sets
-		{nil, "due_date", "asc"},
-		{nil, "due_date", "desc"},
-		{nil, "created_at", "desc"},
-		{nil, "title", "asc"},
-
-		// Status filtered sorted sets
-		{&ListFilter{Status: strPtr("not_started")}, "due_date", "asc"},
-		{&ListFilter{Status: strPtr("in_progress")}, "due_date", "asc"},
-		{&ListFilter{Status: strPtr("completed")}, "due_date", "asc"},
-
-		// Priority filtered sorted sets
-		{&ListFilter{Priority: strPtr("high")}, "due_date", "asc"},
+// updateSortedSetsWithPipeline updates all relevant sorted sets in a
+// pipeline, including the sorted sets of the given tags the todo counts
+// towards
+func (tc *TodoCache) updateSortedSetsWithPipeline(ctx context.Context, pipe redisv8.Pipeliner, todo *entity.Todo, tagIDs []int64) {
+	// The agenda keeps snoozed todos, which it leaves out when read
+	tc.updateAgendaWithPipeline(ctx, pipe, todo)
+
+	// Status sorted sets follow the user's workflow
+	configs := sortedSetConfigs(tc.workflowStatuses(ctx, todo.UserID))
+
+	// Snoozed todos are kept out of the sorted sets until they wake up
+	snoozedKey := BuildSnoozedSetKey(tenantOf(ctx), todo.UserID)
+	if todo.IsSnoozed(time.Now()) {
+		for _, config := range configs {
+			pipe.ZRem(ctx, BuildSortedSetKey(tenantOf(ctx), todo.UserID, config.filters, config.sortBy, config.sortOrder), todo.ID)
+		}
+		tc.removeFromTagSortedSetsWithPipeline(ctx, pipe, todo, tagIDs)
+		pipe.ZAdd(ctx, snoozedKey, &redisv8.Z{Score: float64(todo.SnoozedUntil.Unix()), Member: todo.ID})
+		pipe.Expire(ctx, snoozedKey, tc.sortedSetTTL)
+		return
 	}
+	pipe.ZRem(ctx, snoozedKey, todo.ID)
 
-	for _, config := range sortedSetConfigs {
-		key := BuildSortedSetKey(todo.UserID, config.filters, config.sortBy, config.sortOrder)
+	for _, config := range configs {
+		key := BuildSortedSetKey(tenantOf(ctx), todo.UserID, config.filters, config.sortBy, config.sortOrder)
 
 		// Remove old (if exists)
 		pipe.ZRem(ctx, key, todo.ID)
 
+		// Only add to the sorted sets whose filters the todo matches
+		if !matchesSortedSet(todo, config.filters) {
+			continue
+		}
+
 		// Add new
 		score := GetTodoScore(todo, config.sortBy, config.sortOrder)
 		members := []redisv8.Z{{Score: score, Member: todo.ID}}
@@ -521,11 +701,58 @@ func (tc *TodoCache) updateSortedSetsWithPipeline(ctx context.Context, pipe redi
 		pipe.ZAdd(ctx, key, ptrMembers...)
 		pipe.Expire(ctx, key, tc.sortedSetTTL)
 	}
+
+	tc.addToTagSortedSetsWithPipeline(ctx, pipe, todo, tagIDs)
+}
+
+// addToTagSortedSetsWithPipeline adds a todo to, or moves it within, the
+// sorted sets of the given tags. Unlike the user's other sorted sets they
+// are many, so the ones that are not cached are left to be rebuilt on their
+// next read rather than started with the todo alone.
+func (tc *TodoCache) addToTagSortedSetsWithPipeline(ctx context.Context, pipe redisv8.Pipeliner, todo *entity.Todo, tagIDs []int64) {
+	if len(tagIDs) == 0 {
+		return
+	}
+
+	var keys []string
+	args := []interface{}{todo.ID}
+	for _, tagID := range tagIDs {
+		for _, config := range tagSortedSetConfigs(tagID) {
+			keys = append(keys, BuildSortedSetKey(tenantOf(ctx), todo.UserID, config.filters, config.sortBy, config.sortOrder))
+			args = append(args, GetTodoScore(todo, config.sortBy, config.sortOrder))
+		}
+	}
+	addIfCachedScript.Eval(ctx, pipe, keys, args...)
+}
+
+// removeFromTagSortedSetsWithPipeline removes a todo from the sorted sets of
+// the given tags
+func (tc *TodoCache) removeFromTagSortedSetsWithPipeline(ctx context.Context, pipe redisv8.Pipeliner, todo *entity.Todo, tagIDs []int64) {
+	for _, tagID := range tagIDs {
+		for _, config := range tagSortedSetConfigs(tagID) {
+			pipe.ZRem(ctx, BuildSortedSetKey(tenantOf(ctx), todo.UserID, config.filters, config.sortBy, config.sortOrder), todo.ID)
+		}
+	}
+}
+
+// userLock returns the lock serializing the cache writes for a user's todos
+// in the tenant bound to ctx
+func (tc *TodoCache) userLock(ctx context.Context, userID int64) *RedisLock {
+	return NewLock(tc.redisClient, tenantKey(tenantOf(ctx), fmt.Sprintf("todo:user:%d", userID)))
+}
+
+// tagLineage returns the tags a todo counts towards, looking them up when
+// they are not loaded
+func (tc *TodoCache) tagLineage(ctx context.Context, todo *entity.Todo) ([]int64, error) {
+	if todo.TagIDs != nil || tc.todoTagRepo == nil {
+		return todo.TagIDs, nil
+	}
+	return tc.todoTagRepo.GetTagLineageIDsByTodoID(ctx, todo.ID)
 }
 
 // handleStatusChangeWithPipeline handles status change in sorted sets
 func (tc *TodoCache) handleStatusChangeWithPipeline(ctx context.Context, pipe redisv8.Pipeliner, todoID int64, userID int64, oldStatus, newStatus string) {
-	keys := BuildStatusChangeKeys(userID, oldStatus, newStatus)
+	keys := BuildStatusChangeKeys(tenantOf(ctx), userID, oldStatus, newStatus)
 
 	// Remove from old status sorted set
 	if len(keys) > 0 {
@@ -535,7 +762,7 @@ func (tc *TodoCache) handleStatusChangeWithPipeline(ctx context.Context, pipe re
 	// Add to new status sorted set (get score from todo)
 	if len(keys) > 1 {
 		todoInfo, err := tc.todoRepo.FindByID(ctx, todoID)
-		if err == nil {
+		if err == nil && !todoInfo.IsSnoozed(time.Now()) {
 			score := GetTodoScore(todoInfo, "due_date", "asc")
 			members := []redisv8.Z{{Score: score, Member: todoID}}
 			// Convert []Z to []*Z
@@ -551,15 +778,19 @@ func (tc *TodoCache) handleStatusChangeWithPipeline(ctx context.Context, pipe re
 
 // rebuildSortedSet rebuilds a single sorted set from database
 func (tc *TodoCache) rebuildSortedSet(ctx context.Context, userID int64, filters *ListFilter, sortBy, sortOrder string) error {
-	key := BuildSortedSetKey(userID, filters, sortBy, sortOrder)
+	key := BuildSortedSetKey(tenantOf(ctx), userID, filters, sortBy, sortOrder)
+	now := time.Now()
+
+	// Track snoozed todos so they reappear once their snooze ends
+	if err := tc.rebuildSnoozedSet(ctx, userID, now); err != nil {
+		return err
+	}
 
 	// Load all todos for this user (or with filters)
 	todos, _, err := tc.todoRepo.FindByUserIDAndFilters(
 		ctx,
 		userID,
-		filters.Status,
-		filters.Priority,
-		nil, nil,
+		repository.TodoFilter{Status: filters.Status, Priority: filters.Priority, VisibleAt: &now, TagIDs: filters.TagIDs},
 		sortBy,
 		sortOrder,
 		0, 10000,
@@ -595,6 +826,117 @@ func (tc *TodoCache) rebuildSortedSet(ctx context.Context, userID int64, filters
 
 // Utility functions
 
+// wakeSnoozed moves a user's todos whose snooze ended by now from the snoozed
+// set back into the sorted sets
+func (tc *TodoCache) wakeSnoozed(ctx context.Context, userID int64, now time.Time) error {
+	snoozedKey := BuildSnoozedSetKey(tenantOf(ctx), userID)
+	ids, err := tc.redisClient.ZRangeByScoreWithIDs(ctx, snoozedKey, &redisv8.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	})
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	pipe := tc.redisClient.Pipeline()
+	for _, id := range ids {
+		todo, err := tc.getTodoFromCacheOrDB(ctx, id)
+		if err != nil {
+			// Deleted in the meantime
+			pipe.ZRem(ctx, snoozedKey, id)
+			continue
+		}
+		tagIDs, err := tc.tagLineage(ctx, todo)
+		if err != nil {
+			return err
+		}
+		tc.updateSortedSetsWithPipeline(ctx, pipe, todo, tagIDs)
+	}
+
+	_, err = tc.redisClient.ExecPipeline(pipe)
+	return err
+}
+
+// rebuildSnoozedSet reloads a user's snoozed set from the database
+func (tc *TodoCache) rebuildSnoozedSet(ctx context.Context, userID int64, now time.Time) error {
+	todos, err := tc.todoRepo.FindSnoozedByUserID(ctx, userID, now)
+	if err != nil {
+		return err
+	}
+
+	snoozedKey := BuildSnoozedSetKey(tenantOf(ctx), userID)
+	pipe := tc.redisClient.Pipeline()
+	pipe.Del(ctx, snoozedKey)
+	for _, todo := range todos {
+		pipe.ZAdd(ctx, snoozedKey, &redisv8.Z{Score: float64(todo.SnoozedUntil.Unix()), Member: todo.ID})
+	}
+	pipe.Expire(ctx, snoozedKey, tc.sortedSetTTL)
+
+	_, err = tc.redisClient.ExecPipeline(pipe)
+	return err
+}
+
+// queryCacheTTLFor shortens the query cache TTL so that cached lists expire
+// when the user's next snoozed todo wakes up
+func (tc *TodoCache) queryCacheTTLFor(ctx context.Context, userID int64) time.Duration {
+	next, err := tc.redisClient.GetClient().ZRangeWithScores(ctx, BuildSnoozedSetKey(tenantOf(ctx), userID), 0, 0).Result()
+	if err != nil || len(next) == 0 {
+		return tc.queryCacheTTL
+	}
+
+	untilWake := time.Until(time.Unix(int64(next[0].Score), 0))
+	if untilWake < time.Second {
+		untilWake = time.Second
+	}
+	if untilWake < tc.queryCacheTTL {
+		return untilWake
+	}
+	return tc.queryCacheTTL
+}
+
+// workflowStatuses returns the statuses of a user's workflow, falling back
+// to the default workflow when the user has none or it cannot be loaded.
+// They are cached along with the user's lists, which are dropped when the
+// workflow changes.
+func (tc *TodoCache) workflowStatuses(ctx context.Context, userID int64) []entity.TodoStatus {
+	key := BuildWorkflowKey(tenantOf(ctx), userID)
+	cached, err := tc.redisClient.GetClient().LRange(ctx, key, 0, -1).Result()
+	if err == nil && len(cached) > 0 {
+		statuses := make([]entity.TodoStatus, len(cached))
+		for i, status := range cached {
+			statuses[i] = entity.TodoStatus(status)
+		}
+		return statuses
+	}
+
+	statuses := entity.DefaultWorkflow(userID).Statuses()
+	if tc.workflowRepo != nil {
+		workflow, err := tc.workflowRepo.FindByUserID(ctx, userID)
+		if err == nil {
+			statuses = workflow.Statuses()
+		} else if !errors.Is(err, repositoryImpl.ErrWorkflowNotFound) {
+			// Not cached, so that the workflow is loaded again next time
+			return statuses
+		}
+	}
+	if len(statuses) == 0 {
+		return statuses
+	}
+
+	values := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	pipe := tc.redisClient.Pipeline()
+	pipe.Del(ctx, key)
+	pipe.RPush(ctx, key, values...)
+	pipe.Expire(ctx, key, tc.sortedSetTTL)
+	if _, err := tc.redisClient.ExecPipeline(pipe); err != nil {
+		log.Printf("Warning: failed to cache workflow statuses: %v", err)
+	}
+	return statuses
+}
+
 func strPtr(s string) *string {
 	return &s
 }
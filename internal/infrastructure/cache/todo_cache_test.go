@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTodoCache(t *testing.T) (*TodoCache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client, err := redis.NewConnection(&redis.Config{Host: server.Host(), Port: server.Port()})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return NewTodoCache(client, nil, nil, nil, nil, time.Hour, time.Hour, time.Minute), server
+}
+
+func TestTodoCache_TagSortedSets(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), 1)
+	tc, server := newTestTodoCache(t)
+
+	workKey := BuildSortedSetKey(1, 7, &ListFilter{TagIDs: []int64{5}}, "created_at", "desc")
+	homeKey := BuildSortedSetKey(1, 7, &ListFilter{TagIDs: []int64{6}}, "created_at", "desc")
+	_, err := server.ZAdd(workKey, 1, "1")
+	require.NoError(t, err)
+
+	// Todos are added to the cached sets of their tags, and no set is started
+	// with the todo alone
+	todo := &entity.Todo{ID: 2, UserID: 7, Title: "tagged", Status: entity.TodoStatusNotStarted, CreatedAt: time.Now(), AssigneeIDs: []int64{}, TagIDs: []int64{5, 6}}
+	require.NoError(t, tc.CreateTodo(ctx, todo))
+	members, err := server.ZMembers(workKey)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1", "2"}, members)
+	assert.False(t, server.Exists(homeKey))
+
+	// Deleted todos leave them
+	require.NoError(t, tc.DeleteTodo(ctx, todo))
+	members, err = server.ZMembers(workKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, members)
+
+	// Only the sets of the given tags are dropped
+	_, err = server.ZAdd(homeKey, 1, "3")
+	require.NoError(t, err)
+	require.NoError(t, tc.InvalidateTagged(ctx, 7, []int64{5}))
+	assert.False(t, server.Exists(workKey))
+	assert.True(t, server.Exists(homeKey))
+}
+
+// countingWorkflowRepository counts the workflows loaded of users without one
+type countingWorkflowRepository struct {
+	loads int
+}
+
+func (r *countingWorkflowRepository) FindByUserID(ctx context.Context, userID int64) (*entity.Workflow, error) {
+	r.loads++
+	return nil, repositoryImpl.ErrWorkflowNotFound
+}
+
+func (r *countingWorkflowRepository) Save(ctx context.Context, workflow *entity.Workflow) error {
+	return nil
+}
+
+func (r *countingWorkflowRepository) DeleteByUserID(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func TestTodoCache_WorkflowStatusesCached(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), 1)
+	tc, _ := newTestTodoCache(t)
+	workflowRepo := &countingWorkflowRepository{}
+	tc.workflowRepo = workflowRepo
+
+	// Writes load the workflow once
+	for id := int64(1); id <= 2; id++ {
+		todo := &entity.Todo{ID: id, UserID: 7, Title: "todo", Status: entity.TodoStatusNotStarted, AssigneeIDs: []int64{}, TagIDs: []int64{}}
+		require.NoError(t, tc.CreateTodo(ctx, todo))
+	}
+	assert.Equal(t, 1, workflowRepo.loads)
+	assert.Equal(t, entity.DefaultWorkflow(7).Statuses(), tc.workflowStatuses(ctx, 7))
+
+	// and again once the user's lists are dropped for a workflow change
+	require.NoError(t, tc.InvalidateUser(ctx, 7))
+	tc.workflowStatuses(ctx, 7)
+	assert.Equal(t, 2, workflowRepo.loads)
+}
+
+func TestTodoCache_UserLockScopedByTenant(t *testing.T) {
+	tc, _ := newTestTodoCache(t)
+	ctx := context.Background()
+
+	lockA := tc.userLock(tenant.WithID(ctx, 1), 7)
+	lockB := tc.userLock(tenant.WithID(ctx, 2), 7)
+	assert.Equal(t, "lock:tenant:1:todo:user:7", lockA.key)
+	assert.NotEqual(t, lockA.key, lockB.key)
+}
@@ -18,6 +18,20 @@ type Config struct {
 	Swagger   SwaggerConfig   `mapstructure:"swagger"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
 	Cache     CacheConfig     `mapstructure:"cache"`
+	Webhook   WebhookConfig   `mapstructure:"webhook"`
+	Realtime  RealtimeConfig  `mapstructure:"realtime"`
+	GraphQL   GraphQLConfig   `mapstructure:"graphql"`
+	GRPC      GRPCConfig      `mapstructure:"grpc"`
+	Outbox    OutboxConfig    `mapstructure:"outbox"`
+	Overdue   OverdueConfig   `mapstructure:"overdue"`
+	Archive   ArchiveConfig   `mapstructure:"archive"`
+	Matrix    MatrixConfig    `mapstructure:"matrix"`
+	Mail      MailConfig      `mapstructure:"mail"`
+	Digest    DigestConfig    `mapstructure:"digest"`
+	// Notifications configures the inbox and notification emails
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	// PasswordReset configures emailed password reset links
+	PasswordReset PasswordResetConfig `mapstructure:"password_reset"`
 }
 
 // ServerConfig represents HTTP server configuration
@@ -110,9 +124,10 @@ type RateLimitConfig struct {
 
 // CacheConfig represents cache configuration
 type CacheConfig struct {
-	Todo        CacheTodoConfig `mapstructure:"todo"`
-	Tag         CacheTagConfig  `mapstructure:"tag"`
-	LockTimeout int             `mapstructure:"lock_timeout"`
+	Todo        CacheTodoConfig  `mapstructure:"todo"`
+	Tag         CacheTagConfig   `mapstructure:"tag"`
+	Stats       CacheStatsConfig `mapstructure:"stats"`
+	LockTimeout int              `mapstructure:"lock_timeout"`
 }
 
 // CacheTodoConfig represents todo cache configuration
@@ -124,7 +139,115 @@ type CacheTodoConfig struct {
 
 // CacheTagConfig represents tag cache configuration
 type CacheTagConfig struct {
-	TTL int `mapstructure:"ttl"`
+	TTL           int `mapstructure:"ttl"`
+	UsageHalfLife int `mapstructure:"usage_half_life"`
+}
+
+// CacheStatsConfig represents stats cache configuration
+type CacheStatsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	TTL     int  `mapstructure:"ttl"`
+}
+
+// WebhookConfig represents outgoing webhook delivery configuration
+type WebhookConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	MaxAttempts    int  `mapstructure:"max_attempts"`
+	InitialBackoff int  `mapstructure:"initial_backoff"`
+	MaxBackoff     int  `mapstructure:"max_backoff"`
+	Timeout        int  `mapstructure:"timeout"`
+	PollInterval   int  `mapstructure:"poll_interval"`
+	BatchSize      int  `mapstructure:"batch_size"`
+	Workers        int  `mapstructure:"workers"`
+	SweepInterval  int  `mapstructure:"sweep_interval"`
+	// AllowPrivateNetworks lets webhooks reach private, loopback and
+	// link-local addresses; only for local development
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+}
+
+// RealtimeConfig represents server-sent events and WebSocket configuration
+type RealtimeConfig struct {
+	Enabled           bool `mapstructure:"enabled"`
+	StreamMaxLen      int  `mapstructure:"stream_max_len"`
+	StreamTTL         int  `mapstructure:"stream_ttl"`
+	HeartbeatInterval int  `mapstructure:"heartbeat_interval"`
+	ClientBuffer      int  `mapstructure:"client_buffer"`
+}
+
+// GraphQLConfig represents GraphQL endpoint configuration
+type GraphQLConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxDepth      int  `mapstructure:"max_depth"`
+	MaxComplexity int  `mapstructure:"max_complexity"`
+}
+
+// GRPCConfig represents gRPC server configuration
+type GRPCConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Port    string `mapstructure:"port"`
+}
+
+// OutboxConfig represents transactional outbox relay configuration
+type OutboxConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Sinks        []string `mapstructure:"sinks"`
+	PollInterval int      `mapstructure:"poll_interval"`
+	BatchSize    int      `mapstructure:"batch_size"`
+	LockTTL      int      `mapstructure:"lock_ttl"`
+	Retention    int      `mapstructure:"retention"`
+	StreamKey    string   `mapstructure:"stream_key"`
+	StreamMaxLen int      `mapstructure:"stream_max_len"`
+}
+
+// OverdueConfig represents overdue detection and priority escalation configuration
+type OverdueConfig struct {
+	Enabled            bool `mapstructure:"enabled"`
+	CheckInterval      int  `mapstructure:"check_interval"`
+	EscalationInterval int  `mapstructure:"escalation_interval"`
+	BatchSize          int  `mapstructure:"batch_size"`
+}
+
+// MatrixConfig represents Eisenhower matrix configuration
+type MatrixConfig struct {
+	UrgentWithin int `mapstructure:"urgent_within"`
+}
+
+// ArchiveConfig represents automatic archiving of completed todos configuration
+type ArchiveConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	CheckInterval int  `mapstructure:"check_interval"`
+	AfterDays     int  `mapstructure:"after_days"`
+	BatchSize     int  `mapstructure:"batch_size"`
+}
+
+// MailConfig represents outgoing email (SMTP) configuration
+type MailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// DigestConfig represents digest email configuration
+type DigestConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	CheckInterval  int    `mapstructure:"check_interval"`
+	BatchSize      int    `mapstructure:"batch_size"`
+	UnsubscribeURL string `mapstructure:"unsubscribe_url"`
+	Secret         string `mapstructure:"secret"`
+}
+
+// NotificationsConfig represents inbox notification configuration
+type NotificationsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Email   bool `mapstructure:"email"`
+}
+
+// PasswordResetConfig represents password reset link configuration
+type PasswordResetConfig struct {
+	URL string `mapstructure:"url"`
+	TTL int    `mapstructure:"ttl"`
 }
 
 // Load loads configuration from file and environment variables
@@ -226,7 +349,82 @@ func setDefaults() {
 	viper.SetDefault("cache.todo.sorted_set_ttl", 600)
 	viper.SetDefault("cache.todo.query_ttl", 300)
 	viper.SetDefault("cache.tag.ttl", 1800)
+	viper.SetDefault("cache.tag.usage_half_life", 1209600)
+	viper.SetDefault("cache.stats.enabled", true)
+	viper.SetDefault("cache.stats.ttl", 60)
 	viper.SetDefault("cache.lock_timeout", 10)
+
+	// Webhook defaults
+	viper.SetDefault("webhook.enabled", true)
+	viper.SetDefault("webhook.max_attempts", 8)
+	viper.SetDefault("webhook.initial_backoff", 10)
+	viper.SetDefault("webhook.max_backoff", 21600)
+	viper.SetDefault("webhook.timeout", 10)
+	viper.SetDefault("webhook.poll_interval", 1)
+	viper.SetDefault("webhook.batch_size", 50)
+	viper.SetDefault("webhook.workers", 10)
+	viper.SetDefault("webhook.sweep_interval", 60)
+	viper.SetDefault("webhook.allow_private_networks", false)
+
+	// Realtime defaults
+	viper.SetDefault("realtime.enabled", true)
+	viper.SetDefault("realtime.stream_max_len", 1000)
+	viper.SetDefault("realtime.stream_ttl", 86400)
+	viper.SetDefault("realtime.heartbeat_interval", 25)
+	viper.SetDefault("realtime.client_buffer", 64)
+
+	// GraphQL defaults
+	viper.SetDefault("graphql.enabled", true)
+	viper.SetDefault("graphql.max_depth", 8)
+	viper.SetDefault("graphql.max_complexity", 1000)
+
+	// gRPC defaults
+	viper.SetDefault("grpc.enabled", true)
+	viper.SetDefault("grpc.port", "9090")
+
+	// Outbox defaults
+	viper.SetDefault("outbox.enabled", true)
+	viper.SetDefault("outbox.sinks", []string{"bus"})
+	viper.SetDefault("outbox.poll_interval", 1)
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.lock_ttl", 30)
+	viper.SetDefault("outbox.retention", 604800)
+	viper.SetDefault("outbox.stream_key", "events:stream")
+	viper.SetDefault("outbox.stream_max_len", 100000)
+
+	// Overdue defaults
+	viper.SetDefault("overdue.enabled", true)
+	viper.SetDefault("overdue.check_interval", 60)
+	viper.SetDefault("overdue.escalation_interval", 86400)
+	viper.SetDefault("overdue.batch_size", 500)
+
+	// Archive defaults
+	viper.SetDefault("archive.enabled", true)
+	viper.SetDefault("archive.check_interval", 3600)
+	viper.SetDefault("archive.after_days", 30)
+	viper.SetDefault("archive.batch_size", 500)
+
+	// Matrix defaults
+	viper.SetDefault("matrix.urgent_within", 172800)
+
+	// Mail defaults (a local Mailpit or MailHog)
+	viper.SetDefault("mail.host", "localhost")
+	viper.SetDefault("mail.port", "1025")
+	viper.SetDefault("mail.from", "Todo List <no-reply@todolist.local>")
+
+	// Digest defaults
+	viper.SetDefault("digest.enabled", true)
+	viper.SetDefault("digest.check_interval", 300)
+	viper.SetDefault("digest.batch_size", 500)
+	viper.SetDefault("digest.unsubscribe_url", "http://localhost:8080/api/v1/digest/unsubscribe")
+
+	// Notification defaults
+	viper.SetDefault("notifications.enabled", true)
+	viper.SetDefault("notifications.email", true)
+
+	// Password reset defaults
+	viper.SetDefault("password_reset.url", "http://localhost:8080/reset-password")
+	viper.SetDefault("password_reset.ttl", 3600)
 }
 
 // overrideWithEnv overrides configuration with environment variables
@@ -235,6 +433,10 @@ func overrideWithEnv(config *Config) {
 		config.Server.Port = port
 	}
 
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		config.GRPC.Port = port
+	}
+
 	if host := os.Getenv("MYSQL_HOST"); host != "" {
 		config.Database.MySQL.Host = host
 	}
@@ -277,6 +479,22 @@ func overrideWithEnv(config *Config) {
 		config.JWT.Secret = secret
 	}
 
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		config.Mail.Host = host
+	}
+
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		config.Mail.Port = port
+	}
+
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		config.Mail.Username = username
+	}
+
+	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
+		config.Mail.Password = password
+	}
+
 	if frontendURL := os.Getenv("FRONTEND_URL"); frontendURL != "" {
 		config.CORS.AllowedOrigins = []string{frontendURL}
 	}
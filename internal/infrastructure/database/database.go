@@ -94,8 +94,24 @@ func autoMigrate(db *mysql.DB) error {
 	// Auto migrate all entities
 	return gormDB.AutoMigrate(
 		&entity.User{},
+		&entity.Organization{},
+		&entity.OrganizationMember{},
 		&entity.Todo{},
 		&entity.Tag{},
+		&entity.TagAlias{},
 		&entity.TodoTag{},
+		&entity.TodoAssignee{},
+		&entity.ArchivedTodo{},
+		&entity.ArchivedTodoTag{},
+		&entity.ArchivedTodoAssignee{},
+		&entity.Webhook{},
+		&entity.WebhookDelivery{},
+		&entity.OutboxMessage{},
+		&entity.Template{},
+		&entity.TemplateItem{},
+		&entity.Workflow{},
+		&entity.WorkflowState{},
+		&entity.Notification{},
+		&entity.NotificationPreference{},
 	)
 }
@@ -0,0 +1,112 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/event"
+)
+
+// Bus is an asynchronous in-process event bus. Publish never blocks the
+// caller; events are handed to subscribers by a background worker.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []event.Handler
+
+	queue          chan *event.Event
+	handlerTimeout time.Duration
+	wg             sync.WaitGroup
+	closeOnce      sync.Once
+}
+
+// NewBus creates a new event bus with the given queue size
+func NewBus(queueSize int) *Bus {
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+
+	b := &Bus{
+		queue:          make(chan *event.Event, queueSize),
+		handlerTimeout: 30 * time.Second,
+	}
+
+	b.wg.Add(1)
+	go b.run()
+
+	return b
+}
+
+// Subscribe registers a handler that receives every published event
+func (b *Bus) Subscribe(handler event.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish enqueues an event for asynchronous delivery
+func (b *Bus) Publish(ctx context.Context, evt *event.Event) {
+	if evt == nil {
+		return
+	}
+
+	select {
+	case b.queue <- evt:
+	default:
+		log.Printf("Warning: event bus queue is full, dropping event %s (%s)", evt.ID, evt.Type)
+	}
+}
+
+// PublishWait enqueues an event, waiting for queue space until the context
+// is done. Use it when the caller can retry, such as the outbox relay.
+func (b *Bus) PublishWait(ctx context.Context, evt *event.Event) error {
+	if evt == nil {
+		return nil
+	}
+
+	select {
+	case b.queue <- evt:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events and waits for queued events to be handled
+func (b *Bus) Close() {
+	b.closeOnce.Do(func() {
+		close(b.queue)
+	})
+	b.wg.Wait()
+}
+
+// run dispatches queued events to subscribers
+func (b *Bus) run() {
+	defer b.wg.Done()
+
+	for evt := range b.queue {
+		b.mu.RLock()
+		handlers := make([]event.Handler, len(b.handlers))
+		copy(handlers, b.handlers)
+		b.mu.RUnlock()
+
+		for _, handler := range handlers {
+			b.dispatch(handler, evt)
+		}
+	}
+}
+
+// dispatch invokes a single handler, recovering from panics
+func (b *Bus) dispatch(handler event.Handler, evt *event.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.handlerTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Warning: event handler panicked on event %s (%s): %v", evt.ID, evt.Type, r)
+		}
+	}()
+
+	handler(ctx, evt)
+}
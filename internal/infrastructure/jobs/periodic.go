@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+)
+
+// LockPrefix prefixes the lock resource of every periodic job
+const LockPrefix = "jobs:"
+
+// Periodic runs a function on a fixed interval. With a Redis client, each
+// run first takes the job's distributed lock and keeps it until it expires
+// (nine tenths of the interval), so across all instances the job runs at
+// most about once per interval. Without one, every instance runs it.
+type Periodic struct {
+	name     string
+	interval time.Duration
+	redis    *redis.Client
+	run      func(ctx context.Context) error
+}
+
+// NewPeriodic creates a new periodic job
+func NewPeriodic(name string, interval time.Duration, redisClient *redis.Client, run func(ctx context.Context) error) *Periodic {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	return &Periodic{
+		name:     name,
+		interval: interval,
+		redis:    redisClient,
+		run:      run,
+	}
+}
+
+// Start runs the job on every tick until the context is cancelled
+func (p *Periodic) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce runs the job if this instance wins the lock for the current interval
+func (p *Periodic) RunOnce(ctx context.Context) {
+	if p.redis != nil {
+		lock := cache.NewLock(p.redis, LockPrefix+p.name)
+		acquired, err := lock.TryLock(ctx, p.interval*9/10)
+		if err != nil {
+			log.Printf("Warning: failed to acquire lock for job %s: %v", p.name, err)
+			return
+		}
+		if !acquired {
+			// Another instance ran this interval
+			return
+		}
+	}
+
+	if err := p.run(ctx); err != nil {
+		log.Printf("Warning: job %s failed: %v", p.name, err)
+	}
+}
@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates/digest.html templates/digest.txt templates/notification.txt templates/password_reset.txt
+var templateFS embed.FS
+
+var (
+	digestHTML = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(htmltemplate.FuncMap{
+		"section": func(heading string, todos []DigestTodo) map[string]interface{} {
+			return map[string]interface{}{"Heading": heading, "Todos": todos}
+		},
+	}).ParseFS(templateFS, "templates/digest.html"))
+	digestText = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/digest.txt"))
+)
+
+// Digest is the content of a digest email. Dates and times are already
+// formatted in the recipient's timezone.
+type Digest struct {
+	Username           string
+	Date               string
+	Timezone           string
+	Overdue            []DigestTodo
+	DueToday           []DigestTodo
+	DueThisWeek        []DigestTodo
+	CompletedYesterday []DigestTodo
+	UnsubscribeURL     string
+}
+
+// DigestTodo is a todo listed in a digest. When is the due date or, for
+// completed todos, the completion time.
+type DigestTodo struct {
+	Title    string
+	Priority string
+	When     string
+}
+
+// Empty reports whether the digest has nothing to list
+func (d *Digest) Empty() bool {
+	return len(d.Overdue) == 0 && len(d.DueToday) == 0 && len(d.DueThisWeek) == 0 && len(d.CompletedYesterday) == 0
+}
+
+// NewDigestMessage renders a digest as an HTML email with a plain-text
+// alternative. The unsubscribe link is also offered as a one-click
+// List-Unsubscribe header (RFC 8058).
+func NewDigestMessage(to string, digest *Digest) (*Message, error) {
+	var html, text bytes.Buffer
+	if err := digestHTML.Execute(&html, digest); err != nil {
+		return nil, err
+	}
+	if err := digestText.Execute(&text, digest); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:      to,
+		Subject: "Your todo digest for " + digest.Date,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + digest.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
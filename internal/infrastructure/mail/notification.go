@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"bytes"
+	texttemplate "text/template"
+)
+
+var notificationText = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/notification.txt"))
+
+// Notification is the content of a notification email
+type Notification struct {
+	Username string
+	// Message says what happened, such as who mentioned the user where
+	Message string
+	// Details is an optional excerpt, such as the text the user was
+	// mentioned in
+	Details string
+}
+
+// NewNotificationMessage renders a notification as a plain-text email with
+// the message as its subject
+func NewNotificationMessage(to string, notification *Notification) (*Message, error) {
+	var text bytes.Buffer
+	if err := notificationText.Execute(&text, notification); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:      to,
+		Subject: notification.Message,
+		Text:    text.String(),
+	}, nil
+}
@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"bytes"
+	texttemplate "text/template"
+)
+
+var passwordResetText = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/password_reset.txt"))
+
+// PasswordReset is the content of a password reset email
+type PasswordReset struct {
+	Username string
+	// ResetURL is the link carrying the reset token
+	ResetURL string
+	// ExpiresIn says how long the link works, such as "1h0m0s"
+	ExpiresIn string
+}
+
+// NewPasswordResetMessage renders a password reset link as a plain-text email
+func NewPasswordResetMessage(to string, reset *PasswordReset) (*Message, error) {
+	var text bytes.Buffer
+	if err := passwordResetText.Execute(&text, reset); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:      to,
+		Subject: "Reset your password",
+		Text:    text.String(),
+	}, nil
+}
@@ -0,0 +1,180 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is an email with a plain-text body and an optional HTML alternative
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra headers such as List-Unsubscribe
+	Headers map[string]string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config represents SMTP delivery configuration. Username may be empty for
+// servers without authentication, such as a local Mailpit or MailHog.
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPSender delivers messages through an SMTP server
+type SMTPSender struct {
+	config Config
+}
+
+// NewSMTPSender creates a new SMTP sender
+func NewSMTPSender(config Config) *SMTPSender {
+	return &SMTPSender{config: config}
+}
+
+// Send delivers the message, upgrading to TLS when the server offers it
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	body, err := buildMessage(s.config.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	// smtp.SendMail does not take a context; give up when it is cancelled
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(s.config.Host, s.config.Port), auth, envelopeAddress(s.config.From), []string{msg.To}, body)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	}
+}
+
+// buildMessage renders msg as a MIME message, multipart/alternative when it
+// has an HTML body
+func buildMessage(from string, msg *Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := map[string]string{
+		"From":         from,
+		"To":           msg.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         now.Format(time.RFC1123Z),
+		"MIME-Version": "1.0",
+	}
+	for name, value := range msg.Headers {
+		headers[name] = value
+	}
+
+	if msg.HTML == "" {
+		headers["Content-Type"] = "text/plain; charset=utf-8"
+		headers["Content-Transfer-Encoding"] = "quoted-printable"
+		writeHeaders(&buf, headers)
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	headers["Content-Type"] = fmt.Sprintf("multipart/alternative; boundary=%q", boundary)
+	writeHeaders(&buf, headers)
+
+	// Parts go from least to most preferred
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, part := range parts {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", part.contentType)
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// writeHeaders writes headers in a stable order followed by the blank line
+// that ends the header section
+func writeHeaders(buf *bytes.Buffer, headers map[string]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// Header values must not break out of their line
+		value := strings.NewReplacer("\r", "", "\n", "").Replace(headers[name])
+		fmt.Fprintf(buf, "%s: %s\r\n", name, value)
+	}
+	buf.WriteString("\r\n")
+}
+
+// writeQuotedPrintable writes body with quoted-printable encoding
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// newBoundary generates a random multipart boundary
+func newBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "todolist-" + hex.EncodeToString(b), nil
+}
+
+// envelopeAddress extracts the bare address from a From header value such
+// as "Todo List <no-reply@example.com>"
+func envelopeAddress(from string) string {
+	if start := strings.LastIndex(from, "<"); start >= 0 {
+		if end := strings.LastIndex(from, ">"); end > start {
+			return from[start+1 : end]
+		}
+	}
+	return strings.TrimSpace(from)
+}
@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage_DigestAlternatives(t *testing.T) {
+	digest := &Digest{
+		Username:       "<script>",
+		Date:           "Monday, March 11",
+		Timezone:       "Europe/Paris",
+		Overdue:        []DigestTodo{{Title: "File taxes & receipts", Priority: "p1", When: "due Mar 9"}},
+		UnsubscribeURL: "https://todo.example.com/api/v1/digest/unsubscribe?token=7.abc",
+	}
+	msg, err := NewDigestMessage("ann@example.com", digest)
+	require.NoError(t, err)
+
+	raw, err := buildMessage("Todo List <no-reply@example.com>", msg, time.Date(2024, 3, 11, 7, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	parsed, err := netmail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "ann@example.com", parsed.Header.Get("To"))
+	assert.Equal(t, "<"+digest.UnsubscribeURL+">", parsed.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", parsed.Header.Get("List-Unsubscribe-Post"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := map[string]string{}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[partType] = string(body)
+	}
+
+	assert.Contains(t, bodies["text/plain"], "Hi <script>,")
+	assert.Contains(t, bodies["text/plain"], "File taxes & receipts")
+	assert.Contains(t, bodies["text/html"], "Hi &lt;script&gt;,")
+	assert.Contains(t, bodies["text/html"], "File taxes &amp; receipts")
+	assert.Contains(t, bodies["text/html"], `href="https://todo.example.com/api/v1/digest/unsubscribe?token=7.abc"`)
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	assert.Equal(t, "no-reply@example.com", envelopeAddress("Todo List <no-reply@example.com>"))
+	assert.Equal(t, "no-reply@example.com", envelopeAddress(" no-reply@example.com "))
+}
@@ -0,0 +1,189 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+)
+
+const (
+	// LockResource is the distributed lock that elects a single relaying instance
+	LockResource = "outbox:relay"
+
+	// cleanupInterval is how often published messages are purged
+	cleanupInterval = time.Hour
+)
+
+// Config represents outbox relay configuration
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	LockTTL      time.Duration
+	Retention    time.Duration
+}
+
+// DefaultConfig returns the default outbox relay configuration
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		LockTTL:      30 * time.Second,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+// Relay publishes outbox messages to a sink. Delivery is at-least-once: a
+// message is marked published only after the sink accepts it. Messages of
+// the same aggregate are published in the order they were recorded; when one
+// fails, later messages of that aggregate wait for the next poll.
+type Relay struct {
+	repo   repository.OutboxRepository
+	sink   Sink
+	redis  *redis.Client
+	config Config
+
+	lastCleanup time.Time
+}
+
+// NewRelay creates a new outbox relay. With a Redis client, only the
+// instance holding the relay lock publishes; without one, every call relays.
+func NewRelay(repo repository.OutboxRepository, sink Sink, redisClient *redis.Client, config Config) *Relay {
+	defaults := DefaultConfig()
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = defaults.LockTTL
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+
+	return &Relay{
+		repo:   repo,
+		sink:   sink,
+		redis:  redisClient,
+		config: config,
+	}
+}
+
+// Start polls the outbox until the context is cancelled
+func (r *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.poll(ctx)
+		}
+	}
+}
+
+// poll relays pending messages while holding the relay lock
+func (r *Relay) poll(ctx context.Context) {
+	if r.redis != nil {
+		lock := cache.NewLock(r.redis, LockResource)
+		acquired, err := lock.TryLock(ctx, r.config.LockTTL)
+		if err != nil {
+			log.Printf("Warning: failed to acquire outbox relay lock: %v", err)
+			return
+		}
+		if !acquired {
+			// Another instance is relaying
+			return
+		}
+		defer func() {
+			if err := lock.Unlock(context.Background()); err != nil {
+				log.Printf("Warning: failed to release outbox relay lock: %v", err)
+			}
+		}()
+	}
+
+	// Drain the backlog, leaving headroom before the lock expires
+	deadline := time.Now().Add(r.config.LockTTL / 2)
+	for time.Now().Before(deadline) {
+		processed, err := r.RelayBatch(ctx)
+		if err != nil {
+			log.Printf("Warning: failed to relay outbox messages: %v", err)
+			return
+		}
+		if processed < r.config.BatchSize {
+			break
+		}
+	}
+
+	if time.Since(r.lastCleanup) >= cleanupInterval {
+		r.cleanup(ctx)
+	}
+}
+
+// RelayBatch publishes the oldest unpublished messages and returns how many
+// were fetched
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	messages, err := r.repo.FindUnpublished(ctx, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Aggregates with a failed message; their later messages must wait
+	blocked := make(map[string]bool)
+
+	for _, message := range messages {
+		key := message.AggregateKey()
+		if blocked[key] {
+			continue
+		}
+
+		if err := r.publish(ctx, message); err != nil {
+			blocked[key] = true
+			if err := r.repo.MarkFailed(ctx, message.ID, err.Error()); err != nil {
+				log.Printf("Warning: failed to record outbox failure for message %d: %v", message.ID, err)
+			}
+			continue
+		}
+
+		if err := r.repo.MarkPublished(ctx, message.ID, time.Now()); err != nil {
+			// The message will be published again; consumers deduplicate by event ID
+			blocked[key] = true
+			log.Printf("Warning: failed to mark outbox message %d as published: %v", message.ID, err)
+		}
+	}
+
+	return len(messages), nil
+}
+
+// publish decodes a message and hands it to the sink
+func (r *Relay) publish(ctx context.Context, message *entity.OutboxMessage) error {
+	var evt event.Event
+	if err := json.Unmarshal([]byte(message.Payload), &evt); err != nil {
+		return err
+	}
+	return r.sink.Publish(ctx, &evt)
+}
+
+// cleanup deletes published messages older than the retention period
+func (r *Relay) cleanup(ctx context.Context) {
+	r.lastCleanup = time.Now()
+
+	deleted, err := r.repo.DeletePublishedBefore(ctx, time.Now().Add(-r.config.Retention))
+	if err != nil {
+		log.Printf("Warning: failed to purge published outbox messages: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Purged %d published outbox messages", deleted)
+	}
+}
@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRepository is an in-memory outbox repository
+type memoryRepository struct {
+	messages []*entity.OutboxMessage
+}
+
+func (r *memoryRepository) Create(ctx context.Context, message *entity.OutboxMessage) error {
+	message.ID = int64(len(r.messages) + 1)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *memoryRepository) FindUnpublished(ctx context.Context, limit int) ([]*entity.OutboxMessage, error) {
+	var pending []*entity.OutboxMessage
+	for _, message := range r.messages {
+		if message.PublishedAt == nil && len(pending) < limit {
+			pending = append(pending, message)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+	return pending, nil
+}
+
+func (r *memoryRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	r.messages[id-1].PublishedAt = &publishedAt
+	return nil
+}
+
+func (r *memoryRepository) MarkFailed(ctx context.Context, id int64, lastError string) error {
+	r.messages[id-1].Attempts++
+	r.messages[id-1].LastError = lastError
+	return nil
+}
+
+func (r *memoryRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// recordingSink records published events and fails those listed in failing
+type recordingSink struct {
+	published []*event.Event
+	failing   map[string]bool
+}
+
+func (s *recordingSink) Publish(ctx context.Context, evt *event.Event) error {
+	if s.failing[evt.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, evt)
+	return nil
+}
+
+func record(t *testing.T, repo *memoryRepository, eventType event.Type, aggregateType string, aggregateID int64) *event.Event {
+	t.Helper()
+
+	evt, err := event.New(eventType, 1, aggregateType, aggregateID, map[string]int64{"id": aggregateID})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(evt)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Create(context.Background(), &entity.OutboxMessage{
+		EventID:       evt.ID,
+		EventType:     string(evt.Type),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		UserID:        evt.UserID,
+		Payload:       string(payload),
+	}))
+	return evt
+}
+
+func eventIDs(events []*event.Event) []string {
+	ids := make([]string, len(events))
+	for i, evt := range events {
+		ids[i] = evt.ID
+	}
+	return ids
+}
+
+func TestRelayBatch_PublishesInOrder(t *testing.T) {
+	repo := &memoryRepository{}
+	sink := &recordingSink{}
+	relay := NewRelay(repo, sink, nil, Config{BatchSize: 10})
+
+	first := record(t, repo, event.TodoCreated, "todo", 1)
+	second := record(t, repo, event.TagCreated, "tag", 7)
+	third := record(t, repo, event.TodoUpdated, "todo", 1)
+
+	processed, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, processed)
+	assert.Equal(t, []string{first.ID, second.ID, third.ID}, eventIDs(sink.published))
+	assert.Equal(t, event.TodoCreated, sink.published[0].Type)
+
+	pending, _ := repo.FindUnpublished(context.Background(), 10)
+	assert.Empty(t, pending)
+}
+
+func TestRelayBatch_FailureBlocksOnlyThatAggregate(t *testing.T) {
+	repo := &memoryRepository{}
+	sink := &recordingSink{failing: map[string]bool{}}
+	relay := NewRelay(repo, sink, nil, Config{BatchSize: 10})
+
+	created := record(t, repo, event.TodoCreated, "todo", 1)
+	other := record(t, repo, event.TodoCreated, "todo", 2)
+	updated := record(t, repo, event.TodoUpdated, "todo", 1)
+	sink.failing[created.ID] = true
+
+	_, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+
+	// todo 1 is held back behind its failed message; todo 2 is unaffected
+	assert.Equal(t, []string{other.ID}, eventIDs(sink.published))
+	assert.Equal(t, 1, repo.messages[0].Attempts)
+	assert.Equal(t, "sink unavailable", repo.messages[0].LastError)
+	assert.Nil(t, repo.messages[2].PublishedAt)
+
+	// Once the sink recovers, todo 1's messages go out in their original order
+	delete(sink.failing, created.ID)
+	_, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{other.ID, created.ID, updated.ID}, eventIDs(sink.published))
+}
+
+func TestMultiSink_StopsAtFirstError(t *testing.T) {
+	evt, err := event.New(event.TagDeleted, 1, "tag", 3, nil)
+	require.NoError(t, err)
+
+	first := &recordingSink{}
+	failing := &recordingSink{failing: map[string]bool{evt.ID: true}}
+	last := &recordingSink{}
+
+	err = MultiSink{first, failing, last}.Publish(context.Background(), evt)
+
+	assert.Error(t, err)
+	assert.Len(t, first.published, 1)
+	assert.Empty(t, last.published)
+}
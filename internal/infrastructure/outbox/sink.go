@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	"github.com/darron08/todolist-demo/internal/infrastructure/eventbus"
+)
+
+// Sink receives events relayed from the outbox. Publish must not return
+// until the event is safely handed over; an error makes the relay retry it.
+type Sink interface {
+	Publish(ctx context.Context, evt *event.Event) error
+}
+
+// BusSink hands events to the in-process event bus
+type BusSink struct {
+	bus *eventbus.Bus
+}
+
+// NewBusSink creates a sink that publishes to the in-process event bus
+func NewBusSink(bus *eventbus.Bus) *BusSink {
+	return &BusSink{bus: bus}
+}
+
+// Publish enqueues the event on the bus, waiting for queue space
+func (s *BusSink) Publish(ctx context.Context, evt *event.Event) error {
+	return s.bus.PublishWait(ctx, evt)
+}
+
+// RedisStreamSink appends events to a Redis stream for external consumers
+type RedisStreamSink struct {
+	redis  *redis.Client
+	key    string
+	maxLen int64
+}
+
+// NewRedisStreamSink creates a sink that appends to the given Redis stream,
+// trimming it to approximately maxLen entries
+func NewRedisStreamSink(redisClient *redis.Client, key string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{
+		redis:  redisClient,
+		key:    key,
+		maxLen: maxLen,
+	}
+}
+
+// Publish appends the event to the stream
+func (s *RedisStreamSink) Publish(ctx context.Context, evt *event.Event) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	return s.redis.XAdd(ctx, &goredis.XAddArgs{
+		Stream: s.key,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"id":    evt.ID,
+			"type":  string(evt.Type),
+			"event": payload,
+		},
+	}).Err()
+}
+
+// MultiSink publishes every event to several sinks in order. If a sink
+// fails, the event is retried on all of them, so consumers must tolerate
+// duplicates (deduplicate by event ID).
+type MultiSink []Sink
+
+// Publish publishes the event to each sink, stopping at the first error
+func (m MultiSink) Publish(ctx context.Context, evt *event.Event) error {
+	for _, sink := range m {
+		if err := sink.Publish(ctx, evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+)
+
+const (
+	// ChannelName is the Redis pub/sub channel shared by all API instances
+	ChannelName = "realtime:events"
+
+	// StreamKeyFormat is the per-user Redis stream that backs Last-Event-ID
+	// resume, scoped by the user's tenant
+	StreamKeyFormat = "realtime:events:tenant:%d:user:%d"
+)
+
+// Config represents realtime hub configuration
+type Config struct {
+	StreamMaxLen int64
+	StreamTTL    time.Duration
+	ClientBuffer int
+}
+
+// DefaultConfig returns the default realtime hub configuration
+func DefaultConfig() Config {
+	return Config{
+		StreamMaxLen: 1000,
+		StreamTTL:    24 * time.Hour,
+		ClientBuffer: 64,
+	}
+}
+
+// Message is an event tagged with its position in the user's replay stream
+type Message struct {
+	ID    string       `json:"id"`
+	Event *event.Event `json:"event"`
+}
+
+// Hub fans out domain events to connected clients. Events are appended to a
+// per-user Redis stream for replay and broadcast over Redis pub/sub so that
+// clients connected to any API instance receive them.
+type Hub struct {
+	redis  *redis.Client
+	config Config
+
+	mu            sync.RWMutex
+	subscriptions map[subscriber]map[*Subscription]struct{}
+}
+
+// subscriber identifies the user, within a tenant, a subscription belongs to
+type subscriber struct {
+	tenantID int64
+	userID   int64
+}
+
+// Subscription receives live messages for a single user of a tenant
+type Subscription struct {
+	TenantID int64
+	UserID   int64
+
+	hub      *Hub
+	messages chan *Message
+	once     sync.Once
+}
+
+// NewHub creates a new realtime hub
+func NewHub(redis *redis.Client, config Config) *Hub {
+	defaults := DefaultConfig()
+	if config.StreamMaxLen <= 0 {
+		config.StreamMaxLen = defaults.StreamMaxLen
+	}
+	if config.StreamTTL <= 0 {
+		config.StreamTTL = defaults.StreamTTL
+	}
+	if config.ClientBuffer <= 0 {
+		config.ClientBuffer = defaults.ClientBuffer
+	}
+
+	return &Hub{
+		redis:         redis,
+		config:        config,
+		subscriptions: make(map[subscriber]map[*Subscription]struct{}),
+	}
+}
+
+// HandleEvent records a todo or tag event in the user's replay stream and
+// broadcasts it to every API instance. It is intended to be subscribed to
+// the in-process event bus.
+func (h *Hub) HandleEvent(ctx context.Context, evt *event.Event) {
+	if !Streamable(evt.Type) {
+		return
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		log.Printf("Warning: failed to encode realtime event %s: %v", evt.ID, err)
+		return
+	}
+
+	key := StreamKey(evt.OrganizationID, evt.UserID)
+	id, err := h.redis.XAdd(ctx, &goredis.XAddArgs{
+		Stream: key,
+		MaxLen: h.config.StreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"event": payload},
+	}).Result()
+	if err != nil {
+		log.Printf("Warning: failed to append realtime event %s: %v", evt.ID, err)
+		return
+	}
+
+	// Keep replay streams of inactive users from living forever
+	if err := h.redis.Expire(ctx, key, h.config.StreamTTL); err != nil {
+		log.Printf("Warning: failed to set realtime stream TTL for user %d: %v", evt.UserID, err)
+	}
+
+	message, err := json.Marshal(&Message{ID: id, Event: evt})
+	if err != nil {
+		return
+	}
+
+	if err := h.redis.Publish(ctx, ChannelName, message).Err(); err != nil {
+		log.Printf("Warning: failed to broadcast realtime event %s: %v", evt.ID, err)
+	}
+}
+
+// Start listens on the shared pub/sub channel and delivers messages to local
+// subscriptions until the context is cancelled
+func (h *Hub) Start(ctx context.Context) {
+	pubsub := h.redis.Subscribe(ctx, ChannelName)
+	defer pubsub.Close()
+
+	channel := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-channel:
+			if !ok {
+				return
+			}
+
+			var message Message
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil || message.Event == nil {
+				log.Printf("Warning: discarding malformed realtime message: %v", err)
+				continue
+			}
+
+			h.deliver(&message)
+		}
+	}
+}
+
+// Subscribe registers a live subscription for a user of a tenant
+func (h *Hub) Subscribe(tenantID, userID int64) *Subscription {
+	sub := &Subscription{
+		TenantID: tenantID,
+		UserID:   userID,
+		hub:      h,
+		messages: make(chan *Message, h.config.ClientBuffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := subscriber{tenantID: tenantID, userID: userID}
+	if h.subscriptions[key] == nil {
+		h.subscriptions[key] = make(map[*Subscription]struct{})
+	}
+	h.subscriptions[key][sub] = struct{}{}
+
+	return sub
+}
+
+// Replay returns the messages recorded after lastID in the user's stream
+// within a tenant
+func (h *Hub) Replay(ctx context.Context, tenantID, userID int64, lastID string) ([]*Message, error) {
+	if lastID == "" {
+		return nil, nil
+	}
+	if !ValidStreamID(lastID) {
+		return nil, fmt.Errorf("invalid event id: %s", lastID)
+	}
+
+	entries, err := h.redis.XRange(ctx, StreamKey(tenantID, userID), lastID, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*Message, 0, len(entries))
+	for _, entry := range entries {
+		// XRANGE is inclusive; the client already has lastID
+		if entry.ID == lastID {
+			continue
+		}
+
+		raw, ok := entry.Values["event"].(string)
+		if !ok {
+			continue
+		}
+
+		var evt event.Event
+		if err := json.Unmarshal([]byte(raw), &evt); err != nil || evt.OrganizationID != tenantID {
+			continue
+		}
+
+		messages = append(messages, &Message{ID: entry.ID, Event: &evt})
+	}
+
+	return messages, nil
+}
+
+// deliver hands a message to every local subscription of its user in the
+// event's tenant. A
+// subscription whose buffer is full is closed so that the client reconnects
+// and catches up through Last-Event-ID instead of silently missing events.
+func (h *Hub) deliver(message *Message) {
+	key := subscriber{tenantID: message.Event.OrganizationID, userID: message.Event.UserID}
+
+	h.mu.RLock()
+	subs := make([]*Subscription, 0, len(h.subscriptions[key]))
+	for sub := range h.subscriptions[key] {
+		subs = append(subs, sub)
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.messages <- message:
+		default:
+			log.Printf("Warning: realtime client for user %d is too slow, disconnecting", sub.UserID)
+			sub.Close()
+		}
+	}
+}
+
+// remove unregisters a subscription
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := subscriber{tenantID: sub.TenantID, userID: sub.UserID}
+	subs := h.subscriptions[key]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscriptions, key)
+	}
+}
+
+// Messages returns the channel of live messages. It is closed when the
+// subscription is closed.
+func (s *Subscription) Messages() <-chan *Message {
+	return s.messages
+}
+
+// Close unregisters the subscription and closes its message channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+		close(s.messages)
+	})
+}
+
+// StreamKey returns the replay stream key of a user of a tenant
+func StreamKey(tenantID, userID int64) string {
+	return fmt.Sprintf(StreamKeyFormat, tenantID, userID)
+}
+
+// Streamable reports whether an event type is pushed to realtime clients
+func Streamable(eventType event.Type) bool {
+	return eventType.Matches("todo.*") || eventType.Matches("tag.*")
+}
+
+// ValidStreamID reports whether id is a Redis stream ID ("<ms>-<seq>")
+func ValidStreamID(id string) bool {
+	_, _, ok := parseStreamID(id)
+	return ok
+}
+
+// CompareStreamIDs compares two Redis stream IDs, returning -1, 0 or 1.
+// Malformed IDs sort before well-formed ones.
+func CompareStreamIDs(a, b string) int {
+	aMs, aSeq, aOK := parseStreamID(a)
+	bMs, bSeq, bOK := parseStreamID(b)
+
+	switch {
+	case !aOK && !bOK:
+		return 0
+	case !aOK:
+		return -1
+	case !bOK:
+		return 1
+	}
+
+	switch {
+	case aMs < bMs:
+		return -1
+	case aMs > bMs:
+		return 1
+	case aSeq < bSeq:
+		return -1
+	case aSeq > bSeq:
+		return 1
+	}
+	return 0
+}
+
+// parseStreamID splits a Redis stream ID into its millisecond and sequence parts
+func parseStreamID(id string) (uint64, uint64, bool) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return ms, seq, true
+}
@@ -0,0 +1,99 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+)
+
+func TestCompareStreamIDs(t *testing.T) {
+	assert.Equal(t, 0, CompareStreamIDs("1700000000000-0", "1700000000000-0"))
+	assert.Equal(t, -1, CompareStreamIDs("1700000000000-0", "1700000000000-1"))
+	assert.Equal(t, 1, CompareStreamIDs("1700000000001-0", "1700000000000-9"))
+	// Numeric, not lexicographic, comparison
+	assert.Equal(t, -1, CompareStreamIDs("999-0", "1000-0"))
+	assert.Equal(t, -1, CompareStreamIDs("bogus", "1-0"))
+}
+
+func TestValidStreamID(t *testing.T) {
+	assert.True(t, ValidStreamID("1700000000000-0"))
+	assert.False(t, ValidStreamID(""))
+	assert.False(t, ValidStreamID("1700000000000"))
+	assert.False(t, ValidStreamID("abc-1"))
+	assert.False(t, ValidStreamID("1-2-3"))
+}
+
+func TestStreamable(t *testing.T) {
+	for _, eventType := range event.AllTypes() {
+		if eventType.Matches("user.*") {
+			continue
+		}
+		assert.True(t, Streamable(eventType), eventType)
+	}
+	assert.False(t, Streamable(event.UserCreated))
+	assert.False(t, Streamable(event.UserDeleted))
+}
+
+func TestSubscriptionDelivery(t *testing.T) {
+	hub := NewHub(nil, Config{ClientBuffer: 1})
+
+	sub := hub.Subscribe(1, 1)
+	other := hub.Subscribe(1, 2)
+	defer other.Close()
+	// The same user ID in another tenant is a different user
+	otherTenant := hub.Subscribe(2, 1)
+	defer otherTenant.Close()
+
+	message := &Message{ID: "1-0", Event: &event.Event{UserID: 1, OrganizationID: 1, Type: event.TodoCreated}}
+	hub.deliver(message)
+
+	assert.Equal(t, message, <-sub.Messages())
+	assert.Empty(t, other.Messages())
+	assert.Empty(t, otherTenant.Messages())
+
+	// A full buffer disconnects the slow subscription
+	hub.deliver(message)
+	hub.deliver(message)
+	<-sub.Messages()
+	_, ok := <-sub.Messages()
+	assert.False(t, ok)
+
+	sub.Close()
+}
+
+func TestReplayScopedByTenant(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := redis.NewConnection(&redis.Config{Host: server.Host(), Port: server.Port()})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	hub := NewHub(client, Config{})
+
+	// User 1 of two tenants
+	hub.HandleEvent(ctx, &event.Event{ID: "a", UserID: 1, OrganizationID: 1, Type: event.TodoCreated})
+	hub.HandleEvent(ctx, &event.Event{ID: "b", UserID: 1, OrganizationID: 2, Type: event.TodoCreated})
+
+	messages, err := hub.Replay(ctx, 1, 1, "0-1")
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "a", messages[0].Event.ID)
+
+	// An event recorded in a tenant's stream under another tenant is dropped
+	_, err = client.GetClient().XAdd(ctx, &goredis.XAddArgs{
+		Stream: StreamKey(2, 1),
+		Values: map[string]interface{}{"event": `{"id":"c","user_id":1,"organization_id":1,"type":"todo.created"}`},
+	}).Result()
+	require.NoError(t, err)
+	messages, err = hub.Replay(ctx, 2, 1, "0-1")
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "b", messages[0].Event.ID)
+}
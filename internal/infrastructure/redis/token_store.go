@@ -2,21 +2,62 @@ package redis
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	goredis "github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 )
 
 const (
 	// Redis key prefixes
-	refreshTokenKeyPrefix = "refresh_token:"
+	refreshTokenKeyPrefix       = "refresh_token:"
+	passwordResetKeyPrefix      = "password_reset:"
+	passwordResetEmailKeyPrefix = "password_reset_email:"
 
 	// Default expiry times
 	refreshTokenExpiry = 7 * 24 * time.Hour // 7 days
 )
 
+// The password reset scripts each touch the single key they are given, so
+// that they run under Redis Cluster and ACL key patterns.
+
+// swapResetScript points an email at the hash of its latest password reset
+// token, returning the hash it pointed at before
+var swapResetScript = goredis.NewScript(`
+local previous = redis.call('GET', KEYS[1])
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return previous
+`)
+
+// takeResetScript deletes a password reset token, returning the email it was
+// issued to
+var takeResetScript = goredis.NewScript(`
+local email = redis.call('GET', KEYS[1])
+if email then
+	redis.call('DEL', KEYS[1])
+end
+return email
+`)
+
+// releaseResetScript drops an email's pointer to a token unless a newer
+// token replaced it
+var releaseResetScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// ErrResetTokenNotFound is returned for password reset tokens that were
+// never issued, have expired or were already used
+var ErrResetTokenNotFound = errors.New("password reset token not found")
+
 // TokenStore manages token storage in Redis
 type TokenStore struct {
 	client *redis.Client
@@ -53,16 +94,58 @@ func (s *TokenStore) DeleteRefreshToken(ctx context.Context, userID int64, token
 
 // DeleteAllUserTokens deletes all refresh tokens for a user
 func (s *TokenStore) DeleteAllUserTokens(ctx context.Context, userID int64) error {
-	// Build pattern for all user tokens
-	pattern := s.buildRefreshTokenPattern(userID)
+	if _, err := s.client.DelPattern(ctx, s.buildRefreshTokenPattern(userID)); err != nil {
+		return fmt.Errorf("failed to delete refresh tokens: %w", err)
+	}
+	return nil
+}
 
-	// Note: This is a simplified implementation
-	// For production, you might want to use Redis SCAN for better performance
-	_ = pattern // Use pattern in production with SCAN
+// StorePasswordResetToken stores a password reset token for an email and
+// revokes the one issued before. Only a hash of the token is stored.
+//
+// The token is stored before the email points at it, and whichever token the
+// email points at last survives, so that at most one token works per email
+// even when requests race.
+func (s *TokenStore) StorePasswordResetToken(ctx context.Context, email, token string, ttl time.Duration) error {
+	hash := hashToken(token)
+	if err := s.client.Set(ctx, passwordResetKeyPrefix+hash, email, ttl); err != nil {
+		return fmt.Errorf("failed to store password reset token: %w", err)
+	}
+
+	previous, err := swapResetScript.Run(ctx, s.client.Client, []string{s.buildPasswordResetEmailKey(email)},
+		hash, ttl.Milliseconds()).Text()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil
+		}
+		return fmt.Errorf("failed to store password reset token: %w", err)
+	}
 
-	// For now, just mark user tokens as invalid
-	key := fmt.Sprintf("%s:%s:all", refreshTokenKeyPrefix, userID)
-	return s.client.Set(ctx, key, "1", time.Nanosecond)
+	if previous != hash {
+		if err := s.client.Del(ctx, passwordResetKeyPrefix+previous); err != nil {
+			return fmt.Errorf("failed to revoke previous password reset token: %w", err)
+		}
+	}
+	return nil
+}
+
+// ConsumePasswordResetToken returns the email a password reset token was
+// issued to and deletes it, so that each token is used at most once
+func (s *TokenStore) ConsumePasswordResetToken(ctx context.Context, token string) (string, error) {
+	hash := hashToken(token)
+	email, err := takeResetScript.Run(ctx, s.client.Client, []string{passwordResetKeyPrefix + hash}).Text()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return "", ErrResetTokenNotFound
+		}
+		return "", fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	// The token is already used up; a pointer left behind only names a token
+	// that is gone and expires with it
+	_ = releaseResetScript.Run(ctx, s.client.Client, []string{s.buildPasswordResetEmailKey(email)}, hash).Err()
+
+	return email, nil
 }
 
 // GenerateTokenID generates a unique token ID
@@ -70,6 +153,22 @@ func GenerateTokenID() string {
 	return uuid.New().String()
 }
 
+// GeneratePasswordResetToken generates a random, URL-safe password reset token
+func GeneratePasswordResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken hashes a token for storage, so that tokens cannot be read back
+// from Redis
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
 // buildRefreshTokenKey builds a Redis key for a refresh token
 func (s *TokenStore) buildRefreshTokenKey(userID int64, tokenID string) string {
 	return fmt.Sprintf("%s%d:%s", refreshTokenKeyPrefix, userID, tokenID)
@@ -79,3 +178,9 @@ func (s *TokenStore) buildRefreshTokenKey(userID int64, tokenID string) string {
 func (s *TokenStore) buildRefreshTokenPattern(userID int64) string {
 	return fmt.Sprintf("%s%d:*", refreshTokenKeyPrefix, userID)
 }
+
+// buildPasswordResetEmailKey builds a Redis key for the hash of the latest
+// password reset token of an email
+func (s *TokenStore) buildPasswordResetEmailKey(email string) string {
+	return passwordResetEmailKeyPrefix + email
+}
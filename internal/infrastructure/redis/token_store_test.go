@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenStore(t *testing.T) (*TokenStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client, err := redis.NewConnection(&redis.Config{Host: server.Host(), Port: server.Port()})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return NewTokenStore(client), server
+}
+
+func TestTokenStore_PasswordResetTokens(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestTokenStore(t)
+
+	require.NoError(t, store.StorePasswordResetToken(ctx, "ann@example.com", "first", time.Hour))
+	require.NoError(t, store.StorePasswordResetToken(ctx, "bob@example.com", "other", time.Hour))
+
+	// A second request revokes the first token
+	require.NoError(t, store.StorePasswordResetToken(ctx, "ann@example.com", "second", time.Hour))
+	_, err := store.ConsumePasswordResetToken(ctx, "first")
+	assert.ErrorIs(t, err, ErrResetTokenNotFound)
+	assert.False(t, server.Exists(passwordResetKeyPrefix+hashToken("first")))
+
+	// Each token works once, and only for its own email
+	email, err := store.ConsumePasswordResetToken(ctx, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "ann@example.com", email)
+	_, err = store.ConsumePasswordResetToken(ctx, "second")
+	assert.ErrorIs(t, err, ErrResetTokenNotFound)
+	assert.False(t, server.Exists(store.buildPasswordResetEmailKey("ann@example.com")))
+
+	email, err = store.ConsumePasswordResetToken(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", email)
+
+	// Tokens are stored hashed and expire
+	require.NoError(t, store.StorePasswordResetToken(ctx, "ann@example.com", "third", time.Hour))
+	assert.False(t, server.Exists(passwordResetKeyPrefix+"third"))
+	server.FastForward(time.Hour)
+	_, err = store.ConsumePasswordResetToken(ctx, "third")
+	assert.ErrorIs(t, err, ErrResetTokenNotFound)
+	assert.False(t, server.Exists(store.buildPasswordResetEmailKey("ann@example.com")))
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// NotificationRepositoryImpl implements repository.NotificationRepository interface
+type NotificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) repository.NotificationRepository {
+	return &NotificationRepositoryImpl{db: db}
+}
+
+// Create stores a notification in the tenant bound to ctx, ignoring events
+// the user was already notified of
+func (r *NotificationRepositoryImpl) Create(ctx context.Context, notification *entity.Notification) (bool, error) {
+	orgID, err := assignTenant(ctx, notification.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	notification.OrganizationID = orgID
+
+	result := withContext(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByUserID finds a user's notifications with pagination, newest first
+func (r *NotificationRepositoryImpl) FindByUserID(ctx context.Context, userID int64, unreadOnly bool, offset, limit int) ([]*entity.Notification, int64, error) {
+	var notifications []*entity.Notification
+	var total int64
+
+	query := scoped(ctx, r.db).Model(&entity.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&notifications)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return notifications, total, nil
+}
+
+// CountUnread counts a user's unread notifications
+func (r *NotificationRepositoryImpl) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	result := scoped(ctx, r.db).Model(&entity.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications as read. Notifications
+// already read keep the time they were first read.
+func (r *NotificationRepositoryImpl) MarkRead(ctx context.Context, userID, id int64, readAt time.Time) error {
+	var notification entity.Notification
+	result := scoped(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&notification)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return ErrNotificationNotFound
+		}
+		return result.Error
+	}
+	if notification.IsRead() {
+		return nil
+	}
+
+	return withContext(ctx, r.db).Model(&notification).Update("read_at", readAt).Error
+}
+
+// MarkAllRead marks all of a user's unread notifications as read
+func (r *NotificationRepositoryImpl) MarkAllRead(ctx context.Context, userID int64, readAt time.Time) (int64, error) {
+	result := scoped(ctx, r.db).Model(&entity.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// NotificationPreferenceRepositoryImpl implements repository.NotificationPreferenceRepository interface
+type NotificationPreferenceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewNotificationPreferenceRepository creates a new notification preference repository
+func NewNotificationPreferenceRepository(db *gorm.DB) repository.NotificationPreferenceRepository {
+	return &NotificationPreferenceRepositoryImpl{db: db}
+}
+
+// FindByUserID finds the preferences a user has set, by event type
+func (r *NotificationPreferenceRepositoryImpl) FindByUserID(ctx context.Context, userID int64) ([]*entity.NotificationPreference, error) {
+	var preferences []*entity.NotificationPreference
+	result := withContext(ctx, r.db).Where("user_id = ?", userID).Order("event_type ASC").Find(&preferences)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return preferences, nil
+}
+
+// Save creates or replaces preferences
+func (r *NotificationPreferenceRepositoryImpl) Save(ctx context.Context, preferences []*entity.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	return withContext(ctx, r.db).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"inbox", "email", "updated_at"}),
+	}).Create(&preferences).Error
+}
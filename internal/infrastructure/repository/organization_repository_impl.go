@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrMemberExists         = errors.New("member already exists")
+)
+
+// OrganizationRepositoryImpl implements repository.OrganizationRepository interface
+type OrganizationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a new organization repository
+func NewOrganizationRepository(db *gorm.DB) repository.OrganizationRepository {
+	return &OrganizationRepositoryImpl{db: db}
+}
+
+// Create creates an organization together with its first member
+func (r *OrganizationRepositoryImpl) Create(ctx context.Context, org *entity.Organization, owner *entity.OrganizationMember) error {
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+
+		owner.OrganizationID = org.ID
+		return tx.Omit("Organization", "User").Create(owner).Error
+	})
+}
+
+// FindByID finds an organization by ID
+func (r *OrganizationRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Organization, error) {
+	var org entity.Organization
+	result := withContext(ctx, r.db).Where("id = ? AND deleted_at IS NULL", id).First(&org)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, result.Error
+	}
+	return &org, nil
+}
+
+// FindMemberships finds a user's memberships with their organization, oldest first
+func (r *OrganizationRepositoryImpl) FindMemberships(ctx context.Context, userID int64) ([]*entity.OrganizationMember, error) {
+	var members []*entity.OrganizationMember
+	result := withContext(ctx, r.db).
+		Joins("Organization").
+		Where("organization_members.user_id = ? AND Organization.deleted_at IS NULL", userID).
+		Order("organization_members.created_at ASC, organization_members.organization_id ASC").
+		Find(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
+
+// FindMember finds a user's membership in an organization
+func (r *OrganizationRepositoryImpl) FindMember(ctx context.Context, orgID, userID int64) (*entity.OrganizationMember, error) {
+	var member entity.OrganizationMember
+	result := withContext(ctx, r.db).Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrMemberNotFound
+		}
+		return nil, result.Error
+	}
+	return &member, nil
+}
+
+// ListMembers finds an organization's members with their user, oldest first
+func (r *OrganizationRepositoryImpl) ListMembers(ctx context.Context, orgID int64) ([]*entity.OrganizationMember, error) {
+	var members []*entity.OrganizationMember
+	result := withContext(ctx, r.db).
+		Joins("User").
+		Where("organization_members.organization_id = ? AND User.deleted_at IS NULL", orgID).
+		Order("organization_members.created_at ASC, organization_members.user_id ASC").
+		Find(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
+
+// AddMember adds a user to an organization
+func (r *OrganizationRepositoryImpl) AddMember(ctx context.Context, member *entity.OrganizationMember) error {
+	if _, err := r.FindMember(ctx, member.OrganizationID, member.UserID); err == nil {
+		return ErrMemberExists
+	} else if err != ErrMemberNotFound {
+		return err
+	}
+
+	return withContext(ctx, r.db).Omit("Organization", "User").Create(member).Error
+}
+
+// UpdateMember updates a member's role
+func (r *OrganizationRepositoryImpl) UpdateMember(ctx context.Context, member *entity.OrganizationMember) error {
+	result := withContext(ctx, r.db).Model(&entity.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", member.OrganizationID, member.UserID).
+		Update("role", member.Role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// RemoveMember removes a user from an organization
+func (r *OrganizationRepositoryImpl) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	result := withContext(ctx, r.db).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Delete(&entity.OrganizationMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// CountMembersByRole counts an organization's members with a role
+func (r *OrganizationRepositoryImpl) CountMembersByRole(ctx context.Context, orgID int64, role entity.OrganizationRole) (int64, error) {
+	var count int64
+	result := withContext(ctx, r.db).Model(&entity.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", orgID, role).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
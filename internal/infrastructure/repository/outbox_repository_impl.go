@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+// OutboxRepositoryImpl implements repository.OutboxRepository interface
+type OutboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &OutboxRepositoryImpl{db: db}
+}
+
+// Create stores an outbox message. Call it with a transactional context so
+// the message is committed together with the change it describes.
+func (r *OutboxRepositoryImpl) Create(ctx context.Context, message *entity.OutboxMessage) error {
+	return withContext(ctx, r.db).Create(message).Error
+}
+
+// FindUnpublished finds the oldest unpublished messages in insertion order
+func (r *OutboxRepositoryImpl) FindUnpublished(ctx context.Context, limit int) ([]*entity.OutboxMessage, error) {
+	var messages []*entity.OutboxMessage
+	result := withContext(ctx, r.db).
+		Where("published_at IS NULL").
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return messages, nil
+}
+
+// MarkPublished marks a message as published
+func (r *OutboxRepositoryImpl) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	return withContext(ctx, r.db).Model(&entity.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"published_at": publishedAt,
+			"last_error":   "",
+		}).Error
+}
+
+// MarkFailed records a failed publish attempt
+func (r *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id int64, lastError string) error {
+	if len(lastError) > 1024 {
+		lastError = lastError[:1024]
+	}
+
+	return withContext(ctx, r.db).Model(&entity.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": lastError,
+		}).Error
+}
+
+// DeletePublishedBefore deletes messages published before the given time
+func (r *OutboxRepositoryImpl) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).
+		Where("published_at IS NOT NULL AND published_at < ?", before).
+		Delete(&entity.OutboxMessage{})
+
+	return result.RowsAffected, result.Error
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
 	"github.com/darron08/todolist-demo/internal/domain/repository"
@@ -24,11 +25,17 @@ func NewTagRepository(db *gorm.DB) repository.TagRepository {
 	return &TagRepositoryImpl{db: db}
 }
 
-// Create creates a new tag
+// Create creates a new tag in the tenant bound to ctx
 func (r *TagRepositoryImpl) Create(ctx context.Context, tag *entity.Tag) error {
-	// Check if tag name already exists
+	orgID, err := assignTenant(ctx, tag.OrganizationID)
+	if err != nil {
+		return err
+	}
+	tag.OrganizationID = orgID
+
+	// Check if the owner already has a tag with this name in the organization
 	var existingTag entity.Tag
-	result := r.db.WithContext(ctx).Where("name = ? AND deleted_at IS NULL", tag.Name).First(&existingTag)
+	result := withContext(ctx, r.db).Where("organization_id = ?", tag.OrganizationID).Where("user_id = ? AND name = ? AND deleted_at IS NULL", tag.UserID, tag.Name).First(&existingTag)
 	if result.Error == nil {
 		return errors.New("tag with this name already exists")
 	}
@@ -36,13 +43,13 @@ func (r *TagRepositoryImpl) Create(ctx context.Context, tag *entity.Tag) error {
 		return result.Error
 	}
 
-	return r.db.WithContext(ctx).Create(tag).Error
+	return withContext(ctx, r.db).Create(tag).Error
 }
 
 // FindByID finds a tag by ID
 func (r *TagRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Tag, error) {
 	var tag entity.Tag
-	result := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&tag)
+	result := scoped(ctx, r.db).Where("id = ? AND deleted_at IS NULL", id).First(&tag)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, ErrTagNotFound
@@ -52,10 +59,10 @@ func (r *TagRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Tag
 	return &tag, nil
 }
 
-// FindByName finds a tag by name
-func (r *TagRepositoryImpl) FindByName(ctx context.Context, name string) (*entity.Tag, error) {
+// FindByName finds a user's tag by name
+func (r *TagRepositoryImpl) FindByName(ctx context.Context, userID int64, name string) (*entity.Tag, error) {
 	var tag entity.Tag
-	result := r.db.WithContext(ctx).Where("name = ? AND deleted_at IS NULL", name).First(&tag)
+	result := scoped(ctx, r.db).Where("user_id = ? AND name = ? AND deleted_at IS NULL", userID, name).First(&tag)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, ErrTagNotFound
@@ -65,9 +72,13 @@ func (r *TagRepositoryImpl) FindByName(ctx context.Context, name string) (*entit
 	return &tag, nil
 }
 
-// Update updates a tag
+// Update updates a tag. Tags of another tenant are reported as not found.
 func (r *TagRepositoryImpl) Update(ctx context.Context, tag *entity.Tag) error {
-	result := r.db.WithContext(ctx).Save(tag)
+	if !inCurrentTenant(ctx, tag.OrganizationID) {
+		return ErrTagNotFound
+	}
+
+	result := scoped(ctx, r.db).Select("*").Updates(tag)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -79,7 +90,7 @@ func (r *TagRepositoryImpl) Update(ctx context.Context, tag *entity.Tag) error {
 
 // Delete soft deletes a tag
 func (r *TagRepositoryImpl) Delete(ctx context.Context, id int64) error {
-	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.Tag{})
+	result := scoped(ctx, r.db).Where("id = ?", id).Delete(&entity.Tag{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -89,17 +100,85 @@ func (r *TagRepositoryImpl) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// List lists all tags with pagination
-func (r *TagRepositoryImpl) List(ctx context.Context, offset, limit int) ([]*entity.Tag, error) {
+// FindByUserID lists a user's tags with pagination
+func (r *TagRepositoryImpl) FindByUserID(ctx context.Context, userID int64, offset, limit int) ([]*entity.Tag, int64, error) {
 	var tags []*entity.Tag
-	result := r.db.WithContext(ctx).Where("deleted_at IS NULL").
-		Order("created_at DESC").
+	var total int64
+
+	query := scoped(ctx, r.db).Model(&entity.Tag{}).Where("user_id = ? AND deleted_at IS NULL", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := query.Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&tags)
 
 	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return tags, total, nil
+}
+
+// HasChildren reports whether tags are nested under a tag
+func (r *TagRepositoryImpl) HasChildren(ctx context.Context, id int64) (bool, error) {
+	var count int64
+	result := scoped(ctx, r.db).Model(&entity.Tag{}).
+		Where("parent_id = ? AND deleted_at IS NULL", id).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+// FindByAlias finds the tag a user's alias resolves to
+func (r *TagRepositoryImpl) FindByAlias(ctx context.Context, userID int64, name string) (*entity.Tag, error) {
+	var tag entity.Tag
+	result := withContext(ctx, r.db).
+		Joins("INNER JOIN tag_aliases ON tag_aliases.tag_id = tags.id").
+		Scopes(inTenant(ctx, "tags.organization_id")).
+		Where("tag_aliases.organization_id = tags.organization_id").
+		Where("tag_aliases.user_id = ? AND tag_aliases.name = ? AND tags.deleted_at IS NULL", userID, name).
+		First(&tag)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrTagNotFound
+		}
 		return nil, result.Error
 	}
-	return tags, nil
+	return &tag, nil
+}
+
+// AddAliases adds tag aliases. An existing alias with the same name is
+// repointed to the new tag.
+func (r *TagRepositoryImpl) AddAliases(ctx context.Context, aliases []*entity.TagAlias) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	for _, alias := range aliases {
+		orgID, err := assignTenant(ctx, alias.OrganizationID)
+		if err != nil {
+			return err
+		}
+		alias.OrganizationID = orgID
+	}
+
+	return withContext(ctx, r.db).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"tag_id"}),
+	}).Create(&aliases).Error
+}
+
+// MoveAliases repoints the aliases of some tags to another tag
+func (r *TagRepositoryImpl) MoveAliases(ctx context.Context, fromTagIDs []int64, toTagID int64) error {
+	if len(fromTagIDs) == 0 {
+		return nil
+	}
+
+	return scoped(ctx, r.db).Model(&entity.TagAlias{}).
+		Where("tag_id IN ?", fromTagIDs).
+		Update("tag_id", toTagID).
+		Error
 }
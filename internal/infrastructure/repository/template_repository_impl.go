@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TemplateRepositoryImpl implements repository.TemplateRepository interface
+type TemplateRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewTemplateRepository creates a new template repository
+func NewTemplateRepository(db *gorm.DB) repository.TemplateRepository {
+	return &TemplateRepositoryImpl{db: db}
+}
+
+// Create creates a new template together with its items
+func (r *TemplateRepositoryImpl) Create(ctx context.Context, template *entity.Template) error {
+	return withContext(ctx, r.db).Create(template).Error
+}
+
+// FindByID finds a template by ID
+func (r *TemplateRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Template, error) {
+	var template entity.Template
+	result := r.withItems(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&template)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, result.Error
+	}
+	return &template, nil
+}
+
+// FindByUserID finds all templates owned by a user
+func (r *TemplateRepositoryImpl) FindByUserID(ctx context.Context, userID int64) ([]*entity.Template, error) {
+	var templates []*entity.Template
+	result := r.withItems(ctx).Where("user_id = ? AND deleted_at IS NULL", userID).
+		Order("name ASC").
+		Find(&templates)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return templates, nil
+}
+
+// Update updates a template's own fields; use ReplaceItems for its items
+func (r *TemplateRepositoryImpl) Update(ctx context.Context, template *entity.Template) error {
+	result := withContext(ctx, r.db).Omit("Items").Save(template)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// ReplaceItems replaces all items of a template
+func (r *TemplateRepositoryImpl) ReplaceItems(ctx context.Context, templateID int64, items []entity.TemplateItem) error {
+	db := withContext(ctx, r.db)
+	if err := db.Where("template_id = ?", templateID).Delete(&entity.TemplateItem{}).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	for i := range items {
+		items[i].ID = 0
+		items[i].TemplateID = templateID
+	}
+	return db.Create(&items).Error
+}
+
+// Delete deletes a template; its items are removed by the foreign key
+func (r *TemplateRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	result := withContext(ctx, r.db).Where("id = ?", id).Delete(&entity.Template{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// withItems preloads template items in position order
+func (r *TemplateRepositoryImpl) withItems(ctx context.Context) *gorm.DB {
+	return withContext(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	})
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+)
+
+// ErrNoTenant is returned for tenant-scoped rows accessed through a context
+// bound to no tenant that did not opt out with tenant.Unscoped
+var ErrNoTenant = errors.New("context is not bound to a tenant")
+
+// scoped returns the database handle of withContext restricted to the rows
+// of the tenant bound to ctx, read from the organization_id column
+func scoped(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return withContext(ctx, db).Scopes(inTenant(ctx, "organization_id"))
+}
+
+// inTenant restricts a query to the rows whose column holds the tenant bound
+// to ctx. Unscoped contexts are not restricted, and queries through contexts
+// bound to no tenant fail with ErrNoTenant.
+func inTenant(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tenantID, ok := tenant.FromContext(ctx); ok {
+			return db.Where(column+" = ?", tenantID)
+		}
+		if !tenant.IsUnscoped(ctx) {
+			_ = db.AddError(ErrNoTenant)
+		}
+		return db
+	}
+}
+
+// tenantCondition returns the SQL condition and arguments of inTenant for
+// raw queries
+func tenantCondition(ctx context.Context, column string) (string, []interface{}, error) {
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		return column + " = ?", []interface{}{tenantID}, nil
+	}
+	if tenant.IsUnscoped(ctx) {
+		return "1 = 1", nil, nil
+	}
+	return "", nil, ErrNoTenant
+}
+
+// inCurrentTenant reports whether a row of organizationID may be written
+// through ctx
+func inCurrentTenant(ctx context.Context, organizationID int64) bool {
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		return tenantID == organizationID
+	}
+	return tenant.IsUnscoped(ctx)
+}
+
+// assignTenant returns the organization new rows belong to: the tenant bound
+// to ctx, or organizationID for unscoped contexts
+func assignTenant(ctx context.Context, organizationID int64) (int64, error) {
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		return tenantID, nil
+	}
+	if tenant.IsUnscoped(ctx) && organizationID != 0 {
+		return organizationID, nil
+	}
+	return 0, ErrNoTenant
+}
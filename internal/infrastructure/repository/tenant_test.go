@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// cleanTenantTables removes the rows the tenant tests create
+func cleanTenantTables(db *gorm.DB) {
+	for _, table := range []string{
+		"archived_todo_tags", "archived_todo_assignees", "archived_todos",
+		"todo_tags", "todo_assignees", "tag_aliases", "tags", "todos", "webhooks",
+		"organization_members", "organizations", "users",
+	} {
+		db.Exec("DELETE FROM " + table)
+	}
+}
+
+// seedTenants creates a user belonging to two organizations, returning the
+// user and contexts bound to each organization
+func seedTenants(t *testing.T) (*gorm.DB, *entity.User, context.Context, context.Context) {
+	gormDB := setupTestDB(t).GetDB()
+	cleanTenantTables(gormDB)
+	t.Cleanup(func() { cleanTenantTables(gormDB) })
+
+	ctx := context.Background()
+	user := &entity.User{Username: "tenantuser", Email: "tenant@example.com", PasswordHash: "x", Role: entity.UserRoleUser}
+	require.NoError(t, NewUserRepository(gormDB).Create(ctx, user))
+
+	orgRepo := NewOrganizationRepository(gormDB)
+	orgA := &entity.Organization{Name: "Org A"}
+	require.NoError(t, orgRepo.Create(ctx, orgA, &entity.OrganizationMember{UserID: user.ID, Role: entity.OrganizationRoleOwner}))
+	orgB := &entity.Organization{Name: "Org B"}
+	require.NoError(t, orgRepo.Create(ctx, orgB, &entity.OrganizationMember{UserID: user.ID, Role: entity.OrganizationRoleOwner}))
+
+	return gormDB, user, tenant.WithID(ctx, orgA.ID), tenant.WithID(ctx, orgB.ID)
+}
+
+func createTenantTodo(t *testing.T, ctx context.Context, repo *TodoRepositoryImpl, userID int64, title string) *entity.Todo {
+	todo := &entity.Todo{UserID: userID, Title: title, Status: entity.TodoStatusNotStarted}
+	require.NoError(t, repo.Create(ctx, todo))
+	return todo
+}
+
+func TestTenantIsolation_Todos(t *testing.T) {
+	db, user, ctxA, ctxB := seedTenants(t)
+	todoRepo := &TodoRepositoryImpl{db: db}
+
+	todoA := createTenantTodo(t, ctxA, todoRepo, user.ID, "todo in A")
+	todoB := createTenantTodo(t, ctxB, todoRepo, user.ID, "todo in B")
+	orgA, _ := tenant.FromContext(ctxA)
+	orgB, _ := tenant.FromContext(ctxB)
+	assert.Equal(t, orgA, todoA.OrganizationID)
+	assert.Equal(t, orgB, todoB.OrganizationID)
+
+	// A todo is created in the bound tenant, whatever it claims
+	forgedNew := &entity.Todo{UserID: user.ID, OrganizationID: orgB, Title: "forged", Status: entity.TodoStatusNotStarted}
+	require.NoError(t, todoRepo.Create(ctxA, forgedNew))
+	assert.Equal(t, orgA, forgedNew.OrganizationID)
+
+	// Reads
+	_, err := todoRepo.FindByID(ctxA, todoB.ID)
+	assert.Equal(t, ErrTodoNotFound, err)
+	todos, err := todoRepo.FindByUserID(ctxB, user.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, todoB.ID, todos[0].ID)
+
+	// Updates, also with the organization forged to the current tenant
+	forged := *todoB
+	forged.Title = "changed from A"
+	assert.Equal(t, ErrTodoNotFound, todoRepo.Update(ctxA, &forged))
+	forged.OrganizationID = orgA
+	assert.Equal(t, ErrTodoNotFound, todoRepo.Update(ctxA, &forged))
+	moved := *todoA
+	moved.OrganizationID = orgB
+	assert.Equal(t, ErrTodoNotFound, todoRepo.Update(ctxA, &moved))
+
+	found, err := todoRepo.FindByID(ctxB, todoB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "todo in B", found.Title)
+	assert.Equal(t, orgB, found.OrganizationID)
+
+	// Deletes
+	assert.Equal(t, ErrTodoNotFound, todoRepo.Delete(ctxA, todoB.ID))
+	_, err = todoRepo.FindByID(ctxB, todoB.ID)
+	assert.NoError(t, err)
+}
+
+func TestTenantIsolation_Archive(t *testing.T) {
+	db, user, ctxA, ctxB := seedTenants(t)
+	todoRepo := &TodoRepositoryImpl{db: db}
+
+	todoA := createTenantTodo(t, ctxA, todoRepo, user.ID, "todo in A")
+	todoB := createTenantTodo(t, ctxB, todoRepo, user.ID, "todo in B")
+	tagA := &entity.Tag{UserID: user.ID, Name: "work"}
+	require.NoError(t, (&TagRepositoryImpl{db: db}).Create(ctxA, tagA))
+	todoTagRepo := &TodoTagRepositoryImpl{db: db}
+	require.NoError(t, todoTagRepo.AddTagsToTodo(ctxA, todoA.ID, []int64{tagA.ID}))
+	assigneeRepo := &TodoAssigneeRepositoryImpl{db: db}
+	require.NoError(t, assigneeRepo.AddAssignees(ctxA, []*entity.TodoAssignee{{TodoID: todoA.ID, UserID: user.ID, AssignedBy: user.ID}}))
+
+	// Only the todos of the current tenant are archived
+	require.NoError(t, todoRepo.Archive(ctxA, []int64{todoA.ID, todoB.ID}, time.Now()))
+	_, err := todoRepo.FindByID(ctxB, todoB.ID)
+	assert.NoError(t, err)
+	_, err = todoRepo.FindArchivedByID(ctxA, todoA.ID)
+	assert.NoError(t, err)
+
+	_, err = todoRepo.FindArchivedByID(ctxB, todoA.ID)
+	assert.Equal(t, ErrTodoNotFound, err)
+	lineage, err := todoTagRepo.GetTagLineageIDsByTodoID(ctxA, todoA.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{tagA.ID}, lineage)
+	archived, total, err := todoRepo.FindArchivedByUserID(ctxB, user.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, archived)
+	assert.Zero(t, total)
+
+	// and restored
+	assert.Equal(t, ErrTodoNotFound, todoRepo.Restore(ctxB, todoA.ID))
+	require.NoError(t, todoRepo.Restore(ctxA, todoA.ID))
+	_, err = todoRepo.FindByID(ctxA, todoA.ID)
+	assert.NoError(t, err)
+	tags, err := todoTagRepo.GetTagsByTodoID(ctxA, todoA.ID)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Equal(t, tagA.ID, tags[0].ID)
+	assigneeIDs, err := assigneeRepo.FindUserIDsByTodoID(ctxA, todoA.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{user.ID}, assigneeIDs)
+}
+
+func TestTenantIsolation_Tags(t *testing.T) {
+	db, user, ctxA, ctxB := seedTenants(t)
+	todoRepo := &TodoRepositoryImpl{db: db}
+	tagRepo := &TagRepositoryImpl{db: db}
+	todoTagRepo := &TodoTagRepositoryImpl{db: db}
+
+	// The same name can be used in both tenants
+	tagA := &entity.Tag{UserID: user.ID, Name: "work"}
+	require.NoError(t, tagRepo.Create(ctxA, tagA))
+	tagB := &entity.Tag{UserID: user.ID, Name: "work"}
+	require.NoError(t, tagRepo.Create(ctxB, tagB))
+
+	// Reads
+	_, err := tagRepo.FindByID(ctxA, tagB.ID)
+	assert.Equal(t, ErrTagNotFound, err)
+	found, err := tagRepo.FindByName(ctxA, user.ID, "work")
+	require.NoError(t, err)
+	assert.Equal(t, tagA.ID, found.ID)
+	tags, total, err := tagRepo.FindByUserID(ctxB, user.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, tagB.ID, tags[0].ID)
+
+	// Updates and deletes
+	forged := *tagB
+	forged.Name = "changed from A"
+	assert.Equal(t, ErrTagNotFound, tagRepo.Update(ctxA, &forged))
+	forged.OrganizationID = tagA.OrganizationID
+	assert.Equal(t, ErrTagNotFound, tagRepo.Update(ctxA, &forged))
+	assert.Equal(t, ErrTagNotFound, tagRepo.Delete(ctxA, tagB.ID))
+	found, err = tagRepo.FindByID(ctxB, tagB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "work", found.Name)
+
+	// Aliases resolve only in their own tenant
+	require.NoError(t, tagRepo.AddAliases(ctxB, []*entity.TagAlias{{UserID: user.ID, TagID: tagB.ID, Name: "job"}}))
+	_, err = tagRepo.FindByAlias(ctxA, user.ID, "job")
+	assert.Equal(t, ErrTagNotFound, err)
+	found, err = tagRepo.FindByAlias(ctxB, user.ID, "job")
+	require.NoError(t, err)
+	assert.Equal(t, tagB.ID, found.ID)
+
+	// Tag links
+	todoB := createTenantTodo(t, ctxB, todoRepo, user.ID, "todo in B")
+	require.NoError(t, todoTagRepo.AddTagsToTodo(ctxB, todoB.ID, []int64{tagB.ID}))
+	linked, err := todoTagRepo.GetTagsByTodoID(ctxA, todoB.ID)
+	require.NoError(t, err)
+	assert.Empty(t, linked)
+	tagged, total, err := todoTagRepo.GetTodosByTagID(ctxA, tagB.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, tagged)
+	assert.Zero(t, total)
+
+	// Todos count towards the tags their tags are nested under
+	nestedB := &entity.Tag{UserID: user.ID, Name: "work/backend", ParentID: &tagB.ID}
+	require.NoError(t, tagRepo.Create(ctxB, nestedB))
+	require.NoError(t, todoTagRepo.ReplaceTagsForTodo(ctxB, todoB.ID, []int64{nestedB.ID}))
+	lineage, err := todoTagRepo.GetTagLineageIDsByTodoID(ctxB, todoB.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{tagB.ID, nestedB.ID}, lineage)
+	lineage, err = todoTagRepo.GetTagLineageIDsByTodoID(ctxA, todoB.ID)
+	require.NoError(t, err)
+	assert.Empty(t, lineage)
+}
+
+func TestTenantIsolation_Webhooks(t *testing.T) {
+	db, user, ctxA, ctxB := seedTenants(t)
+	webhookRepo := NewWebhookRepository(db)
+
+	hookA := &entity.Webhook{UserID: user.ID, URL: "https://a.example.com", Secret: "a", Events: "*", Active: true}
+	require.NoError(t, webhookRepo.Create(ctxA, hookA))
+	orgA, _ := tenant.FromContext(ctxA)
+	assert.Equal(t, orgA, hookA.OrganizationID)
+
+	// Events of tenant B reach none of the user's webhooks of tenant A
+	hooks, err := webhookRepo.FindActiveByUserID(ctxB, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+	hooks, err = webhookRepo.FindActiveByUserID(ctxA, user.ID)
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	assert.Equal(t, hookA.ID, hooks[0].ID)
+
+	_, err = webhookRepo.FindByID(ctxB, hookA.ID)
+	assert.Equal(t, ErrWebhookNotFound, err)
+	hooks, err = webhookRepo.FindByUserID(ctxB, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+	assert.Equal(t, ErrWebhookNotFound, webhookRepo.Update(ctxB, hookA))
+	assert.Equal(t, ErrWebhookNotFound, webhookRepo.Delete(ctxB, hookA.ID))
+
+	_, err = webhookRepo.FindActiveByUserID(context.Background(), user.ID)
+	assert.ErrorIs(t, err, ErrNoTenant)
+}
+
+func TestTenant_MissingTenantIsRefused(t *testing.T) {
+	db, user, _, ctxB := seedTenants(t)
+	todoRepo := &TodoRepositoryImpl{db: db}
+	tagRepo := &TagRepositoryImpl{db: db}
+	todoB := createTenantTodo(t, ctxB, todoRepo, user.ID, "todo in B")
+
+	ctx := context.Background()
+	_, err := todoRepo.FindByID(ctx, todoB.ID)
+	assert.ErrorIs(t, err, ErrNoTenant)
+	_, err = todoRepo.FindByUserID(ctx, user.ID, 0, 10)
+	assert.ErrorIs(t, err, ErrNoTenant)
+	_, _, err = todoRepo.FindArchivedByUserID(ctx, user.ID, 0, 10)
+	assert.ErrorIs(t, err, ErrNoTenant)
+	assert.ErrorIs(t, todoRepo.Create(ctx, &entity.Todo{UserID: user.ID, Title: "no tenant"}), ErrNoTenant)
+	assert.ErrorIs(t, todoRepo.Delete(ctx, todoB.ID), ErrNoTenant)
+	assert.ErrorIs(t, todoRepo.Archive(ctx, []int64{todoB.ID}, time.Now()), ErrNoTenant)
+	assert.ErrorIs(t, todoRepo.Restore(ctx, todoB.ID), ErrNoTenant)
+	assert.Equal(t, ErrTodoNotFound, todoRepo.Update(ctx, todoB))
+	assert.ErrorIs(t, tagRepo.Create(ctx, &entity.Tag{UserID: user.ID, Name: "no tenant"}), ErrNoTenant)
+	_, err = tagRepo.FindByName(ctx, user.ID, "work")
+	assert.ErrorIs(t, err, ErrNoTenant)
+	userRepo := NewUserRepository(db)
+	_, err = userRepo.FindByID(ctx, user.ID)
+	assert.ErrorIs(t, err, ErrNoTenant)
+	_, err = userRepo.FindByEmail(ctx, user.Email)
+	assert.ErrorIs(t, err, ErrNoTenant)
+
+	// Background jobs opt in to every tenant
+	found, err := todoRepo.FindByID(tenant.Unscoped(ctx), todoB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, todoB.ID, found.ID)
+}
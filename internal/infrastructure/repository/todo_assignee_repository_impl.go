@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+// TodoAssigneeRepositoryImpl implements repository.TodoAssigneeRepository interface
+type TodoAssigneeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewTodoAssigneeRepository creates a new todo assignee repository
+func NewTodoAssigneeRepository(db *gorm.DB) repository.TodoAssigneeRepository {
+	return &TodoAssigneeRepositoryImpl{db: db}
+}
+
+// AddAssignees assigns todos to users
+func (r *TodoAssigneeRepositoryImpl) AddAssignees(ctx context.Context, assignees []*entity.TodoAssignee) error {
+	if len(assignees) == 0 {
+		return nil
+	}
+	return withContext(ctx, r.db).Create(&assignees).Error
+}
+
+// RemoveAssignees unassigns a todo from users
+func (r *TodoAssigneeRepositoryImpl) RemoveAssignees(ctx context.Context, todoID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	return withContext(ctx, r.db).Where("todo_id = ? AND user_id IN ?", todoID, userIDs).
+		Delete(&entity.TodoAssignee{}).
+		Error
+}
+
+// FindUserIDsByTodoID finds the users a todo is assigned to, looking in the
+// archive as well
+func (r *TodoAssigneeRepositoryImpl) FindUserIDsByTodoID(ctx context.Context, todoID int64) ([]int64, error) {
+	var rows []struct {
+		UserID int64
+	}
+
+	result := withContext(ctx, r.db).Raw(
+		"SELECT user_id, created_at FROM todo_assignees WHERE todo_id = ? UNION ALL SELECT user_id, created_at FROM archived_todo_assignees WHERE todo_id = ? ORDER BY created_at ASC, user_id ASC",
+		todoID, todoID,
+	).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	userIDs := make([]int64, len(rows))
+	for i, row := range rows {
+		userIDs[i] = row.UserID
+	}
+	return userIDs, nil
+}
@@ -16,6 +16,12 @@ var (
 	ErrTodoNotFound = errors.New("todo not found")
 )
 
+// archivedTodosTable holds todos moved out of the todos table by archiving
+const archivedTodosTable = "archived_todos"
+
+// todoColumns are the columns the todos and archived_todos tables share
+const todoColumns = "id, user_id, organization_id, parent_id, title, description, due_date, all_day, due_on, start_date, scheduled_for, status, status_category, priority, urgent, important, completed_at, overdue_at, escalated_at, snoozed_until, created_at, updated_at, deleted_at"
+
 // TodoRepositoryImpl implements repository.TodoRepository interface
 type TodoRepositoryImpl struct {
 	db *gorm.DB
@@ -26,15 +32,20 @@ func NewTodoRepository(db *gorm.DB) repository.TodoRepository {
 	return &TodoRepositoryImpl{db: db}
 }
 
-// Create creates a new todo
+// Create creates a new todo in the tenant bound to ctx
 func (r *TodoRepositoryImpl) Create(ctx context.Context, todo *entity.Todo) error {
-	return r.db.WithContext(ctx).Create(todo).Error
+	orgID, err := assignTenant(ctx, todo.OrganizationID)
+	if err != nil {
+		return err
+	}
+	todo.OrganizationID = orgID
+	return withContext(ctx, r.db).Create(todo).Error
 }
 
 // FindByID finds a todo by ID
 func (r *TodoRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Todo, error) {
 	var todo entity.Todo
-	result := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&todo)
+	result := scoped(ctx, r.db).Where("id = ? AND deleted_at IS NULL", id).First(&todo)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, ErrTodoNotFound
@@ -47,7 +58,7 @@ func (r *TodoRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.To
 // FindByUserID finds todos by user ID with pagination
 func (r *TodoRepositoryImpl) FindByUserID(ctx context.Context, userID int64, offset, limit int) ([]*entity.Todo, error) {
 	var todos []*entity.Todo
-	result := r.db.WithContext(ctx).Where("user_id = ? AND deleted_at IS NULL", userID).
+	result := scoped(ctx, r.db).Where("user_id = ? AND deleted_at IS NULL", userID).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -59,9 +70,15 @@ func (r *TodoRepositoryImpl) FindByUserID(ctx context.Context, userID int64, off
 	return todos, nil
 }
 
-// Update updates a todo
+// Update updates a todo. Todos of another tenant are reported as not found.
 func (r *TodoRepositoryImpl) Update(ctx context.Context, todo *entity.Todo) error {
-	result := r.db.WithContext(ctx).Save(todo)
+	// A todo cannot be moved to another tenant, and only rows still in the
+	// current one are updated
+	if !inCurrentTenant(ctx, todo.OrganizationID) {
+		return ErrTodoNotFound
+	}
+
+	result := scoped(ctx, r.db).Select("*").Updates(todo)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -73,7 +90,7 @@ func (r *TodoRepositoryImpl) Update(ctx context.Context, todo *entity.Todo) erro
 
 // Delete soft deletes a todo
 func (r *TodoRepositoryImpl) Delete(ctx context.Context, id int64) error {
-	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.Todo{})
+	result := scoped(ctx, r.db).Where("id = ?", id).Delete(&entity.Todo{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -86,7 +103,7 @@ func (r *TodoRepositoryImpl) Delete(ctx context.Context, id int64) error {
 // List lists all todos with pagination
 func (r *TodoRepositoryImpl) List(ctx context.Context, offset, limit int) ([]*entity.Todo, error) {
 	var todos []*entity.Todo
-	result := r.db.WithContext(ctx).Where("deleted_at IS NULL").
+	result := scoped(ctx, r.db).Where("deleted_at IS NULL").
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -101,7 +118,7 @@ func (r *TodoRepositoryImpl) List(ctx context.Context, offset, limit int) ([]*en
 // FindByStatus finds todos by status with pagination
 func (r *TodoRepositoryImpl) FindByStatus(ctx context.Context, status entity.TodoStatus, offset, limit int) ([]*entity.Todo, error) {
 	var todos []*entity.Todo
-	result := r.db.WithContext(ctx).Where("status = ? AND deleted_at IS NULL", status).
+	result := scoped(ctx, r.db).Where("status = ? AND deleted_at IS NULL", status).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -116,7 +133,7 @@ func (r *TodoRepositoryImpl) FindByStatus(ctx context.Context, status entity.Tod
 // FindByDueDate finds todos within a date range with pagination
 func (r *TodoRepositoryImpl) FindByDueDate(ctx context.Context, startDate, endDate *time.Time, offset, limit int) ([]*entity.Todo, error) {
 	var todos []*entity.Todo
-	query := r.db.WithContext(ctx).Where("deleted_at IS NULL")
+	query := scoped(ctx, r.db).Where("deleted_at IS NULL")
 
 	if startDate != nil {
 		query = query.Where("due_date >= ?", *startDate)
@@ -137,23 +154,58 @@ func (r *TodoRepositoryImpl) FindByDueDate(ctx context.Context, startDate, endDa
 }
 
 // FindByUserIDAndFilters finds todos by user ID with filters
-func (r *TodoRepositoryImpl) FindByUserIDAndFilters(ctx context.Context, userID int64, status *string, priority *string, dueDateFrom, dueDateTo *time.Time, sortBy, sortOrder string, offset, limit int) ([]*entity.Todo, int64, error) {
+func (r *TodoRepositoryImpl) FindByUserIDAndFilters(ctx context.Context, userID int64, filter repository.TodoFilter, sortBy, sortOrder string, offset, limit int) ([]*entity.Todo, int64, error) {
 	var todos []*entity.Todo
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Todo{}).Where("user_id = ? AND deleted_at IS NULL", userID)
+	var query *gorm.DB
+	switch {
+	case filter.IncludeArchived:
+		query = scoped(ctx, r.db).Table("(?) AS todos", r.withArchived(ctx, userID, filter.AssignedToUser)).Where("deleted_at IS NULL")
+	case filter.AssignedToUser:
+		query = scoped(ctx, r.db).Model(&entity.Todo{}).Where("id IN (?) AND deleted_at IS NULL", withContext(ctx, r.db).Table("todo_assignees").Select("todo_id").Where("user_id = ?", userID))
+	default:
+		query = scoped(ctx, r.db).Model(&entity.Todo{}).Where("user_id = ? AND deleted_at IS NULL", userID)
+	}
 
-	if status != nil {
-		query = query.Where("status = ?", *status)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
 	}
-	if priority != nil {
-		query = query.Where("priority = ?", *priority)
+	if filter.Priority != nil {
+		query = query.Where("priority = ?", *filter.Priority)
+	}
+	if filter.DueDateFrom != nil {
+		query = query.Where("due_date >= ?", *filter.DueDateFrom)
+	}
+	if filter.DueDateTo != nil {
+		query = query.Where("due_date <= ?", *filter.DueDateTo)
 	}
-	if dueDateFrom != nil {
-		query = query.Where("due_date >= ?", *dueDateFrom)
+	if filter.OverdueAt != nil {
+		query = query.Where("status_category <> ? AND due_date IS NOT NULL AND due_date < ?", entity.StatusCategoryDone, *filter.OverdueAt)
 	}
-	if dueDateTo != nil {
-		query = query.Where("due_date <= ?", *dueDateTo)
+	if filter.ExcludeDone {
+		query = query.Where("status_category <> ?", entity.StatusCategoryDone)
+	}
+	if filter.VisibleAt != nil {
+		query = query.Where("(snoozed_until IS NULL OR snoozed_until <= ?)", *filter.VisibleAt)
+	}
+	if filter.AvailableAt != nil {
+		query = query.Where("(start_date IS NULL OR start_date <= ?)", *filter.AvailableAt)
+	}
+	if len(filter.TagIDs) > 0 {
+		if filter.MatchAllTags {
+			for _, tagID := range filter.TagIDs {
+				query = query.Where("id IN (?)", r.taggedTodoIDs(ctx, []int64{tagID}, filter.IncludeArchived))
+			}
+		} else {
+			query = query.Where("id IN (?)", r.taggedTodoIDs(ctx, filter.TagIDs, filter.IncludeArchived))
+		}
+	}
+	if len(filter.ExcludeTagIDs) > 0 {
+		query = query.Where("id NOT IN (?)", r.taggedTodoIDs(ctx, filter.ExcludeTagIDs, filter.IncludeArchived))
+	}
+	if filter.DelegatedByUser {
+		query = query.Where("id IN (?)", r.delegatedTodoIDs(ctx, userID, filter.IncludeArchived))
 	}
 
 	// Count total
@@ -170,6 +222,11 @@ func (r *TodoRepositoryImpl) FindByUserIDAndFilters(ctx context.Context, userID
 		orderByColumn = "status"
 	case "title":
 		orderByColumn = "title"
+	case "priority":
+		// Levels sort as p0 to p4, most important first in ascending order
+		orderByColumn = "priority"
+	case "scheduled_for":
+		orderByColumn = "scheduled_for"
 	default:
 		orderByColumn = "due_date"
 	}
@@ -194,7 +251,7 @@ func (r *TodoRepositoryImpl) FindByUserIDAndFilters(ctx context.Context, userID
 func (r *TodoRepositoryImpl) FindByFilters(ctx context.Context, status *string, priority *string, offset, limit int) ([]*entity.Todo, error) {
 	var todos []*entity.Todo
 
-	query := r.db.WithContext(ctx).Model(&entity.Todo{}).Where("deleted_at IS NULL")
+	query := scoped(ctx, r.db).Model(&entity.Todo{}).Where("deleted_at IS NULL")
 
 	if status != nil {
 		query = query.Where("status = ?", *status)
@@ -215,3 +272,466 @@ func (r *TodoRepositoryImpl) FindByFilters(ctx context.Context, status *string,
 
 	return todos, nil
 }
+
+// GetStatsByUserID aggregates a user's todos with GROUP BY queries instead of
+// loading rows
+func (r *TodoRepositoryImpl) GetStatsByUserID(ctx context.Context, userID int64, since, now time.Time, topTags int) (*entity.TodoStats, error) {
+	stats := &entity.TodoStats{
+		Since:           since,
+		CompletedPerDay: []entity.DailyCount{},
+		StatusCounts:    make(map[entity.TodoStatus]int64),
+		PriorityCounts:  make(map[entity.TodoPriority]int64),
+		TopTags:         []entity.TagUsage{},
+	}
+
+	owned := func() *gorm.DB {
+		return scoped(ctx, r.db).Model(&entity.Todo{}).Where("user_id = ? AND deleted_at IS NULL", userID)
+	}
+
+	// Completion history also covers archived todos
+	completions := func() *gorm.DB {
+		completed := func(table string) *gorm.DB {
+			return scoped(ctx, r.db).Table(table).
+				Select("created_at, completed_at").
+				Where("user_id = ? AND deleted_at IS NULL AND completed_at >= ?", userID, since)
+		}
+		return withContext(ctx, r.db).Table("(? UNION ALL ?) AS completions", completed("todos"), completed(archivedTodosTable))
+	}
+
+	// Completions per day
+	var daily []entity.DailyCount
+	result := completions().
+		Select("DATE_FORMAT(completed_at, '%Y-%m-%d') AS day, COUNT(*) AS count").
+		Group("day").
+		Order("day ASC").
+		Scan(&daily)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, day := range daily {
+		stats.CompletedPerDay = append(stats.CompletedPerDay, day)
+		stats.CompletedCount += day.Count
+	}
+
+	// Average lead time from creation to completion
+	var leadTime struct {
+		Avg *float64
+	}
+	result = completions().
+		Select("AVG(TIMESTAMPDIFF(SECOND, created_at, completed_at)) AS avg").
+		Scan(&leadTime)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if leadTime.Avg != nil {
+		stats.AvgLeadTime = *leadTime.Avg
+	}
+
+	// Open todos past their due date
+	result = owned().
+		Where("status_category <> ? AND due_date IS NOT NULL AND due_date < ?", entity.StatusCategoryDone, now).
+		Count(&stats.OverdueCount)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// Status distribution
+	var statuses []struct {
+		Status string
+		Count  int64
+	}
+	result = owned().Select("status, COUNT(*) AS count").Group("status").Scan(&statuses)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range statuses {
+		stats.StatusCounts[entity.TodoStatus(row.Status)] = row.Count
+		stats.Total += row.Count
+	}
+
+	// Priority distribution
+	var priorities []struct {
+		Priority string
+		Count    int64
+	}
+	result = owned().Select("priority, COUNT(*) AS count").Group("priority").Scan(&priorities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range priorities {
+		stats.PriorityCounts[entity.TodoPriority(row.Priority)] = row.Count
+	}
+
+	// Most-used tags
+	var tags []entity.TagUsage
+	result = withContext(ctx, r.db).Table("todo_tags").
+		Select("tags.id AS tag_id, tags.name AS name, COUNT(*) AS count").
+		Joins("INNER JOIN todos ON todos.id = todo_tags.todo_id").
+		Joins("INNER JOIN tags ON tags.id = todo_tags.tag_id").
+		Scopes(inTenant(ctx, "todos.organization_id")).
+		Where("todos.user_id = ? AND todos.deleted_at IS NULL AND tags.deleted_at IS NULL", userID).
+		Group("tags.id, tags.name").
+		Order("count DESC, tags.name ASC").
+		Limit(topTags).
+		Scan(&tags)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	stats.TopTags = append(stats.TopTags, tags...)
+
+	return stats, nil
+}
+
+// FindOverdue finds open todos past their due date that have not been
+// flagged yet or, when escalateBefore is set, whose priority was last raised
+// (or which fell due) before it
+func (r *TodoRepositoryImpl) FindOverdue(ctx context.Context, now time.Time, escalateBefore *time.Time, limit int) ([]*entity.Todo, error) {
+	var todos []*entity.Todo
+
+	query := scoped(ctx, r.db).
+		Where("deleted_at IS NULL AND status_category <> ? AND due_date IS NOT NULL AND due_date < ?", entity.StatusCategoryDone, now)
+
+	if escalateBefore != nil {
+		query = query.Where("overdue_at IS NULL OR (priority <> ? AND COALESCE(escalated_at, due_date) <= ?)", entity.TodoPriorityP0, *escalateBefore)
+	} else {
+		query = query.Where("overdue_at IS NULL")
+	}
+
+	result := query.Order("due_date ASC").Limit(limit).Find(&todos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+// UpdateOverdueState saves only the overdue flag and escalated priority, so
+// that it does not overwrite concurrent edits to other fields
+func (r *TodoRepositoryImpl) UpdateOverdueState(ctx context.Context, todo *entity.Todo) error {
+	result := scoped(ctx, r.db).Model(&entity.Todo{}).
+		Where("id = ?", todo.ID).
+		Updates(map[string]interface{}{
+			"overdue_at":   todo.OverdueAt,
+			"escalated_at": todo.EscalatedAt,
+			"priority":     todo.Priority,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTodoNotFound
+	}
+	return nil
+}
+
+// FindPlannedByUserID finds a user's open todos that have a due date or are
+// scheduled
+func (r *TodoRepositoryImpl) FindPlannedByUserID(ctx context.Context, userID int64) ([]*entity.Todo, error) {
+	var todos []*entity.Todo
+	result := scoped(ctx, r.db).
+		Where("user_id = ? AND deleted_at IS NULL AND status_category <> ?", userID, entity.StatusCategoryDone).
+		Where("due_date IS NOT NULL OR scheduled_for IS NOT NULL").
+		Find(&todos)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+// FindSnoozedByUserID finds a user's todos that are still snoozed at now
+func (r *TodoRepositoryImpl) FindSnoozedByUserID(ctx context.Context, userID int64, now time.Time) ([]*entity.Todo, error) {
+	var todos []*entity.Todo
+	result := scoped(ctx, r.db).
+		Where("user_id = ? AND deleted_at IS NULL AND snoozed_until > ?", userID, now).
+		Order("snoozed_until ASC").
+		Find(&todos)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+// FindCompletedBefore finds todos in a done state that were completed before
+// the given time, oldest completion first
+func (r *TodoRepositoryImpl) FindCompletedBefore(ctx context.Context, before time.Time, limit int) ([]*entity.Todo, error) {
+	var todos []*entity.Todo
+	result := scoped(ctx, r.db).
+		Where("deleted_at IS NULL AND status_category = ? AND completed_at < ?", entity.StatusCategoryDone, before).
+		Order("completed_at ASC").
+		Limit(limit).
+		Find(&todos)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+// FindCompletedBetween finds a user's todos in a done state that were
+// completed in [from, to), in completion order
+func (r *TodoRepositoryImpl) FindCompletedBetween(ctx context.Context, userID int64, from, to time.Time) ([]*entity.Todo, error) {
+	var todos []*entity.Todo
+	result := scoped(ctx, r.db).
+		Where("user_id = ? AND deleted_at IS NULL AND status_category = ?", userID, entity.StatusCategoryDone).
+		Where("completed_at >= ? AND completed_at < ?", from, to).
+		Order("completed_at ASC").
+		Find(&todos)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
+// Archive moves todos and their tag links to the archive tables. IDs are
+// kept, so archived todos can be restored under the same ID.
+func (r *TodoRepositoryImpl) Archive(ctx context.Context, ids []int64, archivedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Only todos of the current tenant are moved
+	inTenant, tenantArgs, err := tenantCondition(ctx, "organization_id")
+	if err != nil {
+		return err
+	}
+	if err := scoped(ctx, r.db).Model(&entity.Todo{}).Where("id IN ?", ids).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO "+archivedTodosTable+" ("+todoColumns+", archived_at) SELECT "+todoColumns+", ? FROM todos WHERE id IN ? AND "+inTenant,
+			append([]interface{}{archivedAt, ids}, tenantArgs...)...,
+		).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(
+			"INSERT INTO archived_todo_tags (todo_id, tag_id, created_at) SELECT todo_id, tag_id, created_at FROM todo_tags WHERE todo_id IN ?",
+			ids,
+		).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(
+			"INSERT INTO archived_todo_assignees (todo_id, user_id, assigned_by, created_at) SELECT todo_id, user_id, assigned_by, created_at FROM todo_assignees WHERE todo_id IN ?",
+			ids,
+		).Error; err != nil {
+			return err
+		}
+
+		// Tag links and assignees are removed here, as databases set up by
+		// AutoMigrate lack the foreign keys
+		if err := tx.Exec("DELETE FROM todo_tags WHERE todo_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM todo_assignees WHERE todo_id IN ?", ids).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("DELETE FROM todos WHERE id IN ? AND "+inTenant, append([]interface{}{ids}, tenantArgs...)...).Error
+	})
+}
+
+// Restore moves an archived todo, its tag links and its assignees back to the
+// todos tables
+func (r *TodoRepositoryImpl) Restore(ctx context.Context, id int64) error {
+	inTenant, tenantArgs, err := tenantCondition(ctx, "organization_id")
+	if err != nil {
+		return err
+	}
+
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(
+			"INSERT INTO todos ("+todoColumns+") SELECT "+todoColumns+" FROM "+archivedTodosTable+" WHERE id = ? AND "+inTenant,
+			append([]interface{}{id}, tenantArgs...)...,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTodoNotFound
+		}
+
+		if err := tx.Exec(
+			"INSERT INTO todo_tags (todo_id, tag_id, created_at) SELECT todo_id, tag_id, created_at FROM archived_todo_tags WHERE todo_id = ?",
+			id,
+		).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(
+			"INSERT INTO todo_assignees (todo_id, user_id, assigned_by, created_at) SELECT todo_id, user_id, assigned_by, created_at FROM archived_todo_assignees WHERE todo_id = ?",
+			id,
+		).Error; err != nil {
+			return err
+		}
+
+		// Archived tag links and assignees are removed here, as databases set
+		// up by AutoMigrate lack the foreign keys
+		if err := tx.Exec("DELETE FROM archived_todo_tags WHERE todo_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM archived_todo_assignees WHERE todo_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("DELETE FROM "+archivedTodosTable+" WHERE id = ?", id).Error
+	})
+}
+
+// FindArchivedByID finds an archived todo by ID
+func (r *TodoRepositoryImpl) FindArchivedByID(ctx context.Context, id int64) (*entity.Todo, error) {
+	var todo entity.Todo
+	result := scoped(ctx, r.db).Table(archivedTodosTable).Where("id = ? AND deleted_at IS NULL", id).First(&todo)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrTodoNotFound
+		}
+		return nil, result.Error
+	}
+	return &todo, nil
+}
+
+// FindArchivedByUserID finds a user's archived todos, most recently archived first
+func (r *TodoRepositoryImpl) FindArchivedByUserID(ctx context.Context, userID int64, offset, limit int) ([]*entity.Todo, int64, error) {
+	var todos []*entity.Todo
+	var total int64
+
+	query := scoped(ctx, r.db).Table(archivedTodosTable).Where("user_id = ? AND deleted_at IS NULL", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := query.Order("archived_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&todos)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return todos, total, nil
+}
+
+// CountByStatuses counts a user's todos, archived ones included, in any of
+// the statuses
+func (r *TodoRepositoryImpl) CountByStatuses(ctx context.Context, userID int64, statuses []entity.TodoStatus) (int64, error) {
+	if len(statuses) == 0 {
+		return 0, nil
+	}
+
+	var total int64
+	result := scoped(ctx, r.db).Table("(?) AS todos", r.withArchived(ctx, userID, false)).
+		Where("deleted_at IS NULL AND status IN ?", statuses).
+		Count(&total)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return total, nil
+}
+
+// UpdateStatusCategory moves a user's todos in a status, archived ones
+// included, to another category. Todos entering the done category are
+// stamped as completed now; todos leaving it lose their completion time.
+func (r *TodoRepositoryImpl) UpdateStatusCategory(ctx context.Context, userID int64, status entity.TodoStatus, category entity.StatusCategory, now time.Time) error {
+	updates := map[string]interface{}{"status_category": category}
+	if category == entity.StatusCategoryDone {
+		updates["completed_at"] = gorm.Expr("COALESCE(completed_at, ?)", now)
+	} else {
+		updates["completed_at"] = nil
+	}
+
+	for _, table := range []string{"todos", archivedTodosTable} {
+		result := scoped(ctx, r.db).Table(table).
+			Where("user_id = ? AND status = ? AND status_category <> ?", userID, status, category).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// RezoneAllDay recomputes the due dates of a user's all-day todos, archived
+// ones included, as the end of their day in loc
+func (r *TodoRepositoryImpl) RezoneAllDay(ctx context.Context, userID int64, loc *time.Location) error {
+	for _, table := range []string{"todos", archivedTodosTable} {
+		var rows []struct {
+			ID    int64
+			DueOn entity.Date
+		}
+		if err := scoped(ctx, r.db).Table(table).
+			Select("id, due_on").
+			Where("user_id = ? AND all_day = ? AND due_on IS NOT NULL", userID, true).
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := scoped(ctx, r.db).Table(table).
+				Where("id = ?", row.ID).
+				Update("due_date", row.DueOn.EndIn(loc)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// taggedTodoIDs selects the IDs of todos tagged with any of the tags or with
+// a tag nested under one of them
+func (r *TodoRepositoryImpl) taggedTodoIDs(ctx context.Context, tagIDs []int64, includeArchived bool) *gorm.DB {
+	links := withContext(ctx, r.db).Table("todo_tags").Select("todo_id, tag_id")
+	if includeArchived {
+		links = withContext(ctx, r.db).Raw("SELECT todo_id, tag_id FROM todo_tags UNION ALL SELECT todo_id, tag_id FROM archived_todo_tags")
+	}
+
+	return withContext(ctx, r.db).Table("(?) AS links", links).
+		Select("links.todo_id").
+		Joins("INNER JOIN tags d ON d.id = links.tag_id").
+		Joins("INNER JOIN tags p ON "+tagSubtreeJoin).
+		Where("p.id IN ? AND d.deleted_at IS NULL", tagIDs)
+}
+
+// withArchived selects a user's todos in the current tenant from both the
+// todos and the archive tables; the todos assigned to the user instead when
+// assigned is set
+func (r *TodoRepositoryImpl) withArchived(ctx context.Context, userID int64, assigned bool) *gorm.DB {
+	inTenant, tenantArgs, err := tenantCondition(ctx, "organization_id")
+	if err != nil {
+		db := withContext(ctx, r.db)
+		_ = db.AddError(err)
+		return db
+	}
+	args := append([]interface{}{userID}, tenantArgs...)
+
+	live, archived := "user_id = ?", "user_id = ?"
+	if assigned {
+		live = "id IN (SELECT todo_id FROM todo_assignees WHERE user_id = ?)"
+		archived = "id IN (SELECT todo_id FROM archived_todo_assignees WHERE user_id = ?)"
+	}
+
+	return withContext(ctx, r.db).Raw(
+		"SELECT "+todoColumns+", NULL AS archived_at FROM todos WHERE "+live+" AND "+inTenant+" UNION ALL SELECT "+todoColumns+", archived_at FROM "+archivedTodosTable+" WHERE "+archived+" AND "+inTenant,
+		append(args, args...)...,
+	)
+}
+
+// delegatedTodoIDs selects the IDs of a user's todos, archived ones included
+// when includeArchived is set, that are assigned to someone else
+func (r *TodoRepositoryImpl) delegatedTodoIDs(ctx context.Context, userID int64, includeArchived bool) *gorm.DB {
+	query := "SELECT todo_id FROM todo_assignees WHERE user_id <> ?"
+	args := []interface{}{userID}
+	if includeArchived {
+		query += " UNION ALL SELECT todo_id FROM archived_todo_assignees WHERE user_id <> ?"
+		args = append(args, userID)
+	}
+	return withContext(ctx, r.db).Raw(query, args...)
+}
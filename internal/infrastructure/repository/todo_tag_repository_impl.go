@@ -9,6 +9,10 @@ import (
 	"github.com/darron08/todolist-demo/internal/domain/repository"
 )
 
+// tagSubtreeJoin matches tags d that are tag p or nested under it, going by
+// their path-style names
+const tagSubtreeJoin = "d.user_id = p.user_id AND d.organization_id = p.organization_id AND (d.id = p.id OR LEFT(d.name, CHAR_LENGTH(p.name) + 1) = CONCAT(p.name, '/'))"
+
 // TodoTagRepositoryImpl implements repository.TodoTagRepository interface
 type TodoTagRepositoryImpl struct {
 	db *gorm.DB
@@ -33,7 +37,7 @@ func (r *TodoTagRepositoryImpl) AddTagsToTodo(ctx context.Context, todoID int64,
 		})
 	}
 
-	return r.db.WithContext(ctx).Create(&todoTags).Error
+	return withContext(ctx, r.db).Create(&todoTags).Error
 }
 
 // RemoveTagsFromTodo removes tags from a todo
@@ -42,21 +46,22 @@ func (r *TodoTagRepositoryImpl) RemoveTagsFromTodo(ctx context.Context, todoID i
 		return nil
 	}
 
-	return r.db.WithContext(ctx).Where("todo_id = ? AND tag_id IN ?", todoID, tagIDs).
+	return withContext(ctx, r.db).Where("todo_id = ? AND tag_id IN ?", todoID, tagIDs).
 		Delete(&entity.TodoTag{}).
 		Error
 }
 
 // ReplaceTagsForTodo replaces all tags for a todo
 func (r *TodoTagRepositoryImpl) ReplaceTagsForTodo(ctx context.Context, todoID int64, tagIDs []int64) error {
-	tx := r.db.WithContext(ctx).Begin()
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("todo_id = ?", todoID).Delete(&entity.TodoTag{}).Error; err != nil {
+			return err
+		}
 
-	if err := tx.Where("todo_id = ?", todoID).Delete(&entity.TodoTag{}).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		if len(tagIDs) == 0 {
+			return nil
+		}
 
-	if len(tagIDs) > 0 {
 		var todoTags []entity.TodoTag
 		for _, tagID := range tagIDs {
 			todoTags = append(todoTags, entity.TodoTag{
@@ -64,22 +69,41 @@ func (r *TodoTagRepositoryImpl) ReplaceTagsForTodo(ctx context.Context, todoID i
 				TagID:  tagID,
 			})
 		}
-		if err := tx.Create(&todoTags).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
+		return tx.Create(&todoTags).Error
+	})
+}
+
+// MoveTags repoints the todo links of some tags, archived todos included, to
+// another tag. Todos already linked to the target tag keep a single link.
+func (r *TodoTagRepositoryImpl) MoveTags(ctx context.Context, fromTagIDs []int64, toTagID int64) error {
+	if len(fromTagIDs) == 0 {
+		return nil
 	}
 
-	return tx.Commit().Error
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"todo_tags", "archived_todo_tags"} {
+			// INSERT IGNORE skips links the todo already has to the target
+			insert := "INSERT IGNORE INTO " + table + " (todo_id, tag_id, created_at) " +
+				"SELECT todo_id, ?, MIN(created_at) FROM " + table + " WHERE tag_id IN ? GROUP BY todo_id"
+			if err := tx.Exec(insert, toTagID, fromTagIDs).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM "+table+" WHERE tag_id IN ?", fromTagIDs).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // GetTagsByTodoID gets all tags for a todo
 func (r *TodoTagRepositoryImpl) GetTagsByTodoID(ctx context.Context, todoID int64) ([]*entity.Tag, error) {
 	var tags []*entity.Tag
 
-	result := r.db.WithContext(ctx).Table("tags").
+	result := withContext(ctx, r.db).Table("tags").
 		Select("tags.*").
 		Joins("INNER JOIN todo_tags ON todo_tags.tag_id = tags.id").
+		Scopes(inTenant(ctx, "tags.organization_id")).
 		Where("todo_tags.todo_id = ? AND tags.deleted_at IS NULL", todoID).
 		Order("tags.name ASC").
 		Find(&tags)
@@ -90,14 +114,55 @@ func (r *TodoTagRepositoryImpl) GetTagsByTodoID(ctx context.Context, todoID int6
 	return tags, nil
 }
 
-// GetTodosByTagID gets all todos for a tag
+// GetTagsByTodoIDs gets the tags of several todos in a single query, keyed by todo ID
+func (r *TodoTagRepositoryImpl) GetTagsByTodoIDs(ctx context.Context, todoIDs []int64) (map[int64][]*entity.Tag, error) {
+	tagsByTodo := make(map[int64][]*entity.Tag, len(todoIDs))
+	if len(todoIDs) == 0 {
+		return tagsByTodo, nil
+	}
+
+	var rows []struct {
+		entity.Tag
+		TodoID int64
+	}
+
+	result := withContext(ctx, r.db).Table("tags").
+		Select("tags.*, todo_tags.todo_id").
+		Joins("INNER JOIN todo_tags ON todo_tags.tag_id = tags.id").
+		Scopes(inTenant(ctx, "tags.organization_id")).
+		Where("todo_tags.todo_id IN ? AND tags.deleted_at IS NULL", todoIDs).
+		Order("tags.name ASC").
+		Find(&rows)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for i := range rows {
+		tag := rows[i].Tag
+		tagsByTodo[rows[i].TodoID] = append(tagsByTodo[rows[i].TodoID], &tag)
+	}
+	return tagsByTodo, nil
+}
+
+// GetTodosByTagID gets all todos for a tag, including those tagged with a
+// tag nested under it
 func (r *TodoTagRepositoryImpl) GetTodosByTagID(ctx context.Context, tagID int64, offset, limit int) ([]*entity.Todo, int64, error) {
 	var todos []*entity.Todo
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&entity.Todo{}).
-		Joins("INNER JOIN todo_tags ON todo_tags.todo_id = todos.id").
-		Where("todo_tags.tag_id = ? AND todos.deleted_at IS NULL", tagID)
+	subtree := withContext(ctx, r.db).Table("tags p").
+		Select("d.id").
+		Joins("INNER JOIN tags d ON "+tagSubtreeJoin).
+		Scopes(inTenant(ctx, "p.organization_id")).
+		Where("p.id = ? AND d.deleted_at IS NULL", tagID)
+	tagged := withContext(ctx, r.db).Table("todo_tags").
+		Select("todo_id").
+		Where("tag_id IN (?)", subtree)
+
+	query := withContext(ctx, r.db).Model(&entity.Todo{}).
+		Scopes(inTenant(ctx, "todos.organization_id")).
+		Where("todos.id IN (?) AND todos.deleted_at IS NULL", tagged)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -115,7 +180,7 @@ func (r *TodoTagRepositoryImpl) GetTodosByTagID(ctx context.Context, tagID int64
 	return todos, total, nil
 }
 
-// GetTagStatsByUserID gets tag statistics for a user
+// GetTagStatsByUserID gets the number of todos per tag for a user
 func (r *TodoTagRepositoryImpl) GetTagStatsByUserID(ctx context.Context, userID int64) (map[int64]int64, error) {
 	type TagStat struct {
 		TagID int64
@@ -124,11 +189,16 @@ func (r *TodoTagRepositoryImpl) GetTagStatsByUserID(ctx context.Context, userID
 
 	var stats []TagStat
 
-	result := r.db.WithContext(ctx).Table("todo_tags").
-		Select("tag_id, COUNT(*) as count").
+	// Counts roll up the tree: a todo tagged "work/backend" also counts
+	// towards "work", once even when it has several tags under it
+	result := withContext(ctx, r.db).Table("tags p").
+		Select("p.id AS tag_id, COUNT(DISTINCT todo_tags.todo_id) as count").
+		Joins("INNER JOIN tags d ON "+tagSubtreeJoin).
+		Joins("INNER JOIN todo_tags ON todo_tags.tag_id = d.id").
 		Joins("INNER JOIN todos ON todos.id = todo_tags.todo_id").
-		Where("todos.user_id = ?", userID).
-		Group("tag_id").
+		Scopes(inTenant(ctx, "p.organization_id")).
+		Where("p.user_id = ? AND todos.user_id = ?", userID, userID).
+		Group("p.id").
 		Find(&stats)
 
 	if result.Error != nil {
@@ -142,3 +212,24 @@ func (r *TodoTagRepositoryImpl) GetTagStatsByUserID(ctx context.Context, userID
 
 	return tagStats, nil
 }
+
+// GetTagLineageIDsByTodoID gets the IDs of the tags a todo, archived or not,
+// counts towards: its own tags and the tags they are nested under
+func (r *TodoTagRepositoryImpl) GetTagLineageIDsByTodoID(ctx context.Context, todoID int64) ([]int64, error) {
+	var tagIDs []int64
+
+	// A todo is either active or archived, so at most one table has its links
+	links := withContext(ctx, r.db).Raw("SELECT tag_id FROM todo_tags WHERE todo_id = ? UNION ALL SELECT tag_id FROM archived_todo_tags WHERE todo_id = ?", todoID, todoID)
+	result := withContext(ctx, r.db).Table("(?) AS links", links).
+		Joins("INNER JOIN tags d ON d.id = links.tag_id").
+		Joins("INNER JOIN tags p ON "+tagSubtreeJoin).
+		Scopes(inTenant(ctx, "d.organization_id")).
+		Where("d.deleted_at IS NULL AND p.deleted_at IS NULL").
+		Distinct().
+		Pluck("p.id", &tagIDs)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tagIDs, nil
+}
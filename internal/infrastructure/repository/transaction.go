@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+type txContextKey struct{}
+
+// TransactionManagerImpl implements repository.TransactionManager interface
+type TransactionManagerImpl struct {
+	db *gorm.DB
+}
+
+// NewTransactionManager creates a new transaction manager
+func NewTransactionManager(db *gorm.DB) repository.TransactionManager {
+	return &TransactionManagerImpl{db: db}
+}
+
+// WithinTransaction runs fn inside a database transaction. Repositories
+// called with the context passed to fn take part in the transaction. Nested
+// calls join the outer transaction.
+func (m *TransactionManagerImpl) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// withContext returns the transaction bound to ctx, or db when there is none
+func withContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
 	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
 )
 
 var (
@@ -26,11 +28,25 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 	return &UserRepositoryImpl{db: db}
 }
 
-// Create creates a new user
+// members returns the database handle of withContext restricted to the
+// members of the tenant bound to ctx. Unscoped contexts see every user, and
+// queries through contexts bound to no tenant fail with ErrNoTenant.
+func (r *UserRepositoryImpl) members(ctx context.Context) *gorm.DB {
+	db := withContext(ctx, r.db)
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		return db.Where("id IN (SELECT user_id FROM organization_members WHERE organization_id = ?)", tenantID)
+	}
+	if !tenant.IsUnscoped(ctx) {
+		_ = db.AddError(ErrNoTenant)
+	}
+	return db
+}
+
+// Create creates a new user. Usernames and emails are unique across tenants.
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *entity.User) error {
 	// Check if username already exists
 	var existingUser entity.User
-	result := r.db.WithContext(ctx).Where("username = ?", user.Username).First(&existingUser)
+	result := withContext(ctx, r.db).Where("username = ?", user.Username).First(&existingUser)
 	if result.Error == nil {
 		return ErrUserExists
 	}
@@ -39,7 +55,7 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 	}
 
 	// Check if email already exists
-	result = r.db.WithContext(ctx).Where("email = ?", user.Email).First(&existingUser)
+	result = withContext(ctx, r.db).Where("email = ?", user.Email).First(&existingUser)
 	if result.Error == nil {
 		return ErrEmailExists
 	}
@@ -47,13 +63,13 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 		return result.Error
 	}
 
-	return r.db.WithContext(ctx).Create(user).Error
+	return withContext(ctx, r.db).Create(user).Error
 }
 
 // FindByID finds a user by ID
 func (r *UserRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&user)
+	result := r.members(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, ErrUserNotFound
@@ -66,7 +82,7 @@ func (r *UserRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Us
 // FindByUsername finds a user by username
 func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).Where("username = ? AND deleted_at IS NULL", username).First(&user)
+	result := r.members(ctx).Where("username = ? AND deleted_at IS NULL", username).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, ErrUserNotFound
@@ -79,7 +95,7 @@ func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, username string
 // FindByEmail finds a user by email
 func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	result := r.db.WithContext(ctx).Where("email = ? AND deleted_at IS NULL", email).First(&user)
+	result := r.members(ctx).Where("email = ? AND deleted_at IS NULL", email).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, ErrUserNotFound
@@ -89,9 +105,18 @@ func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*en
 	return &user, nil
 }
 
-// Update updates a user
+// Update updates a user. Users outside the tenant are reported as not found.
 func (r *UserRepositoryImpl) Update(ctx context.Context, user *entity.User) error {
-	result := r.db.WithContext(ctx).Save(user)
+	// Save inserts rows it cannot update, so check membership beforehand
+	var count int64
+	if err := r.members(ctx).Model(&entity.User{}).Where("id = ?", user.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+
+	result := withContext(ctx, r.db).Save(user)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -103,7 +128,7 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, user *entity.User) erro
 
 // Delete soft deletes a user
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id int64) error {
-	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entity.User{})
+	result := r.members(ctx).Where("id = ?", id).Delete(&entity.User{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -116,9 +141,33 @@ func (r *UserRepositoryImpl) Delete(ctx context.Context, id int64) error {
 // List lists users with pagination
 func (r *UserRepositoryImpl) List(ctx context.Context, offset, limit int) ([]*entity.User, error) {
 	var users []*entity.User
-	result := r.db.WithContext(ctx).Where("deleted_at IS NULL").Order("created_at DESC").Limit(limit).Offset(offset).Find(&users)
+	result := r.members(ctx).Where("deleted_at IS NULL").Order("created_at DESC").Limit(limit).Offset(offset).Find(&users)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return users, nil
 }
+
+// FindDigestSubscribers finds users opted into the digest email with an ID
+// above afterID, in ID order
+func (r *UserRepositoryImpl) FindDigestSubscribers(ctx context.Context, afterID int64, limit int) ([]*entity.User, error) {
+	var users []*entity.User
+	result := r.members(ctx).
+		Where("deleted_at IS NULL AND digest_frequency <> ? AND id > ?", entity.DigestFrequencyOff, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
+// MarkDigestSent records when a user's digest was last sent
+func (r *UserRepositoryImpl) MarkDigestSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	result := r.members(ctx).
+		Model(&entity.User{}).
+		Where("id = ?", userID).
+		Update("digest_sent_at", sentAt)
+	return result.Error
+}
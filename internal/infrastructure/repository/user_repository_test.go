@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
 	"github.com/darron08/todolist-demo/internal/infrastructure/database/mysql"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
@@ -65,7 +67,7 @@ func createTestUser(t *testing.T, db *mysql.DB) *entity.User {
 	}
 
 	userRepo := NewUserRepository(db.GetDB())
-	if err := userRepo.Create(user); err != nil {
+	if err := userRepo.Create(context.Background(), user); err != nil {
 		t.Fatalf("Failed to create test user: %v", err)
 	}
 
@@ -88,7 +90,7 @@ func TestUserRepository_Create(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
-	err := userRepo.Create(user)
+	err := userRepo.Create(context.Background(), user)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, user.ID)
 }
@@ -109,7 +111,7 @@ func TestUserRepository_Create_DuplicateUsername(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
-	err := userRepo.Create(user1)
+	err := userRepo.Create(context.Background(), user1)
 	assert.NoError(t, err)
 
 	user2 := &entity.User{
@@ -121,7 +123,7 @@ func TestUserRepository_Create_DuplicateUsername(t *testing.T) {
 		UpdatedAt:    time.Now(),
 	}
 
-	err = userRepo.Create(user2)
+	err = userRepo.Create(context.Background(), user2)
 	assert.Error(t, err)
 	assert.Equal(t, "user already exists", err.Error())
 }
@@ -133,7 +135,7 @@ func TestUserRepository_FindByID(t *testing.T) {
 	user := createTestUser(t, db)
 	userRepo := NewUserRepository(db.GetDB())
 
-	foundUser, err := userRepo.FindByID(user.ID)
+	foundUser, err := userRepo.FindByID(tenant.Unscoped(context.Background()), user.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, user.ID, foundUser.ID)
 	assert.Equal(t, user.Username, foundUser.Username)
@@ -147,7 +149,7 @@ func TestUserRepository_FindByUsername(t *testing.T) {
 	user := createTestUser(t, db)
 	userRepo := NewUserRepository(db.GetDB())
 
-	foundUser, err := userRepo.FindByUsername(user.Username)
+	foundUser, err := userRepo.FindByUsername(tenant.Unscoped(context.Background()), user.Username)
 	assert.NoError(t, err)
 	assert.Equal(t, user.ID, foundUser.ID)
 	assert.Equal(t, user.Username, foundUser.Username)
@@ -161,10 +163,10 @@ func TestUserRepository_Update(t *testing.T) {
 	userRepo := NewUserRepository(db.GetDB())
 
 	user.Email = "updated@example.com"
-	err := userRepo.Update(user)
+	err := userRepo.Update(tenant.Unscoped(context.Background()), user)
 	assert.NoError(t, err)
 
-	updatedUser, err := userRepo.FindByID(user.ID)
+	updatedUser, err := userRepo.FindByID(tenant.Unscoped(context.Background()), user.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, "updated@example.com", updatedUser.Email)
 }
@@ -176,10 +178,10 @@ func TestUserRepository_Delete(t *testing.T) {
 	user := createTestUser(t, db)
 	userRepo := NewUserRepository(db.GetDB())
 
-	err := userRepo.Delete(user.ID)
+	err := userRepo.Delete(tenant.Unscoped(context.Background()), user.ID)
 	assert.NoError(t, err)
 
-	_, err = userRepo.FindByID(user.ID)
+	_, err = userRepo.FindByID(tenant.Unscoped(context.Background()), user.ID)
 	assert.Error(t, err)
 	assert.Equal(t, "user not found", err.Error())
 }
@@ -200,11 +202,11 @@ func TestUserRepository_List(t *testing.T) {
 			CreatedAt:    time.Now(),
 			UpdatedAt:    time.Now(),
 		}
-		err := userRepo.Create(user)
+		err := userRepo.Create(context.Background(), user)
 		assert.NoError(t, err)
 	}
 
-	users, err := userRepo.List(0, 10)
+	users, err := userRepo.List(tenant.Unscoped(context.Background()), 0, 10)
 	assert.NoError(t, err)
 	assert.GreaterOrEqual(t, len(users), 5)
 }
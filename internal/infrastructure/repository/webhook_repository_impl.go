@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookRepositoryImpl implements repository.WebhookRepository interface
+type WebhookRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &WebhookRepositoryImpl{db: db}
+}
+
+// Create creates a new webhook in the tenant bound to ctx
+func (r *WebhookRepositoryImpl) Create(ctx context.Context, webhook *entity.Webhook) error {
+	orgID, err := assignTenant(ctx, webhook.OrganizationID)
+	if err != nil {
+		return err
+	}
+	webhook.OrganizationID = orgID
+
+	return withContext(ctx, r.db).Create(webhook).Error
+}
+
+// FindByID finds a webhook by ID
+func (r *WebhookRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	result := scoped(ctx, r.db).Where("id = ? AND deleted_at IS NULL", id).First(&webhook)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, result.Error
+	}
+	return &webhook, nil
+}
+
+// FindByUserID finds all webhooks owned by a user
+func (r *WebhookRepositoryImpl) FindByUserID(ctx context.Context, userID int64) ([]*entity.Webhook, error) {
+	var webhooks []*entity.Webhook
+	result := scoped(ctx, r.db).Where("user_id = ? AND deleted_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&webhooks)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return webhooks, nil
+}
+
+// FindActiveByUserID finds all active webhooks owned by a user
+func (r *WebhookRepositoryImpl) FindActiveByUserID(ctx context.Context, userID int64) ([]*entity.Webhook, error) {
+	var webhooks []*entity.Webhook
+	result := scoped(ctx, r.db).Where("user_id = ? AND active = ? AND deleted_at IS NULL", userID, true).
+		Find(&webhooks)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return webhooks, nil
+}
+
+// Update updates a webhook
+func (r *WebhookRepositoryImpl) Update(ctx context.Context, webhook *entity.Webhook) error {
+	if !inCurrentTenant(ctx, webhook.OrganizationID) {
+		return ErrWebhookNotFound
+	}
+
+	result := scoped(ctx, r.db).Select("*").Updates(webhook)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Delete soft deletes a webhook
+func (r *WebhookRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	result := scoped(ctx, r.db).Where("id = ?", id).Delete(&entity.Webhook{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// WebhookDeliveryRepositoryImpl implements repository.WebhookDeliveryRepository interface
+type WebhookDeliveryRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *gorm.DB) repository.WebhookDeliveryRepository {
+	return &WebhookDeliveryRepositoryImpl{db: db}
+}
+
+// Create creates a new delivery record
+func (r *WebhookDeliveryRepositoryImpl) Create(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	return withContext(ctx, r.db).Create(delivery).Error
+}
+
+// FindByID finds a delivery record by ID
+func (r *WebhookDeliveryRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	result := withContext(ctx, r.db).Where("id = ?", id).First(&delivery)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, result.Error
+	}
+	return &delivery, nil
+}
+
+// FindByWebhookID finds delivery records for a webhook with pagination
+func (r *WebhookDeliveryRepositoryImpl) FindByWebhookID(ctx context.Context, webhookID int64, offset, limit int) ([]*entity.WebhookDelivery, int64, error) {
+	var deliveries []*entity.WebhookDelivery
+	var total int64
+
+	query := withContext(ctx, r.db).Model(&entity.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return deliveries, total, nil
+}
+
+// FindStalled finds pending deliveries whose next attempt was due before the
+// given time, oldest first
+func (r *WebhookDeliveryRepositoryImpl) FindStalled(ctx context.Context, before time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+	result := withContext(ctx, r.db).
+		Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryPending, before).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return deliveries, nil
+}
+
+// Update updates a delivery record
+func (r *WebhookDeliveryRepositoryImpl) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	result := withContext(ctx, r.db).Save(delivery)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+)
+
+var ErrWorkflowNotFound = errors.New("workflow not found")
+
+// WorkflowRepositoryImpl implements repository.WorkflowRepository interface
+type WorkflowRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewWorkflowRepository creates a new workflow repository
+func NewWorkflowRepository(db *gorm.DB) repository.WorkflowRepository {
+	return &WorkflowRepositoryImpl{db: db}
+}
+
+// FindByUserID finds the workflow a user defined
+func (r *WorkflowRepositoryImpl) FindByUserID(ctx context.Context, userID int64) (*entity.Workflow, error) {
+	var workflow entity.Workflow
+	result := withContext(ctx, r.db).
+		Preload("States", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where("user_id = ?", userID).
+		First(&workflow)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, ErrWorkflowNotFound
+		}
+		return nil, result.Error
+	}
+	return &workflow, nil
+}
+
+// Save creates a workflow, or replaces the states of an existing one
+func (r *WorkflowRepositoryImpl) Save(ctx context.Context, workflow *entity.Workflow) error {
+	db := withContext(ctx, r.db)
+	if workflow.ID == 0 {
+		return db.Create(workflow).Error
+	}
+
+	if err := db.Omit("States").Save(workflow).Error; err != nil {
+		return err
+	}
+	if err := db.Where("workflow_id = ?", workflow.ID).Delete(&entity.WorkflowState{}).Error; err != nil {
+		return err
+	}
+	for i := range workflow.States {
+		workflow.States[i].ID = 0
+		workflow.States[i].WorkflowID = workflow.ID
+	}
+	return db.Create(&workflow.States).Error
+}
+
+// DeleteByUserID deletes a user's workflow; its states are removed by the
+// foreign key
+func (r *WorkflowRepositoryImpl) DeleteByUserID(ctx context.Context, userID int64) error {
+	result := withContext(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.Workflow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWorkflowNotFound
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+)
+
+// maxResponseExcerpt limits how much of a receiver's response is stored in the delivery log
+const maxResponseExcerpt = 256
+
+// Config represents webhook dispatcher configuration
+type Config struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	PollInterval   time.Duration
+	BatchSize      int64
+	// Workers bounds how many deliveries are sent at once, so that slow
+	// receivers do not hold up the others
+	Workers int
+	// SweepInterval is how often pending deliveries missing from the queue
+	// are put back in it
+	SweepInterval time.Duration
+	// AllowPrivateNetworks lets webhooks reach private, loopback and
+	// link-local addresses; only for local development
+	AllowPrivateNetworks bool
+}
+
+// Dispatcher fans domain events out to webhook subscriptions and delivers them
+// from the Redis-backed retry queue
+type Dispatcher struct {
+	webhookRepo  repository.WebhookRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	queue        *Queue
+	httpClient   *http.Client
+	config       Config
+	// lease is how long a claimed delivery is kept from other instances; it
+	// outlasts an attempt
+	lease time.Duration
+	// slots holds a token per delivery being sent
+	slots chan struct{}
+	// inFlight tracks the deliveries being sent
+	inFlight sync.WaitGroup
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(webhookRepo repository.WebhookRepository, deliveryRepo repository.WebhookDeliveryRepository, queue *Queue, config Config) *Dispatcher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 10 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 6 * time.Hour
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.Workers <= 0 {
+		config.Workers = 10
+	}
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = time.Minute
+	}
+
+	return &Dispatcher{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		queue:        queue,
+		httpClient:   newHTTPClient(config.Timeout, config.AllowPrivateNetworks),
+		config:       config,
+		lease:        2*config.Timeout + 30*time.Second,
+		slots:        make(chan struct{}, config.Workers),
+	}
+}
+
+// AllowsHost reports whether a webhook URL host may receive deliveries
+func (d *Dispatcher) AllowsHost(host string) bool {
+	return d.config.AllowPrivateNetworks || IsPublicHost(host)
+}
+
+// HandleEvent creates a delivery for every active webhook subscribed to the
+// event in the tenant it happened in
+func (d *Dispatcher) HandleEvent(ctx context.Context, evt *event.Event) {
+	webhooks, err := d.webhookRepo.FindActiveByUserID(tenant.WithID(ctx, evt.OrganizationID), evt.UserID)
+	if err != nil {
+		log.Printf("Warning: failed to load webhooks for user %d: %v", evt.UserID, err)
+		return
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		log.Printf("Warning: failed to marshal event %s: %v", evt.ID, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !subscribes(webhook, evt.Type) {
+			continue
+		}
+
+		now := time.Now()
+		delivery := &entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       evt.ID,
+			EventType:     string(evt.Type),
+			Payload:       string(payload),
+			Status:        entity.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+
+		if err := d.deliveryRepo.Create(ctx, delivery); err != nil {
+			log.Printf("Warning: failed to create delivery for webhook %d: %v", webhook.ID, err)
+			continue
+		}
+
+		if err := d.queue.Enqueue(ctx, delivery.ID, now); err != nil {
+			log.Printf("Warning: failed to enqueue delivery %d: %v", delivery.ID, err)
+		}
+	}
+}
+
+// Redeliver resets a delivery and schedules it for immediate delivery
+func (d *Dispatcher) Redeliver(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	now := time.Now()
+	delivery.Status = entity.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+
+	if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
+		return err
+	}
+
+	return d.queue.Enqueue(ctx, delivery.ID, now)
+}
+
+// Start polls the retry queue until the context is cancelled, then waits for
+// the deliveries being sent
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	sweepTicker := time.NewTicker(d.config.SweepInterval)
+	defer sweepTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.inFlight.Wait()
+			return
+		case <-ticker.C:
+			d.processDue(ctx)
+		case <-sweepTicker.C:
+			d.sweep(ctx)
+		}
+	}
+}
+
+// processDue claims as many due deliveries as there are idle workers and
+// sends each from its own goroutine
+func (d *Dispatcher) processDue(ctx context.Context) {
+	idle := int64(cap(d.slots) - len(d.slots))
+	if idle > d.config.BatchSize {
+		idle = d.config.BatchSize
+	}
+	if idle == 0 {
+		return
+	}
+
+	ids, err := d.queue.ClaimDue(ctx, time.Now(), d.lease, idle)
+	if err != nil {
+		log.Printf("Warning: failed to claim webhook deliveries: %v", err)
+	}
+
+	for _, id := range ids {
+		d.slots <- struct{}{}
+		d.inFlight.Add(1)
+		go func(id int64) {
+			defer func() {
+				<-d.slots
+				d.inFlight.Done()
+			}()
+			if err := d.deliver(ctx, id); err != nil {
+				log.Printf("Warning: failed to process webhook delivery %d: %v", id, err)
+			}
+		}(id)
+	}
+}
+
+// sweep puts pending deliveries that have been due for longer than a lease
+// back in the queue, such as those whose enqueueing failed. Deliveries still
+// queued or leased are left alone.
+func (d *Dispatcher) sweep(ctx context.Context) {
+	deliveries, err := d.deliveryRepo.FindStalled(ctx, time.Now().Add(-d.lease), int(d.config.BatchSize))
+	if err != nil {
+		log.Printf("Warning: failed to find stalled webhook deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		if err := d.queue.Restore(ctx, delivery.ID, *delivery.NextAttemptAt); err != nil {
+			log.Printf("Warning: failed to restore webhook delivery %d: %v", delivery.ID, err)
+		}
+	}
+}
+
+// deliver performs a single delivery attempt and schedules a retry on
+// failure. The delivery leaves the queue once its outcome is recorded; if
+// recording fails, it is attempted again when its lease runs out.
+func (d *Dispatcher) deliver(ctx context.Context, deliveryID int64) error {
+	delivery, err := d.deliveryRepo.FindByID(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrWebhookDeliveryNotFound) {
+			return d.queue.Complete(ctx, deliveryID)
+		}
+		return err
+	}
+
+	if delivery.Status != entity.WebhookDeliveryPending {
+		return d.queue.Complete(ctx, deliveryID)
+	}
+
+	// Deliveries were scoped when they were created
+	webhook, err := d.webhookRepo.FindByID(tenant.Unscoped(ctx), delivery.WebhookID)
+	if err != nil {
+		if !errors.Is(err, repositoryImpl.ErrWebhookNotFound) {
+			return err
+		}
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = nil
+		return d.complete(ctx, delivery)
+	}
+
+	delivery.Attempts++
+	statusCode, body, sendErr := d.send(ctx, webhook, delivery)
+	delivery.ResponseStatus = statusCode
+	delivery.ResponseBody = body
+
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		return d.complete(ctx, delivery)
+	}
+
+	delivery.LastError = sendErr.Error()
+
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		return d.complete(ctx, delivery)
+	}
+
+	next := time.Now().Add(Backoff(delivery.Attempts, d.config.InitialBackoff, d.config.MaxBackoff))
+	delivery.NextAttemptAt = &next
+	if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
+		return err
+	}
+
+	return d.queue.Enqueue(ctx, delivery.ID, next)
+}
+
+// complete records the final outcome of a delivery and removes it from the
+// queue
+func (d *Dispatcher) complete(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	if err := d.deliveryRepo.Update(ctx, delivery); err != nil {
+		return err
+	}
+	return d.queue.Complete(ctx, delivery.ID)
+}
+
+// send posts the signed payload to the webhook URL, returning the response
+// status and an excerpt of its body. Redirects are not followed.
+func (d *Dispatcher) send(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todolist-webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, fmt.Sprintf("%d", delivery.ID))
+	req.Header.Set(HeaderTimestamp, fmt.Sprintf("%d", timestamp))
+	req.Header.Set(HeaderSignature, BuildSignatureHeader(webhook.Secret, timestamp, body))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseExcerpt))
+	excerpt := responseExcerpt(respBody)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, excerpt, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, excerpt, nil
+}
+
+// responseExcerpt turns the start of a response body into a single line of
+// printable text for the delivery log
+func responseExcerpt(body []byte) string {
+	text := strings.ToValidUTF8(string(body), "")
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Backoff returns the exponential retry delay after the given number of attempts
+func Backoff(attempts int, initial, max time.Duration) time.Duration {
+	if attempts < 1 {
+		return initial
+	}
+
+	delay := initial
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// subscribes reports whether a webhook is subscribed to an event type
+func subscribes(webhook *entity.Webhook, eventType event.Type) bool {
+	for _, pattern := range webhook.EventList() {
+		if eventType.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
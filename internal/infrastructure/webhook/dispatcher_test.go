@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureHeader_RoundTrip(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"type":"todo.created"}`)
+	now := time.Now()
+
+	header := BuildSignatureHeader(secret, now.Unix(), body)
+
+	assert.True(t, VerifySignatureHeader(secret, header, body, 5*time.Minute, now))
+	assert.False(t, VerifySignatureHeader("wrong-secret", header, body, 5*time.Minute, now))
+	assert.False(t, VerifySignatureHeader(secret, header, []byte(`{"type":"todo.deleted"}`), 5*time.Minute, now))
+}
+
+func TestSignatureHeader_RejectsStaleTimestamp(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{}`)
+	signedAt := time.Now().Add(-10 * time.Minute)
+
+	header := BuildSignatureHeader(secret, signedAt.Unix(), body)
+
+	assert.False(t, VerifySignatureHeader(secret, header, body, 5*time.Minute, time.Now()))
+	assert.True(t, VerifySignatureHeader(secret, header, body, 0, time.Now()))
+}
+
+func TestBackoff(t *testing.T) {
+	initial := 10 * time.Second
+	max := time.Minute
+
+	assert.Equal(t, 10*time.Second, Backoff(1, initial, max))
+	assert.Equal(t, 20*time.Second, Backoff(2, initial, max))
+	assert.Equal(t, 40*time.Second, Backoff(3, initial, max))
+	assert.Equal(t, time.Minute, Backoff(4, initial, max))
+	assert.Equal(t, time.Minute, Backoff(20, initial, max))
+}
+
+func TestSubscribes(t *testing.T) {
+	webhook := &entity.Webhook{}
+	webhook.SetEventList([]string{"todo.completed", "tag.*"})
+
+	assert.True(t, subscribes(webhook, event.TodoCompleted))
+	assert.True(t, subscribes(webhook, event.TagCreated))
+	assert.True(t, subscribes(webhook, event.TagDeleted))
+	assert.False(t, subscribes(webhook, event.TodoCreated))
+
+	webhook.SetEventList([]string{"*"})
+	assert.True(t, subscribes(webhook, event.TodoDeleted))
+}
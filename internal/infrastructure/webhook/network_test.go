@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for _, addr := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.100.100.200", "0.0.0.0", "255.255.255.255", "224.0.0.1",
+		"::1", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1", "64:ff9b::a00:1",
+	} {
+		assert.False(t, IsPublicIP(net.ParseIP(addr)), addr)
+	}
+	for _, addr := range []string{"93.184.216.34", "8.8.8.8", "2606:4700:4700::1111"} {
+		assert.True(t, IsPublicIP(net.ParseIP(addr)), addr)
+	}
+
+	assert.False(t, IsPublicHost("localhost"))
+	assert.False(t, IsPublicHost("api.localhost."))
+	assert.False(t, IsPublicHost("169.254.169.254"))
+	assert.True(t, IsPublicHost("hooks.example.com"))
+}
+
+func TestSend_BlocksPrivateAddresses(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(nil, nil, nil, Config{Timeout: time.Second})
+	_, _, err := d.send(context.Background(), &entity.Webhook{URL: server.URL}, &entity.WebhookDelivery{Payload: "{}"})
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+	assert.Zero(t, hits)
+}
+
+func TestSend_DoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			followed = true
+			return
+		}
+		w.Header().Set("Location", "/internal")
+		w.WriteHeader(http.StatusFound)
+		w.Write([]byte("moved\x00\r\n  to\t/internal"))
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(nil, nil, nil, Config{Timeout: time.Second, AllowPrivateNetworks: true})
+	status, body, err := d.send(context.Background(), &entity.Webhook{URL: server.URL}, &entity.WebhookDelivery{Payload: "{}"})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Equal(t, "moved to /internal", body)
+	assert.False(t, followed)
+}
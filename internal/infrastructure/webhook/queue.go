@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	redisv8 "github.com/go-redis/redis/v8"
+)
+
+// QueueKey is the Redis sorted set holding pending delivery IDs scored by next attempt time
+const QueueKey = "webhook:deliveries:queue"
+
+// claimScript leases due deliveries by pushing their score past the lease, so
+// that no other instance claims them until the lease runs out
+var claimScript = redisv8.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[1], 'XX', ARGV[3], id)
+end
+return ids
+`)
+
+// Queue is a Redis-backed delayed queue of webhook delivery IDs
+type Queue struct {
+	redisClient *redis.Client
+	key         string
+}
+
+// NewQueue creates a new delivery queue
+func NewQueue(redisClient *redis.Client) *Queue {
+	return &Queue{
+		redisClient: redisClient,
+		key:         QueueKey,
+	}
+}
+
+// Enqueue schedules a delivery to be attempted at the given time
+func (q *Queue) Enqueue(ctx context.Context, deliveryID int64, at time.Time) error {
+	return q.redisClient.ZAddByID(ctx, q.key, float64(at.Unix()), deliveryID)
+}
+
+// Restore schedules a delivery unless it is already queued, leaving the
+// time of queued and leased deliveries as is
+func (q *Queue) Restore(ctx context.Context, deliveryID int64, at time.Time) error {
+	return q.redisClient.ZAddNX(ctx, q.key, &redisv8.Z{
+		Score:  float64(at.Unix()),
+		Member: deliveryID,
+	}).Err()
+}
+
+// ClaimDue claims up to limit deliveries that are due at the given time,
+// leasing them until now+lease. Claimed deliveries stay in the queue until
+// Complete or Enqueue records their outcome, so a delivery whose instance
+// stops before then is claimed again once its lease runs out.
+func (q *Queue) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int64) ([]int64, error) {
+	members, err := claimScript.Run(ctx, q.redisClient.Client, []string{q.key},
+		now.Unix(), limit, now.Add(lease).Unix()).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]int64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		claimed = append(claimed, id)
+	}
+
+	return claimed, nil
+}
+
+// Complete removes a delivery that needs no further attempts from the queue
+func (q *Queue) Complete(ctx context.Context, deliveryID int64) error {
+	return q.redisClient.ZRemByID(ctx, q.key, deliveryID)
+}
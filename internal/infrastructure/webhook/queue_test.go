@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueue(t *testing.T) (*Queue, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client, err := redis.NewConnection(&redis.Config{Host: server.Host(), Port: server.Port()})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return NewQueue(client), server
+}
+
+func TestQueue_ClaimLeasesUntilComplete(t *testing.T) {
+	ctx := context.Background()
+	queue, _ := newTestQueue(t)
+	now := time.Now()
+
+	require.NoError(t, queue.Enqueue(ctx, 1, now))
+	require.NoError(t, queue.Enqueue(ctx, 2, now))
+	require.NoError(t, queue.Enqueue(ctx, 3, now.Add(time.Hour)))
+
+	claimed, err := queue.ClaimDue(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, claimed)
+
+	// Leased deliveries are not claimed again
+	claimed, err = queue.ClaimDue(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// until their lease runs out without an outcome, as when an instance stops
+	require.NoError(t, queue.Complete(ctx, 1))
+	claimed, err = queue.ClaimDue(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, claimed)
+
+	// Restoring leaves queued and leased deliveries as they are
+	require.NoError(t, queue.Restore(ctx, 2, now))
+	require.NoError(t, queue.Restore(ctx, 1, now))
+	claimed, err = queue.ClaimDue(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, claimed)
+}
+
+// memoryWebhookRepository keeps webhooks in a map
+type memoryWebhookRepository struct {
+	repository.WebhookRepository
+	webhooks map[int64]*entity.Webhook
+}
+
+func (r *memoryWebhookRepository) FindByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, repositoryImpl.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// memoryDeliveryRepository keeps deliveries in a map, safe for concurrent use
+type memoryDeliveryRepository struct {
+	repository.WebhookDeliveryRepository
+	mu         sync.Mutex
+	deliveries map[int64]entity.WebhookDelivery
+}
+
+func (r *memoryDeliveryRepository) FindByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, repositoryImpl.ErrWebhookDeliveryNotFound
+	}
+	return &delivery, nil
+}
+
+func (r *memoryDeliveryRepository) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *memoryDeliveryRepository) status(id int64) entity.WebhookDeliveryStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deliveries[id].Status
+}
+
+func TestDispatcher_SlowReceiverDoesNotHoldUpOthers(t *testing.T) {
+	ctx := context.Background()
+	queue, server := newTestQueue(t)
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	webhooks := &memoryWebhookRepository{webhooks: map[int64]*entity.Webhook{
+		1: {ID: 1, URL: slow.URL},
+		2: {ID: 2, URL: fast.URL},
+	}}
+	deliveries := &memoryDeliveryRepository{deliveries: map[int64]entity.WebhookDelivery{
+		1: {ID: 1, WebhookID: 1, Payload: "{}", Status: entity.WebhookDeliveryPending},
+		2: {ID: 2, WebhookID: 2, Payload: "{}", Status: entity.WebhookDeliveryPending},
+	}}
+	d := NewDispatcher(webhooks, deliveries, queue, Config{Timeout: 5 * time.Second, Workers: 2, AllowPrivateNetworks: true})
+
+	now := time.Now()
+	require.NoError(t, queue.Enqueue(ctx, 1, now))
+	require.NoError(t, queue.Enqueue(ctx, 2, now))
+	d.processDue(ctx)
+
+	require.Eventually(t, func() bool {
+		return deliveries.status(2) == entity.WebhookDeliverySucceeded
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, entity.WebhookDeliveryPending, deliveries.status(1))
+
+	// Busy workers claim nothing; the slow delivery stays leased
+	members, err := server.ZMembers(QueueKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, members)
+
+	close(release)
+	d.inFlight.Wait()
+	assert.Equal(t, entity.WebhookDeliverySucceeded, deliveries.status(1))
+	assert.False(t, server.Exists(QueueKey))
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header names sent with every webhook delivery
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// Sign computes the hex-encoded HMAC-SHA256 of "<timestamp>.<body>" using the secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// BuildSignatureHeader builds the signature header value ("t=<timestamp>,v1=<signature>")
+func BuildSignatureHeader(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(secret, timestamp, body))
+}
+
+// VerifySignatureHeader verifies a signature header against the body. Signatures
+// older than tolerance are rejected to prevent replay attacks.
+func VerifySignatureHeader(secret, header string, body []byte, tolerance time.Duration, now time.Time) bool {
+	var timestamp int64
+	var signature string
+
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			ts, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return false
+			}
+			timestamp = ts
+		case "v1":
+			signature = kv[1]
+		}
+	}
+
+	if timestamp == 0 || signature == "" {
+		return false
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(timestamp, 0))
+		if age < -tolerance || age > tolerance {
+			return false
+		}
+	}
+
+	expected := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// GenerateSecret generates a random signing secret
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
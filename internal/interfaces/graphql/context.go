@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+type contextKey string
+
+const (
+	userIDKey  contextKey = "graphql.user_id"
+	loadersKey contextKey = "graphql.loaders"
+)
+
+// Loaders holds the dataloaders of a single request
+type Loaders struct {
+	TodoTags *Loader[int64, []dto.TagResponse]
+}
+
+// newLoaders creates fresh loaders so that nothing is cached across requests
+func newLoaders(todoUseCase *usecase.TodoUseCase) *Loaders {
+	return &Loaders{
+		TodoTags: NewLoader(todoUseCase.GetTagsByTodoIDs),
+	}
+}
+
+func withUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+func userIDFrom(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDKey).(int64)
+	return userID, ok && userID > 0
+}
+
+func withLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey, loaders)
+}
+
+func loadersFrom(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersKey).(*Loaders)
+	return loaders
+}
@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderBatchesQueuedKeys(t *testing.T) {
+	var batches [][]int64
+	loader := NewLoader(func(ctx context.Context, keys []int64) (map[int64]string, error) {
+		batches = append(batches, keys)
+		values := make(map[int64]string, len(keys))
+		for _, key := range keys {
+			values[key] = "tag"
+		}
+		return values, nil
+	})
+
+	ctx := context.Background()
+	thunks := []func() (interface{}, error){
+		loader.Load(ctx, 1),
+		loader.Load(ctx, 2),
+		loader.Load(ctx, 2),
+		loader.Load(ctx, 3),
+	}
+
+	for _, thunk := range thunks {
+		value, err := thunk()
+		require.NoError(t, err)
+		assert.Equal(t, "tag", value)
+	}
+
+	// Cached keys are not fetched again
+	value, err := loader.Load(ctx, 1)()
+	require.NoError(t, err)
+	assert.Equal(t, "tag", value)
+
+	assert.Equal(t, [][]int64{{1, 2, 3}}, batches)
+}
+
+func TestMeasure(t *testing.T) {
+	doc, err := parser.Parse(parser.ParseParams{Source: `
+		query List($limit: Int) {
+			me { id username }
+			todos(limit: $limit) {
+				total
+				data { id title tags { id name } }
+			}
+		}
+	`})
+	require.NoError(t, err)
+
+	cost := Measure(doc, map[string]interface{}{"limit": float64(5)})
+	assert.Equal(t, 4, cost.Depth)
+	// me(1+2) + todos(1 + 5*(total + data(1 + id + title + tags(1 + 20*2))))
+	assert.Equal(t, 3+1+5*(1+1+2+1+20*2), cost.Complexity)
+}
+
+func TestMeasureFragmentCycle(t *testing.T) {
+	doc, err := parser.Parse(parser.ParseParams{Source: `
+		query { me { ...A } }
+		fragment A on User { id ...B }
+		fragment B on User { username ...A }
+	`})
+	require.NoError(t, err)
+
+	cost := Measure(doc, nil)
+	assert.Equal(t, 2, cost.Depth)
+	assert.Equal(t, 3, cost.Complexity)
+}
+
+func TestExecuteRejectsExpensiveQueries(t *testing.T) {
+	schema, err := NewSchema(nil, nil, nil, Limits{MaxDepth: 3, MaxComplexity: 50})
+	require.NoError(t, err)
+
+	result := schema.Execute(context.Background(), 1, &Request{
+		Query: `{ todos { data { tags { id } } } }`,
+	})
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, ErrQueryTooDeep.Error())
+
+	result = schema.Execute(context.Background(), 1, &Request{
+		Query: `{ todos(limit: 100) { data { id title } } }`,
+	})
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, ErrQueryTooComplex.Error())
+}
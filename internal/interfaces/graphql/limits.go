@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+var (
+	ErrQueryTooDeep    = errors.New("query exceeds maximum depth")
+	ErrQueryTooComplex = errors.New("query exceeds maximum complexity")
+)
+
+// defaultListSizes is the assumed page size of list fields queried without
+// an explicit limit argument
+var defaultListSizes = map[string]int{
+	"todos":  20,
+	"tags":   20,
+	"myTags": 50,
+}
+
+// Limits bounds the cost of a single query
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Cost is the measured depth and complexity of a query document
+type Cost struct {
+	Depth      int
+	Complexity int
+}
+
+// Check measures a parsed query and rejects it if it exceeds the limits.
+// Limits of zero or less are not enforced.
+func (l Limits) Check(doc *ast.Document, variables map[string]interface{}) error {
+	cost := Measure(doc, variables)
+
+	if l.MaxDepth > 0 && cost.Depth > l.MaxDepth {
+		return fmt.Errorf("%w: %d > %d", ErrQueryTooDeep, cost.Depth, l.MaxDepth)
+	}
+	if l.MaxComplexity > 0 && cost.Complexity > l.MaxComplexity {
+		return fmt.Errorf("%w: %d > %d", ErrQueryTooComplex, cost.Complexity, l.MaxComplexity)
+	}
+	return nil
+}
+
+// Measure computes the depth and complexity of every operation in a
+// document, returning the most expensive values. Each field costs one
+// point; the cost of a list field's selections is multiplied by its limit
+// argument, or by its default page size.
+func Measure(doc *ast.Document, variables map[string]interface{}) Cost {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	m := &measurer{fragments: fragments, variables: variables}
+
+	var cost Cost
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		depth, complexity := m.selectionSet(operation.SelectionSet, map[string]bool{})
+		if depth > cost.Depth {
+			cost.Depth = depth
+		}
+		if complexity > cost.Complexity {
+			cost.Complexity = complexity
+		}
+	}
+	return cost
+}
+
+// measurer walks selection sets, expanding fragments
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selectionSet returns the depth and complexity of a selection set. visiting
+// holds the fragments on the current path so that cycles terminate.
+func (m *measurer) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	maxDepth, complexity := 0, 0
+	for _, selection := range set.Selections {
+		var depth, cost int
+
+		switch node := selection.(type) {
+		case *ast.Field:
+			childDepth, childCost := m.selectionSet(node.SelectionSet, visiting)
+			depth = childDepth + 1
+			cost = 1 + childCost*m.multiplier(node)
+		case *ast.InlineFragment:
+			depth, cost = m.selectionSet(node.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := node.Name.Value
+			fragment, ok := m.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			depth, cost = m.selectionSet(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		complexity += cost
+	}
+
+	return maxDepth, complexity
+}
+
+// multiplier returns how many times a field's selections are resolved
+func (m *measurer) multiplier(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		if limit := m.intValue(argument.Value); limit > 0 {
+			return limit
+		}
+	}
+
+	if size, ok := defaultListSizes[field.Name.Value]; ok {
+		return size
+	}
+	return 1
+}
+
+// intValue resolves an integer literal or variable
+func (m *measurer) intValue(value ast.Value) int {
+	switch v := value.(type) {
+	case *ast.IntValue:
+		n, _ := strconv.Atoi(v.Value)
+		return n
+	case *ast.Variable:
+		switch n := m.variables[v.Name.Value].(type) {
+		case int:
+			return n
+		case float64:
+			return int(n)
+		}
+	}
+	return 0
+}
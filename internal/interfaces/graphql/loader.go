@@ -0,0 +1,77 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc loads the values of several keys in a single call. Keys missing
+// from the result resolve to the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader is a per-request dataloader. Load queues a key and returns a thunk;
+// the first thunk that runs fetches every queued key in one batch. The
+// GraphQL executor resolves thunks breadth-first, so all sibling list items
+// queue their keys before the batch is dispatched.
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]struct{}
+	cache   map[K]V
+	errs    map[K]error
+}
+
+// NewLoader creates a new loader around a batch function
+func NewLoader[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:  batch,
+		queued: make(map[K]struct{}),
+		cache:  make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// Load queues a key and returns a thunk that resolves its value
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, cached := l.cache[key]; !cached {
+		if _, queued := l.queued[key]; !queued {
+			l.queued[key] = struct{}{}
+			l.pending = append(l.pending, key)
+		}
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		return l.get(ctx, key)
+	}
+}
+
+// get returns a key's value, dispatching the pending batch if needed
+func (l *Loader[K, V]) get(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if value, ok := l.cache[key]; ok {
+		return value, l.errs[key]
+	}
+
+	keys := l.pending
+	if _, queued := l.queued[key]; !queued {
+		keys = append(keys, key)
+	}
+	l.pending = nil
+	l.queued = make(map[K]struct{})
+
+	values, err := l.batch(ctx, keys)
+	for _, k := range keys {
+		l.cache[k] = values[k]
+		if err != nil {
+			l.errs[k] = err
+		}
+	}
+
+	return l.cache[key], l.errs[key]
+}
@@ -0,0 +1,577 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+var (
+	ErrUnauthenticated = errors.New("user not authenticated")
+	ErrInvalidID       = errors.New("invalid id")
+	ErrTooManyTags     = errors.New("a todo can have at most 10 tags")
+	ErrInternal        = errors.New("internal server error")
+)
+
+// publicErrors are usecase errors whose messages are safe to return to clients
+var publicErrors = []error{
+	usecase.ErrTodoTitleRequired,
+	usecase.ErrTodoTitleTooLong,
+	usecase.ErrTodoDescriptionTooLong,
+	usecase.ErrInvalidStatus,
+	usecase.ErrTransitionNotAllowed,
+	usecase.ErrInvalidPriority,
+	usecase.ErrStartAfterDue,
+	usecase.ErrScheduledAfterDue,
+	usecase.ErrScheduledBeforeStart,
+	usecase.ErrInvalidAssignee,
+	usecase.ErrTodoNotFound,
+	usecase.ErrTagNotFound,
+	usecase.ErrUnauthorized,
+	usecase.ErrTagNameRequired,
+	usecase.ErrTagNameTooLong,
+	usecase.ErrInvalidTagPath,
+	usecase.ErrTagPathTooDeep,
+	usecase.ErrTagMoveIntoSelf,
+	usecase.ErrTagHasChildren,
+	usecase.ErrUserNotFound,
+	repositoryImpl.ErrTodoNotFound,
+	repositoryImpl.ErrTagNotFound,
+	ErrUnauthenticated,
+	ErrInvalidID,
+	ErrTooManyTags,
+}
+
+// Request represents a GraphQL request body
+type Request struct {
+	Query         string                 `json:"query" form:"query" binding:"required"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Schema exposes todos, tags and users over GraphQL on top of the usecase layer
+type Schema struct {
+	schema      gql.Schema
+	todoUseCase *usecase.TodoUseCase
+	tagUseCase  *usecase.TagUseCase
+	userUseCase *usecase.UserUseCase
+	limits      Limits
+}
+
+// NewSchema builds the GraphQL schema
+func NewSchema(todoUseCase *usecase.TodoUseCase, tagUseCase *usecase.TagUseCase, userUseCase *usecase.UserUseCase, limits Limits) (*Schema, error) {
+	s := &Schema{
+		todoUseCase: todoUseCase,
+		tagUseCase:  tagUseCase,
+		userUseCase: userUseCase,
+		limits:      limits,
+	}
+
+	schema, err := gql.NewSchema(gql.SchemaConfig{
+		Query:    s.queryType(),
+		Mutation: s.mutationType(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+
+	return s, nil
+}
+
+// Execute runs a request on behalf of a user. Queries exceeding the depth or
+// complexity limits are rejected before any resolver runs.
+func (s *Schema) Execute(ctx context.Context, userID int64, req *Request) *gql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &gql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}
+	}
+
+	if err := s.limits.Check(doc, req.Variables); err != nil {
+		return &gql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())}}
+	}
+
+	ctx = withUserID(ctx, userID)
+	ctx = withLoaders(ctx, newLoaders(s.todoUseCase))
+
+	return gql.Do(gql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+}
+
+// Types
+
+var tagType = gql.NewObject(gql.ObjectConfig{
+	Name: "Tag",
+	Fields: gql.Fields{
+		"id":          &gql.Field{Type: gql.NewNonNull(gql.ID)},
+		"name":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"color":       &gql.Field{Type: gql.String},
+		"icon":        &gql.Field{Type: gql.String},
+		"description": &gql.Field{Type: gql.String},
+		"todoCount":   &gql.Field{Type: gql.Int},
+		"createdAt":   &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+		"updatedAt":   &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+	},
+})
+
+var userType = gql.NewObject(gql.ObjectConfig{
+	Name: "User",
+	Fields: gql.Fields{
+		"id": &gql.Field{
+			Type: gql.NewNonNull(gql.ID),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				if user, ok := p.Source.(*dto.UserResponse); ok {
+					return user.UserID, nil
+				}
+				return nil, nil
+			},
+		},
+		"username": &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"email":    &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"role":     &gql.Field{Type: gql.NewNonNull(gql.String)},
+	},
+})
+
+var tagConnectionType = gql.NewObject(gql.ObjectConfig{
+	Name: "TagConnection",
+	Fields: gql.Fields{
+		"data":       &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(tagType)))},
+		"page":       &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		"limit":      &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		"total":      &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		"totalPages": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+	},
+})
+
+var todoConnectionType = gql.NewObject(gql.ObjectConfig{
+	Name: "TodoConnection",
+	Fields: gql.Fields{
+		"data": &gql.Field{
+			Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(todoType))),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				list, ok := p.Source.(*dto.TodoListResponse)
+				if !ok {
+					return nil, nil
+				}
+				todos := make([]*dto.TodoResponse, len(list.Data))
+				for i := range list.Data {
+					todos[i] = &list.Data[i]
+				}
+				return todos, nil
+			},
+		},
+		"page":       &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		"limit":      &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		"total":      &gql.Field{Type: gql.NewNonNull(gql.Int)},
+		"totalPages": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+	},
+})
+
+var createTodoInputType = gql.NewInputObject(gql.InputObjectConfig{
+	Name: "CreateTodoInput",
+	Fields: gql.InputObjectConfigFieldMap{
+		"title":        &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+		"description":  &gql.InputObjectFieldConfig{Type: gql.String},
+		"dueDate":      &gql.InputObjectFieldConfig{Type: gql.DateTime},
+		"allDay":       &gql.InputObjectFieldConfig{Type: gql.Boolean},
+		"startDate":    &gql.InputObjectFieldConfig{Type: gql.DateTime},
+		"scheduledFor": &gql.InputObjectFieldConfig{Type: gql.DateTime},
+		"priority":     &gql.InputObjectFieldConfig{Type: gql.String},
+		"urgent":       &gql.InputObjectFieldConfig{Type: gql.Boolean},
+		"important":    &gql.InputObjectFieldConfig{Type: gql.Boolean},
+		"tags":         &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+	},
+})
+
+var updateTodoInputType = gql.NewInputObject(gql.InputObjectConfig{
+	Name: "UpdateTodoInput",
+	Fields: gql.InputObjectConfigFieldMap{
+		"title":        &gql.InputObjectFieldConfig{Type: gql.String},
+		"description":  &gql.InputObjectFieldConfig{Type: gql.String},
+		"dueDate":      &gql.InputObjectFieldConfig{Type: gql.DateTime},
+		"allDay":       &gql.InputObjectFieldConfig{Type: gql.Boolean},
+		"startDate":    &gql.InputObjectFieldConfig{Type: gql.DateTime},
+		"scheduledFor": &gql.InputObjectFieldConfig{Type: gql.DateTime},
+		"status":       &gql.InputObjectFieldConfig{Type: gql.String},
+		"priority":     &gql.InputObjectFieldConfig{Type: gql.String},
+		"urgent":       &gql.InputObjectFieldConfig{Type: gql.Boolean},
+		"important":    &gql.InputObjectFieldConfig{Type: gql.Boolean},
+		"tags":         &gql.InputObjectFieldConfig{Type: gql.NewList(gql.NewNonNull(gql.String))},
+	},
+})
+
+// todoType resolves its tags through the request's dataloader so that
+// listing todos costs a single tag query
+var todoType = gql.NewObject(gql.ObjectConfig{
+	Name: "Todo",
+	Fields: gql.Fields{
+		"id":            &gql.Field{Type: gql.NewNonNull(gql.ID)},
+		"userId":        &gql.Field{Type: gql.NewNonNull(gql.ID)},
+		"title":         &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"description":   &gql.Field{Type: gql.String},
+		"dueDate":       &gql.Field{Type: gql.DateTime},
+		"allDay":        &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+		"dueOn":         &gql.Field{Type: gql.String},
+		"startDate":     &gql.Field{Type: gql.DateTime},
+		"scheduledFor":  &gql.Field{Type: gql.DateTime},
+		"status":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"priority":      &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"priorityLabel": &gql.Field{Type: gql.NewNonNull(gql.String)},
+		"urgent":        &gql.Field{Type: gql.Boolean},
+		"important":     &gql.Field{Type: gql.Boolean},
+		"completedAt":   &gql.Field{Type: gql.DateTime},
+		"snoozedUntil":  &gql.Field{Type: gql.DateTime},
+		"createdAt":     &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+		"updatedAt":     &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+		"assigneeIds":   &gql.Field{Type: gql.NewList(gql.NewNonNull(gql.ID))},
+		"tags": &gql.Field{
+			Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(tagType))),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				todo, ok := p.Source.(*dto.TodoResponse)
+				if !ok {
+					return nil, nil
+				}
+				return loadersFrom(p.Context).TodoTags.Load(p.Context, todo.ID), nil
+			},
+		},
+	},
+})
+
+// queryType builds the root query type
+func (s *Schema) queryType() *gql.Object {
+	return gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"me": &gql.Field{
+				Type: gql.NewNonNull(userType),
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					return s.userUseCase.GetProfile(p.Context, userID)
+				}),
+			},
+			"todo": &gql.Field{
+				Type: todoType,
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return s.todoUseCase.GetTodo(p.Context, id, userID)
+				}),
+			},
+			"todos": &gql.Field{
+				Type: gql.NewNonNull(todoConnectionType),
+				Args: gql.FieldConfigArgument{
+					"page":        &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 1},
+					"limit":       &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 20},
+					"status":      &gql.ArgumentConfig{Type: gql.String},
+					"priority":    &gql.ArgumentConfig{Type: gql.String},
+					"search":      &gql.ArgumentConfig{Type: gql.String},
+					"dueDateFrom": &gql.ArgumentConfig{Type: gql.DateTime},
+					"dueDateTo":   &gql.ArgumentConfig{Type: gql.DateTime},
+					"available":   &gql.ArgumentConfig{Type: gql.Boolean},
+					"assignment":  &gql.ArgumentConfig{Type: gql.String},
+					"sortBy":      &gql.ArgumentConfig{Type: gql.String},
+					"sortOrder":   &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					req := &dto.ListTodosRequest{
+						Page:        intArg(p, "page"),
+						Limit:       intArg(p, "limit"),
+						Status:      stringArg(p.Args, "status"),
+						Priority:    stringArg(p.Args, "priority"),
+						Search:      stringArg(p.Args, "search"),
+						DueDateFrom: dto.DueBound(timeArg(p.Args, "dueDateFrom")),
+						DueDateTo:   dto.DueBound(timeArg(p.Args, "dueDateTo")),
+						Available:   boolArg(p.Args, "available"),
+						Assignment:  stringArg(p.Args, "assignment"),
+						SortBy:      stringArg(p.Args, "sortBy"),
+						SortOrder:   stringArg(p.Args, "sortOrder"),
+					}
+					return s.todoUseCase.ListTodos(p.Context, userID, req)
+				}),
+			},
+			"tag": &gql.Field{
+				Type: tagType,
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return s.tagUseCase.GetTag(p.Context, id, userID)
+				}),
+			},
+			"tags": &gql.Field{
+				Type: gql.NewNonNull(tagConnectionType),
+				Args: gql.FieldConfigArgument{
+					"page":  &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 1},
+					"limit": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 20},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					return s.tagUseCase.ListTags(p.Context, userID, intArg(p, "page"), intArg(p, "limit"))
+				}),
+			},
+			"myTags": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(tagType))),
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					return s.tagUseCase.GetTagsByUserID(p.Context, userID)
+				}),
+			},
+		},
+	})
+}
+
+// mutationType builds the root mutation type
+func (s *Schema) mutationType() *gql.Object {
+	return gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createTodo": &gql.Field{
+				Type: gql.NewNonNull(todoType),
+				Args: gql.FieldConfigArgument{
+					"input": &gql.ArgumentConfig{Type: gql.NewNonNull(createTodoInputType)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					input, _ := p.Args["input"].(map[string]interface{})
+					req := &dto.CreateTodoRequest{
+						Title:        stringArg(input, "title"),
+						Description:  stringArg(input, "description"),
+						DueDate:      timeArg(input, "dueDate"),
+						AllDay:       boolArg(input, "allDay"),
+						StartDate:    timeArg(input, "startDate"),
+						ScheduledFor: timeArg(input, "scheduledFor"),
+						Priority:     stringArg(input, "priority"),
+						Tags:         stringListArg(input, "tags"),
+						Urgent:       optionalBoolArg(input, "urgent"),
+						Important:    optionalBoolArg(input, "important"),
+					}
+					if len(req.Tags) > 10 {
+						return nil, ErrTooManyTags
+					}
+					return s.todoUseCase.CreateTodo(p.Context, userID, req)
+				}),
+			},
+			"updateTodo": &gql.Field{
+				Type: gql.NewNonNull(todoType),
+				Args: gql.FieldConfigArgument{
+					"id":    &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"input": &gql.ArgumentConfig{Type: gql.NewNonNull(updateTodoInputType)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					input, _ := p.Args["input"].(map[string]interface{})
+					req := &dto.UpdateTodoRequest{
+						Title:        optionalStringArg(input, "title"),
+						Description:  optionalStringArg(input, "description"),
+						DueDate:      timeArg(input, "dueDate"),
+						AllDay:       optionalBoolArg(input, "allDay"),
+						StartDate:    timeArg(input, "startDate"),
+						ScheduledFor: timeArg(input, "scheduledFor"),
+						Status:       optionalStringArg(input, "status"),
+						Priority:     optionalStringArg(input, "priority"),
+						Tags:         stringListArg(input, "tags"),
+						Urgent:       optionalBoolArg(input, "urgent"),
+						Important:    optionalBoolArg(input, "important"),
+					}
+					if len(req.Tags) > 10 {
+						return nil, ErrTooManyTags
+					}
+					return s.todoUseCase.UpdateTodo(p.Context, id, userID, req)
+				}),
+			},
+			"updateTodoStatus": &gql.Field{
+				Type: gql.NewNonNull(todoType),
+				Args: gql.FieldConfigArgument{
+					"id":     &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"status": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return s.todoUseCase.UpdateTodoStatus(p.Context, id, userID, stringArg(p.Args, "status"))
+				}),
+			},
+			"deleteTodo": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					if err := s.todoUseCase.DeleteTodo(p.Context, id, userID); err != nil {
+						return nil, err
+					}
+					return true, nil
+				}),
+			},
+			"createTag": &gql.Field{
+				Type: gql.NewNonNull(tagType),
+				Args: gql.FieldConfigArgument{
+					"name": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					return s.tagUseCase.CreateTag(p.Context, userID, &dto.CreateTagRequest{Name: stringArg(p.Args, "name")})
+				}),
+			},
+			"updateTag": &gql.Field{
+				Type: gql.NewNonNull(tagType),
+				Args: gql.FieldConfigArgument{
+					"id":   &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"name": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return s.tagUseCase.UpdateTag(p.Context, id, userID, &dto.UpdateTagRequest{Name: stringArg(p.Args, "name")})
+				}),
+			},
+			"deleteTag": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				},
+				Resolve: s.authenticated(func(p gql.ResolveParams, userID int64) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					if err := s.tagUseCase.DeleteTag(p.Context, id, userID); err != nil {
+						return nil, err
+					}
+					return true, nil
+				}),
+			},
+		},
+	})
+}
+
+// authenticated wraps a resolver with the request's user ID and maps errors
+// that are not meant for clients to a generic message
+func (s *Schema) authenticated(resolve func(p gql.ResolveParams, userID int64) (interface{}, error)) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		userID, ok := userIDFrom(p.Context)
+		if !ok {
+			return nil, ErrUnauthenticated
+		}
+
+		result, err := resolve(p, userID)
+		if err != nil {
+			return nil, publicError(err)
+		}
+		return result, nil
+	}
+}
+
+// publicError returns err if its message is safe for clients, otherwise a
+// generic internal error
+func publicError(err error) error {
+	for _, public := range publicErrors {
+		if errors.Is(err, public) {
+			return err
+		}
+	}
+	if err.Error() == "tag with this name already exists" {
+		return err
+	}
+
+	log.Printf("Warning: graphql resolver failed: %v", err)
+	return ErrInternal
+}
+
+// Argument helpers
+
+func idArg(p gql.ResolveParams, name string) (int64, error) {
+	raw := fmt.Sprint(p.Args[name])
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidID
+	}
+	return id, nil
+}
+
+func intArg(p gql.ResolveParams, name string) int {
+	value, _ := p.Args[name].(int)
+	return value
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func boolArg(args map[string]interface{}, name string) bool {
+	value, _ := args[name].(bool)
+	return value
+}
+
+func optionalStringArg(args map[string]interface{}, name string) *string {
+	value, ok := args[name].(string)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func optionalBoolArg(args map[string]interface{}, name string) *bool {
+	value, ok := args[name].(bool)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func timeArg(args map[string]interface{}, name string) *time.Time {
+	value, ok := args[name].(time.Time)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func stringListArg(args map[string]interface{}, name string) []string {
+	values, ok := args[name].([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
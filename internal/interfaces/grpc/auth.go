@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/pkg/utils"
+)
+
+type contextKey string
+
+const claimsKey contextKey = "grpc.claims"
+
+// publicMethodPrefixes are services that do not require authentication
+var publicMethodPrefixes = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// UnaryAuthInterceptor validates the bearer access token of unary calls
+func UnaryAuthInterceptor(jwtManager *utils.JWTManager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, jwtManager)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor validates the bearer access token of streaming calls
+func StreamAuthInterceptor(jwtManager *utils.JWTManager) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := authenticate(stream.Context(), jwtManager)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate reads the "authorization: Bearer <token>" metadata and stores
+// the token claims in the context, bound to the token's tenant
+func authenticate(ctx context.Context, jwtManager *utils.JWTManager) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is missing")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is missing")
+	}
+
+	if !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata format is invalid")
+	}
+
+	claims, err := jwtManager.ValidateAccessToken(strings.TrimPrefix(values[0], "Bearer "))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	ctx = tenant.WithID(ctx, claims.TenantID)
+	return context.WithValue(ctx, claimsKey, claims), nil
+}
+
+// userIDFromContext returns the authenticated user ID
+func userIDFromContext(ctx context.Context) (int64, error) {
+	claims, ok := ctx.Value(claimsKey).(*utils.Claims)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	return claims.UserID, nil
+}
+
+// isPublicMethod reports whether a method skips authentication
+func isPublicMethod(fullMethod string) bool {
+	for _, prefix := range publicMethodPrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticatedStream overrides the context of a server stream
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the authenticated context
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
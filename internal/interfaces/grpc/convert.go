@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	todov1 "github.com/darron08/todolist-demo/api/proto/todo/v1"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+// toStatusError maps usecase errors to gRPC status errors
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrTodoTitleRequired),
+		errors.Is(err, usecase.ErrTodoTitleTooLong),
+		errors.Is(err, usecase.ErrTodoDescriptionTooLong),
+		errors.Is(err, usecase.ErrInvalidStatus),
+		errors.Is(err, usecase.ErrInvalidPriority),
+		errors.Is(err, usecase.ErrStartAfterDue),
+		errors.Is(err, usecase.ErrScheduledAfterDue),
+		errors.Is(err, usecase.ErrScheduledBeforeStart),
+		errors.Is(err, usecase.ErrInvalidAssignee),
+		errors.Is(err, usecase.ErrTagNameRequired),
+		errors.Is(err, usecase.ErrTagNameTooLong),
+		errors.Is(err, usecase.ErrInvalidTagPath),
+		errors.Is(err, usecase.ErrTagPathTooDeep):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrTodoNotFound),
+		errors.Is(err, usecase.ErrTagNotFound),
+		errors.Is(err, usecase.ErrUserNotFound),
+		errors.Is(err, repositoryImpl.ErrTodoNotFound),
+		errors.Is(err, repositoryImpl.ErrTagNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrTransitionNotAllowed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrUnauthorized):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	log.Printf("Warning: gRPC call failed: %v", err)
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// toTimestamp converts an optional time to a protobuf timestamp
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// fromTimestamp converts an optional protobuf timestamp to a time
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// toProtoUser converts dto.UserResponse to todov1.User
+func toProtoUser(user *dto.UserResponse) *todov1.User {
+	return &todov1.User{
+		Id:       user.UserID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+	}
+}
+
+// toProtoTag converts dto.TagResponse to todov1.Tag
+func toProtoTag(tag *dto.TagResponse) *todov1.Tag {
+	return &todov1.Tag{
+		Id:        tag.ID,
+		Name:      tag.Name,
+		TodoCount: tag.TodoCount,
+		CreatedAt: timestamppb.New(tag.CreatedAt),
+		UpdatedAt: timestamppb.New(tag.UpdatedAt),
+	}
+}
+
+// toProtoTodo converts dto.TodoResponse to todov1.Todo
+func toProtoTodo(todo *dto.TodoResponse) *todov1.Todo {
+	tags := make([]*todov1.Tag, len(todo.Tags))
+	for i, tag := range todo.Tags {
+		tags[i] = &todov1.Tag{Id: tag.ID, Name: tag.Name}
+	}
+
+	return &todov1.Todo{
+		Id:          todo.ID,
+		UserId:      todo.UserID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		DueDate:     toTimestamp(todo.DueDate),
+		Status:      todo.Status,
+		Priority:    todo.Priority,
+		Tags:        tags,
+		CreatedAt:   timestamppb.New(todo.CreatedAt),
+		UpdatedAt:   timestamppb.New(todo.UpdatedAt),
+	}
+}
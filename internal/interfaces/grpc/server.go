@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	todov1 "github.com/darron08/todolist-demo/api/proto/todo/v1"
+	"github.com/darron08/todolist-demo/internal/infrastructure/realtime"
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/utils"
+)
+
+// NewServer creates a gRPC server exposing the todo and tag services, the
+// standard health service and server reflection. All todo and tag calls
+// require a bearer access token in the "authorization" metadata.
+func NewServer(
+	jwtManager *utils.JWTManager,
+	todoUseCase *usecase.TodoUseCase,
+	tagUseCase *usecase.TagUseCase,
+	userUseCase *usecase.UserUseCase,
+	hub *realtime.Hub,
+) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryAuthInterceptor(jwtManager)),
+		grpc.StreamInterceptor(StreamAuthInterceptor(jwtManager)),
+	)
+
+	todov1.RegisterTodoServiceServer(server, NewTodoService(todoUseCase, userUseCase, hub))
+	todov1.RegisterTagServiceServer(server, NewTagService(tagUseCase))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(todov1.TodoService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(todov1.TagService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server
+}
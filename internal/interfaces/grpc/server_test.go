@@ -0,0 +1,172 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	todov1 "github.com/darron08/todolist-demo/api/proto/todo/v1"
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/utils"
+)
+
+// fakeUserRepository serves a fixed set of users from memory, recording the
+// tenant each lookup ran in
+type fakeUserRepository struct {
+	users   map[int64]*entity.User
+	tenants []int64
+}
+
+func (r *fakeUserRepository) Create(ctx context.Context, user *entity.User) error { return nil }
+func (r *fakeUserRepository) FindByID(ctx context.Context, id int64) (*entity.User, error) {
+	tenantID, _ := tenant.FromContext(ctx)
+	r.tenants = append(r.tenants, tenantID)
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, errors.New("user not found")
+}
+func (r *fakeUserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return nil, errors.New("user not found")
+}
+func (r *fakeUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return nil, errors.New("user not found")
+}
+func (r *fakeUserRepository) Update(ctx context.Context, user *entity.User) error { return nil }
+func (r *fakeUserRepository) Delete(ctx context.Context, id int64) error          { return nil }
+func (r *fakeUserRepository) List(ctx context.Context, offset, limit int) ([]*entity.User, error) {
+	return nil, nil
+}
+func (r *fakeUserRepository) FindDigestSubscribers(ctx context.Context, afterID int64, limit int) ([]*entity.User, error) {
+	return nil, nil
+}
+func (r *fakeUserRepository) MarkDigestSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	return nil
+}
+
+// newTestClient starts the server over bufconn and returns a connected client
+func newTestClient(t *testing.T) (*grpc.ClientConn, *utils.JWTManager) {
+	conn, jwtManager, _ := newTestServer(t)
+	return conn, jwtManager
+}
+
+// newTestServer starts the server over bufconn and returns a connected
+// client and the repository serving users
+func newTestServer(t *testing.T) (*grpc.ClientConn, *utils.JWTManager, *fakeUserRepository) {
+	t.Helper()
+
+	jwtManager := utils.NewJWTManager("test-secret", "test", time.Minute, time.Hour)
+	userRepo := &fakeUserRepository{users: map[int64]*entity.User{
+		1: {ID: 1, Username: "alice", Email: "alice@example.com", Role: entity.UserRoleUser},
+	}}
+	userUseCase := usecase.NewUserUseCase(userRepo, nil, nil, nil, jwtManager, nil, nil, nil, nil, "", 0)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(jwtManager, nil, nil, userUseCase, nil)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, jwtManager, userRepo
+}
+
+// withToken attaches a bearer access token for the given user, acting in the
+// default organization
+func withToken(t *testing.T, jwtManager *utils.JWTManager, userID int64) context.Context {
+	t.Helper()
+	return withTenantToken(t, jwtManager, userID, 1)
+}
+
+// withTenantToken attaches a bearer access token for the given user acting in
+// an organization
+func withTenantToken(t *testing.T, jwtManager *utils.JWTManager, userID, tenantID int64) context.Context {
+	t.Helper()
+
+	token, err := jwtManager.GenerateAccessToken(userID, "alice", "user", tenantID)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestHealthCheckDoesNotRequireAuth(t *testing.T) {
+	conn, _ := newTestClient(t)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: todov1.TodoService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestUnaryAuth(t *testing.T) {
+	conn, jwtManager := newTestClient(t)
+	client := todov1.NewTodoServiceClient(conn)
+
+	_, err := client.GetProfile(context.Background(), &todov1.GetProfileRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	badCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not-a-token")
+	_, err = client.GetProfile(badCtx, &todov1.GetProfileRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	user, err := client.GetProfile(withToken(t, jwtManager, 1), &todov1.GetProfileRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), user.Id)
+	assert.Equal(t, "alice", user.Username)
+
+	_, err = client.GetProfile(withToken(t, jwtManager, 2), &todov1.GetProfileRequest{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestUnaryAuthBindsTenant(t *testing.T) {
+	conn, jwtManager, userRepo := newTestServer(t)
+	client := todov1.NewTodoServiceClient(conn)
+
+	_, err := client.GetProfile(withTenantToken(t, jwtManager, 1, 7), &todov1.GetProfileRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7}, userRepo.tenants)
+
+	// Tokens that do not name a tenant are rejected
+	_, err = client.GetProfile(withTenantToken(t, jwtManager, 1, 0), &todov1.GetProfileRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Len(t, userRepo.tenants, 1)
+}
+
+func TestStreamAuth(t *testing.T) {
+	conn, jwtManager := newTestClient(t)
+	client := todov1.NewTodoServiceClient(conn)
+
+	stream, err := client.WatchTodos(context.Background(), &todov1.WatchTodosRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Authenticated, but no realtime hub is configured
+	stream, err = client.WatchTodos(withToken(t, jwtManager, 1), &todov1.WatchTodosRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+
+	todov1 "github.com/darron08/todolist-demo/api/proto/todo/v1"
+	"github.com/darron08/todolist-demo/internal/usecase"
+)
+
+// TagService implements todov1.TagServiceServer on top of the usecase layer
+type TagService struct {
+	todov1.UnimplementedTagServiceServer
+
+	tagUseCase *usecase.TagUseCase
+}
+
+// NewTagService creates a new tag gRPC service
+func NewTagService(tagUseCase *usecase.TagUseCase) *TagService {
+	return &TagService{
+		tagUseCase: tagUseCase,
+	}
+}
+
+// GetTag retrieves a tag owned by the authenticated user
+func (s *TagService) GetTag(ctx context.Context, req *todov1.GetTagRequest) (*todov1.Tag, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tagUseCase.GetTag(ctx, req.GetId(), userID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toProtoTag(tag), nil
+}
+
+// ListTags lists the authenticated user's tags with pagination
+func (s *TagService) ListTags(ctx context.Context, req *todov1.ListTagsRequest) (*todov1.ListTagsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.tagUseCase.ListTags(ctx, userID, int(req.GetPage()), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	tags := make([]*todov1.Tag, len(list.Data))
+	for i := range list.Data {
+		tags[i] = toProtoTag(&list.Data[i])
+	}
+
+	return &todov1.ListTagsResponse{
+		Tags:       tags,
+		Page:       int32(list.Page),
+		Limit:      int32(list.Limit),
+		Total:      list.Total,
+		TotalPages: int32(list.TotalPages),
+	}, nil
+}
+
+// ListMyTags lists the tags owned by the authenticated user with todo counts
+func (s *TagService) ListMyTags(ctx context.Context, req *todov1.ListMyTagsRequest) (*todov1.ListMyTagsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userTags, err := s.tagUseCase.GetTagsByUserID(ctx, userID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	tags := make([]*todov1.Tag, len(userTags))
+	for i, tag := range userTags {
+		tags[i] = toProtoTag(tag)
+	}
+	return &todov1.ListMyTagsResponse{Tags: tags}, nil
+}
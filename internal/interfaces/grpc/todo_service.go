@@ -0,0 +1,257 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	todov1 "github.com/darron08/todolist-demo/api/proto/todo/v1"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/realtime"
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+// TodoService implements todov1.TodoServiceServer on top of the usecase layer
+type TodoService struct {
+	todov1.UnimplementedTodoServiceServer
+
+	todoUseCase *usecase.TodoUseCase
+	userUseCase *usecase.UserUseCase
+	hub         *realtime.Hub
+}
+
+// NewTodoService creates a new todo gRPC service. WatchTodos is unavailable
+// when hub is nil.
+func NewTodoService(todoUseCase *usecase.TodoUseCase, userUseCase *usecase.UserUseCase, hub *realtime.Hub) *TodoService {
+	return &TodoService{
+		todoUseCase: todoUseCase,
+		userUseCase: userUseCase,
+		hub:         hub,
+	}
+}
+
+// GetProfile returns the authenticated user
+func (s *TodoService) GetProfile(ctx context.Context, req *todov1.GetProfileRequest) (*todov1.User, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userUseCase.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toProtoUser(user), nil
+}
+
+// CreateTodo creates a todo
+func (s *TodoService) CreateTodo(ctx context.Context, req *todov1.CreateTodoRequest) (*todov1.Todo, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.GetTags()) > 10 {
+		return nil, status.Error(codes.InvalidArgument, "a todo can have at most 10 tags")
+	}
+
+	todo, err := s.todoUseCase.CreateTodo(ctx, userID, &dto.CreateTodoRequest{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		DueDate:     fromTimestamp(req.GetDueDate()),
+		Priority:    req.GetPriority(),
+		Tags:        req.GetTags(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+// GetTodo retrieves a todo
+func (s *TodoService) GetTodo(ctx context.Context, req *todov1.GetTodoRequest) (*todov1.Todo, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoUseCase.GetTodo(ctx, req.GetId(), userID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+// ListTodos lists todos with pagination and filters
+func (s *TodoService) ListTodos(ctx context.Context, req *todov1.ListTodosRequest) (*todov1.ListTodosResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.todoUseCase.ListTodos(ctx, userID, &dto.ListTodosRequest{
+		Page:        int(req.GetPage()),
+		Limit:       int(req.GetLimit()),
+		Status:      req.GetStatus(),
+		Priority:    req.GetPriority(),
+		Search:      req.GetSearch(),
+		DueDateFrom: dto.DueBound(fromTimestamp(req.GetDueDateFrom())),
+		DueDateTo:   dto.DueBound(fromTimestamp(req.GetDueDateTo())),
+		SortBy:      req.GetSortBy(),
+		SortOrder:   req.GetSortOrder(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	todos := make([]*todov1.Todo, len(list.Data))
+	for i := range list.Data {
+		todos[i] = toProtoTodo(&list.Data[i])
+	}
+
+	return &todov1.ListTodosResponse{
+		Todos:      todos,
+		Page:       int32(list.Page),
+		Limit:      int32(list.Limit),
+		Total:      list.Total,
+		TotalPages: int32(list.TotalPages),
+	}, nil
+}
+
+// UpdateTodo updates a todo
+func (s *TodoService) UpdateTodo(ctx context.Context, req *todov1.UpdateTodoRequest) (*todov1.Todo, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.GetTags()) > 10 {
+		return nil, status.Error(codes.InvalidArgument, "a todo can have at most 10 tags")
+	}
+
+	todo, err := s.todoUseCase.UpdateTodo(ctx, req.GetId(), userID, &dto.UpdateTodoRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     fromTimestamp(req.GetDueDate()),
+		Status:      req.Status,
+		Priority:    req.Priority,
+		Tags:        req.GetTags(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+// UpdateTodoStatus updates the status of a todo
+func (s *TodoService) UpdateTodoStatus(ctx context.Context, req *todov1.UpdateTodoStatusRequest) (*todov1.Todo, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoUseCase.UpdateTodoStatus(ctx, req.GetId(), userID, req.GetStatus())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toProtoTodo(todo), nil
+}
+
+// DeleteTodo deletes a todo
+func (s *TodoService) DeleteTodo(ctx context.Context, req *todov1.DeleteTodoRequest) (*todov1.DeleteTodoResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.todoUseCase.DeleteTodo(ctx, req.GetId(), userID); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &todov1.DeleteTodoResponse{}, nil
+}
+
+// WatchTodos streams changes to the caller's todos. Events missed since
+// last_event_id are sent first.
+func (s *TodoService) WatchTodos(req *todov1.WatchTodosRequest, stream grpc.ServerStreamingServer[todov1.TodoEvent]) error {
+	ctx := stream.Context()
+
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.hub == nil {
+		return status.Error(codes.Unavailable, "realtime events are disabled")
+	}
+
+	// Only events of the token's tenant are streamed
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	lastEventID := req.GetLastEventId()
+	if lastEventID != "" && !realtime.ValidStreamID(lastEventID) {
+		return status.Error(codes.InvalidArgument, "invalid last event id")
+	}
+
+	// Subscribe before replaying so no event falls between the two
+	sub := s.hub.Subscribe(tenantID, userID)
+	defer sub.Close()
+
+	backlog, err := s.hub.Replay(ctx, tenantID, userID, lastEventID)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	replayedID := ""
+	for _, message := range backlog {
+		if err := sendTodoEvent(stream, message); err != nil {
+			return err
+		}
+		replayedID = message.ID
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-sub.Messages():
+			if !ok {
+				return status.Error(codes.Aborted, "subscriber fell behind, resume with last_event_id")
+			}
+			if replayedID != "" && realtime.CompareStreamIDs(message.ID, replayedID) <= 0 {
+				continue
+			}
+			if err := sendTodoEvent(stream, message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendTodoEvent sends a realtime message if it is a todo event
+func sendTodoEvent(stream grpc.ServerStreamingServer[todov1.TodoEvent], message *realtime.Message) error {
+	if !message.Event.Type.Matches("todo.*") {
+		return nil
+	}
+
+	var todo dto.TodoResponse
+	if err := json.Unmarshal(message.Event.Data, &todo); err != nil {
+		log.Printf("Warning: skipping undecodable todo event %s: %v", message.Event.ID, err)
+		return nil
+	}
+
+	return stream.Send(&todov1.TodoEvent{
+		Id:         message.ID,
+		Type:       string(message.Event.Type),
+		Todo:       toProtoTodo(&todo),
+		OccurredAt: timestamppb.New(message.Event.OccurredAt),
+	})
+}
@@ -203,8 +203,8 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 // @Security Bearer
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
-// @Param status query string false "Filter by status" Enums(not_started, in_progress, completed)
-// @Param priority query string false "Filter by priority" Enums(low, medium, high)
+// @Param status query string false "Filter by status"
+// @Param priority query string false "Filter by priority (p0-p4)"
 // @Success 200 {object} response.PaginatedResponse "Todos retrieved successfully"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 403 {object} response.ErrorResponse "Forbidden - admin role required"
@@ -242,6 +242,10 @@ func (h *AdminHandler) ListAllTodos(c *gin.Context) {
 
 	todos, err := h.adminUseCase.ListAllTodos(c.Request.Context(), pageInt, limitInt, statusFilter, priorityFilter)
 	if err != nil {
+		if err == usecase.ErrInvalidPriority {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		response.InternalServerError(c, "failed to list todos")
 		return
 	}
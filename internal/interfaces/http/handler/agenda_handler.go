@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// AgendaHandler handles HTTP requests for the day-by-day agenda
+type AgendaHandler struct {
+	agendaUseCase *usecase.AgendaUseCase
+}
+
+// NewAgendaHandler creates a new agenda handler
+func NewAgendaHandler(agendaUseCase *usecase.AgendaUseCase) *AgendaHandler {
+	return &AgendaHandler{
+		agendaUseCase: agendaUseCase,
+	}
+}
+
+// GetAgenda handles GET /api/v1/agenda
+// @Summary Get agenda
+// @Description Open todos due or scheduled today, or over the coming week, grouped by day in the user's timezone. Todos past their due date are carried forward to today. Todos do not recur, so no projected occurrences are listed.
+// @Tags Agenda
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param range query string false "Days to list" Enums(today, week) default(today)
+// @Success 200 {object} dto.AgendaResponse "Agenda retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /agenda [get]
+func (h *AgendaHandler) GetAgenda(c *gin.Context) {
+	var req dto.AgendaRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	agenda, err := h.agendaUseCase.GetAgenda(c.Request.Context(), userID, req.Range)
+	if err != nil {
+		response.InternalServerError(c, "failed to get agenda")
+		return
+	}
+
+	response.Success(c, agenda)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// DigestHandler handles HTTP requests for the digest email
+type DigestHandler struct {
+	digestUseCase *usecase.DigestUseCase
+}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler(digestUseCase *usecase.DigestUseCase) *DigestHandler {
+	return &DigestHandler{
+		digestUseCase: digestUseCase,
+	}
+}
+
+// Unsubscribe handles GET and POST /api/v1/digest/unsubscribe
+// @Summary Unsubscribe from the digest email
+// @Description One-click unsubscribe link sent in every digest email. Mail clients supporting RFC 8058 POST to it; no authentication is needed, the signed token identifies the user.
+// @Tags Digest
+// @Produce json
+// @Param token query string true "Signed unsubscribe token"
+// @Success 200 {object} response.SuccessResponse "Unsubscribed from the digest"
+// @Failure 400 {object} response.ErrorResponse "Invalid unsubscribe token"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /digest/unsubscribe [get]
+// @Router /digest/unsubscribe [post]
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.BadRequest(c, "token is required")
+		return
+	}
+
+	err := h.digestUseCase.Unsubscribe(c.Request.Context(), token)
+	if err != nil {
+		if err == usecase.ErrInvalidUnsubscribeToken {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if err == usecase.ErrUserNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to unsubscribe from the digest")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "unsubscribed from the digest email"})
+}
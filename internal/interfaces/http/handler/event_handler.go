@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/realtime"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// EventHandler streams todo and tag change events to connected clients
+type EventHandler struct {
+	hub               *realtime.Hub
+	heartbeatInterval time.Duration
+	upgrader          websocket.Upgrader
+}
+
+// NewEventHandler creates a new event handler
+func NewEventHandler(hub *realtime.Hub, heartbeatInterval time.Duration, allowedOrigins []string) *EventHandler {
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = 25 * time.Second
+	}
+
+	return &EventHandler{
+		hub:               hub,
+		heartbeatInterval: heartbeatInterval,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				for _, allowed := range allowedOrigins {
+					if allowed == "*" || allowed == origin {
+						return true
+					}
+				}
+				return false
+			},
+		},
+	}
+}
+
+// Stream handles GET /api/v1/events
+// @Summary Stream change events
+// @Description Stream todo and tag change events for the authenticated user as Server-Sent Events. Each event carries an id; reconnect with the Last-Event-ID header (or last_event_id query parameter) to resume. The access token may be passed as the access_token query parameter for EventSource clients.
+// @Tags Events
+// @Produce text/event-stream
+// @Security Bearer
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "ID of the last event received"
+// @Param access_token query string false "Access token for clients that cannot set headers"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} response.ErrorResponse "Invalid event ID"
+// @Failure 401 {object} response.ErrorResponse "User not authenticated"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /events [get]
+func (h *EventHandler) Stream(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	sub, backlog, ok := h.open(c, userID)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Tell EventSource how long to wait before reconnecting
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", 3000)
+	c.Writer.Flush()
+
+	send := func(message *realtime.Message) error {
+		data, err := json.Marshal(message.Event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", message.ID, message.Event.Type, data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	heartbeat := func() error {
+		if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	h.pump(c.Request.Context(), sub, backlog, send, heartbeat)
+}
+
+// WebSocket handles GET /api/v1/events/ws
+// @Summary Stream change events over WebSocket
+// @Description Upgrade to a WebSocket that receives todo and tag change events for the authenticated user as JSON messages of the form {"id": "...", "event": {...}}. Pass last_event_id to resume after a reconnect. The access token may be passed as the access_token query parameter.
+// @Tags Events
+// @Security Bearer
+// @Param last_event_id query string false "ID of the last event received"
+// @Param access_token query string false "Access token for clients that cannot set headers"
+// @Success 101 {string} string "Switching protocols"
+// @Failure 400 {object} response.ErrorResponse "Invalid event ID"
+// @Failure 401 {object} response.ErrorResponse "User not authenticated"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /events/ws [get]
+func (h *EventHandler) WebSocket(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	sub, backlog, ok := h.open(c, userID)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// Clients only send control frames; the read loop detects disconnects
+	readTimeout := 2 * h.heartbeatInterval
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	writeTimeout := 10 * time.Second
+
+	send := func(message *realtime.Message) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		return conn.WriteJSON(message)
+	}
+
+	heartbeat := func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+	}
+
+	h.pump(ctx, sub, backlog, send, heartbeat)
+
+	_ = conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+		time.Now().Add(writeTimeout),
+	)
+}
+
+// userID extracts the authenticated user ID, writing an error response on failure
+func (h *EventHandler) userID(c *gin.Context) (int64, bool) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return 0, false
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// open subscribes to live events and loads the events missed since the
+// client's last event ID. The subscription is taken before the replay so
+// no event can fall between the two. Only events of the token's tenant are
+// streamed.
+func (h *EventHandler) open(c *gin.Context, userID int64) (*realtime.Subscription, []*realtime.Message, bool) {
+	tenantID, ok := tenant.FromContext(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "user not authenticated")
+		return nil, nil, false
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	if lastEventID != "" && !realtime.ValidStreamID(lastEventID) {
+		response.BadRequest(c, "invalid last event id")
+		return nil, nil, false
+	}
+
+	sub := h.hub.Subscribe(tenantID, userID)
+
+	backlog, err := h.hub.Replay(c.Request.Context(), tenantID, userID, lastEventID)
+	if err != nil {
+		sub.Close()
+		response.InternalServerError(c, "failed to load missed events")
+		return nil, nil, false
+	}
+
+	return sub, backlog, true
+}
+
+// pump writes the replayed backlog followed by live messages until the
+// client disconnects. Live messages at or before the end of the backlog were
+// already sent during replay and are skipped.
+func (h *EventHandler) pump(ctx context.Context, sub *realtime.Subscription, backlog []*realtime.Message, send func(*realtime.Message) error, heartbeat func() error) {
+	replayedID := ""
+	for _, message := range backlog {
+		if err := send(message); err != nil {
+			return
+		}
+		replayedID = message.ID
+	}
+
+	ticker := time.NewTicker(h.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-sub.Messages():
+			if !ok {
+				return
+			}
+			if replayedID != "" && realtime.CompareStreamIDs(message.ID, replayedID) <= 0 {
+				continue
+			}
+			if err := send(message); err != nil {
+				log.Printf("Warning: failed to send event to user %d: %v", sub.UserID, err)
+				return
+			}
+		case <-ticker.C:
+			if err := heartbeat(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/interfaces/graphql"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// GraphQLHandler handles GraphQL requests
+type GraphQLHandler struct {
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(schema *graphql.Schema) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema: schema,
+	}
+}
+
+// Query handles POST /graphql
+// @Summary Execute a GraphQL query
+// @Description Execute a GraphQL query or mutation against todos, tags and the current user. Queries exceeding the configured depth or complexity limits are rejected.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body graphql.Request true "GraphQL request"
+// @Success 200 {object} map[string]interface{} "GraphQL result with data and errors"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format"
+// @Failure 401 {object} response.ErrorResponse "User not authenticated"
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if err := c.ShouldBind(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	// GraphQL reports errors in the result body, per the GraphQL over HTTP convention
+	result := h.schema.Execute(c.Request.Context(), userID, &req)
+	c.JSON(http.StatusOK, result)
+}
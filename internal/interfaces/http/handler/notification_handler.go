@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// NotificationHandler handles HTTP requests for the notification inbox
+type NotificationHandler struct {
+	notificationUseCase *usecase.NotificationUseCase
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationUseCase *usecase.NotificationUseCase) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+// ListNotifications handles GET /api/v1/notifications
+// @Summary List notifications
+// @Description Retrieve the authenticated user's inbox in the current organization, newest first, with the number of unread notifications
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Param unread query bool false "Only list unread notifications"
+// @Success 200 {object} dto.NotificationListResponse "Notifications retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	var req dto.ListNotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	notifications, err := h.notificationUseCase.ListNotifications(c.Request.Context(), userID, &req)
+	if err != nil {
+		response.InternalServerError(c, "failed to list notifications")
+		return
+	}
+
+	response.Success(c, notifications)
+}
+
+// MarkRead handles POST /api/v1/notifications/:id/read
+// @Summary Mark a notification as read
+// @Description Mark one of the authenticated user's notifications as read, returning the number still unread
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Notification ID"
+// @Success 200 {object} dto.MarkReadResponse "Notification marked as read"
+// @Failure 400 {object} response.ErrorResponse "Invalid notification ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Notification not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid notification id")
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	result, usecaseErr := h.notificationUseCase.MarkRead(c.Request.Context(), id, userID)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrNotificationNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to mark notification as read")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// MarkAllRead handles POST /api/v1/notifications/read-all
+// @Summary Mark all notifications as read
+// @Description Mark all of the authenticated user's notifications in the current organization as read
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} dto.MarkReadResponse "Notifications marked as read"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	result, err := h.notificationUseCase.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "failed to mark notifications as read")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetPreferences handles GET /api/v1/notifications/preferences
+// @Summary Get notification preferences
+// @Description List, for each event type users are notified of, whether it lands in the inbox and whether it is also emailed
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} []dto.NotificationPreferenceResponse "Preferences retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	preferences, err := h.notificationUseCase.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "failed to get notification preferences")
+		return
+	}
+
+	response.Success(c, preferences)
+}
+
+// UpdatePreferences handles PUT /api/v1/notifications/preferences
+// @Summary Update notification preferences
+// @Description Set whether events of the given types land in the inbox and whether they are also emailed. Event types left out keep their preference.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.UpdateNotificationPreferencesRequest true "Preferences by event type"
+// @Success 200 {object} []dto.NotificationPreferenceResponse "Preferences updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or event type"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req dto.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	preferences, usecaseErr := h.notificationUseCase.UpdatePreferences(c.Request.Context(), userID, &req)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrInvalidNotificationEvent {
+			response.BadRequest(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to update notification preferences")
+		return
+	}
+
+	response.Success(c, preferences)
+}
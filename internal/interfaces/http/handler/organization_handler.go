@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// OrganizationHandler handles HTTP requests for organizations and their members
+type OrganizationHandler struct {
+	organizationUseCase *usecase.OrganizationUseCase
+	userUseCase         *usecase.UserUseCase
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationUseCase *usecase.OrganizationUseCase, userUseCase *usecase.UserUseCase) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationUseCase: organizationUseCase,
+		userUseCase:         userUseCase,
+	}
+}
+
+// ListOrganizations handles GET /api/v1/organizations
+// @Summary List my organizations
+// @Description List the organizations the authenticated user belongs to, with their role in each. The organization the token acts in is flagged as current.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} dto.OrganizationResponse "Organizations retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	orgs, err := h.organizationUseCase.ListOrganizations(c.Request.Context(), userID, c.GetInt64("TenantID"))
+	if err != nil {
+		response.InternalServerError(c, "failed to list organizations")
+		return
+	}
+
+	response.Success(c, orgs)
+}
+
+// CreateOrganization handles POST /api/v1/organizations
+// @Summary Create an organization
+// @Description Create an organization owned by the authenticated user. Switch to it to work on its todos and tags.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.CreateOrganizationRequest true "Organization details"
+// @Success 201 {object} dto.OrganizationResponse "Organization created successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req dto.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.organizationUseCase.CreateOrganization(c.Request.Context(), userID, &req)
+	if err != nil {
+		if err == usecase.ErrOrganizationNameRequired {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to create organization")
+		return
+	}
+
+	response.Created(c, org)
+}
+
+// SwitchOrganization handles POST /api/v1/organizations/:id/switch
+// @Summary Switch organization
+// @Description Get a new token pair acting in another organization the authenticated user belongs to
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Organization ID"
+// @Success 200 {object} dto.LoginResponse "Tokens issued successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid organization ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Not a member of the organization"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/switch [post]
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.userUseCase.SwitchOrganization(c.Request.Context(), userID, orgID)
+	if err != nil {
+		h.writeError(c, err, "failed to switch organization")
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// ListMembers handles GET /api/v1/organizations/:id/members
+// @Summary List organization members
+// @Description List the members of an organization the authenticated user belongs to
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Organization ID"
+// @Success 200 {array} dto.MemberResponse "Members retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid organization ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Not a member of the organization"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationUseCase.ListMembers(c.Request.Context(), orgID, userID)
+	if err != nil {
+		h.writeError(c, err, "failed to list members")
+		return
+	}
+
+	response.Success(c, members)
+}
+
+// AddMember handles POST /api/v1/organizations/:id/members
+// @Summary Add an organization member
+// @Description Add an existing user, by username, to an organization. Requires the owner or admin role; only owners add owners.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Organization ID"
+// @Param request body dto.AddMemberRequest true "Member details"
+// @Success 201 {object} dto.MemberResponse "Member added successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "User is already a member"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/members [post]
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.organizationUseCase.AddMember(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		h.writeError(c, err, "failed to add member")
+		return
+	}
+
+	response.Created(c, member)
+}
+
+// UpdateMember handles PUT /api/v1/organizations/:id/members/:user_id
+// @Summary Change a member's role
+// @Description Change the role of an organization member. Requires the owner or admin role; only owners manage owners, and the last owner cannot be demoted.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Organization ID"
+// @Param user_id path int true "Member user ID"
+// @Param request body dto.UpdateMemberRequest true "New role"
+// @Success 200 {object} dto.MemberResponse "Member updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} response.ErrorResponse "Member not found"
+// @Failure 409 {object} response.ErrorResponse "Last owner"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/members/{user_id} [put]
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user id")
+		return
+	}
+
+	var req dto.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.organizationUseCase.UpdateMember(c.Request.Context(), orgID, userID, memberID, &req)
+	if err != nil {
+		h.writeError(c, err, "failed to update member")
+		return
+	}
+
+	response.Success(c, member)
+}
+
+// RemoveMember handles DELETE /api/v1/organizations/:id/members/:user_id
+// @Summary Remove a member
+// @Description Remove a member from an organization. Requires the owner or admin role, except to remove yourself; the last owner cannot be removed.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Organization ID"
+// @Param user_id path int true "Member user ID"
+// @Success 200 {object} map[string]string "Member removed successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} response.ErrorResponse "Member not found"
+// @Failure 409 {object} response.ErrorResponse "Last owner"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /organizations/{id}/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user id")
+		return
+	}
+
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationUseCase.RemoveMember(c.Request.Context(), orgID, userID, memberID); err != nil {
+		h.writeError(c, err, "failed to remove member")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "member removed successfully"})
+}
+
+// writeError maps organization errors to responses
+func (h *OrganizationHandler) writeError(c *gin.Context, err error, message string) {
+	switch err {
+	case usecase.ErrInvalidOrganizationRole:
+		response.BadRequest(c, err.Error())
+	case usecase.ErrNotOrganizationMember, usecase.ErrMemberManagementForbidden, usecase.ErrOwnerManagementForbidden:
+		response.Forbidden(c, err.Error())
+	case usecase.ErrUserNotFound, usecase.ErrMemberNotFound:
+		response.NotFound(c, err.Error())
+	case usecase.ErrMemberExists, usecase.ErrLastOwner:
+		response.Conflict(c, err.Error())
+	default:
+		response.InternalServerError(c, message)
+	}
+}
+
+// orgID parses the organization ID path parameter, writing an error
+// response on failure
+func (h *OrganizationHandler) orgID(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid organization id")
+		return 0, false
+	}
+	return orgID, true
+}
+
+// userID extracts the authenticated user ID, writing an error response on failure
+func (h *OrganizationHandler) userID(c *gin.Context) (int64, bool) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return 0, false
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return 0, false
+	}
+
+	return userID, true
+}
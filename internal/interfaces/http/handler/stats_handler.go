@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// StatsHandler handles HTTP requests for productivity analytics
+type StatsHandler struct {
+	statsUseCase *usecase.StatsUseCase
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(statsUseCase *usecase.StatsUseCase) *StatsHandler {
+	return &StatsHandler{
+		statsUseCase: statsUseCase,
+	}
+}
+
+// GetStats handles GET /api/v1/stats
+// @Summary Get productivity stats
+// @Description Completions per day and week, average lead time from creation to completion, overdue count, status and priority distribution and most-used tags for the authenticated user
+// @Tags Stats
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param days query int false "Number of days of completion history (1-365)" default(30)
+// @Success 200 {object} dto.StatsResponse "Stats retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /stats [get]
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	var req dto.StatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	stats, err := h.statsUseCase.GetStats(c.Request.Context(), userID, req.Days)
+	if err != nil {
+		response.InternalServerError(c, "failed to get stats")
+		return
+	}
+
+	response.Success(c, stats)
+}
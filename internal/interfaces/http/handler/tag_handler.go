@@ -24,7 +24,7 @@ func NewTagHandler(tagUseCase *usecase.TagUseCase) *TagHandler {
 
 // CreateTag handles POST /api/v1/tags
 // @Summary Create a new tag
-// @Description Create a new tag
+// @Description Create a new tag owned by the authenticated user. Names are unique per user; path-style names such as work/backend nest the tag under its parent, which is created when missing. Color, icon and description are optional display metadata.
 // @Tags Tags
 // @Accept json
 // @Produce json
@@ -41,10 +41,29 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagUseCase.CreateTag(c.Request.Context(), &req)
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tag, err := h.tagUseCase.CreateTag(c.Request.Context(), userID, &req)
 	if err != nil {
 		if err == usecase.ErrTagNameRequired ||
-			err == usecase.ErrTagNameTooLong {
+			err == usecase.ErrTagNameTooLong ||
+			err == usecase.ErrInvalidTagPath ||
+			err == usecase.ErrTagPathTooDeep ||
+			err == usecase.ErrInvalidTagColor ||
+			err == usecase.ErrInvalidTagIcon ||
+			err == usecase.ErrTagIconTooLong ||
+			err == usecase.ErrTagDescriptionTooLong {
 			response.BadRequest(c, err.Error())
 			return
 		}
@@ -61,7 +80,7 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 
 // GetTag handles GET /api/v1/tags/:id
 // @Summary Get a tag by ID
-// @Description Retrieve a specific tag by its ID
+// @Description Retrieve a specific tag owned by the authenticated user
 // @Tags Tags
 // @Accept json
 // @Produce json
@@ -69,6 +88,7 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 // @Param id path int true "Tag ID"
 // @Success 200 {object} dto.TagResponse "Tag retrieved successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid tag ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 404 {object} response.ErrorResponse "Tag not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags/{id} [get]
@@ -86,7 +106,20 @@ func (h *TagHandler) GetTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagUseCase.GetTag(c.Request.Context(), id)
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tag, err := h.tagUseCase.GetTag(c.Request.Context(), id, userID)
 	if err != nil {
 		if err == usecase.ErrTagNotFound {
 			response.NotFound(c, err.Error())
@@ -101,7 +134,7 @@ func (h *TagHandler) GetTag(c *gin.Context) {
 
 // UpdateTag handles PUT /api/v1/tags/:id
 // @Summary Update a tag
-// @Description Update an existing tag by its ID
+// @Description Rename a tag owned by the authenticated user and update its color, icon and description. Nested tags move along with it; a path-style name moves it under another parent. Omitted metadata is left unchanged.
 // @Tags Tags
 // @Accept json
 // @Produce json
@@ -133,11 +166,34 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagUseCase.UpdateTag(c.Request.Context(), id, &req)
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tag, err := h.tagUseCase.UpdateTag(c.Request.Context(), id, userID, &req)
 	if err != nil {
-		if err == usecase.ErrTagNotFound ||
-			err == usecase.ErrTagNameRequired ||
-			err == usecase.ErrTagNameTooLong {
+		if err == usecase.ErrTagNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		if err == usecase.ErrTagNameRequired ||
+			err == usecase.ErrTagNameTooLong ||
+			err == usecase.ErrInvalidTagPath ||
+			err == usecase.ErrTagPathTooDeep ||
+			err == usecase.ErrTagMoveIntoSelf ||
+			err == usecase.ErrInvalidTagColor ||
+			err == usecase.ErrInvalidTagIcon ||
+			err == usecase.ErrTagIconTooLong ||
+			err == usecase.ErrTagDescriptionTooLong {
 			response.BadRequest(c, err.Error())
 			return
 		}
@@ -154,7 +210,7 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 
 // DeleteTag handles DELETE /api/v1/tags/:id
 // @Summary Delete a tag
-// @Description Delete a tag by its ID
+// @Description Delete a tag owned by the authenticated user. Tags with nested tags cannot be deleted.
 // @Tags Tags
 // @Accept json
 // @Produce json
@@ -163,6 +219,7 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 // @Success 200 {object} response.SuccessResponse "Tag deleted successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid tag ID"
 // @Failure 404 {object} response.ErrorResponse "Tag not found"
+// @Failure 409 {object} response.ErrorResponse "Tag has nested tags"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags/{id} [delete]
 func (h *TagHandler) DeleteTag(c *gin.Context) {
@@ -179,12 +236,29 @@ func (h *TagHandler) DeleteTag(c *gin.Context) {
 		return
 	}
 
-	err := h.tagUseCase.DeleteTag(c.Request.Context(), id)
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	err := h.tagUseCase.DeleteTag(c.Request.Context(), id, userID)
 	if err != nil {
 		if err == usecase.ErrTagNotFound {
 			response.NotFound(c, err.Error())
 			return
 		}
+		if err == usecase.ErrTagHasChildren {
+			response.Conflict(c, err.Error())
+			return
+		}
 		response.InternalServerError(c, "failed to delete tag")
 		return
 	}
@@ -192,9 +266,72 @@ func (h *TagHandler) DeleteTag(c *gin.Context) {
 	response.Success(c, gin.H{"message": "tag deleted successfully"})
 }
 
+// MergeTags handles POST /api/v1/tags/:id/merge
+// @Summary Merge tags
+// @Description Merge source tags into the tag in the path. Todos of the sources are retagged with the target and the sources are deleted; with keep_aliases their names still resolve to the target when tagging todos by name.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Target tag ID"
+// @Param request body dto.MergeTagsRequest true "Tags to merge"
+// @Success 200 {object} dto.TagResponse "Tags merged successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Tag not found"
+// @Failure 409 {object} response.ErrorResponse "A source tag has nested tags"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /tags/{id}/merge [post]
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tag id")
+		return
+	}
+
+	var req dto.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tag, usecaseErr := h.tagUseCase.MergeTags(c.Request.Context(), id, userID, &req)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrTagNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		if usecaseErr == usecase.ErrTagMergeSelf {
+			response.BadRequest(c, usecaseErr.Error())
+			return
+		}
+		if usecaseErr == usecase.ErrTagHasChildren {
+			response.Conflict(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to merge tags")
+		return
+	}
+
+	response.Success(c, tag)
+}
+
 // ListTags handles GET /api/v1/tags
-// @Summary List all tags
-// @Description Retrieve a paginated list of all tags
+// @Summary List tags
+// @Description Retrieve a paginated list of the tags owned by the authenticated user
 // @Tags Tags
 // @Accept json
 // @Produce json
@@ -202,6 +339,7 @@ func (h *TagHandler) DeleteTag(c *gin.Context) {
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
 // @Success 200 {object} response.PaginatedResponse "Tags retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags [get]
 func (h *TagHandler) ListTags(c *gin.Context) {
@@ -211,7 +349,20 @@ func (h *TagHandler) ListTags(c *gin.Context) {
 		return
 	}
 
-	tags, err := h.tagUseCase.ListTags(c.Request.Context(), req.Page, req.Limit)
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tags, err := h.tagUseCase.ListTags(c.Request.Context(), userID, req.Page, req.Limit)
 	if err != nil {
 		response.InternalServerError(c, "failed to list tags")
 		return
@@ -227,7 +378,7 @@ func (h *TagHandler) ListTags(c *gin.Context) {
 
 // GetUserTags handles GET /api/v1/users/my-tags
 // @Summary Get current user's tags
-// @Description Retrieve all tags used by the authenticated user with todo counts
+// @Description Retrieve all tags owned by the authenticated user with todo counts
 // @Tags Tags
 // @Accept json
 // @Produce json
@@ -258,3 +409,80 @@ func (h *TagHandler) GetUserTags(c *gin.Context) {
 
 	response.Success(c, tags)
 }
+
+// GetTagTree handles GET /api/v1/tags/tree
+// @Summary Get the tag tree
+// @Description Retrieve the authenticated user's tags nested by path. Todo counts include the todos of nested tags.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} []dto.TagTreeNode "Tag tree retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /tags/tree [get]
+func (h *TagHandler) GetTagTree(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tree, err := h.tagUseCase.GetTagTree(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "failed to get tag tree")
+		return
+	}
+
+	response.Success(c, tree)
+}
+
+// SuggestTags handles GET /api/v1/tags/suggest
+// @Summary Suggest tags
+// @Description Autocomplete the authenticated user's tags. Tags whose path or any path segment starts with the prefix are returned, most frequently and recently used first.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param prefix query string false "Prefix to match"
+// @Param limit query int false "Maximum number of suggestions" default(10) minimum(1) maximum(50)
+// @Success 200 {object} []dto.TagResponse "Tag suggestions retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /tags/suggest [get]
+func (h *TagHandler) SuggestTags(c *gin.Context) {
+	var req dto.SuggestTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tags, err := h.tagUseCase.SuggestTags(c.Request.Context(), userID, req.Prefix, req.Limit)
+	if err != nil {
+		response.InternalServerError(c, "failed to suggest tags")
+		return
+	}
+
+	response.Success(c, tags)
+}
@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// TemplateHandler handles HTTP requests for todo templates
+type TemplateHandler struct {
+	templateUseCase *usecase.TemplateUseCase
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler(templateUseCase *usecase.TemplateUseCase) *TemplateHandler {
+	return &TemplateHandler{
+		templateUseCase: templateUseCase,
+	}
+}
+
+// CreateTemplate handles POST /api/v1/templates
+// @Summary Create a template
+// @Description Create a named set of todo blueprints. Items may nest subtasks; due dates are given as day offsets from the instantiation date.
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.CreateTemplateRequest true "Template details"
+// @Success 201 {object} dto.TemplateResponse "Template created successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /templates [post]
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req dto.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	template, createErr := h.templateUseCase.CreateTemplate(c.Request.Context(), userID, &req)
+	if createErr != nil {
+		if createErr == usecase.ErrTooManyTemplateItems ||
+			createErr == usecase.ErrInvalidPriority {
+			response.BadRequest(c, createErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to create template")
+		return
+	}
+
+	response.Created(c, template)
+}
+
+// CreateTemplateFromTodos handles POST /api/v1/templates/from-todos
+// @Summary Create a template from todos
+// @Description Capture existing todos as a template. Due dates become day offsets from the reference date and subtask links between the selected todos are kept.
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.CreateTemplateFromTodosRequest true "Template name and todos"
+// @Success 201 {object} dto.TemplateResponse "Template created successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Todo not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /templates/from-todos [post]
+func (h *TemplateHandler) CreateTemplateFromTodos(c *gin.Context) {
+	var req dto.CreateTemplateFromTodosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	template, createErr := h.templateUseCase.CreateTemplateFromTodos(c.Request.Context(), userID, &req)
+	if createErr != nil {
+		if createErr == usecase.ErrTodoNotFound {
+			response.NotFound(c, createErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to create template")
+		return
+	}
+
+	response.Created(c, template)
+}
+
+// ListTemplates handles GET /api/v1/templates
+// @Summary List templates
+// @Description Retrieve all templates owned by the authenticated user
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} []dto.TemplateResponse "Templates retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /templates [get]
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	templates, err := h.templateUseCase.ListTemplates(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "failed to list templates")
+		return
+	}
+
+	response.Success(c, templates)
+}
+
+// GetTemplate handles GET /api/v1/templates/:id
+// @Summary Get a template by ID
+// @Description Retrieve a specific template owned by the authenticated user
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Template ID"
+// @Success 200 {object} dto.TemplateResponse "Template retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid template ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Template not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /templates/{id} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid template id")
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	template, usecaseErr := h.templateUseCase.GetTemplate(c.Request.Context(), id, userID)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrTemplateNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to get template")
+		return
+	}
+
+	response.Success(c, template)
+}
+
+// UpdateTemplate handles PUT /api/v1/templates/:id
+// @Summary Update a template
+// @Description Update the name or description of a template, or replace its items
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Template ID"
+// @Param request body dto.UpdateTemplateRequest true "Updated template details"
+// @Success 200 {object} dto.TemplateResponse "Template updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Template not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /templates/{id} [put]
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid template id")
+		return
+	}
+
+	var req dto.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	template, usecaseErr := h.templateUseCase.UpdateTemplate(c.Request.Context(), id, userID, &req)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrTemplateNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		if usecaseErr == usecase.ErrTooManyTemplateItems ||
+			usecaseErr == usecase.ErrInvalidPriority {
+			response.BadRequest(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to update template")
+		return
+	}
+
+	response.Success(c, template)
+}
+
+// DeleteTemplate handles DELETE /api/v1/templates/:id
+// @Summary Delete a template
+// @Description Delete a template owned by the authenticated user. Todos created from it are kept.
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Template ID"
+// @Success 200 {object} response.SuccessResponse "Template deleted successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid template ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Template not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /templates/{id} [delete]
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid template id")
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	if err := h.templateUseCase.DeleteTemplate(c.Request.Context(), id, userID); err != nil {
+		if err == usecase.ErrTemplateNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to delete template")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "template deleted successfully"})
+}
+
+// InstantiateTemplate handles POST /api/v1/templates/:id/instantiate
+// @Summary Instantiate a template
+// @Description Create all todos of a template in one transaction. Due dates are the item offsets added to start_date (default now).
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Template ID"
+// @Param request body dto.InstantiateTemplateRequest false "Instantiation options"
+// @Success 201 {object} []dto.TodoResponse "Todos created successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Template not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /templates/{id}/instantiate [post]
+func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid template id")
+		return
+	}
+
+	// The body is optional
+	var req dto.InstantiateTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	todos, usecaseErr := h.templateUseCase.InstantiateTemplate(c.Request.Context(), id, userID, &req)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrTemplateNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to instantiate template")
+		return
+	}
+
+	response.Created(c, todos)
+}
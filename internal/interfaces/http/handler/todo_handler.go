@@ -61,7 +61,16 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 		if createErr == usecase.ErrTodoTitleRequired ||
 			createErr == usecase.ErrTodoTitleTooLong ||
 			createErr == usecase.ErrTodoDescriptionTooLong ||
-			createErr == usecase.ErrInvalidPriority {
+			createErr == usecase.ErrInvalidPriority ||
+			createErr == usecase.ErrStartAfterDue ||
+			createErr == usecase.ErrScheduledAfterDue ||
+			createErr == usecase.ErrScheduledBeforeStart ||
+			createErr == usecase.ErrParentTodoNotFound ||
+			createErr == usecase.ErrInvalidAssignee ||
+			createErr == usecase.ErrTagNameRequired ||
+			createErr == usecase.ErrTagNameTooLong ||
+			createErr == usecase.ErrInvalidTagPath ||
+			createErr == usecase.ErrTagPathTooDeep {
 			response.BadRequest(c, createErr.Error())
 			return
 		}
@@ -74,7 +83,7 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 
 // GetTodo handles GET /api/v1/todos/:id
 // @Summary Get a todo by ID
-// @Description Retrieve a specific todo item by its ID (only own todos)
+// @Description Retrieve a specific todo item by its ID (own todos and todos assigned to you)
 // @Tags Todos
 // @Accept json
 // @Produce json
@@ -143,6 +152,7 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 404 {object} response.ErrorResponse "Todo not found"
+// @Failure 409 {object} response.ErrorResponse "Status transition not allowed by the workflow"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /todos/{id} [put]
 func (h *TodoHandler) UpdateTodo(c *gin.Context) {
@@ -188,11 +198,23 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 			response.Unauthorized(c, usecaseErr.Error())
 			return
 		}
+		if usecaseErr == usecase.ErrTransitionNotAllowed {
+			response.Conflict(c, usecaseErr.Error())
+			return
+		}
 		if usecaseErr == usecase.ErrTodoTitleRequired ||
 			usecaseErr == usecase.ErrTodoTitleTooLong ||
 			usecaseErr == usecase.ErrTodoDescriptionTooLong ||
 			usecaseErr == usecase.ErrInvalidStatus ||
-			usecaseErr == usecase.ErrInvalidPriority {
+			usecaseErr == usecase.ErrInvalidPriority ||
+			usecaseErr == usecase.ErrStartAfterDue ||
+			usecaseErr == usecase.ErrScheduledAfterDue ||
+			usecaseErr == usecase.ErrScheduledBeforeStart ||
+			usecaseErr == usecase.ErrInvalidAssignee ||
+			usecaseErr == usecase.ErrTagNameRequired ||
+			usecaseErr == usecase.ErrTagNameTooLong ||
+			usecaseErr == usecase.ErrInvalidTagPath ||
+			usecaseErr == usecase.ErrTagPathTooDeep {
 			response.BadRequest(c, usecaseErr.Error())
 			return
 		}
@@ -263,7 +285,7 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 
 // UpdateTodoStatus handles PATCH /api/v1/todos/:id/status
 // @Summary Update todo status
-// @Description Update the status of a todo item by its ID (only own todos)
+// @Description Update the status of a todo item by its ID (own todos and todos assigned to you), following the owner's workflow
 // @Tags Todos
 // @Accept json
 // @Produce json
@@ -274,6 +296,7 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 404 {object} response.ErrorResponse "Todo not found"
+// @Failure 409 {object} response.ErrorResponse "Status transition not allowed by the workflow"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /todos/{id}/status [patch]
 func (h *TodoHandler) UpdateTodoStatus(c *gin.Context) {
@@ -323,6 +346,10 @@ func (h *TodoHandler) UpdateTodoStatus(c *gin.Context) {
 			response.BadRequest(c, usecaseErr.Error())
 			return
 		}
+		if usecaseErr == usecase.ErrTransitionNotAllowed {
+			response.Conflict(c, usecaseErr.Error())
+			return
+		}
 		response.InternalServerError(c, "failed to update todo status")
 		return
 	}
@@ -330,6 +357,240 @@ func (h *TodoHandler) UpdateTodoStatus(c *gin.Context) {
 	response.Success(c, todo)
 }
 
+// SnoozeTodo handles POST /api/v1/todos/:id/snooze
+// @Summary Snooze todo
+// @Description Hide a todo from default lists until the given time (only own todos)
+// @Tags Todos
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Todo ID"
+// @Param request body dto.SnoozeTodoRequest true "Time the todo reappears"
+// @Success 200 {object} dto.TodoResponse "Todo snoozed successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or snooze time in the past"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Todo not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /todos/{id}/snooze [post]
+func (h *TodoHandler) SnoozeTodo(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid todo id")
+		return
+	}
+
+	var req dto.SnoozeTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.GetString("UserID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	todo, usecaseErr := h.todoUseCase.SnoozeTodo(c.Request.Context(), id, userID, req.Until)
+	if usecaseErr != nil {
+		h.handleTodoError(c, usecaseErr, "failed to snooze todo")
+		return
+	}
+
+	response.Success(c, todo)
+}
+
+// UnsnoozeTodo handles DELETE /api/v1/todos/:id/snooze
+// @Summary Unsnooze todo
+// @Description Make a snoozed todo visible again immediately (only own todos)
+// @Tags Todos
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Todo ID"
+// @Success 200 {object} dto.TodoResponse "Todo unsnoozed successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid todo ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Todo not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /todos/{id}/snooze [delete]
+func (h *TodoHandler) UnsnoozeTodo(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid todo id")
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.GetString("UserID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	todo, usecaseErr := h.todoUseCase.UnsnoozeTodo(c.Request.Context(), id, userID)
+	if usecaseErr != nil {
+		h.handleTodoError(c, usecaseErr, "failed to unsnooze todo")
+		return
+	}
+
+	response.Success(c, todo)
+}
+
+// ArchiveTodo handles POST /api/v1/todos/:id/archive
+// @Summary Archive todo
+// @Description Move a todo to the archive, hiding it from default lists (only own todos)
+// @Tags Todos
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Todo ID"
+// @Success 200 {object} dto.TodoResponse "Todo archived successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid todo ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Todo not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /todos/{id}/archive [post]
+func (h *TodoHandler) ArchiveTodo(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid todo id")
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.GetString("UserID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	todo, usecaseErr := h.todoUseCase.ArchiveTodo(c.Request.Context(), id, userID)
+	if usecaseErr != nil {
+		h.handleTodoError(c, usecaseErr, "failed to archive todo")
+		return
+	}
+
+	response.Success(c, todo)
+}
+
+// RestoreTodo handles POST /api/v1/todos/archive/:id/restore
+// @Summary Restore archived todo
+// @Description Move an archived todo back to the active todos (only own todos)
+// @Tags Todos
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Todo ID"
+// @Success 200 {object} dto.TodoResponse "Todo restored successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid todo ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Archived todo not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /todos/archive/{id}/restore [post]
+func (h *TodoHandler) RestoreTodo(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid todo id")
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.GetString("UserID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	todo, usecaseErr := h.todoUseCase.RestoreTodo(c.Request.Context(), id, userID)
+	if usecaseErr != nil {
+		h.handleTodoError(c, usecaseErr, "failed to restore todo")
+		return
+	}
+
+	response.Success(c, todo)
+}
+
+// ListArchivedTodos handles GET /api/v1/todos/archive
+// @Summary List archived todos
+// @Description Retrieve a paginated list of the authenticated user's archived todos, most recently archived first
+// @Tags Todos
+// @Produce json
+// @Security Bearer
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.PaginatedResponse "Archived todos retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid user ID or request format"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /todos/archive [get]
+func (h *TodoHandler) ListArchivedTodos(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.GetString("UserID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	var req dto.ListArchivedTodosRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	todos, usecaseErr := h.todoUseCase.ListArchivedTodos(c.Request.Context(), userID, &req)
+	if usecaseErr != nil {
+		response.InternalServerError(c, "failed to list archived todos")
+		return
+	}
+
+	response.SuccessWithPagination(c, todos.Data, &response.Pagination{
+		Page:       todos.Page,
+		Limit:      todos.Limit,
+		Total:      int(todos.Total),
+		TotalPages: todos.TotalPages,
+	})
+}
+
+// GetMatrix handles GET /api/v1/todos/matrix
+// @Summary Eisenhower matrix
+// @Description Sort the authenticated user's open, unsnoozed todos into the do (urgent and important), schedule (important), delegate (urgent) and eliminate quadrants. Todos are important when flagged so or at priority p0 or p1, and urgent when flagged so or due within matrix.urgent_within.
+// @Tags Todos
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "Todos listed per quadrant" default(20) minimum(1) maximum(100)
+// @Success 200 {object} dto.MatrixResponse "Matrix retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid user ID or request format"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /todos/matrix [get]
+func (h *TodoHandler) GetMatrix(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.GetString("UserID"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	var req dto.MatrixRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	matrix, usecaseErr := h.todoUseCase.GetMatrix(c.Request.Context(), userID, req.Limit)
+	if usecaseErr != nil {
+		response.InternalServerError(c, "failed to get matrix")
+		return
+	}
+
+	response.Success(c, matrix)
+}
+
+// handleTodoError maps todo use case errors to responses
+func (h *TodoHandler) handleTodoError(c *gin.Context, err error, message string) {
+	switch err {
+	case usecase.ErrTodoNotFound:
+		response.NotFound(c, err.Error())
+	case usecase.ErrUnauthorized:
+		response.Unauthorized(c, err.Error())
+	case usecase.ErrInvalidSnoozeTime:
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, message)
+	}
+}
+
 // ListTodos handles GET /api/v1/todos
 // @Summary List todos
 // @Description Retrieve a paginated list of todos for the authenticated user with optional filters and sorting
@@ -339,12 +600,20 @@ func (h *TodoHandler) UpdateTodoStatus(c *gin.Context) {
 // @Security Bearer
 // @Param page query int false "Page number" default(1) minimum(1)
 // @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
-// @Param status query string false "Filter by status" Enums(not_started, in_progress, completed)
-// @Param priority query string false "Filter by priority" Enums(low, medium, high)
+// @Param status query string false "Filter by status; one of the states of the user's workflow"
+// @Param priority query string false "Filter by priority (p0-p4; low, medium and high are read as p3, p2 and p1)"
 // @Param search query string false "Search in title and description" maxlength(100)
-// @Param due_date_from query string false "Filter todos due after this date (RFC3339 format)" format(date-time)
-// @Param due_date_to query string false "Filter todos due before this date (RFC3339 format)" format(date-time)
-// @Param sort_by query string false "Sort field" Enums(due_date, status, title) default(due_date)
+// @Param due_date_from query string false "Filter todos due after this time (RFC3339), or from this date (YYYY-MM-DD) in the user's timezone"
+// @Param due_date_to query string false "Filter todos due before this time (RFC3339), or through this date (YYYY-MM-DD) in the user's timezone"
+// @Param overdue query bool false "Only open todos past their due date"
+// @Param available query bool false "Only todos whose start date has passed"
+// @Param include_snoozed query bool false "Also list todos whose snooze has not ended"
+// @Param include_archived query bool false "Also list archived todos"
+// @Param tags query string false "Comma-separated tag names; nested tags count as their parent"
+// @Param tag_match query string false "Whether todos need any or all of the tags" Enums(any, all) default(any)
+// @Param exclude_tags query string false "Comma-separated tag names to leave out"
+// @Param assignment query string false "List the todos assigned to you, whoever owns them, or your todos assigned to someone else" Enums(assigned_to_me, delegated_by_me)
+// @Param sort_by query string false "Sort field; priority sorts p0 first in ascending order" Enums(due_date, status, title, priority, scheduled_for) default(due_date)
 // @Param sort_order query string false "Sort order" Enums(asc, desc) default(asc)
 // @Success 200 {object} response.PaginatedResponse "Todos retrieved successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid user ID or request format"
@@ -372,6 +641,89 @@ func (h *TodoHandler) ListTodos(c *gin.Context) {
 
 	todos, usecaseErr := h.todoUseCase.ListTodos(c.Request.Context(), userID, &req)
 	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrInvalidPriority ||
+			usecaseErr == usecase.ErrInvalidDueDateBound ||
+			usecaseErr == usecase.ErrTagNameRequired ||
+			usecaseErr == usecase.ErrTagNameTooLong ||
+			usecaseErr == usecase.ErrInvalidTagPath ||
+			usecaseErr == usecase.ErrTagPathTooDeep {
+			response.BadRequest(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to list todos")
+		return
+	}
+
+	response.SuccessWithPagination(c, todos.Data, &response.Pagination{
+		Page:       todos.Page,
+		Limit:      todos.Limit,
+		Total:      int(todos.Total),
+		TotalPages: todos.TotalPages,
+	})
+}
+
+// ListTodosByTag handles GET /api/v1/tags/:id/todos
+// @Summary List todos by tag
+// @Description Retrieve a paginated list of the authenticated user's todos tagged with a tag or a tag nested under it
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Tag ID"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Param status query string false "Filter by status; one of the states of the user's workflow"
+// @Param priority query string false "Filter by priority (p0-p4; low, medium and high are read as p3, p2 and p1)"
+// @Param exclude_tags query string false "Comma-separated tag names to leave out"
+// @Param sort_by query string false "Sort field; priority sorts p0 first in ascending order" Enums(due_date, status, title, priority, scheduled_for) default(due_date)
+// @Param sort_order query string false "Sort order" Enums(asc, desc) default(asc)
+// @Success 200 {object} response.PaginatedResponse "Todos retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid tag ID or request format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Tag not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /tags/{id}/todos [get]
+func (h *TodoHandler) ListTodosByTag(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	tagID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid tag ID")
+		return
+	}
+
+	var req dto.ListTodosRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	todos, usecaseErr := h.todoUseCase.ListTodosByTag(c.Request.Context(), userID, tagID, &req)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrTagNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		if usecaseErr == usecase.ErrInvalidPriority ||
+			usecaseErr == usecase.ErrInvalidDueDateBound ||
+			usecaseErr == usecase.ErrTagNameRequired ||
+			usecaseErr == usecase.ErrTagNameTooLong ||
+			usecaseErr == usecase.ErrInvalidTagPath ||
+			usecaseErr == usecase.ErrTagPathTooDeep {
+			response.BadRequest(c, usecaseErr.Error())
+			return
+		}
 		response.InternalServerError(c, "failed to list todos")
 		return
 	}
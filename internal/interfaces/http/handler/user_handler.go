@@ -64,6 +64,10 @@ func (h *UserHandler) Register(c *gin.Context) {
 			response.BadRequest(c, err.Error())
 			return
 		}
+		if err == usecase.ErrInvalidTimezone {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		response.InternalServerError(c, "failed to register user")
 		return
 	}
@@ -73,7 +77,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login handles POST /api/v1/auth/login
 // @Summary User login
-// @Description Authenticate user and return access and refresh tokens
+// @Description Authenticate user and return access and refresh tokens acting in the requested organization, by default the user's oldest one
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -81,6 +85,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 // @Success 200 {object} dto.LoginResponse "Login successful"
 // @Failure 400 {object} response.ErrorResponse "Invalid request format"
 // @Failure 401 {object} response.ErrorResponse "Invalid username or password"
+// @Failure 403 {object} response.ErrorResponse "Not a member of the organization"
 // @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
@@ -97,6 +102,10 @@ func (h *UserHandler) Login(c *gin.Context) {
 			response.Unauthorized(c, err.Error())
 			return
 		}
+		if err == usecase.ErrNotOrganizationMember || err == usecase.ErrNoOrganization {
+			response.Forbidden(c, err.Error())
+			return
+		}
 		response.InternalServerError(c, "failed to login")
 		return
 	}
@@ -189,6 +198,62 @@ func (h *UserHandler) Logout(c *gin.Context) {
 	response.Success(c, gin.H{"message": "logged out successfully"})
 }
 
+// ForgotPassword handles POST /api/v1/auth/forgot-password
+// @Summary Request a password reset link
+// @Description Email a single-use, expiring password reset link to the account with the given email address. The response is the same whether or not there is such an account.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Email address of the account"
+// @Success 200 {object} response.SuccessResponse "Reset link sent if the account exists"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/forgot-password [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request format: "+err.Error())
+		return
+	}
+
+	if err := h.userUseCase.ForgotPassword(c.Request.Context(), &req); err != nil {
+		response.InternalServerError(c, "failed to send password reset link")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "if an account uses this email, a password reset link has been sent to it"})
+}
+
+// ResetPassword handles POST /api/v1/auth/reset-password
+// @Summary Reset password
+// @Description Set a new password with the token from a password reset link. Each token works once, and all of the user's refresh tokens are revoked.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.SuccessResponse "Password reset"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format, token or password"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /auth/reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request format: "+err.Error())
+		return
+	}
+
+	if err := h.userUseCase.ResetPassword(c.Request.Context(), &req); err != nil {
+		if err == usecase.ErrInvalidResetToken || err == usecase.ErrInvalidPassword {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to reset password")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "password reset successfully"})
+}
+
 // GetProfile handles GET /api/v1/users/profile
 // @Summary Get current user profile
 // @Description Retrieve the profile information of the currently authenticated user
@@ -230,3 +295,106 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 	response.Success(c, profile)
 }
+
+// UpdateProfile handles PATCH /api/v1/users/profile
+// @Summary Update current user profile
+// @Description Update the preferences of the currently authenticated user. Changing the timezone moves the due dates of all-day todos to the end of their day in the new zone. The digest fields opt into a daily or weekly summary email sent at digest_hour in the user's timezone.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.UpdateProfileRequest true "Profile fields to update"
+// @Success 200 {object} dto.UserResponse "User profile updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format, timezone or digest preferences"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /users/profile [patch]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	profile, err := h.userUseCase.UpdateProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		if err == usecase.ErrInvalidTimezone || err == usecase.ErrInvalidDigest {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if err == usecase.ErrUserNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to update user profile")
+		return
+	}
+
+	response.Success(c, profile)
+}
+
+// ChangePassword handles PUT /api/v1/users/password
+// @Summary Change password
+// @Description Change the password of the currently authenticated user, who confirms the current one. All of the user's refresh tokens are revoked, so they sign in again.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.SuccessResponse "Password changed"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or new password"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized or incorrect current password"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /users/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	if err := h.userUseCase.ChangePassword(c.Request.Context(), userID, &req); err != nil {
+		if err == usecase.ErrInvalidPassword {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if err == usecase.ErrIncorrectPassword {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		if err == usecase.ErrUserNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to change password")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "password changed successfully"})
+}
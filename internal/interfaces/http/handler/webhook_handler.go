@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions
+type WebhookHandler struct {
+	webhookUseCase *usecase.WebhookUseCase
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookUseCase *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// CreateWebhook handles POST /api/v1/webhooks
+// @Summary Create a webhook
+// @Description Subscribe a URL to todo and tag events. Deliveries are signed with HMAC-SHA256; the secret is only returned once.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.CreateWebhookRequest true "Webhook details"
+// @Success 201 {object} dto.WebhookResponse "Webhook created successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	webhook, createErr := h.webhookUseCase.CreateWebhook(c.Request.Context(), userID, &req)
+	if createErr != nil {
+		if createErr == usecase.ErrInvalidWebhookURL ||
+			createErr == usecase.ErrWebhookURLNotPublic ||
+			createErr == usecase.ErrInvalidWebhookEvent {
+			response.BadRequest(c, createErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to create webhook")
+		return
+	}
+
+	response.Created(c, webhook)
+}
+
+// ListWebhooks handles GET /api/v1/webhooks
+// @Summary List webhooks
+// @Description Retrieve all webhooks owned by the authenticated user
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} []dto.WebhookResponse "Webhooks retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	webhooks, err := h.webhookUseCase.ListWebhooks(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "failed to list webhooks")
+		return
+	}
+
+	response.Success(c, webhooks)
+}
+
+// GetWebhook handles GET /api/v1/webhooks/:id
+// @Summary Get a webhook by ID
+// @Description Retrieve a specific webhook owned by the authenticated user
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} dto.WebhookResponse "Webhook retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid webhook ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Webhook not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid webhook id")
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	webhook, usecaseErr := h.webhookUseCase.GetWebhook(c.Request.Context(), id, userID)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrWebhookNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to get webhook")
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
+// UpdateWebhook handles PUT /api/v1/webhooks/:id
+// @Summary Update a webhook
+// @Description Update the URL, events, description or active flag of a webhook
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Param request body dto.UpdateWebhookRequest true "Updated webhook details"
+// @Success 200 {object} dto.WebhookResponse "Webhook updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Webhook not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid webhook id")
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	webhook, usecaseErr := h.webhookUseCase.UpdateWebhook(c.Request.Context(), id, userID, &req)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrWebhookNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		if usecaseErr == usecase.ErrInvalidWebhookURL ||
+			usecaseErr == usecase.ErrWebhookURLNotPublic ||
+			usecaseErr == usecase.ErrInvalidWebhookEvent {
+			response.BadRequest(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to update webhook")
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
+// DeleteWebhook handles DELETE /api/v1/webhooks/:id
+// @Summary Delete a webhook
+// @Description Delete a webhook owned by the authenticated user
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.SuccessResponse "Webhook deleted successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid webhook ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Webhook not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid webhook id")
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	if err := h.webhookUseCase.DeleteWebhook(c.Request.Context(), id, userID); err != nil {
+		if err == usecase.ErrWebhookNotFound {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to delete webhook")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "webhook deleted successfully"})
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/:id/deliveries
+// @Summary List webhook deliveries
+// @Description Retrieve the delivery log of a webhook, newest first
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response.PaginatedResponse "Deliveries retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid webhook ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Webhook not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid webhook id")
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	deliveries, usecaseErr := h.webhookUseCase.ListDeliveries(c.Request.Context(), id, userID, page, limit)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrWebhookNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to list webhook deliveries")
+		return
+	}
+
+	response.SuccessWithPagination(c, deliveries.Data, &response.Pagination{
+		Page:       deliveries.Page,
+		Limit:      deliveries.Limit,
+		Total:      int(deliveries.Total),
+		TotalPages: deliveries.TotalPages,
+	})
+}
+
+// Redeliver handles POST /api/v1/webhooks/:id/deliveries/:delivery_id/redeliver
+// @Summary Redeliver a webhook delivery
+// @Description Schedule a past delivery to be sent again immediately
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 200 {object} dto.WebhookDeliveryResponse "Delivery scheduled successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid webhook or delivery ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Webhook or delivery not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid webhook id")
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid delivery id")
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, userErr := strconv.ParseInt(userIDStr, 10, 64)
+	if userErr != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	delivery, usecaseErr := h.webhookUseCase.Redeliver(c.Request.Context(), id, deliveryID, userID)
+	if usecaseErr != nil {
+		if usecaseErr == usecase.ErrWebhookNotFound ||
+			usecaseErr == usecase.ErrWebhookDeliveryNotFound {
+			response.NotFound(c, usecaseErr.Error())
+			return
+		}
+		response.InternalServerError(c, "failed to redeliver webhook")
+		return
+	}
+
+	response.Success(c, delivery)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/darron08/todolist-demo/internal/usecase"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/response"
+)
+
+// WorkflowHandler handles HTTP requests for status workflows
+type WorkflowHandler struct {
+	workflowUseCase *usecase.WorkflowUseCase
+}
+
+// NewWorkflowHandler creates a new workflow handler
+func NewWorkflowHandler(workflowUseCase *usecase.WorkflowUseCase) *WorkflowHandler {
+	return &WorkflowHandler{
+		workflowUseCase: workflowUseCase,
+	}
+}
+
+// GetWorkflow handles GET /api/v1/workflow
+// @Summary Get status workflow
+// @Description Get the statuses the authenticated user's todos move through, with their categories and allowed transitions. Users without their own workflow get the default not_started, in_progress, completed workflow.
+// @Tags Workflow
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} dto.WorkflowResponse "Workflow retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /workflow [get]
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	workflow, err := h.workflowUseCase.GetWorkflow(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalServerError(c, "failed to get workflow")
+		return
+	}
+
+	response.Success(c, workflow)
+}
+
+// UpdateWorkflow handles PUT /api/v1/workflow
+// @Summary Replace status workflow
+// @Description Replace the authenticated user's workflow. States are listed in order and new todos start in the first one. Each state has a category (open, active or done) and the states todos in it may move to. States still used by todos cannot be removed.
+// @Tags Workflow
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.UpdateWorkflowRequest true "Workflow states"
+// @Success 200 {object} dto.WorkflowResponse "Workflow updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request format or validation error"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 409 {object} response.ErrorResponse "A removed state is still used by todos"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /workflow [put]
+func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
+	var req dto.UpdateWorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	workflow, err := h.workflowUseCase.UpdateWorkflow(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleWorkflowError(c, err, "failed to update workflow")
+		return
+	}
+
+	response.Success(c, workflow)
+}
+
+// ResetWorkflow handles DELETE /api/v1/workflow
+// @Summary Reset status workflow
+// @Description Go back to the default not_started, in_progress, completed workflow. Fails while todos are in states the default workflow does not have.
+// @Tags Workflow
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} dto.WorkflowResponse "Workflow reset successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 409 {object} response.ErrorResponse "A removed state is still used by todos"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /workflow [delete]
+func (h *WorkflowHandler) ResetWorkflow(c *gin.Context) {
+	userIDStr := c.GetString("UserID")
+	if userIDStr == "" {
+		response.Unauthorized(c, "user not authenticated")
+		return
+	}
+
+	// Convert user ID to int64
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user ID")
+		return
+	}
+
+	workflow, err := h.workflowUseCase.ResetWorkflow(c.Request.Context(), userID)
+	if err != nil {
+		h.handleWorkflowError(c, err, "failed to reset workflow")
+		return
+	}
+
+	response.Success(c, workflow)
+}
+
+// handleWorkflowError maps workflow use case errors to HTTP responses
+func (h *WorkflowHandler) handleWorkflowError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case usecase.ErrWorkflowStateRequired,
+		usecase.ErrInvalidWorkflowState,
+		usecase.ErrDuplicateWorkflowState,
+		usecase.ErrTooManyWorkflowStates,
+		usecase.ErrInvalidStatusCategory,
+		usecase.ErrUnknownTransitionTarget,
+		usecase.ErrInitialStateDone:
+		response.BadRequest(c, err.Error())
+	case usecase.ErrWorkflowStateInUse:
+		response.Conflict(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
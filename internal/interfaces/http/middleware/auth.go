@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
 	"github.com/darron08/todolist-demo/pkg/utils"
 )
 
@@ -57,9 +58,7 @@ func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 		}
 
 		// Set user context
-		c.Set("UserID", fmt.Sprintf("%d", claims.UserID))
-		c.Set("Username", claims.Username)
-		c.Set("Role", claims.Role)
+		setClaims(c, claims)
 
 		c.Next()
 	}
@@ -98,14 +97,22 @@ func OptionalAuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 		}
 
 		// Set user context if token is valid
-		c.Set("UserID", fmt.Sprintf("%d", claims.UserID))
-		c.Set("Username", claims.Username)
-		c.Set("Role", claims.Role)
+		setClaims(c, claims)
 
 		c.Next()
 	}
 }
 
+// setClaims sets the user context from token claims and binds the request
+// context to the token's tenant, which scopes every repository query
+func setClaims(c *gin.Context, claims *utils.Claims) {
+	c.Set("UserID", fmt.Sprintf("%d", claims.UserID))
+	c.Set("Username", claims.Username)
+	c.Set("Role", claims.Role)
+	c.Set("TenantID", claims.TenantID)
+	c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), claims.TenantID))
+}
+
 // RequireRole checks if user has required role
 func RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -134,3 +141,18 @@ func RequireRole(requiredRole string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// StreamAuthMiddleware validates JWT tokens for streaming endpoints. Browser
+// EventSource and WebSocket clients cannot set an Authorization header, so
+// the access token may also be passed in the access_token query parameter.
+func StreamAuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+	auth := AuthMiddleware(jwtManager)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		auth(c)
+	}
+}
@@ -21,6 +21,16 @@ func SetupRouter(
 	todoHandler *httpHandler.TodoHandler,
 	adminHandler *httpHandler.AdminHandler,
 	tagHandler *httpHandler.TagHandler,
+	webhookHandler *httpHandler.WebhookHandler,
+	eventHandler *httpHandler.EventHandler,
+	graphqlHandler *httpHandler.GraphQLHandler,
+	statsHandler *httpHandler.StatsHandler,
+	templateHandler *httpHandler.TemplateHandler,
+	workflowHandler *httpHandler.WorkflowHandler,
+	agendaHandler *httpHandler.AgendaHandler,
+	digestHandler *httpHandler.DigestHandler,
+	organizationHandler *httpHandler.OrganizationHandler,
+	notificationHandler *httpHandler.NotificationHandler,
 ) *gin.Engine {
 	r := gin.New()
 
@@ -76,6 +86,8 @@ func SetupRouter(
 			auth.POST("/register", userHandler.Register)
 			auth.POST("/login", userHandler.Login)
 			auth.POST("/refresh", userHandler.RefreshToken)
+			auth.POST("/forgot-password", userHandler.ForgotPassword)
+			auth.POST("/reset-password", userHandler.ResetPassword)
 			auth.POST("/logout", middleware.AuthMiddleware(jwtManager), userHandler.Logout)
 		}
 
@@ -84,6 +96,21 @@ func SetupRouter(
 		users.Use(middleware.AuthMiddleware(jwtManager))
 		{
 			users.GET("/profile", userHandler.GetProfile)
+			users.PATCH("/profile", userHandler.UpdateProfile)
+			users.PUT("/password", userHandler.ChangePassword)
+		}
+
+		// Organization routes (require authentication)
+		organizations := v1.Group("/organizations")
+		organizations.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			organizations.GET("", organizationHandler.ListOrganizations)
+			organizations.POST("", organizationHandler.CreateOrganization)
+			organizations.POST("/:id/switch", organizationHandler.SwitchOrganization)
+			organizations.GET("/:id/members", organizationHandler.ListMembers)
+			organizations.POST("/:id/members", organizationHandler.AddMember)
+			organizations.PUT("/:id/members/:user_id", organizationHandler.UpdateMember)
+			organizations.DELETE("/:id/members/:user_id", organizationHandler.RemoveMember)
 		}
 
 		// Todo routes (require authentication)
@@ -92,10 +119,16 @@ func SetupRouter(
 		{
 			todos.POST("", todoHandler.CreateTodo)
 			todos.GET("", todoHandler.ListTodos)
+			todos.GET("/matrix", todoHandler.GetMatrix)
 			todos.GET("/:id", todoHandler.GetTodo)
 			todos.PUT("/:id", todoHandler.UpdateTodo)
 			todos.DELETE("/:id", todoHandler.DeleteTodo)
 			todos.PATCH("/:id/status", todoHandler.UpdateTodoStatus)
+			todos.POST("/:id/snooze", todoHandler.SnoozeTodo)
+			todos.DELETE("/:id/snooze", todoHandler.UnsnoozeTodo)
+			todos.POST("/:id/archive", todoHandler.ArchiveTodo)
+			todos.GET("/archive", todoHandler.ListArchivedTodos)
+			todos.POST("/archive/:id/restore", todoHandler.RestoreTodo)
 		}
 
 		// Admin routes (require admin role)
@@ -117,13 +150,86 @@ func SetupRouter(
 		{
 			tags.POST("", tagHandler.CreateTag)
 			tags.GET("", tagHandler.ListTags)
+			tags.GET("/tree", tagHandler.GetTagTree)
+			tags.GET("/suggest", tagHandler.SuggestTags)
 			tags.GET("/:id", tagHandler.GetTag)
 			tags.PUT("/:id", tagHandler.UpdateTag)
 			tags.DELETE("/:id", tagHandler.DeleteTag)
+			tags.POST("/:id/merge", tagHandler.MergeTags)
+			tags.GET("/:id/todos", todoHandler.ListTodosByTag)
 		}
 
 		// User tag routes
 		users.GET("/my-tags", tagHandler.GetUserTags)
+
+		// Webhook routes (require authentication)
+		webhooks := v1.Group("/webhooks")
+		webhooks.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.GET("", webhookHandler.ListWebhooks)
+			webhooks.GET("/:id", webhookHandler.GetWebhook)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		}
+
+		// Template routes (require authentication)
+		templates := v1.Group("/templates")
+		templates.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			templates.POST("", templateHandler.CreateTemplate)
+			templates.POST("/from-todos", templateHandler.CreateTemplateFromTodos)
+			templates.GET("", templateHandler.ListTemplates)
+			templates.GET("/:id", templateHandler.GetTemplate)
+			templates.PUT("/:id", templateHandler.UpdateTemplate)
+			templates.DELETE("/:id", templateHandler.DeleteTemplate)
+			templates.POST("/:id/instantiate", templateHandler.InstantiateTemplate)
+		}
+
+		// Workflow routes (require authentication)
+		workflow := v1.Group("/workflow")
+		workflow.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			workflow.GET("", workflowHandler.GetWorkflow)
+			workflow.PUT("", workflowHandler.UpdateWorkflow)
+			workflow.DELETE("", workflowHandler.ResetWorkflow)
+		}
+
+		// Notification routes (require authentication)
+		notifications := v1.Group("/notifications")
+		notifications.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.POST("/read-all", notificationHandler.MarkAllRead)
+			notifications.GET("/preferences", notificationHandler.GetPreferences)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
+		}
+
+		// Stats routes (require authentication)
+		v1.GET("/stats", middleware.AuthMiddleware(jwtManager), statsHandler.GetStats)
+
+		// Agenda routes (require authentication)
+		v1.GET("/agenda", middleware.AuthMiddleware(jwtManager), agendaHandler.GetAgenda)
+
+		// Digest unsubscribe routes (public; the link carries a signed token)
+		v1.GET("/digest/unsubscribe", digestHandler.Unsubscribe)
+		v1.POST("/digest/unsubscribe", digestHandler.Unsubscribe)
+
+		// Realtime event routes (require authentication)
+		events := v1.Group("/events")
+		events.Use(middleware.StreamAuthMiddleware(jwtManager))
+		{
+			events.GET("", eventHandler.Stream)
+			events.GET("/ws", eventHandler.WebSocket)
+		}
+	}
+
+	// GraphQL endpoint (requires authentication)
+	if cfg.GraphQL.Enabled {
+		r.POST("/graphql", middleware.AuthMiddleware(jwtManager), graphqlHandler.Query)
 	}
 
 	// Swagger documentation (if enabled)
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
 	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
 	"github.com/darron08/todolist-demo/pkg/dto"
 	"github.com/darron08/todolist-demo/pkg/utils"
 )
@@ -20,24 +22,34 @@ var (
 // AdminUseCase implements business logic for admin operations
 type AdminUseCase struct {
 	userRepo          repository.UserRepository
+	orgRepo           repository.OrganizationRepository
 	todoRepo          repository.TodoRepository
+	txManager         repository.TransactionManager
+	outboxRepo        repository.OutboxRepository
 	passwordValidator *utils.Validator
 }
 
 // NewAdminUseCase creates a new admin use case
 func NewAdminUseCase(
 	userRepo repository.UserRepository,
+	orgRepo repository.OrganizationRepository,
 	todoRepo repository.TodoRepository,
+	txManager repository.TransactionManager,
+	outboxRepo repository.OutboxRepository,
 ) *AdminUseCase {
 	validator := utils.NewValidator()
 	return &AdminUseCase{
 		userRepo:          userRepo,
+		orgRepo:           orgRepo,
 		todoRepo:          todoRepo,
+		txManager:         txManager,
+		outboxRepo:        outboxRepo,
 		passwordValidator: validator,
 	}
 }
 
-// CreateUser creates a new user with specified role (admin only)
+// CreateUser creates a new user with specified role (admin only). The user
+// joins the organization the admin acts in as a member.
 func (uc *AdminUseCase) CreateUser(ctx context.Context, userID int64, req *dto.AdminCreateUserRequest) (*dto.RegisterResponse, error) {
 	role := entity.UserRoleUser
 	if req.Role == "admin" {
@@ -59,13 +71,14 @@ func (uc *AdminUseCase) CreateUser(ctx context.Context, userID int64, req *dto.A
 		return nil, ErrInvalidPassword
 	}
 
-	// Check if username already exists
-	if _, err := uc.userRepo.FindByUsername(ctx, req.Username); err == nil {
+	// Check if username already exists; usernames and emails are unique
+	// across organizations
+	if _, err := uc.userRepo.FindByUsername(tenant.Unscoped(ctx), req.Username); err == nil {
 		return nil, ErrUsernameExists
 	}
 
 	// Check if email already exists
-	if _, err := uc.userRepo.FindByEmail(ctx, req.Email); err == nil {
+	if _, err := uc.userRepo.FindByEmail(tenant.Unscoped(ctx), req.Email); err == nil {
 		return nil, ErrEmailExists
 	}
 
@@ -86,9 +99,21 @@ func (uc *AdminUseCase) CreateUser(ctx context.Context, userID int64, req *dto.A
 		UpdatedAt:    now,
 	}
 
-	// Save to database
-	if err := uc.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	// Save to database and record event
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if tenantID, ok := tenant.FromContext(ctx); ok {
+			member := &entity.OrganizationMember{OrganizationID: tenantID, UserID: user.ID, Role: entity.OrganizationRoleMember}
+			if err := uc.orgRepo.AddMember(ctx, member); err != nil {
+				return fmt.Errorf("failed to add member: %w", err)
+			}
+		}
+		return recordEvent(ctx, uc.outboxRepo, event.UserCreated, user.ID, "user", user.ID, dto.ToUserResponse(user))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return response
@@ -132,17 +157,23 @@ func (uc *AdminUseCase) GetUser(ctx context.Context, id int64) (*dto.UserRespons
 		return nil, ErrUserNotFound
 	}
 
-	return &dto.UserResponse{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Role:     string(user.Role),
-	}, nil
+	response := dto.ToUserResponse(user)
+	return &response, nil
 }
 
 // DeleteUser deletes a user by ID (admin only)
 func (uc *AdminUseCase) DeleteUser(ctx context.Context, id int64) error {
-	return uc.userRepo.Delete(ctx, id)
+	user, err := uc.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.userRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return recordEvent(ctx, uc.outboxRepo, event.UserDeleted, user.ID, "user", user.ID, dto.ToUserResponse(user))
+	})
 }
 
 // ListAllTodos lists all todos from all users with pagination and filters (admin only)
@@ -156,6 +187,15 @@ func (uc *AdminUseCase) ListAllTodos(ctx context.Context, page, limit int, statu
 
 	offset := (page - 1) * limit
 
+	if priority != nil {
+		parsed, ok := entity.ParsePriority(*priority)
+		if !ok {
+			return nil, ErrInvalidPriority
+		}
+		value := string(parsed)
+		priority = &value
+	}
+
 	// Get todos with filters (without user ID filter)
 	todos, err := uc.todoRepo.FindByFilters(ctx, status, priority, offset, limit)
 	if err != nil {
@@ -182,7 +222,17 @@ func (uc *AdminUseCase) ListAllTodos(ctx context.Context, page, limit int, statu
 
 // DeleteAnyTodo deletes any todo by ID regardless of ownership (admin only)
 func (uc *AdminUseCase) DeleteAnyTodo(ctx context.Context, id int64) error {
-	return uc.todoRepo.Delete(ctx, id)
+	todo, err := uc.todoRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return recordEvent(ctx, uc.outboxRepo, event.TodoDeleted, todo.UserID, "todo", todo.ID, dto.ToTodoResponse(todo))
+	})
 }
 
 // countTotalUsers counts total users
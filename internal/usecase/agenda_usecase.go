@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+const (
+	agendaRangeToday = "today"
+	agendaRangeWeek  = "week"
+
+	agendaReasonOverdue   = "overdue"
+	agendaReasonDue       = "due"
+	agendaReasonScheduled = "scheduled"
+)
+
+// agendaRangeDays is the number of days listed per agenda range
+var agendaRangeDays = map[string]int{
+	agendaRangeToday: 1,
+	agendaRangeWeek:  7,
+}
+
+// AgendaUseCase assembles a user's todos into a day-by-day agenda
+type AgendaUseCase struct {
+	todoRepo  repository.TodoRepository
+	userRepo  repository.UserRepository
+	todoCache *cache.TodoCache
+}
+
+// NewAgendaUseCase creates a new agenda use case. todoCache may be nil.
+func NewAgendaUseCase(todoRepo repository.TodoRepository, userRepo repository.UserRepository, todoCache *cache.TodoCache) *AgendaUseCase {
+	return &AgendaUseCase{
+		todoRepo:  todoRepo,
+		userRepo:  userRepo,
+		todoCache: todoCache,
+	}
+}
+
+// GetAgenda returns a user's open todos for today, or for the coming week,
+// grouped by day in the user's timezone. Todos still open after their due
+// date are carried forward to today.
+func (uc *AgendaUseCase) GetAgenda(ctx context.Context, userID int64, rangeName string) (*dto.AgendaResponse, error) {
+	if _, ok := agendaRangeDays[rangeName]; !ok {
+		rangeName = agendaRangeToday
+	}
+
+	loc, err := loadLocation(ctx, uc.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := entity.DateOf(now.In(loc))
+	from := today.StartIn(loc)
+	to := today.AddDays(agendaRangeDays[rangeName] - 1).EndIn(loc)
+
+	var todos []*entity.Todo
+	if uc.todoCache != nil {
+		todos, err = uc.todoCache.GetAgenda(ctx, userID, from, to)
+	} else {
+		todos, err = uc.todoRepo.FindPlannedByUserID(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAgendaResponse(todos, rangeName, loc, now), nil
+}
+
+// buildAgendaResponse places todos on the days of the range starting today
+// in loc. A todo is listed once per day: on the day it is due, on the day it
+// is scheduled for, and on today when its due date has passed. Todos that
+// are done or snoozed at now are left out. Todos have no recurrence rule, so
+// there are no projected occurrences to place.
+func buildAgendaResponse(todos []*entity.Todo, rangeName string, loc *time.Location, now time.Time) *dto.AgendaResponse {
+	today := entity.DateOf(now.In(loc))
+	days := agendaRangeDays[rangeName]
+	from := today.StartIn(loc)
+	to := today.AddDays(days - 1).EndIn(loc)
+
+	type agendaEntry struct {
+		todo   *entity.Todo
+		reason string
+		at     time.Time
+	}
+	entries := make(map[entity.Date]map[int64]agendaEntry, days)
+	place := func(todo *entity.Todo, day entity.Date, reason string, at time.Time) {
+		if entries[day] == nil {
+			entries[day] = make(map[int64]agendaEntry)
+		}
+		// A todo due on the day it is scheduled for is listed as due
+		if existing, ok := entries[day][todo.ID]; ok && agendaReasonRank(existing.reason) <= agendaReasonRank(reason) {
+			return
+		}
+		entries[day][todo.ID] = agendaEntry{todo, reason, at}
+	}
+
+	for _, todo := range todos {
+		if todo.IsDone() || todo.IsSnoozed(now) {
+			continue
+		}
+		if todo.DueDate != nil {
+			switch {
+			case todo.DueDate.Before(from):
+				place(todo, today, agendaReasonOverdue, *todo.DueDate)
+			case !todo.DueDate.After(to):
+				place(todo, entity.DateOf(todo.DueDate.In(loc)), agendaReasonDue, *todo.DueDate)
+			}
+		}
+		if todo.ScheduledFor != nil && !todo.ScheduledFor.Before(from) && !todo.ScheduledFor.After(to) {
+			place(todo, entity.DateOf(todo.ScheduledFor.In(loc)), agendaReasonScheduled, *todo.ScheduledFor)
+		}
+	}
+
+	response := &dto.AgendaResponse{
+		Range:    rangeName,
+		Timezone: loc.String(),
+		From:     from,
+		To:       to,
+		Days:     make([]dto.AgendaDay, days),
+	}
+	for i := range response.Days {
+		day := today.AddDays(i)
+		dayEntries := make([]agendaEntry, 0, len(entries[day]))
+		for _, entry := range entries[day] {
+			dayEntries = append(dayEntries, entry)
+		}
+
+		// Overdue todos first, then by time of day and priority
+		sort.Slice(dayEntries, func(i, j int) bool {
+			a, b := dayEntries[i], dayEntries[j]
+			if (a.reason == agendaReasonOverdue) != (b.reason == agendaReasonOverdue) {
+				return a.reason == agendaReasonOverdue
+			}
+			if !a.at.Equal(b.at) {
+				return a.at.Before(b.at)
+			}
+			if a.todo.Priority.Level() != b.todo.Priority.Level() {
+				return a.todo.Priority.Level() < b.todo.Priority.Level()
+			}
+			return a.todo.ID < b.todo.ID
+		})
+
+		items := make([]dto.AgendaItem, len(dayEntries))
+		for j, entry := range dayEntries {
+			items[j] = dto.AgendaItem{Reason: entry.reason, Todo: dto.ToTodoResponse(entry.todo)}
+		}
+		response.Days[i] = dto.AgendaDay{Date: day.String(), Items: items}
+	}
+
+	return response
+}
+
+// agendaReasonRank orders the reasons a todo is on a day, most pressing first
+func agendaReasonRank(reason string) int {
+	switch reason {
+	case agendaReasonOverdue:
+		return 0
+	case agendaReasonDue:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plannedTodoRepository serves FindPlannedByUserID from memory
+type plannedTodoRepository struct {
+	repository.TodoRepository
+	todos []*entity.Todo
+}
+
+func (r *plannedTodoRepository) FindPlannedByUserID(ctx context.Context, userID int64) ([]*entity.Todo, error) {
+	return r.todos, nil
+}
+
+func TestBuildAgendaResponse(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// 23:30 on Saturday 2024-03-09 in New York, already Sunday in UTC
+	now := time.Date(2024, 3, 10, 4, 30, 0, 0, time.UTC)
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2024, 3, day, hour, 0, 0, 0, newYork)
+		return &t
+	}
+	snoozedUntil := now.Add(time.Hour)
+
+	todos := []*entity.Todo{
+		{ID: 1, Title: "Overdue", DueDate: at(7, 17), Priority: entity.TodoPriorityP2},
+		{ID: 2, Title: "Due tonight", DueDate: at(9, 23), Priority: entity.TodoPriorityP2},
+		{ID: 3, Title: "Scheduled monday, due friday", ScheduledFor: at(11, 9), DueDate: at(15, 17), Priority: entity.TodoPriorityP2},
+		{ID: 4, Title: "Scheduled and due sunday", ScheduledFor: at(10, 9), DueDate: at(10, 17), Priority: entity.TodoPriorityP2},
+		{ID: 5, Title: "Done", DueDate: at(9, 12), StatusCategory: entity.StatusCategoryDone},
+		{ID: 6, Title: "Snoozed", DueDate: at(9, 12), SnoozedUntil: &snoozedUntil},
+		{ID: 7, Title: "Next month", DueDate: at(30, 12)},
+		{ID: 8, Title: "Missed plan", ScheduledFor: at(8, 9)},
+	}
+
+	agenda := buildAgendaResponse(todos, agendaRangeToday, newYork, now)
+	require.Len(t, agenda.Days, 1)
+	assert.Equal(t, "2024-03-09", agenda.Days[0].Date)
+	assert.Equal(t, "America/New_York", agenda.Timezone)
+	assert.Equal(t, []int64{1, 2}, agendaIDs(agenda.Days[0]))
+	assert.Equal(t, []string{agendaReasonOverdue, agendaReasonDue}, agendaReasons(agenda.Days[0]))
+
+	// The week spans the switch to daylight saving time on 2024-03-10
+	agenda = buildAgendaResponse(todos, agendaRangeWeek, newYork, now)
+	require.Len(t, agenda.Days, 7)
+	assert.Equal(t, "2024-03-15", agenda.Days[6].Date)
+	assert.Equal(t, time.Date(2024, 3, 9, 5, 0, 0, 0, time.UTC), agenda.From.UTC())
+	assert.Equal(t, time.Date(2024, 3, 16, 3, 59, 59, 0, time.UTC), agenda.To.UTC())
+
+	assert.Equal(t, []int64{4}, agendaIDs(agenda.Days[1]))
+	assert.Equal(t, []string{agendaReasonDue}, agendaReasons(agenda.Days[1]))
+	assert.Equal(t, []int64{3}, agendaIDs(agenda.Days[2]))
+	assert.Equal(t, []string{agendaReasonScheduled}, agendaReasons(agenda.Days[2]))
+	assert.Empty(t, agenda.Days[3].Items)
+	assert.Equal(t, []int64{3}, agendaIDs(agenda.Days[6]))
+	assert.Equal(t, []string{agendaReasonDue}, agendaReasons(agenda.Days[6]))
+}
+
+func TestGetAgenda_WithoutCache(t *testing.T) {
+	due := time.Now().Add(-48 * time.Hour)
+	repo := &plannedTodoRepository{todos: []*entity.Todo{{ID: 1, UserID: 7, DueDate: &due}}}
+	users := &memoryUserRepository{users: map[int64]*entity.User{7: {ID: 7, Timezone: "Europe/Paris"}}}
+	uc := NewAgendaUseCase(repo, users, nil)
+
+	agenda, err := uc.GetAgenda(context.Background(), 7, "")
+	require.NoError(t, err)
+	assert.Equal(t, agendaRangeToday, agenda.Range)
+	assert.Equal(t, "Europe/Paris", agenda.Timezone)
+	require.Len(t, agenda.Days, 1)
+	assert.Equal(t, []string{agendaReasonOverdue}, agendaReasons(agenda.Days[0]))
+}
+
+func agendaIDs(day dto.AgendaDay) []int64 {
+	ids := make([]int64, len(day.Items))
+	for i, item := range day.Items {
+		ids[i] = item.Todo.ID
+	}
+	return ids
+}
+
+func agendaReasons(day dto.AgendaDay) []string {
+	reasons := make([]string, len(day.Items))
+	for i, item := range day.Items {
+		reasons[i] = item.Reason
+	}
+	return reasons
+}
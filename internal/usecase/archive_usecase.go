@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+// ArchiveUseCase moves todos that were completed long ago to the archive,
+// keeping the todos table and the todo caches small
+type ArchiveUseCase struct {
+	todoRepo     repository.TodoRepository
+	todoCache    *cache.TodoCache
+	txManager    repository.TransactionManager
+	outboxRepo   repository.OutboxRepository
+	archiveAfter time.Duration
+	batchSize    int
+}
+
+// NewArchiveUseCase creates a new archive use case. Todos are archived once
+// they have been completed for archiveAfter.
+func NewArchiveUseCase(
+	todoRepo repository.TodoRepository,
+	todoCache *cache.TodoCache,
+	txManager repository.TransactionManager,
+	outboxRepo repository.OutboxRepository,
+	archiveAfter time.Duration,
+	batchSize int,
+) *ArchiveUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	return &ArchiveUseCase{
+		todoRepo:     todoRepo,
+		todoCache:    todoCache,
+		txManager:    txManager,
+		outboxRepo:   outboxRepo,
+		archiveAfter: archiveAfter,
+		batchSize:    batchSize,
+	}
+}
+
+// ArchiveCompleted archives one batch of todos completed before now minus
+// the archive delay, emitting todo.archived for each. It returns the number
+// of todos archived.
+func (uc *ArchiveUseCase) ArchiveCompleted(ctx context.Context, now time.Time) (int, error) {
+	// The job works through the todos of every tenant
+	ctx = tenant.Unscoped(ctx)
+
+	todos, err := uc.todoRepo.FindCompletedBefore(ctx, now.Add(-uc.archiveAfter), uc.batchSize)
+	if err != nil || len(todos) == 0 {
+		return 0, err
+	}
+
+	ids := make([]int64, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+		todo.ArchivedAt = &now
+	}
+
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoRepo.Archive(ctx, ids, now); err != nil {
+			return err
+		}
+		for _, todo := range todos {
+			// Record each event in the tenant of its todo
+			response := dto.ToTodoResponse(todo)
+			if err := recordEvent(tenant.WithID(ctx, todo.OrganizationID), uc.outboxRepo, event.TodoArchived, todo.UserID, "todo", todo.ID, response); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Update cache of each todo's own tenant; the job spans tenants
+	if uc.todoCache != nil {
+		for _, todo := range todos {
+			if err := uc.todoCache.DeleteTodo(tenant.WithID(ctx, todo.OrganizationID), todo); err != nil {
+				// Log error but don't fail the job
+				// In production, use proper logging
+			}
+		}
+	}
+
+	return len(todos), nil
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// archiveTodoRepository serves FindCompletedBefore from memory and records archived IDs
+type archiveTodoRepository struct {
+	repository.TodoRepository
+	todos    []*entity.Todo
+	before   time.Time
+	archived []int64
+}
+
+func (r *archiveTodoRepository) FindCompletedBefore(ctx context.Context, before time.Time, limit int) ([]*entity.Todo, error) {
+	r.before = before
+	var todos []*entity.Todo
+	for _, todo := range r.todos {
+		if todo.CompletedAt != nil && todo.CompletedAt.Before(before) && len(todos) < limit {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+func (r *archiveTodoRepository) Archive(ctx context.Context, ids []int64, archivedAt time.Time) error {
+	r.archived = append(r.archived, ids...)
+	return nil
+}
+
+func TestArchiveCompleted(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	longAgo := now.AddDate(0, 0, -45)
+	recently := now.AddDate(0, 0, -2)
+
+	repo := &archiveTodoRepository{todos: []*entity.Todo{
+		{ID: 1, UserID: 7, Status: entity.TodoStatusCompleted, CompletedAt: &longAgo},
+		{ID: 2, UserID: 7, Status: entity.TodoStatusCompleted, CompletedAt: &recently},
+		{ID: 3, UserID: 8, Status: entity.TodoStatusCompleted, CompletedAt: &longAgo},
+	}}
+	outbox := &memoryOutbox{}
+
+	uc := NewArchiveUseCase(repo, nil, nil, outbox, 30*24*time.Hour, 100)
+
+	archived, err := uc.ArchiveCompleted(context.Background(), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, archived)
+	assert.Equal(t, now.AddDate(0, 0, -30), repo.before)
+	assert.Equal(t, []int64{1, 3}, repo.archived)
+	assert.Equal(t, []string{"todo.archived", "todo.archived"}, outbox.types())
+	assert.Equal(t, &now, repo.todos[0].ArchivedAt)
+	assert.Nil(t, repo.todos[1].ArchivedAt)
+}
+
+func TestArchiveCompleted_NothingToArchive(t *testing.T) {
+	repo := &archiveTodoRepository{}
+	outbox := &memoryOutbox{}
+
+	uc := NewArchiveUseCase(repo, nil, nil, outbox, 30*24*time.Hour, 100)
+
+	archived, err := uc.ArchiveCompleted(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, archived)
+	assert.Empty(t, repo.archived)
+	assert.Empty(t, outbox.messages)
+}
@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/mail"
+)
+
+var (
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+)
+
+// digestWeekDays is the number of days, today included, listed as due this week
+const digestWeekDays = 7
+
+// DigestUseCase sends the opt-in digest email summarizing a user's overdue
+// todos, todos due today and this week, and yesterday's completions
+type DigestUseCase struct {
+	userRepo       repository.UserRepository
+	todoRepo       repository.TodoRepository
+	sender         mail.Sender
+	unsubscribeURL string
+	secret         []byte
+	batchSize      int
+}
+
+// NewDigestUseCase creates a new digest use case. Unsubscribe links point to
+// unsubscribeURL with a token signed with secret.
+func NewDigestUseCase(
+	userRepo repository.UserRepository,
+	todoRepo repository.TodoRepository,
+	sender mail.Sender,
+	unsubscribeURL string,
+	secret string,
+	batchSize int,
+) *DigestUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	return &DigestUseCase{
+		userRepo:       userRepo,
+		todoRepo:       todoRepo,
+		sender:         sender,
+		unsubscribeURL: unsubscribeURL,
+		secret:         []byte(secret),
+		batchSize:      batchSize,
+	}
+}
+
+// SendDue sends the digest to every subscriber whose digest is due at now in
+// their timezone. Digests with nothing to list are skipped but still count as
+// sent for the day. A user whose delivery fails is retried on the next run.
+// It returns the number of digests sent and the first delivery error.
+func (uc *DigestUseCase) SendDue(ctx context.Context, now time.Time) (int, error) {
+	// A digest lists the user's todos in every organization
+	ctx = tenant.Unscoped(ctx)
+
+	sent := 0
+	var firstErr error
+	var afterID int64
+
+	for {
+		users, err := uc.userRepo.FindDigestSubscribers(ctx, afterID, uc.batchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, user := range users {
+			afterID = user.ID
+			if !user.DigestDue(now) {
+				continue
+			}
+
+			delivered, err := uc.sendDigest(ctx, user, now)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to send digest to user %d: %w", user.ID, err)
+				}
+				continue
+			}
+			if delivered {
+				sent++
+			}
+
+			if err := uc.userRepo.MarkDigestSent(ctx, user.ID, now); err != nil {
+				return sent, err
+			}
+		}
+
+		if len(users) < uc.batchSize {
+			return sent, firstErr
+		}
+	}
+}
+
+// sendDigest builds and sends a user's digest, reporting whether there was
+// anything to send
+func (uc *DigestUseCase) sendDigest(ctx context.Context, user *entity.User, now time.Time) (bool, error) {
+	digest, err := uc.buildDigest(ctx, user, now)
+	if err != nil {
+		return false, err
+	}
+	if digest.Empty() {
+		return false, nil
+	}
+
+	msg, err := mail.NewDigestMessage(user.Email, digest)
+	if err != nil {
+		return false, err
+	}
+	if err := uc.sender.Send(ctx, msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// buildDigest gathers a user's digest at now in the user's timezone
+func (uc *DigestUseCase) buildDigest(ctx context.Context, user *entity.User, now time.Time) (*mail.Digest, error) {
+	loc := user.Location()
+	today := entity.DateOf(now.In(loc))
+	todayStart := today.StartIn(loc)
+	todayEnd := today.EndIn(loc)
+	weekEnd := today.AddDays(digestWeekDays - 1).EndIn(loc)
+
+	planned, err := uc.todoRepo.FindPlannedByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := uc.todoRepo.FindCompletedBetween(ctx, user.ID, today.AddDays(-1).StartIn(loc), todayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// Most pressing first: earliest due date, then highest priority
+	sort.SliceStable(planned, func(i, j int) bool {
+		a, b := planned[i], planned[j]
+		if a.DueDate != nil && b.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+			return a.DueDate.Before(*b.DueDate)
+		}
+		return a.Priority.Level() < b.Priority.Level()
+	})
+
+	digest := &mail.Digest{
+		Username:       user.Username,
+		Date:           today.StartIn(loc).Format("Monday, January 2"),
+		Timezone:       loc.String(),
+		UnsubscribeURL: uc.UnsubscribeURL(user.ID),
+	}
+	for _, todo := range planned {
+		if todo.DueDate == nil || todo.IsDone() || todo.IsSnoozed(now) {
+			continue
+		}
+		due := todo.DueDate.In(loc)
+		switch {
+		case due.Before(todayStart):
+			digest.Overdue = append(digest.Overdue, digestTodo(todo, "due "+formatDigestDay(due, todo.AllDay)))
+		case !due.After(todayEnd):
+			when := "today"
+			if !todo.AllDay {
+				when = "at " + due.Format("15:04")
+			}
+			digest.DueToday = append(digest.DueToday, digestTodo(todo, when))
+		case !due.After(weekEnd):
+			digest.DueThisWeek = append(digest.DueThisWeek, digestTodo(todo, "due "+formatDigestDay(due, todo.AllDay)))
+		}
+	}
+	for _, todo := range completed {
+		digest.CompletedYesterday = append(digest.CompletedYesterday, digestTodo(todo, "at "+todo.CompletedAt.In(loc).Format("15:04")))
+	}
+
+	return digest, nil
+}
+
+// digestTodo converts a todo to a digest line
+func digestTodo(todo *entity.Todo, when string) mail.DigestTodo {
+	return mail.DigestTodo{Title: todo.Title, Priority: string(todo.Priority), When: when}
+}
+
+// formatDigestDay formats a due date as a day, with its time of day unless
+// the todo is due all day
+func formatDigestDay(due time.Time, allDay bool) string {
+	if allDay {
+		return due.Format("Mon, Jan 2")
+	}
+	return due.Format("Mon, Jan 2 15:04")
+}
+
+// UnsubscribeURL returns the one-click link that turns off a user's digest
+func (uc *DigestUseCase) UnsubscribeURL(userID int64) string {
+	separator := "?"
+	if strings.Contains(uc.unsubscribeURL, "?") {
+		separator = "&"
+	}
+	return uc.unsubscribeURL + separator + "token=" + url.QueryEscape(uc.unsubscribeToken(userID))
+}
+
+// Unsubscribe turns off the digest of the user an unsubscribe token was
+// issued to
+func (uc *DigestUseCase) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := uc.parseUnsubscribeToken(token)
+	if err != nil {
+		return err
+	}
+
+	// Unsubscribe links are followed without signing in
+	ctx = tenant.Unscoped(ctx)
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.DigestFrequency == entity.DigestFrequencyOff {
+		return nil
+	}
+	user.DigestFrequency = entity.DigestFrequencyOff
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// unsubscribeToken signs a user ID as "<user id>.<signature>". Tokens do not
+// expire, so links in old digests keep working.
+func (uc *DigestUseCase) unsubscribeToken(userID int64) string {
+	id := strconv.FormatInt(userID, 10)
+	return id + "." + uc.signUnsubscribe(id)
+}
+
+// parseUnsubscribeToken verifies a token and returns the user ID it was
+// issued to
+func (uc *DigestUseCase) parseUnsubscribeToken(token string) (int64, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+	if !hmac.Equal([]byte(uc.signUnsubscribe(parts[0])), []byte(parts[1])) {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+	return userID, nil
+}
+
+// signUnsubscribe computes the URL-safe HMAC-SHA256 of an unsubscribe token's
+// user ID
+func (uc *DigestUseCase) signUnsubscribe(id string) string {
+	mac := hmac.New(sha256.New, uc.secret)
+	mac.Write([]byte("digest-unsubscribe:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/mail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// digestUserRepository serves digest subscribers from memory
+type digestUserRepository struct {
+	memoryUserRepository
+}
+
+func (r *digestUserRepository) FindDigestSubscribers(ctx context.Context, afterID int64, limit int) ([]*entity.User, error) {
+	var users []*entity.User
+	for _, user := range r.users {
+		if user.ID > afterID && user.DigestFrequency != entity.DigestFrequencyOff {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *digestUserRepository) MarkDigestSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	r.users[userID].DigestSentAt = &sentAt
+	return nil
+}
+
+func (r *digestUserRepository) Update(ctx context.Context, user *entity.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+// digestTodoRepository serves the planned and completed todos of each user
+type digestTodoRepository struct {
+	repository.TodoRepository
+	todos []*entity.Todo
+}
+
+func (r *digestTodoRepository) FindPlannedByUserID(ctx context.Context, userID int64) ([]*entity.Todo, error) {
+	var todos []*entity.Todo
+	for _, todo := range r.todos {
+		if todo.UserID == userID && !todo.IsDone() && (todo.DueDate != nil || todo.ScheduledFor != nil) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+func (r *digestTodoRepository) FindCompletedBetween(ctx context.Context, userID int64, from, to time.Time) ([]*entity.Todo, error) {
+	var todos []*entity.Todo
+	for _, todo := range r.todos {
+		if todo.UserID == userID && todo.IsDone() && !todo.CompletedAt.Before(from) && todo.CompletedAt.Before(to) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+// recordingSender keeps sent messages
+type recordingSender struct {
+	messages []*mail.Message
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg *mail.Message) error {
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func TestDigest_SendDue(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	// Monday 2024-03-11: 07:30 in Paris, 02:30 in New York, 15:30 in Tokyo
+	now := time.Date(2024, 3, 11, 6, 30, 0, 0, time.UTC)
+	at := func(day, hour, minute int) *time.Time {
+		t := time.Date(2024, 3, day, hour, minute, 0, 0, paris)
+		return &t
+	}
+
+	users := &digestUserRepository{memoryUserRepository{users: map[int64]*entity.User{
+		1: {ID: 1, Username: "ann", Email: "ann@example.com", Timezone: "Europe/Paris", DigestFrequency: entity.DigestFrequencyDaily, DigestHour: 7},
+		2: {ID: 2, Username: "bob", Email: "bob@example.com", Timezone: "America/New_York", DigestFrequency: entity.DigestFrequencyDaily, DigestHour: 7},
+		3: {ID: 3, Username: "cat", Email: "cat@example.com", Timezone: "Asia/Tokyo", DigestFrequency: entity.DigestFrequencyWeekly, DigestHour: 7, DigestWeekday: time.Monday},
+		4: {ID: 4, Username: "dan", Email: "dan@example.com", DigestFrequency: entity.DigestFrequencyOff},
+	}}}
+	todos := &digestTodoRepository{todos: []*entity.Todo{
+		{ID: 1, UserID: 1, Title: "Overdue report", DueDate: at(8, 17, 0), Priority: entity.TodoPriorityP1},
+		{ID: 2, UserID: 1, Title: "Call the bank", DueDate: at(11, 18, 0), Priority: entity.TodoPriorityP2},
+		{ID: 3, UserID: 1, Title: "Renew passport", DueDate: at(13, 23, 59), AllDay: true, Priority: entity.TodoPriorityP2},
+		{ID: 4, UserID: 1, Title: "Next month", DueDate: at(30, 12, 0), Priority: entity.TodoPriorityP2},
+		{ID: 5, UserID: 1, Title: "Water plants", StatusCategory: entity.StatusCategoryDone, CompletedAt: at(10, 9, 15), Priority: entity.TodoPriorityP3},
+		{ID: 6, UserID: 1, Title: "Done last week", StatusCategory: entity.StatusCategoryDone, CompletedAt: at(4, 9, 15), Priority: entity.TodoPriorityP3},
+		{ID: 7, UserID: 2, Title: "Not yet", DueDate: at(11, 18, 0), Priority: entity.TodoPriorityP2},
+	}}
+	sender := &recordingSender{}
+	uc := NewDigestUseCase(users, todos, sender, "https://todo.example.com/api/v1/digest/unsubscribe", "secret", 2)
+
+	sent, err := uc.SendDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	// Tokyo had nothing to list; it still counts as done for the day
+	require.Len(t, sender.messages, 1)
+	assert.NotNil(t, users.users[3].DigestSentAt)
+	assert.Nil(t, users.users[2].DigestSentAt)
+
+	msg := sender.messages[0]
+	assert.Equal(t, "ann@example.com", msg.To)
+	assert.Equal(t, "Your todo digest for Monday, March 11", msg.Subject)
+	assert.Contains(t, msg.Text, "Overdue (1):\n  - Overdue report [p1] due Fri, Mar 8 17:00")
+	assert.Contains(t, msg.Text, "Due today (1):\n  - Call the bank [p2] at 18:00")
+	assert.Contains(t, msg.Text, "Due this week (1):\n  - Renew passport [p2] due Wed, Mar 13\n")
+	assert.Contains(t, msg.Text, "Completed yesterday (1):\n  - Water plants [p3] at 09:15")
+	assert.NotContains(t, msg.Text, "Next month")
+	assert.NotContains(t, msg.Text, "Done last week")
+
+	// A second run the same day sends nothing more
+	sent, err = uc.SendDue(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Len(t, sender.messages, 1)
+}
+
+func TestDigest_Unsubscribe(t *testing.T) {
+	users := &digestUserRepository{memoryUserRepository{users: map[int64]*entity.User{
+		7: {ID: 7, DigestFrequency: entity.DigestFrequencyWeekly},
+	}}}
+	uc := NewDigestUseCase(users, nil, nil, "https://todo.example.com/api/v1/digest/unsubscribe", "secret", 0)
+
+	link := uc.UnsubscribeURL(7)
+	require.True(t, strings.HasPrefix(link, "https://todo.example.com/api/v1/digest/unsubscribe?token=7."))
+	token := strings.TrimPrefix(link, "https://todo.example.com/api/v1/digest/unsubscribe?token=")
+
+	// Tokens are bound to their user and secret
+	other := NewDigestUseCase(users, nil, nil, "", "other-secret", 0)
+	assert.Equal(t, ErrInvalidUnsubscribeToken, other.Unsubscribe(context.Background(), token))
+	assert.Equal(t, ErrInvalidUnsubscribeToken, uc.Unsubscribe(context.Background(), "8"+strings.TrimPrefix(token, "7")))
+	assert.Equal(t, ErrInvalidUnsubscribeToken, uc.Unsubscribe(context.Background(), "garbage"))
+	assert.Equal(t, entity.DigestFrequencyWeekly, users.users[7].DigestFrequency)
+
+	require.NoError(t, uc.Unsubscribe(context.Background(), token))
+	assert.Equal(t, entity.DigestFrequencyOff, users.users[7].DigestFrequency)
+}
+
+func TestUser_DigestDue(t *testing.T) {
+	// Sunday 2024-03-10 23:30 in New York is Monday 03:30 UTC
+	now := time.Date(2024, 3, 11, 3, 30, 0, 0, time.UTC)
+	sentEarlier := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	user := &entity.User{Timezone: "America/New_York", DigestFrequency: entity.DigestFrequencyWeekly, DigestHour: 7, DigestWeekday: time.Sunday}
+	assert.True(t, user.DigestDue(now))
+
+	user.DigestSentAt = &sentEarlier
+	assert.False(t, user.DigestDue(now))
+
+	user.DigestSentAt = nil
+	user.DigestWeekday = time.Monday
+	assert.False(t, user.DigestDue(now))
+
+	user.DigestFrequency = entity.DigestFrequencyOff
+	assert.False(t, user.DigestDue(now))
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+)
+
+// withinTransaction runs fn inside a database transaction. Without a
+// transaction manager fn runs directly, which keeps use cases usable in tests.
+func withinTransaction(ctx context.Context, txManager repository.TransactionManager, fn func(ctx context.Context) error) error {
+	if txManager == nil {
+		return fn(ctx)
+	}
+	return txManager.WithinTransaction(ctx, fn)
+}
+
+// recordEvent builds a domain event and stores it in the outbox. Call it with
+// the context of the transaction that makes the change, so the event is
+// committed if and only if the change is; the outbox relay publishes it later.
+func recordEvent(ctx context.Context, outboxRepo repository.OutboxRepository, eventType event.Type, userID int64, aggregateType string, aggregateID int64, data interface{}) error {
+	if outboxRepo == nil {
+		return nil
+	}
+
+	evt, err := event.New(eventType, userID, aggregateType, aggregateID, data)
+	if err != nil {
+		return err
+	}
+	evt.OrganizationID, _ = tenant.FromContext(ctx)
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	return outboxRepo.Create(ctx, &entity.OutboxMessage{
+		EventID:       evt.ID,
+		EventType:     string(evt.Type),
+		AggregateType: evt.AggregateType,
+		AggregateID:   evt.AggregateID,
+		UserID:        evt.UserID,
+		Payload:       string(payload),
+		CreatedAt:     evt.OccurredAt,
+	})
+}
@@ -0,0 +1,337 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/mail"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+var (
+	ErrNotificationNotFound     = errors.New("notification not found")
+	ErrInvalidNotificationEvent = errors.New("invalid notification event type")
+)
+
+// notificationDefault is where events of a type are delivered to users who
+// have not set a preference for it
+type notificationDefault struct {
+	inbox bool
+	email bool
+}
+
+// notificationTypes lists the event types users are notified of, in the
+// order preferences are listed
+var notificationTypes = []event.Type{
+	event.TodoMentioned,
+	event.TodoAssigned,
+	event.TodoUnassigned,
+	event.TodoOverdue,
+}
+
+// notificationDefaults are the default preferences of notificationTypes
+var notificationDefaults = map[event.Type]notificationDefault{
+	event.TodoMentioned:  {inbox: true, email: true},
+	event.TodoAssigned:   {inbox: true, email: true},
+	event.TodoUnassigned: {inbox: true},
+	event.TodoOverdue:    {inbox: true},
+}
+
+// NotificationUseCase turns events addressed to a user into notifications
+// in their inbox and, if they wish, emails
+type NotificationUseCase struct {
+	notificationRepo repository.NotificationRepository
+	preferenceRepo   repository.NotificationPreferenceRepository
+	userRepo         repository.UserRepository
+	sender           mail.Sender
+}
+
+// NewNotificationUseCase creates a new notification use case. Without a
+// sender, notifications are not emailed.
+func NewNotificationUseCase(
+	notificationRepo repository.NotificationRepository,
+	preferenceRepo repository.NotificationPreferenceRepository,
+	userRepo repository.UserRepository,
+	sender mail.Sender,
+) *NotificationUseCase {
+	return &NotificationUseCase{
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		userRepo:         userRepo,
+		sender:           sender,
+	}
+}
+
+// HandleEvent notifies the user an event is addressed to; subscribe it to
+// the event bus
+func (uc *NotificationUseCase) HandleEvent(ctx context.Context, evt *event.Event) {
+	if err := uc.Notify(ctx, evt); err != nil {
+		log.Printf("Warning: failed to notify user %d of event %s (%s): %v", evt.UserID, evt.ID, evt.Type, err)
+	}
+}
+
+// Notify delivers an event to the inbox and email of the user it is
+// addressed to, as their preferences say. Events are delivered at least once,
+// so an event already in the inbox is not emailed again. Events outside of
+// any organization have no inbox to land in.
+func (uc *NotificationUseCase) Notify(ctx context.Context, evt *event.Event) error {
+	if _, ok := notificationDefaults[evt.Type]; !ok {
+		return nil
+	}
+
+	preference, err := uc.preference(ctx, evt.UserID, evt.Type)
+	if err != nil {
+		return err
+	}
+	inbox := preference.Inbox && evt.OrganizationID != 0
+	email := preference.Email && uc.sender != nil
+	if !inbox && !email {
+		return nil
+	}
+
+	// Users are looked up across tenants; the event names its own
+	ctx = tenant.Unscoped(ctx)
+	notification, details, err := uc.buildNotification(ctx, evt)
+	if err != nil {
+		return err
+	}
+
+	if inbox {
+		created, err := uc.notificationRepo.Create(ctx, notification)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return nil
+		}
+	}
+
+	if email {
+		return uc.sendEmail(ctx, notification, details)
+	}
+	return nil
+}
+
+// buildNotification describes an event to the user it is addressed to,
+// returning the notification and an excerpt for the email
+func (uc *NotificationUseCase) buildNotification(ctx context.Context, evt *event.Event) (*entity.Notification, string, error) {
+	notification := &entity.Notification{
+		OrganizationID: evt.OrganizationID,
+		UserID:         evt.UserID,
+		EventID:        evt.ID,
+		EventType:      string(evt.Type),
+		AggregateType:  evt.AggregateType,
+		AggregateID:    evt.AggregateID,
+		CreatedAt:      evt.OccurredAt,
+	}
+
+	var details string
+	switch evt.Type {
+	case event.TodoMentioned:
+		var payload dto.TodoMentionEvent
+		if err := json.Unmarshal(evt.Data, &payload); err != nil {
+			return nil, "", err
+		}
+		notification.ActorID = &payload.MentionedBy
+		notification.Message = fmt.Sprintf("%s mentioned you in %q", uc.username(ctx, payload.MentionedBy), payload.Todo.Title)
+		details = payload.Todo.Description
+	case event.TodoAssigned, event.TodoUnassigned:
+		var payload dto.TodoAssignmentEvent
+		if err := json.Unmarshal(evt.Data, &payload); err != nil {
+			return nil, "", err
+		}
+		notification.ActorID = &payload.AssignedBy
+		format := "%s assigned you %q"
+		if evt.Type == event.TodoUnassigned {
+			format = "%s unassigned you from %q"
+		}
+		notification.Message = fmt.Sprintf(format, uc.username(ctx, payload.AssignedBy), payload.Todo.Title)
+	case event.TodoOverdue:
+		var payload dto.TodoResponse
+		if err := json.Unmarshal(evt.Data, &payload); err != nil {
+			return nil, "", err
+		}
+		notification.Message = fmt.Sprintf("%q is overdue", payload.Title)
+	}
+
+	return notification, details, nil
+}
+
+// username returns a user's username for a notification message
+func (uc *NotificationUseCase) username(ctx context.Context, userID int64) string {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "Someone"
+	}
+	return user.Username
+}
+
+// sendEmail emails a notification to its user
+func (uc *NotificationUseCase) sendEmail(ctx context.Context, notification *entity.Notification, details string) error {
+	user, err := uc.userRepo.FindByID(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	msg, err := mail.NewNotificationMessage(user.Email, &mail.Notification{
+		Username: user.Username,
+		Message:  notification.Message,
+		Details:  details,
+	})
+	if err != nil {
+		return err
+	}
+	return uc.sender.Send(ctx, msg)
+}
+
+// ListNotifications lists a user's notifications in the current
+// organization, newest first, with the number of unread ones
+func (uc *NotificationUseCase) ListNotifications(ctx context.Context, userID int64, req *dto.ListNotificationsRequest) (*dto.NotificationListResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	limit := req.Limit
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	notifications, total, err := uc.notificationRepo.FindByUserID(ctx, userID, req.Unread, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := uc.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &dto.NotificationListResponse{
+		Data:        dto.ToNotificationResponseList(notifications),
+		Page:        page,
+		Limit:       limit,
+		Total:       total,
+		TotalPages:  totalPages,
+		UnreadCount: unread,
+	}, nil
+}
+
+// MarkRead marks one of a user's notifications as read, returning the
+// number still unread
+func (uc *NotificationUseCase) MarkRead(ctx context.Context, id, userID int64) (*dto.MarkReadResponse, error) {
+	if err := uc.notificationRepo.MarkRead(ctx, userID, id, time.Now()); err != nil {
+		if errors.Is(err, repositoryImpl.ErrNotificationNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+
+	unread, err := uc.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MarkReadResponse{Marked: 1, UnreadCount: unread}, nil
+}
+
+// MarkAllRead marks all of a user's notifications in the current
+// organization as read
+func (uc *NotificationUseCase) MarkAllRead(ctx context.Context, userID int64) (*dto.MarkReadResponse, error) {
+	marked, err := uc.notificationRepo.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MarkReadResponse{Marked: marked}, nil
+}
+
+// GetPreferences lists where events of each notified type are delivered to
+// a user, defaults included
+func (uc *NotificationUseCase) GetPreferences(ctx context.Context, userID int64) ([]dto.NotificationPreferenceResponse, error) {
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]*entity.NotificationPreference, len(preferences))
+	for _, preference := range preferences {
+		set[preference.EventType] = preference
+	}
+
+	responses := make([]dto.NotificationPreferenceResponse, len(notificationTypes))
+	for i, eventType := range notificationTypes {
+		preference, ok := set[string(eventType)]
+		if !ok {
+			preference = defaultPreference(userID, eventType)
+		}
+		responses[i] = dto.NotificationPreferenceResponse{
+			EventType: preference.EventType,
+			Inbox:     preference.Inbox,
+			Email:     preference.Email,
+		}
+	}
+	return responses, nil
+}
+
+// UpdatePreferences sets where events of the given types are delivered to a
+// user
+func (uc *NotificationUseCase) UpdatePreferences(ctx context.Context, userID int64, req *dto.UpdateNotificationPreferencesRequest) ([]dto.NotificationPreferenceResponse, error) {
+	preferences := make([]*entity.NotificationPreference, len(req.Preferences))
+	for i, preference := range req.Preferences {
+		if _, ok := notificationDefaults[event.Type(preference.EventType)]; !ok {
+			return nil, ErrInvalidNotificationEvent
+		}
+		preferences[i] = &entity.NotificationPreference{
+			UserID:    userID,
+			EventType: preference.EventType,
+			Inbox:     *preference.Inbox,
+			Email:     *preference.Email,
+		}
+	}
+
+	if err := uc.preferenceRepo.Save(ctx, preferences); err != nil {
+		return nil, err
+	}
+	return uc.GetPreferences(ctx, userID)
+}
+
+// preference returns a user's preference for an event type, or its default
+func (uc *NotificationUseCase) preference(ctx context.Context, userID int64, eventType event.Type) (*entity.NotificationPreference, error) {
+	preferences, err := uc.preferenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, preference := range preferences {
+		if preference.EventType == string(eventType) {
+			return preference, nil
+		}
+	}
+	return defaultPreference(userID, eventType), nil
+}
+
+// defaultPreference returns the preference of users who have not set one
+// for an event type
+func defaultPreference(userID int64, eventType event.Type) *entity.NotificationPreference {
+	defaults := notificationDefaults[eventType]
+	return &entity.NotificationPreference{
+		UserID:    userID,
+		EventType: string(eventType),
+		Inbox:     defaults.inbox,
+		Email:     defaults.email,
+	}
+}
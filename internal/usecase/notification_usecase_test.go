@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryNotificationRepository keeps notifications in a slice, newest last
+type memoryNotificationRepository struct {
+	notifications []*entity.Notification
+}
+
+func (r *memoryNotificationRepository) Create(ctx context.Context, notification *entity.Notification) (bool, error) {
+	for _, n := range r.notifications {
+		if n.UserID == notification.UserID && n.EventID == notification.EventID {
+			return false, nil
+		}
+	}
+	notification.ID = int64(len(r.notifications) + 1)
+	r.notifications = append(r.notifications, notification)
+	return true, nil
+}
+
+func (r *memoryNotificationRepository) FindByUserID(ctx context.Context, userID int64, unreadOnly bool, offset, limit int) ([]*entity.Notification, int64, error) {
+	var found []*entity.Notification
+	for i := len(r.notifications) - 1; i >= 0; i-- {
+		n := r.notifications[i]
+		if n.UserID == userID && (!unreadOnly || !n.IsRead()) {
+			found = append(found, n)
+		}
+	}
+	return found, int64(len(found)), nil
+}
+
+func (r *memoryNotificationRepository) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	_, count, err := r.FindByUserID(ctx, userID, true, 0, 0)
+	return count, err
+}
+
+func (r *memoryNotificationRepository) MarkRead(ctx context.Context, userID, id int64, readAt time.Time) error {
+	for _, n := range r.notifications {
+		if n.ID == id && n.UserID == userID {
+			if !n.IsRead() {
+				n.ReadAt = &readAt
+			}
+			return nil
+		}
+	}
+	return repositoryImpl.ErrNotificationNotFound
+}
+
+func (r *memoryNotificationRepository) MarkAllRead(ctx context.Context, userID int64, readAt time.Time) (int64, error) {
+	var marked int64
+	for _, n := range r.notifications {
+		if n.UserID == userID && !n.IsRead() {
+			n.ReadAt = &readAt
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+// memoryNotificationPreferenceRepository keeps preferences by user and event type
+type memoryNotificationPreferenceRepository struct {
+	preferences map[int64]map[string]*entity.NotificationPreference
+}
+
+func (r *memoryNotificationPreferenceRepository) FindByUserID(ctx context.Context, userID int64) ([]*entity.NotificationPreference, error) {
+	var preferences []*entity.NotificationPreference
+	for _, preference := range r.preferences[userID] {
+		preferences = append(preferences, preference)
+	}
+	return preferences, nil
+}
+
+func (r *memoryNotificationPreferenceRepository) Save(ctx context.Context, preferences []*entity.NotificationPreference) error {
+	for _, preference := range preferences {
+		if r.preferences[preference.UserID] == nil {
+			r.preferences[preference.UserID] = make(map[string]*entity.NotificationPreference)
+		}
+		r.preferences[preference.UserID][preference.EventType] = preference
+	}
+	return nil
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func newEvent(t *testing.T, eventType event.Type, userID int64, data interface{}) *event.Event {
+	evt, err := event.New(eventType, userID, "todo", 1, data)
+	require.NoError(t, err)
+	evt.OrganizationID = 1
+	return evt
+}
+
+func TestNotificationUseCase_DeliversByPreference(t *testing.T) {
+	ctx := context.Background()
+	notifications := &memoryNotificationRepository{}
+	preferences := &memoryNotificationPreferenceRepository{preferences: map[int64]map[string]*entity.NotificationPreference{}}
+	users := &memoryUserRepository{users: map[int64]*entity.User{
+		7: {ID: 7, Username: "alice", Email: "alice@example.com"},
+		8: {ID: 8, Username: "bob", Email: "bob@example.com"},
+	}}
+	sender := &recordingSender{}
+	uc := NewNotificationUseCase(notifications, preferences, users, sender)
+
+	todo := dto.TodoResponse{ID: 1, UserID: 7, Title: "Plan offsite", Description: "@bob can you book the venue?"}
+
+	// Mentions land in the inbox and are emailed, once
+	mention := newEvent(t, event.TodoMentioned, 8, dto.TodoMentionEvent{MentionedUserID: 8, MentionedBy: 7, Todo: todo})
+	require.NoError(t, uc.Notify(ctx, mention))
+	require.NoError(t, uc.Notify(ctx, mention))
+	require.Len(t, notifications.notifications, 1)
+	assert.Equal(t, `alice mentioned you in "Plan offsite"`, notifications.notifications[0].Message)
+	require.Len(t, sender.messages, 1)
+	assert.Equal(t, "bob@example.com", sender.messages[0].To)
+	assert.Contains(t, sender.messages[0].Text, "@bob can you book the venue?")
+
+	// Users choose what is emailed and what lands in the inbox
+	_, err := uc.UpdatePreferences(ctx, 8, &dto.UpdateNotificationPreferencesRequest{Preferences: []dto.NotificationPreferenceRequest{
+		{EventType: "todo.created", Inbox: boolPtr(true), Email: boolPtr(true)},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidNotificationEvent)
+	prefs, err := uc.UpdatePreferences(ctx, 8, &dto.UpdateNotificationPreferencesRequest{Preferences: []dto.NotificationPreferenceRequest{
+		{EventType: "todo.assigned", Inbox: boolPtr(true), Email: boolPtr(false)},
+		{EventType: "todo.overdue", Inbox: boolPtr(false), Email: boolPtr(false)},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []dto.NotificationPreferenceResponse{
+		{EventType: "todo.mentioned", Inbox: true, Email: true},
+		{EventType: "todo.assigned", Inbox: true, Email: false},
+		{EventType: "todo.unassigned", Inbox: true, Email: false},
+		{EventType: "todo.overdue", Inbox: false, Email: false},
+	}, prefs)
+
+	require.NoError(t, uc.Notify(ctx, newEvent(t, event.TodoAssigned, 8, dto.TodoAssignmentEvent{AssigneeID: 8, AssignedBy: 7, Todo: todo})))
+	require.NoError(t, uc.Notify(ctx, newEvent(t, event.TodoOverdue, 8, todo)))
+	require.NoError(t, uc.Notify(ctx, newEvent(t, event.TodoUpdated, 8, todo)))
+	require.Len(t, notifications.notifications, 2)
+	assert.Equal(t, `alice assigned you "Plan offsite"`, notifications.notifications[1].Message)
+	assert.Len(t, sender.messages, 1)
+
+	// The inbox counts unread notifications
+	inbox, err := uc.ListNotifications(ctx, 8, &dto.ListNotificationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, inbox.Data, 2)
+	assert.Equal(t, "todo.assigned", inbox.Data[0].EventType)
+	assert.Equal(t, int64(2), inbox.UnreadCount)
+
+	read, err := uc.MarkRead(ctx, inbox.Data[0].ID, 8)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), read.UnreadCount)
+	_, err = uc.MarkRead(ctx, inbox.Data[0].ID, 7)
+	assert.ErrorIs(t, err, ErrNotificationNotFound)
+
+	read, err = uc.MarkAllRead(ctx, 8)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), read.Marked)
+	inbox, err = uc.ListNotifications(ctx, 8, &dto.ListNotificationsRequest{Unread: true})
+	require.NoError(t, err)
+	assert.Empty(t, inbox.Data)
+	assert.Equal(t, int64(0), inbox.UnreadCount)
+}
+
+func TestMentions(t *testing.T) {
+	assert.Equal(t, []string{"bob", "carol.smith"},
+		entity.Mentions("@bob, ask @carol.smith. Mail bob@example.com or @Bob"))
+	assert.Equal(t, []string{"dave"}, entity.NewMentions("@bob and @dave", "cc @BOB"))
+	assert.Empty(t, entity.Mentions("no one @ all"))
+}
+
+func TestTodoUseCase_RecordsMentions(t *testing.T) {
+	repo := &memoryTodoRepository{todos: map[int64]*entity.Todo{}}
+	users := &namedUserRepository{memoryUserRepository{users: map[int64]*entity.User{
+		7: {ID: 7, Username: "alice"},
+		8: {ID: 8, Username: "bob"},
+		9: {ID: 9, Username: "carol"},
+	}}}
+	outbox := &memoryOutbox{}
+	uc := NewTodoUseCase(repo, nil, &memoryTodoTagRepository{}, nil, nil, users, nil, nil, outbox, 0)
+	ctx := context.Background()
+
+	// Authors and unknown users are not notified
+	description := "@bob, book the venue; @alice and @nobody will check"
+	response, err := uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Plan offsite", Description: description})
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(event.TodoCreated), string(event.TodoMentioned)}, outbox.types())
+	assert.Equal(t, int64(8), outbox.messages[1].UserID)
+
+	// Only users newly mentioned in an edit are notified
+	outbox.messages = nil
+	description = "@bob, book the venue with @carol"
+	_, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{Description: &description})
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(event.TodoUpdated), string(event.TodoMentioned)}, outbox.types())
+	assert.Equal(t, int64(9), outbox.messages[1].UserID)
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+var (
+	ErrNoOrganization            = errors.New("user does not belong to any organization")
+	ErrNotOrganizationMember     = errors.New("not a member of this organization")
+	ErrOrganizationNameRequired  = errors.New("organization name is required")
+	ErrInvalidOrganizationRole   = errors.New("role must be owner, admin or member")
+	ErrMemberManagementForbidden = errors.New("insufficient permissions: organization owner or admin role required")
+	ErrOwnerManagementForbidden  = errors.New("insufficient permissions: only owners manage owners")
+	ErrLastOwner                 = errors.New("an organization needs at least one owner")
+	ErrMemberExists              = errors.New("user is already a member of this organization")
+	ErrMemberNotFound            = errors.New("member not found")
+)
+
+// OrganizationUseCase implements business logic for organizations and their
+// members. Owners and admins manage members; only owners manage owners, and
+// an organization always keeps at least one owner.
+type OrganizationUseCase struct {
+	orgRepo  repository.OrganizationRepository
+	userRepo repository.UserRepository
+}
+
+// NewOrganizationUseCase creates a new organization use case
+func NewOrganizationUseCase(orgRepo repository.OrganizationRepository, userRepo repository.UserRepository) *OrganizationUseCase {
+	return &OrganizationUseCase{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+	}
+}
+
+// ListOrganizations lists the organizations a user belongs to, flagging
+// currentID as the one their token acts in
+func (uc *OrganizationUseCase) ListOrganizations(ctx context.Context, userID, currentID int64) ([]dto.OrganizationResponse, error) {
+	memberships, err := uc.orgRepo.FindMemberships(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find organizations: %w", err)
+	}
+
+	responses := make([]dto.OrganizationResponse, len(memberships))
+	for i, member := range memberships {
+		responses[i] = dto.ToOrganizationResponse(member, currentID)
+	}
+	return responses, nil
+}
+
+// CreateOrganization creates an organization owned by a user
+func (uc *OrganizationUseCase) CreateOrganization(ctx context.Context, userID int64, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrOrganizationNameRequired
+	}
+
+	org := &entity.Organization{Name: name}
+	owner := &entity.OrganizationMember{UserID: userID, Role: entity.OrganizationRoleOwner, Organization: org}
+	if err := uc.orgRepo.Create(ctx, org, owner); err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	response := dto.ToOrganizationResponse(owner, 0)
+	return &response, nil
+}
+
+// ListMembers lists the members of an organization the user belongs to
+func (uc *OrganizationUseCase) ListMembers(ctx context.Context, orgID, userID int64) ([]dto.MemberResponse, error) {
+	if _, err := uc.membership(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := uc.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	responses := make([]dto.MemberResponse, len(members))
+	for i, member := range members {
+		responses[i] = dto.ToMemberResponse(member)
+	}
+	return responses, nil
+}
+
+// AddMember adds a user, found by username, to an organization on behalf of
+// one of its owners or admins. New members join as members by default.
+func (uc *OrganizationUseCase) AddMember(ctx context.Context, orgID, actorID int64, req *dto.AddMemberRequest) (*dto.MemberResponse, error) {
+	role := entity.OrganizationRoleMember
+	if req.Role != "" {
+		role = entity.OrganizationRole(req.Role)
+	}
+	if !role.IsValid() {
+		return nil, ErrInvalidOrganizationRole
+	}
+
+	actor, err := uc.membership(ctx, orgID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkManages(actor, role); err != nil {
+		return nil, err
+	}
+
+	// The user to add is not in the organization yet
+	user, err := uc.userRepo.FindByUsername(tenant.Unscoped(ctx), req.Username)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	member := &entity.OrganizationMember{OrganizationID: orgID, UserID: user.ID, Role: role}
+	if err := uc.orgRepo.AddMember(ctx, member); err != nil {
+		if errors.Is(err, repositoryImpl.ErrMemberExists) {
+			return nil, ErrMemberExists
+		}
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	member.User = user
+
+	response := dto.ToMemberResponse(member)
+	return &response, nil
+}
+
+// UpdateMember changes the role of a member on behalf of one of the
+// organization's owners or admins
+func (uc *OrganizationUseCase) UpdateMember(ctx context.Context, orgID, actorID, memberID int64, req *dto.UpdateMemberRequest) (*dto.MemberResponse, error) {
+	role := entity.OrganizationRole(req.Role)
+	if !role.IsValid() {
+		return nil, ErrInvalidOrganizationRole
+	}
+
+	actor, err := uc.membership(ctx, orgID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := uc.member(ctx, orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkManages(actor, member.Role); err != nil {
+		return nil, err
+	}
+	if err := checkManages(actor, role); err != nil {
+		return nil, err
+	}
+
+	if member.Role != role {
+		if err := uc.checkKeepsOwner(ctx, member); err != nil {
+			return nil, err
+		}
+		member.Role = role
+		if err := uc.orgRepo.UpdateMember(ctx, member); err != nil {
+			return nil, fmt.Errorf("failed to update member: %w", err)
+		}
+	}
+
+	response := dto.ToMemberResponse(member)
+	return &response, nil
+}
+
+// RemoveMember removes a member from an organization on behalf of one of its
+// owners or admins. Any member may remove themselves to leave.
+func (uc *OrganizationUseCase) RemoveMember(ctx context.Context, orgID, actorID, memberID int64) error {
+	actor, err := uc.membership(ctx, orgID, actorID)
+	if err != nil {
+		return err
+	}
+	member, err := uc.member(ctx, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if actorID != memberID {
+		if err := checkManages(actor, member.Role); err != nil {
+			return err
+		}
+	}
+	if err := uc.checkKeepsOwner(ctx, member); err != nil {
+		return err
+	}
+
+	if err := uc.orgRepo.RemoveMember(ctx, orgID, memberID); err != nil {
+		if errors.Is(err, repositoryImpl.ErrMemberNotFound) {
+			return ErrMemberNotFound
+		}
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// membership returns a user's membership in an organization, denying access
+// to organizations the user does not belong to
+func (uc *OrganizationUseCase) membership(ctx context.Context, orgID, userID int64) (*entity.OrganizationMember, error) {
+	member, err := uc.orgRepo.FindMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrMemberNotFound) {
+			return nil, ErrNotOrganizationMember
+		}
+		return nil, fmt.Errorf("failed to find membership: %w", err)
+	}
+	return member, nil
+}
+
+// member returns the membership of a user another member acts on
+func (uc *OrganizationUseCase) member(ctx context.Context, orgID, userID int64) (*entity.OrganizationMember, error) {
+	member, err := uc.orgRepo.FindMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrMemberNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to find member: %w", err)
+	}
+	return member, nil
+}
+
+// checkKeepsOwner denies demoting or removing the last owner
+func (uc *OrganizationUseCase) checkKeepsOwner(ctx context.Context, member *entity.OrganizationMember) error {
+	if member.Role != entity.OrganizationRoleOwner {
+		return nil
+	}
+	owners, err := uc.orgRepo.CountMembersByRole(ctx, member.OrganizationID, entity.OrganizationRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// checkManages reports whether a member may manage members with a role
+func checkManages(actor *entity.OrganizationMember, role entity.OrganizationRole) error {
+	if !actor.Role.CanManageMembers() {
+		return ErrMemberManagementForbidden
+	}
+	if role == entity.OrganizationRoleOwner && actor.Role != entity.OrganizationRoleOwner {
+		return ErrOwnerManagementForbidden
+	}
+	return nil
+}
+
+// invalidateUserTodos drops a user's cached todos in every organization they
+// belong to, after a change that spans tenants
+func invalidateUserTodos(ctx context.Context, todoCache *cache.TodoCache, orgRepo repository.OrganizationRepository, userID int64) error {
+	if orgRepo == nil {
+		return todoCache.InvalidateUser(ctx, userID)
+	}
+
+	memberships, err := orgRepo.FindMemberships(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, member := range memberships {
+		if err := todoCache.InvalidateUser(tenant.WithID(ctx, member.OrganizationID), userID); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOrganizationRepository keeps organization memberships in memory
+type memoryOrganizationRepository struct {
+	members map[int64]map[int64]*entity.OrganizationMember
+}
+
+func newMemoryOrganizationRepository(members ...*entity.OrganizationMember) *memoryOrganizationRepository {
+	r := &memoryOrganizationRepository{members: make(map[int64]map[int64]*entity.OrganizationMember)}
+	for _, member := range members {
+		_ = r.AddMember(context.Background(), member)
+	}
+	return r
+}
+
+func (r *memoryOrganizationRepository) Create(ctx context.Context, org *entity.Organization, owner *entity.OrganizationMember) error {
+	org.ID = int64(len(r.members) + 1)
+	owner.OrganizationID = org.ID
+	return r.AddMember(ctx, owner)
+}
+
+func (r *memoryOrganizationRepository) FindByID(ctx context.Context, id int64) (*entity.Organization, error) {
+	if _, ok := r.members[id]; !ok {
+		return nil, repositoryImpl.ErrOrganizationNotFound
+	}
+	return &entity.Organization{ID: id}, nil
+}
+
+func (r *memoryOrganizationRepository) FindMemberships(ctx context.Context, userID int64) ([]*entity.OrganizationMember, error) {
+	var memberships []*entity.OrganizationMember
+	for orgID := int64(1); orgID <= int64(len(r.members)); orgID++ {
+		if member, ok := r.members[orgID][userID]; ok {
+			memberships = append(memberships, member)
+		}
+	}
+	return memberships, nil
+}
+
+func (r *memoryOrganizationRepository) FindMember(ctx context.Context, orgID, userID int64) (*entity.OrganizationMember, error) {
+	member, ok := r.members[orgID][userID]
+	if !ok {
+		return nil, repositoryImpl.ErrMemberNotFound
+	}
+	copied := *member
+	return &copied, nil
+}
+
+func (r *memoryOrganizationRepository) ListMembers(ctx context.Context, orgID int64) ([]*entity.OrganizationMember, error) {
+	var members []*entity.OrganizationMember
+	for _, member := range r.members[orgID] {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (r *memoryOrganizationRepository) AddMember(ctx context.Context, member *entity.OrganizationMember) error {
+	if _, ok := r.members[member.OrganizationID][member.UserID]; ok {
+		return repositoryImpl.ErrMemberExists
+	}
+	if r.members[member.OrganizationID] == nil {
+		r.members[member.OrganizationID] = make(map[int64]*entity.OrganizationMember)
+	}
+	r.members[member.OrganizationID][member.UserID] = member
+	return nil
+}
+
+func (r *memoryOrganizationRepository) UpdateMember(ctx context.Context, member *entity.OrganizationMember) error {
+	if _, ok := r.members[member.OrganizationID][member.UserID]; !ok {
+		return repositoryImpl.ErrMemberNotFound
+	}
+	r.members[member.OrganizationID][member.UserID] = member
+	return nil
+}
+
+func (r *memoryOrganizationRepository) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	if _, ok := r.members[orgID][userID]; !ok {
+		return repositoryImpl.ErrMemberNotFound
+	}
+	delete(r.members[orgID], userID)
+	return nil
+}
+
+func (r *memoryOrganizationRepository) CountMembersByRole(ctx context.Context, orgID int64, role entity.OrganizationRole) (int64, error) {
+	var count int64
+	for _, member := range r.members[orgID] {
+		if member.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// namedUserRepository also finds users by username
+type namedUserRepository struct {
+	memoryUserRepository
+}
+
+func (r *namedUserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, repositoryImpl.ErrUserNotFound
+}
+
+func TestOrganizationUseCase_ManagesMembers(t *testing.T) {
+	ctx := context.Background()
+	orgs := newMemoryOrganizationRepository(
+		&entity.OrganizationMember{OrganizationID: 1, UserID: 1, Role: entity.OrganizationRoleOwner},
+		&entity.OrganizationMember{OrganizationID: 1, UserID: 2, Role: entity.OrganizationRoleAdmin},
+		&entity.OrganizationMember{OrganizationID: 1, UserID: 3, Role: entity.OrganizationRoleMember},
+	)
+	users := &namedUserRepository{memoryUserRepository{users: map[int64]*entity.User{
+		4: {ID: 4, Username: "dave"},
+		5: {ID: 5, Username: "erin"},
+	}}}
+	uc := NewOrganizationUseCase(orgs, users)
+
+	// Members do not manage members
+	_, err := uc.AddMember(ctx, 1, 3, &dto.AddMemberRequest{Username: "dave"})
+	assert.Equal(t, ErrMemberManagementForbidden, err)
+
+	// Admins add members but not owners
+	member, err := uc.AddMember(ctx, 1, 2, &dto.AddMemberRequest{Username: "dave"})
+	require.NoError(t, err)
+	assert.Equal(t, "member", member.Role)
+	_, err = uc.AddMember(ctx, 1, 2, &dto.AddMemberRequest{Username: "erin", Role: "owner"})
+	assert.Equal(t, ErrOwnerManagementForbidden, err)
+	_, err = uc.AddMember(ctx, 1, 2, &dto.AddMemberRequest{Username: "dave"})
+	assert.Equal(t, ErrMemberExists, err)
+	_, err = uc.AddMember(ctx, 1, 2, &dto.AddMemberRequest{Username: "nobody"})
+	assert.Equal(t, ErrUserNotFound, err)
+
+	// nor demote owners
+	_, err = uc.UpdateMember(ctx, 1, 2, 1, &dto.UpdateMemberRequest{Role: "member"})
+	assert.Equal(t, ErrOwnerManagementForbidden, err)
+
+	// The last owner stays an owner
+	_, err = uc.UpdateMember(ctx, 1, 1, 1, &dto.UpdateMemberRequest{Role: "admin"})
+	assert.Equal(t, ErrLastOwner, err)
+	assert.Equal(t, ErrLastOwner, uc.RemoveMember(ctx, 1, 1, 1))
+
+	// until another owner is appointed
+	_, err = uc.UpdateMember(ctx, 1, 1, 2, &dto.UpdateMemberRequest{Role: "owner"})
+	require.NoError(t, err)
+	require.NoError(t, uc.RemoveMember(ctx, 1, 1, 1))
+
+	// Members leave on their own
+	require.NoError(t, uc.RemoveMember(ctx, 1, 3, 3))
+	assert.Equal(t, ErrNotOrganizationMember, uc.RemoveMember(ctx, 1, 3, 4))
+
+	members, err := uc.ListMembers(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+}
+
+func TestOrganizationUseCase_DeniesOutsiders(t *testing.T) {
+	ctx := context.Background()
+	orgs := newMemoryOrganizationRepository(
+		&entity.OrganizationMember{OrganizationID: 1, UserID: 1, Role: entity.OrganizationRoleOwner},
+		&entity.OrganizationMember{OrganizationID: 2, UserID: 2, Role: entity.OrganizationRoleOwner},
+	)
+	users := &namedUserRepository{memoryUserRepository{users: map[int64]*entity.User{
+		1: {ID: 1, Username: "alice"},
+		2: {ID: 2, Username: "bob"},
+	}}}
+	uc := NewOrganizationUseCase(orgs, users)
+
+	_, err := uc.ListMembers(ctx, 2, 1)
+	assert.Equal(t, ErrNotOrganizationMember, err)
+	_, err = uc.AddMember(ctx, 2, 1, &dto.AddMemberRequest{Username: "alice"})
+	assert.Equal(t, ErrNotOrganizationMember, err)
+	assert.Equal(t, ErrNotOrganizationMember, uc.RemoveMember(ctx, 2, 1, 2))
+
+	// Tokens are only issued for organizations the user belongs to
+	userUC := NewUserUseCase(users, orgs, nil, nil, nil, nil, nil, nil, nil, "", 0)
+	_, err = userUC.SwitchOrganization(ctx, 1, 2)
+	assert.Equal(t, ErrNotOrganizationMember, err)
+
+	orgList, err := uc.ListOrganizations(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, orgList, 1)
+	assert.Equal(t, int64(1), orgList[0].ID)
+	assert.True(t, orgList[0].Current)
+}
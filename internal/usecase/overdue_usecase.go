@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+// OverdueUseCase flags todos that have passed their due date and escalates
+// their priority while they stay open
+type OverdueUseCase struct {
+	todoRepo           repository.TodoRepository
+	todoCache          *cache.TodoCache
+	txManager          repository.TransactionManager
+	outboxRepo         repository.OutboxRepository
+	escalationInterval time.Duration
+	batchSize          int
+}
+
+// NewOverdueUseCase creates a new overdue use case. An escalationInterval of
+// zero disables priority escalation.
+func NewOverdueUseCase(
+	todoRepo repository.TodoRepository,
+	todoCache *cache.TodoCache,
+	txManager repository.TransactionManager,
+	outboxRepo repository.OutboxRepository,
+	escalationInterval time.Duration,
+	batchSize int,
+) *OverdueUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	return &OverdueUseCase{
+		todoRepo:           todoRepo,
+		todoCache:          todoCache,
+		txManager:          txManager,
+		outboxRepo:         outboxRepo,
+		escalationInterval: escalationInterval,
+		batchSize:          batchSize,
+	}
+}
+
+// ProcessOverdue handles one batch of overdue todos. Newly overdue todos are
+// flagged and emit todo.overdue; todos overdue for another full escalation
+// interval have their priority raised one level and emit todo.updated.
+// It returns the number of todos changed.
+func (uc *OverdueUseCase) ProcessOverdue(ctx context.Context, now time.Time) (int, error) {
+	// The job works through the todos of every tenant
+	ctx = tenant.Unscoped(ctx)
+
+	var escalateBefore *time.Time
+	if uc.escalationInterval > 0 {
+		before := now.Add(-uc.escalationInterval)
+		escalateBefore = &before
+	}
+
+	todos, err := uc.todoRepo.FindOverdue(ctx, now, escalateBefore, uc.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, todo := range todos {
+		newlyOverdue := todo.OverdueAt == nil
+		if newlyOverdue {
+			todo.OverdueAt = &now
+		}
+
+		escalated := false
+		if escalateBefore != nil {
+			last := todo.DueDate
+			if todo.EscalatedAt != nil {
+				last = todo.EscalatedAt
+			}
+			if !last.After(*escalateBefore) && todo.EscalatePriority() {
+				todo.EscalatedAt = &now
+				escalated = true
+			}
+		}
+
+		if !newlyOverdue && !escalated {
+			continue
+		}
+
+		// Events carry the tenant of the todo they are about
+		response := dto.ToTodoResponse(todo)
+		err := withinTransaction(tenant.WithID(ctx, todo.OrganizationID), uc.txManager, func(ctx context.Context) error {
+			if err := uc.todoRepo.UpdateOverdueState(ctx, todo); err != nil {
+				return err
+			}
+			if newlyOverdue {
+				if err := recordEvent(ctx, uc.outboxRepo, event.TodoOverdue, todo.UserID, "todo", todo.ID, response); err != nil {
+					return err
+				}
+			}
+			if escalated {
+				return recordEvent(ctx, uc.outboxRepo, event.TodoUpdated, todo.UserID, "todo", todo.ID, response)
+			}
+			return nil
+		})
+		if err != nil {
+			return changed, err
+		}
+		changed++
+
+		// Update cache, which also drops the user's cached overdue lists;
+		// the job spans tenants, so each todo is cached in its own
+		if uc.todoCache != nil {
+			if err := uc.todoCache.UpdateTodo(tenant.WithID(ctx, todo.OrganizationID), todo); err != nil {
+				// Log error but don't fail the job
+				// In production, use proper logging
+			}
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// overdueTodoRepository serves FindOverdue from memory
+type overdueTodoRepository struct {
+	repository.TodoRepository
+	todos   []*entity.Todo
+	updated []int64
+}
+
+func (r *overdueTodoRepository) FindOverdue(ctx context.Context, now time.Time, escalateBefore *time.Time, limit int) ([]*entity.Todo, error) {
+	return r.todos, nil
+}
+
+func (r *overdueTodoRepository) UpdateOverdueState(ctx context.Context, todo *entity.Todo) error {
+	r.updated = append(r.updated, todo.ID)
+	return nil
+}
+
+// memoryOutbox records outbox messages
+type memoryOutbox struct {
+	repository.OutboxRepository
+	messages []*entity.OutboxMessage
+}
+
+func (o *memoryOutbox) Create(ctx context.Context, message *entity.OutboxMessage) error {
+	o.messages = append(o.messages, message)
+	return nil
+}
+
+func (o *memoryOutbox) types() []string {
+	types := make([]string, len(o.messages))
+	for i, message := range o.messages {
+		types[i] = message.EventType
+	}
+	return types
+}
+
+func TestProcessOverdue_FlagsAndEscalates(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	justDue := now.Add(-time.Hour)
+	longDue := now.Add(-50 * time.Hour)
+	flaggedAt := now.Add(-49 * time.Hour)
+	recentlyEscalated := now.Add(-2 * time.Hour)
+
+	repo := &overdueTodoRepository{todos: []*entity.Todo{
+		// Newly overdue: flagged, not yet escalated
+		{ID: 1, UserID: 7, DueDate: &justDue, Status: entity.TodoStatusNotStarted, Priority: entity.TodoPriorityP3},
+		// Flagged two days ago and never escalated: escalated once
+		{ID: 2, UserID: 7, DueDate: &longDue, OverdueAt: &flaggedAt, Status: entity.TodoStatusInProgress, Priority: entity.TodoPriorityP2},
+		// Escalated recently: left alone
+		{ID: 3, UserID: 7, DueDate: &longDue, OverdueAt: &flaggedAt, EscalatedAt: &recentlyEscalated, Status: entity.TodoStatusNotStarted, Priority: entity.TodoPriorityP2},
+	}}
+	outbox := &memoryOutbox{}
+
+	uc := NewOverdueUseCase(repo, nil, nil, outbox, 24*time.Hour, 100)
+
+	changed, err := uc.ProcessOverdue(context.Background(), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, changed)
+	assert.Equal(t, []int64{1, 2}, repo.updated)
+
+	assert.Equal(t, &now, repo.todos[0].OverdueAt)
+	assert.Equal(t, entity.TodoPriorityP3, repo.todos[0].Priority)
+	assert.Equal(t, entity.TodoPriorityP1, repo.todos[1].Priority)
+	assert.Equal(t, &now, repo.todos[1].EscalatedAt)
+	assert.Equal(t, entity.TodoPriorityP2, repo.todos[2].Priority)
+
+	assert.Equal(t, []string{string(event.TodoOverdue), string(event.TodoUpdated)}, outbox.types())
+
+	var evt event.Event
+	require.NoError(t, json.Unmarshal([]byte(outbox.messages[0].Payload), &evt))
+	assert.Equal(t, int64(7), evt.UserID)
+	assert.Equal(t, int64(1), evt.AggregateID)
+}
+
+func TestProcessOverdue_EscalationDisabled(t *testing.T) {
+	now := time.Now()
+	due := now.Add(-72 * time.Hour)
+
+	repo := &overdueTodoRepository{todos: []*entity.Todo{
+		{ID: 1, DueDate: &due, Status: entity.TodoStatusNotStarted, Priority: entity.TodoPriorityP3},
+	}}
+
+	uc := NewOverdueUseCase(repo, nil, nil, nil, 0, 100)
+
+	changed, err := uc.ProcessOverdue(context.Background(), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, entity.TodoPriorityP3, repo.todos[0].Priority)
+	assert.NotNil(t, repo.todos[0].OverdueAt)
+}
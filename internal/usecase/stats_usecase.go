@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+	statsTopTags     = 10
+)
+
+// StatsUseCase implements productivity analytics
+type StatsUseCase struct {
+	todoRepo     repository.TodoRepository
+	workflowRepo repository.WorkflowRepository
+	statsCache   *cache.StatsCache
+}
+
+// NewStatsUseCase creates a new stats use case. statsCache may be nil.
+func NewStatsUseCase(todoRepo repository.TodoRepository, workflowRepo repository.WorkflowRepository, statsCache *cache.StatsCache) *StatsUseCase {
+	return &StatsUseCase{
+		todoRepo:     todoRepo,
+		workflowRepo: workflowRepo,
+		statsCache:   statsCache,
+	}
+}
+
+// GetStats returns a user's completion history over the last days days,
+// plus their current status, priority and tag distribution
+func (uc *StatsUseCase) GetStats(ctx context.Context, userID int64, days int) (*dto.StatsResponse, error) {
+	if days < 1 || days > maxStatsDays {
+		days = defaultStatsDays
+	}
+
+	// Try cache first
+	if uc.statsCache != nil {
+		if stats, ok := uc.statsCache.Get(ctx, userID, days); ok {
+			return stats, nil
+		}
+	}
+
+	now := time.Now()
+	since := startOfDay(now).AddDate(0, 0, -(days - 1))
+
+	stats, err := uc.todoRepo.GetStatsByUserID(ctx, userID, since, now, statsTopTags)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := loadWorkflow(ctx, uc.workflowRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := buildStatsResponse(stats, workflow.Statuses(), days, since, now)
+
+	// Update cache
+	if uc.statsCache != nil {
+		if err := uc.statsCache.Set(ctx, userID, days, response); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+
+	return response, nil
+}
+
+// buildStatsResponse converts aggregated stats, filling in days without
+// completions and rolling days up into Monday-based weeks. Every status of
+// the workflow is listed in the status distribution, even when unused.
+func buildStatsResponse(stats *entity.TodoStats, workflowStatuses []entity.TodoStatus, days int, since, now time.Time) *dto.StatsResponse {
+	completedOn := make(map[string]int64, len(stats.CompletedPerDay))
+	for _, day := range stats.CompletedPerDay {
+		completedOn[day.Day] = day.Count
+	}
+
+	perDay := make([]dto.DailyCompletion, 0, days)
+	perWeek := []dto.WeeklyCompletion{}
+	for day := since; !day.After(now); day = day.AddDate(0, 0, 1) {
+		count := completedOn[day.Format("2006-01-02")]
+		perDay = append(perDay, dto.DailyCompletion{Date: day.Format("2006-01-02"), Count: count})
+
+		weekStart := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format("2006-01-02")
+		if len(perWeek) == 0 || perWeek[len(perWeek)-1].WeekStart != weekStart {
+			perWeek = append(perWeek, dto.WeeklyCompletion{WeekStart: weekStart})
+		}
+		perWeek[len(perWeek)-1].Count += count
+	}
+
+	statuses := make(map[string]int64, len(workflowStatuses))
+	for _, status := range workflowStatuses {
+		statuses[string(status)] = 0
+	}
+	for status, count := range stats.StatusCounts {
+		statuses[string(status)] = count
+	}
+
+	priorities := make(map[string]int64, len(entity.Priorities()))
+	for _, priority := range entity.Priorities() {
+		priorities[string(priority)] = 0
+	}
+	for priority, count := range stats.PriorityCounts {
+		priorities[string(priority)] = count
+	}
+
+	topTags := make([]dto.TagUsageResponse, len(stats.TopTags))
+	for i, tag := range stats.TopTags {
+		topTags[i] = dto.TagUsageResponse{ID: tag.TagID, Name: tag.Name, Count: tag.Count}
+	}
+
+	return &dto.StatsResponse{
+		Days:                 days,
+		Since:                since,
+		TotalTodos:           stats.Total,
+		CompletedCount:       stats.CompletedCount,
+		CompletedPerDay:      perDay,
+		CompletedPerWeek:     perWeek,
+		AvgLeadTimeSeconds:   stats.AvgLeadTime,
+		OverdueCount:         stats.OverdueCount,
+		StatusDistribution:   statuses,
+		PriorityDistribution: priorities,
+		TopTags:              topTags,
+		GeneratedAt:          now,
+	}
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildStatsResponse_FillsDaysAndWeeks(t *testing.T) {
+	// Saturday 2024-03-09 through Tuesday 2024-03-12
+	since := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+
+	stats := &entity.TodoStats{
+		Total: 5,
+		CompletedPerDay: []entity.DailyCount{
+			{Day: "2024-03-09", Count: 2},
+			{Day: "2024-03-11", Count: 1},
+			{Day: "2024-03-12", Count: 3},
+		},
+		StatusCounts: map[entity.TodoStatus]int64{entity.TodoStatusCompleted: 5},
+	}
+
+	response := buildStatsResponse(stats, entity.DefaultWorkflow(1).Statuses(), 4, since, now)
+
+	assert.Equal(t, []int64{2, 0, 1, 3}, dailyCounts(response.CompletedPerDay))
+	assert.Equal(t, "2024-03-10", response.CompletedPerDay[1].Date)
+
+	assert.Len(t, response.CompletedPerWeek, 2)
+	assert.Equal(t, "2024-03-04", response.CompletedPerWeek[0].WeekStart)
+	assert.Equal(t, int64(2), response.CompletedPerWeek[0].Count)
+	assert.Equal(t, "2024-03-11", response.CompletedPerWeek[1].WeekStart)
+	assert.Equal(t, int64(4), response.CompletedPerWeek[1].Count)
+
+	assert.Equal(t, int64(0), response.StatusDistribution["in_progress"])
+	assert.Equal(t, int64(5), response.StatusDistribution["completed"])
+	assert.Equal(t, int64(0), response.PriorityDistribution["p1"])
+}
+
+func TestTodoSetStatus_StampsAndClearsCompletedAt(t *testing.T) {
+	workflow := entity.DefaultWorkflow(1)
+	completed, _ := workflow.State(entity.TodoStatusCompleted)
+	notStarted, _ := workflow.State(entity.TodoStatusNotStarted)
+
+	todo := &entity.Todo{Status: entity.TodoStatusInProgress, StatusCategory: entity.StatusCategoryActive}
+	completedAt := time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)
+
+	todo.SetStatus(completed, completedAt)
+	assert.Equal(t, &completedAt, todo.CompletedAt)
+	assert.True(t, todo.IsDone())
+
+	// Completing again keeps the original completion time
+	todo.SetStatus(completed, completedAt.Add(time.Hour))
+	assert.Equal(t, &completedAt, todo.CompletedAt)
+
+	todo.SetStatus(notStarted, completedAt.Add(2*time.Hour))
+	assert.Nil(t, todo.CompletedAt)
+	assert.Equal(t, entity.StatusCategoryOpen, todo.StatusCategory)
+}
+
+func dailyCounts(days []dto.DailyCompletion) []int64 {
+	counts := make([]int64, len(days))
+	for i, day := range days {
+		counts[i] = day.Count
+	}
+	return counts
+}
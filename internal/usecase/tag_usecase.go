@@ -4,16 +4,43 @@ import (
 	"context"
 	"errors"
 	"math"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
 	"github.com/darron08/todolist-demo/internal/domain/repository"
 	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	tagRepositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
 	"github.com/darron08/todolist-demo/pkg/dto"
 )
 
+// maxTagDepth bounds the number of segments in a path-style tag name
+const maxTagDepth = 5
+
+// defaultTagSuggestions is the number of suggestions returned when the
+// request does not set a limit
+const defaultTagSuggestions = 10
+
+var (
+	tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	tagIconPattern  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
 var (
 	ErrTagNameRequired = errors.New("tag name is required")
 	ErrTagNameTooLong  = errors.New("tag name is too long")
+	ErrTagMergeSelf    = errors.New("a tag cannot be merged into itself")
+	ErrInvalidTagPath  = errors.New("tag path has an empty segment")
+	ErrTagPathTooDeep  = errors.New("tag path is too deep")
+	ErrTagMoveIntoSelf = errors.New("a tag cannot be moved under itself")
+	ErrTagHasChildren  = errors.New("tag has nested tags")
+
+	ErrInvalidTagColor       = errors.New("tag color must be a hex color such as #1e90ff")
+	ErrInvalidTagIcon        = errors.New("tag icon must be a lowercase name such as briefcase or check-circle")
+	ErrTagIconTooLong        = errors.New("tag icon is too long")
+	ErrTagDescriptionTooLong = errors.New("tag description is too long")
 )
 
 // TagUseCase implements business logic for tags
@@ -21,40 +48,71 @@ type TagUseCase struct {
 	tagRepo     repository.TagRepository
 	todoTagRepo repository.TodoTagRepository
 	tagCache    *cache.TagCache
+	todoCache   *cache.TodoCache
+	tagUsage    *cache.TagUsage
+	txManager   repository.TransactionManager
+	outboxRepo  repository.OutboxRepository
 }
 
 // NewTagUseCase creates a new tag use case
-func NewTagUseCase(tagRepo repository.TagRepository, todoTagRepo repository.TodoTagRepository, tagCache *cache.TagCache) *TagUseCase {
+func NewTagUseCase(tagRepo repository.TagRepository, todoTagRepo repository.TodoTagRepository, tagCache *cache.TagCache, todoCache *cache.TodoCache, tagUsage *cache.TagUsage, txManager repository.TransactionManager, outboxRepo repository.OutboxRepository) *TagUseCase {
 	return &TagUseCase{
 		tagRepo:     tagRepo,
 		todoTagRepo: todoTagRepo,
 		tagCache:    tagCache,
+		todoCache:   todoCache,
+		tagUsage:    tagUsage,
+		txManager:   txManager,
+		outboxRepo:  outboxRepo,
 	}
 }
 
-// CreateTag creates a new tag
-func (uc *TagUseCase) CreateTag(ctx context.Context, req *dto.CreateTagRequest) (*dto.TagResponse, error) {
+// CreateTag creates a new tag on behalf of a user. Path-style names such as
+// "work/backend" nest the tag, creating missing parents.
+func (uc *TagUseCase) CreateTag(ctx context.Context, userID int64, req *dto.CreateTagRequest) (*dto.TagResponse, error) {
 	// Validate name
-	if req.Name == "" {
-		return nil, ErrTagNameRequired
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
 	}
-	if len(req.Name) > 100 {
-		return nil, ErrTagNameTooLong
+
+	// Validate metadata
+	color := strings.TrimSpace(req.Color)
+	icon := strings.TrimSpace(req.Icon)
+	description := strings.TrimSpace(req.Description)
+	if err := validateTagMetadata(color, icon, description); err != nil {
+		return nil, err
 	}
 
-	// Check if tag already exists
-	_, err := uc.tagRepo.FindByName(ctx, req.Name)
+	// Check if the user already has a tag with this name
+	_, err = uc.tagRepo.FindByName(ctx, userID, name)
 	if err == nil {
 		return nil, errors.New("tag with this name already exists")
 	}
 
 	// Create tag entity
 	tag := &entity.Tag{
-		Name: req.Name,
+		UserID:      userID,
+		Name:        name,
+		Color:       color,
+		Icon:        icon,
+		Description: description,
 	}
 
-	// Save to database
-	if err := uc.tagRepo.Create(ctx, tag); err != nil {
+	// Save to database and record event
+	var response dto.TagResponse
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := ensureTagParent(ctx, uc.tagRepo, tag); err != nil {
+			return err
+		}
+		if err := uc.tagRepo.Create(ctx, tag); err != nil {
+			return err
+		}
+
+		response = dto.ToTagResponse(tag)
+		return recordEvent(ctx, uc.outboxRepo, event.TagCreated, userID, "tag", tag.ID, response)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -66,12 +124,11 @@ func (uc *TagUseCase) CreateTag(ctx context.Context, req *dto.CreateTagRequest)
 		}
 	}
 
-	response := dto.ToTagResponse(tag)
 	return &response, nil
 }
 
-// GetTag retrieves a single tag by ID
-func (uc *TagUseCase) GetTag(ctx context.Context, id int64) (*dto.TagResponse, error) {
+// GetTag retrieves a single tag owned by a user
+func (uc *TagUseCase) GetTag(ctx context.Context, id int64, userID int64) (*dto.TagResponse, error) {
 	var tag *entity.Tag
 	var err error
 
@@ -83,64 +140,171 @@ func (uc *TagUseCase) GetTag(ctx context.Context, id int64) (*dto.TagResponse, e
 	}
 
 	if err != nil {
+		if errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
+			return nil, ErrTagNotFound
+		}
 		return nil, err
 	}
 
+	// Tags of other users are reported as not found
+	if tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+
 	response := dto.ToTagResponse(tag)
 	return &response, nil
 }
 
-// UpdateTag updates an existing tag
-func (uc *TagUseCase) UpdateTag(ctx context.Context, id int64, req *dto.UpdateTagRequest) (*dto.TagResponse, error) {
+// UpdateTag updates a tag owned by a user
+func (uc *TagUseCase) UpdateTag(ctx context.Context, id int64, userID int64, req *dto.UpdateTagRequest) (*dto.TagResponse, error) {
 	// Get existing tag
-	tag, err := uc.tagRepo.FindByID(ctx, id)
+	tag, err := uc.findOwnedTag(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Validate name
-	if req.Name == "" {
-		return nil, ErrTagNameRequired
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
 	}
-	if len(req.Name) > 100 {
-		return nil, ErrTagNameTooLong
+	if strings.HasPrefix(name, tag.Name+entity.TagPathSeparator) {
+		return nil, ErrTagMoveIntoSelf
 	}
 
 	// Check if another tag with the same name exists
-	existingTag, err := uc.tagRepo.FindByName(ctx, req.Name)
+	existingTag, err := uc.tagRepo.FindByName(ctx, userID, name)
 	if err == nil && existingTag.ID != id {
 		return nil, errors.New("tag with this name already exists")
 	}
 
+	// Tags nested under the renamed tag move along with it
+	var descendants []*entity.Tag
+	if name != tag.Name {
+		descendants, err = uc.findDescendants(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, descendant := range descendants {
+			if _, err := normalizeTagName(name + strings.TrimPrefix(descendant.Name, tag.Name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Validate metadata, keeping the current values of omitted fields
+	color, icon, description := tag.Color, tag.Icon, tag.Description
+	if req.Color != nil {
+		color = strings.TrimSpace(*req.Color)
+	}
+	if req.Icon != nil {
+		icon = strings.TrimSpace(*req.Icon)
+	}
+	if req.Description != nil {
+		description = strings.TrimSpace(*req.Description)
+	}
+	if err := validateTagMetadata(color, icon, description); err != nil {
+		return nil, err
+	}
+
 	// Update tag
-	tag.Name = req.Name
+	oldName, oldParentID := tag.Name, tag.ParentID
+	tag.Name = name
+	tag.Color = color
+	tag.Icon = icon
+	tag.Description = description
+
+	// Save changes and record event
+	var response dto.TagResponse
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := ensureTagParent(ctx, uc.tagRepo, tag); err != nil {
+			return err
+		}
+		if err := uc.tagRepo.Update(ctx, tag); err != nil {
+			return err
+		}
+
+		for _, descendant := range descendants {
+			descendant.Name = name + strings.TrimPrefix(descendant.Name, oldName)
+			if err := uc.tagRepo.Update(ctx, descendant); err != nil {
+				return err
+			}
+		}
+
+		if req.KeepAlias && oldName != tag.Name {
+			alias := &entity.TagAlias{UserID: userID, TagID: tag.ID, Name: oldName}
+			if err := uc.tagRepo.AddAliases(ctx, []*entity.TagAlias{alias}); err != nil {
+				return err
+			}
+		}
 
-	// Save changes
-	if err := uc.tagRepo.Update(ctx, tag); err != nil {
+		response = dto.ToTagResponse(tag)
+		return recordEvent(ctx, uc.outboxRepo, event.TagUpdated, userID, "tag", tag.ID, response)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	// Update cache
 	if uc.tagCache != nil {
-		if err := uc.tagCache.UpdateTag(ctx, tag); err != nil {
+		for _, updated := range append(descendants, tag) {
+			if err := uc.tagCache.UpdateTag(ctx, updated); err != nil {
+				// Log error but don't fail the request
+				// In production, use proper logging
+			}
+		}
+	}
+	// Moving a tag changes which tags it is nested under, and so the tags its
+	// todos count towards
+	if uc.todoCache != nil && oldName != tag.Name {
+		oldLineage, _ := tagLineageIDs(ctx, uc.tagRepo, &entity.Tag{ID: tag.ID, ParentID: oldParentID})
+		lineage, _ := tagLineageIDs(ctx, uc.tagRepo, tag)
+		if err := uc.todoCache.InvalidateTagged(ctx, userID, changedIDs(oldLineage, lineage)); err != nil {
 			// Log error but don't fail the request
 			// In production, use proper logging
 		}
 	}
 
-	response := dto.ToTagResponse(tag)
 	return &response, nil
 }
 
-// DeleteTag deletes a tag
-func (uc *TagUseCase) DeleteTag(ctx context.Context, id int64) error {
-	if err := uc.tagRepo.Delete(ctx, id); err != nil {
+// DeleteTag deletes a tag owned by a user. Tags with nested tags cannot be
+// deleted.
+func (uc *TagUseCase) DeleteTag(ctx context.Context, id int64, userID int64) error {
+	tag, err := uc.findOwnedTag(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkNoChildren(ctx, tag.ID); err != nil {
+		return err
+	}
+
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.tagRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return recordEvent(ctx, uc.outboxRepo, event.TagDeleted, userID, "tag", tag.ID, dto.ToTagResponse(tag))
+	})
+	if err != nil {
 		return err
 	}
 
 	// Delete from cache
 	if uc.tagCache != nil {
-		if err := uc.tagCache.DeleteTag(ctx, id); err != nil {
+		if err := uc.tagCache.DeleteTag(ctx, tag); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+	if uc.todoCache != nil {
+		lineage, _ := tagLineageIDs(ctx, uc.tagRepo, tag)
+		if err := uc.todoCache.InvalidateTagged(ctx, userID, lineage); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+	if uc.tagUsage != nil {
+		if err := uc.tagUsage.Remove(ctx, userID, []int64{tag.ID}); err != nil {
 			// Log error but don't fail the request
 			// In production, use proper logging
 		}
@@ -149,8 +313,104 @@ func (uc *TagUseCase) DeleteTag(ctx context.Context, id int64) error {
 	return nil
 }
 
-// ListTags lists all tags with pagination
-func (uc *TagUseCase) ListTags(ctx context.Context, page, limit int) (*dto.TagListResponse, error) {
+// MergeTags merges source tags owned by a user into a target tag. Todos of
+// the sources are retagged with the target and the sources are deleted;
+// their names can be kept as aliases of the target. Tags with nested tags
+// cannot be merged away.
+func (uc *TagUseCase) MergeTags(ctx context.Context, targetID int64, userID int64, req *dto.MergeTagsRequest) (*dto.TagResponse, error) {
+	target, err := uc.findOwnedTag(ctx, targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []*entity.Tag
+	var sourceIDs []int64
+	seen := make(map[int64]bool, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		if id == targetID {
+			return nil, ErrTagMergeSelf
+		}
+		if seen[id] {
+			continue
+		}
+		source, err := uc.findOwnedTag(ctx, id, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.checkNoChildren(ctx, source.ID); err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+		sourceIDs = append(sourceIDs, id)
+		seen[id] = true
+	}
+
+	response := dto.ToTagResponse(target)
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoTagRepo.MoveTags(ctx, sourceIDs, targetID); err != nil {
+			return err
+		}
+
+		// Aliases of the sources follow them into the target
+		if err := uc.tagRepo.MoveAliases(ctx, sourceIDs, targetID); err != nil {
+			return err
+		}
+		if req.KeepAliases {
+			aliases := make([]*entity.TagAlias, len(sources))
+			for i, source := range sources {
+				aliases[i] = &entity.TagAlias{UserID: userID, TagID: targetID, Name: source.Name}
+			}
+			if err := uc.tagRepo.AddAliases(ctx, aliases); err != nil {
+				return err
+			}
+		}
+
+		for _, source := range sources {
+			if err := uc.tagRepo.Delete(ctx, source.ID); err != nil {
+				return err
+			}
+			if err := recordEvent(ctx, uc.outboxRepo, event.TagDeleted, userID, "tag", source.ID, dto.ToTagResponse(source)); err != nil {
+				return err
+			}
+		}
+		return recordEvent(ctx, uc.outboxRepo, event.TagUpdated, userID, "tag", target.ID, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	if uc.tagCache != nil {
+		for _, source := range sources {
+			if err := uc.tagCache.DeleteTag(ctx, source); err != nil {
+				// Log error but don't fail the request
+				// In production, use proper logging
+			}
+		}
+	}
+	if uc.todoCache != nil {
+		lineage, _ := tagLineageIDs(ctx, uc.tagRepo, target)
+		for _, source := range sources {
+			sourceLineage, _ := tagLineageIDs(ctx, uc.tagRepo, source)
+			lineage = append(lineage, sourceLineage...)
+		}
+		if err := uc.todoCache.InvalidateTagged(ctx, userID, lineage); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+	if uc.tagUsage != nil {
+		if err := uc.tagUsage.Move(ctx, userID, sourceIDs, targetID); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+
+	return &response, nil
+}
+
+// ListTags lists a user's tags with pagination
+func (uc *TagUseCase) ListTags(ctx context.Context, userID int64, page, limit int) (*dto.TagListResponse, error) {
 	// Set default pagination values
 	if page < 1 {
 		page = 1
@@ -167,18 +427,9 @@ func (uc *TagUseCase) ListTags(ctx context.Context, page, limit int) (*dto.TagLi
 	var err error
 
 	if uc.tagCache != nil {
-		tags, total, err = uc.tagCache.GetTagList(ctx, page, limit)
+		tags, total, err = uc.tagCache.GetTagList(ctx, userID, page, limit)
 	} else {
-		tags, err = uc.tagRepo.List(ctx, offset, limit)
-		if err != nil {
-			return nil, err
-		}
-		// Get all tags for counting total
-		allTags, err := uc.tagRepo.List(ctx, 0, 10000)
-		if err != nil {
-			return nil, err
-		}
-		total = int64(len(allTags))
+		tags, total, err = uc.tagRepo.FindByUserID(ctx, userID, offset, limit)
 	}
 
 	if err != nil {
@@ -197,9 +448,9 @@ func (uc *TagUseCase) ListTags(ctx context.Context, page, limit int) (*dto.TagLi
 	}, nil
 }
 
-// GetTagsByUserID gets tags used by a specific user with todo counts
+// GetTagsByUserID gets the tags owned by a user with todo counts
 func (uc *TagUseCase) GetTagsByUserID(ctx context.Context, userID int64) ([]*dto.TagResponse, error) {
-	tags, err := uc.tagRepo.List(ctx, 0, 10000)
+	tags, _, err := uc.tagRepo.FindByUserID(ctx, userID, 0, 10000)
 	if err != nil {
 		return nil, err
 	}
@@ -221,3 +472,212 @@ func (uc *TagUseCase) GetTagsByUserID(ctx context.Context, userID int64) ([]*dto
 
 	return responses, nil
 }
+
+// GetTagTree returns a user's tags nested by path, with todo counts that
+// include the todos of nested tags
+func (uc *TagUseCase) GetTagTree(ctx context.Context, userID int64) ([]dto.TagTreeNode, error) {
+	tags, _, err := uc.tagRepo.FindByUserID(ctx, userID, 0, 10000)
+	if err != nil {
+		return nil, err
+	}
+
+	tagStats, err := uc.todoTagRepo.GetTagStatsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToTagTree(tags, tagStats), nil
+}
+
+// SuggestTags returns a user's tags whose path, or any segment of it,
+// starts with a prefix, ranked by how often and how recently they were used.
+// Unused tags follow in alphabetical order.
+func (uc *TagUseCase) SuggestTags(ctx context.Context, userID int64, prefix string, limit int) ([]dto.TagResponse, error) {
+	if limit < 1 || limit > 50 {
+		limit = defaultTagSuggestions
+	}
+
+	tags, _, err := uc.tagRepo.FindByUserID(ctx, userID, 0, 10000)
+	if err != nil {
+		return nil, err
+	}
+
+	var scores map[int64]float64
+	if uc.tagUsage != nil {
+		scores, err = uc.tagUsage.Scores(ctx, userID)
+		if err != nil {
+			// Usage only affects ranking; fall back to alphabetical order
+			scores = nil
+		}
+	}
+
+	return dto.ToTagResponseList(rankTagSuggestions(tags, scores, prefix, limit)), nil
+}
+
+// rankTagSuggestions filters tags by prefix, case-insensitively, and orders
+// them by usage score, then by name
+func rankTagSuggestions(tags []*entity.Tag, scores map[int64]float64, prefix string, limit int) []*entity.Tag {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+
+	var matches []*entity.Tag
+	for _, tag := range tags {
+		if matchesTagPrefix(tag.Name, prefix) {
+			matches = append(matches, tag)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		si, sj := scores[matches[i].ID], scores[matches[j].ID]
+		if si != sj {
+			return si > sj
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// matchesTagPrefix reports whether a tag path or one of its segments starts
+// with a lowercase prefix, so "back" suggests "work/backend"
+func matchesTagPrefix(name, prefix string) bool {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, prefix) {
+		return true
+	}
+	for _, segment := range strings.Split(name, entity.TagPathSeparator) {
+		if strings.HasPrefix(segment, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findDescendants finds the tags nested under a tag
+func (uc *TagUseCase) findDescendants(ctx context.Context, tag *entity.Tag) ([]*entity.Tag, error) {
+	tags, _, err := uc.tagRepo.FindByUserID(ctx, tag.UserID, 0, 10000)
+	if err != nil {
+		return nil, err
+	}
+
+	var descendants []*entity.Tag
+	for _, candidate := range tags {
+		if strings.HasPrefix(candidate.Name, tag.Name+entity.TagPathSeparator) {
+			descendants = append(descendants, candidate)
+		}
+	}
+	return descendants, nil
+}
+
+// checkNoChildren returns ErrTagHasChildren when tags are nested under a tag
+func (uc *TagUseCase) checkNoChildren(ctx context.Context, id int64) error {
+	hasChildren, err := uc.tagRepo.HasChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return ErrTagHasChildren
+	}
+	return nil
+}
+
+// findOwnedTag finds a tag and checks ownership
+func (uc *TagUseCase) findOwnedTag(ctx context.Context, id, userID int64) (*entity.Tag, error) {
+	tag, err := uc.tagRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	if tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// normalizeTagName validates a tag name and trims the segments of
+// path-style names, turning "work / backend" into "work/backend"
+func normalizeTagName(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", ErrTagNameRequired
+	}
+
+	segments := strings.Split(name, entity.TagPathSeparator)
+	if len(segments) > maxTagDepth {
+		return "", ErrTagPathTooDeep
+	}
+	for i, segment := range segments {
+		segments[i] = strings.TrimSpace(segment)
+		if segments[i] == "" {
+			return "", ErrInvalidTagPath
+		}
+	}
+
+	name = strings.Join(segments, entity.TagPathSeparator)
+	if len(name) > 100 {
+		return "", ErrTagNameTooLong
+	}
+	return name, nil
+}
+
+// validateTagMetadata validates the display metadata of a tag. Empty values
+// are allowed and clear the field.
+func validateTagMetadata(color, icon, description string) error {
+	if color != "" && !tagColorPattern.MatchString(color) {
+		return ErrInvalidTagColor
+	}
+	if len(icon) > 50 {
+		return ErrTagIconTooLong
+	}
+	if icon != "" && !tagIconPattern.MatchString(icon) {
+		return ErrInvalidTagIcon
+	}
+	if len(description) > 255 {
+		return ErrTagDescriptionTooLong
+	}
+	return nil
+}
+
+// tagLineageIDs returns the IDs of a tag and of the tags it is nested under,
+// which the todos tagged with it count towards, as far as they were found
+func tagLineageIDs(ctx context.Context, tagRepo repository.TagRepository, tag *entity.Tag) ([]int64, error) {
+	ids := []int64{tag.ID}
+	for tag.ParentID != nil {
+		parent, err := tagRepo.FindByID(ctx, *tag.ParentID)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, parent.ID)
+		tag = parent
+	}
+	return ids, nil
+}
+
+// ensureTagParent points a path-style tag at its parent, creating the
+// parent and its own ancestors when they do not exist yet
+func ensureTagParent(ctx context.Context, tagRepo repository.TagRepository, tag *entity.Tag) error {
+	parentPath := tag.ParentPath()
+	if parentPath == "" {
+		tag.ParentID = nil
+		return nil
+	}
+
+	parent, err := tagRepo.FindByName(ctx, tag.UserID, parentPath)
+	if errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
+		parent = &entity.Tag{UserID: tag.UserID, Name: parentPath}
+		if err := ensureTagParent(ctx, tagRepo, parent); err != nil {
+			return err
+		}
+		err = tagRepo.Create(ctx, parent)
+	}
+	if err != nil {
+		return err
+	}
+
+	tag.ParentID = &parent.ID
+	return nil
+}
@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	tagRepositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTagRepository keeps tags and aliases in memory
+type memoryTagRepository struct {
+	repository.TagRepository
+	tags    map[int64]*entity.Tag
+	aliases []*entity.TagAlias
+}
+
+func (r *memoryTagRepository) Create(ctx context.Context, tag *entity.Tag) error {
+	tag.ID = int64(len(r.tags) + 1)
+	r.tags[tag.ID] = tag
+	return nil
+}
+
+func (r *memoryTagRepository) FindByID(ctx context.Context, id int64) (*entity.Tag, error) {
+	tag, ok := r.tags[id]
+	if !ok {
+		return nil, tagRepositoryImpl.ErrTagNotFound
+	}
+	return tag, nil
+}
+
+func (r *memoryTagRepository) FindByName(ctx context.Context, userID int64, name string) (*entity.Tag, error) {
+	for _, tag := range r.tags {
+		if tag.UserID == userID && tag.Name == name {
+			return tag, nil
+		}
+	}
+	return nil, tagRepositoryImpl.ErrTagNotFound
+}
+
+func (r *memoryTagRepository) FindByUserID(ctx context.Context, userID int64, offset, limit int) ([]*entity.Tag, int64, error) {
+	var tags []*entity.Tag
+	for _, tag := range r.tags {
+		if tag.UserID == userID {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, int64(len(tags)), nil
+}
+
+func (r *memoryTagRepository) HasChildren(ctx context.Context, id int64) (bool, error) {
+	for _, tag := range r.tags {
+		if tag.ParentID != nil && *tag.ParentID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryTagRepository) FindByAlias(ctx context.Context, userID int64, name string) (*entity.Tag, error) {
+	for _, alias := range r.aliases {
+		if alias.UserID == userID && alias.Name == name {
+			return r.FindByID(ctx, alias.TagID)
+		}
+	}
+	return nil, tagRepositoryImpl.ErrTagNotFound
+}
+
+func (r *memoryTagRepository) AddAliases(ctx context.Context, aliases []*entity.TagAlias) error {
+	r.aliases = append(r.aliases, aliases...)
+	return nil
+}
+
+func (r *memoryTagRepository) MoveAliases(ctx context.Context, fromTagIDs []int64, toTagID int64) error {
+	for _, alias := range r.aliases {
+		for _, id := range fromTagIDs {
+			if alias.TagID == id {
+				alias.TagID = toTagID
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryTagRepository) Update(ctx context.Context, tag *entity.Tag) error {
+	r.tags[tag.ID] = tag
+	return nil
+}
+
+func (r *memoryTagRepository) Delete(ctx context.Context, id int64) error {
+	delete(r.tags, id)
+	return nil
+}
+
+// mergingTodoTagRepository records the links it was asked to move
+type mergingTodoTagRepository struct {
+	memoryTodoTagRepository
+	movedFrom []int64
+	movedTo   int64
+}
+
+func (r *mergingTodoTagRepository) MoveTags(ctx context.Context, fromTagIDs []int64, toTagID int64) error {
+	r.movedFrom = fromTagIDs
+	r.movedTo = toTagID
+	return nil
+}
+
+func TestTagUseCase_ScopesTagsToOwner(t *testing.T) {
+	tags := &memoryTagRepository{tags: map[int64]*entity.Tag{}}
+	outbox := &memoryOutbox{}
+	uc := NewTagUseCase(tags, &memoryTodoTagRepository{}, nil, nil, nil, nil, outbox)
+	ctx := context.Background()
+
+	// Both users can have a tag with the same name
+	mine, err := uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "work"})
+	require.NoError(t, err)
+	theirs, err := uc.CreateTag(ctx, 8, &dto.CreateTagRequest{Name: "work"})
+	require.NoError(t, err)
+	assert.NotEqual(t, mine.ID, theirs.ID)
+
+	_, err = uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "work"})
+	assert.EqualError(t, err, "tag with this name already exists")
+
+	// Other users' tags cannot be read, renamed or deleted
+	_, err = uc.GetTag(ctx, theirs.ID, 7)
+	assert.ErrorIs(t, err, ErrTagNotFound)
+	_, err = uc.UpdateTag(ctx, theirs.ID, 7, &dto.UpdateTagRequest{Name: "play"})
+	assert.ErrorIs(t, err, ErrTagNotFound)
+	assert.ErrorIs(t, uc.DeleteTag(ctx, theirs.ID, 7), ErrTagNotFound)
+	assert.Equal(t, "work", tags.tags[theirs.ID].Name)
+
+	renamed, err := uc.UpdateTag(ctx, mine.ID, 7, &dto.UpdateTagRequest{Name: "play"})
+	require.NoError(t, err)
+	assert.Equal(t, "play", renamed.Name)
+	require.NoError(t, uc.DeleteTag(ctx, mine.ID, 7))
+	assert.NotContains(t, tags.tags, mine.ID)
+
+	assert.Equal(t, []string{
+		string(event.TagCreated), string(event.TagCreated), string(event.TagUpdated), string(event.TagDeleted),
+	}, outbox.types())
+}
+
+func TestResolveTagIDs_UsesOwnTags(t *testing.T) {
+	tags := &memoryTagRepository{tags: map[int64]*entity.Tag{
+		1: {ID: 1, UserID: 8, Name: "work"},
+	}}
+	ctx := context.Background()
+
+	ids, err := resolveTagIDs(ctx, tags, 7, []string{"work"})
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.NotEqual(t, int64(1), ids[0])
+	assert.Equal(t, int64(7), tags.tags[ids[0]].UserID)
+
+	again, err := resolveTagIDs(ctx, tags, 7, []string{"work"})
+	require.NoError(t, err)
+	assert.Equal(t, ids, again)
+}
+
+func TestMergeTags(t *testing.T) {
+	tags := &memoryTagRepository{tags: map[int64]*entity.Tag{
+		1: {ID: 1, UserID: 7, Name: "bug"},
+		2: {ID: 2, UserID: 7, Name: "bugs"},
+		3: {ID: 3, UserID: 7, Name: "defect"},
+		4: {ID: 4, UserID: 8, Name: "issue"},
+	}}
+	tags.aliases = []*entity.TagAlias{{UserID: 7, TagID: 3, Name: "defects"}}
+	todoTags := &mergingTodoTagRepository{}
+	outbox := &memoryOutbox{}
+	uc := NewTagUseCase(tags, todoTags, nil, nil, nil, nil, outbox)
+	ctx := context.Background()
+
+	_, err := uc.MergeTags(ctx, 1, 7, &dto.MergeTagsRequest{SourceIDs: []int64{1}})
+	assert.ErrorIs(t, err, ErrTagMergeSelf)
+	_, err = uc.MergeTags(ctx, 1, 7, &dto.MergeTagsRequest{SourceIDs: []int64{4}})
+	assert.ErrorIs(t, err, ErrTagNotFound)
+	assert.Empty(t, outbox.types())
+
+	target, err := uc.MergeTags(ctx, 1, 7, &dto.MergeTagsRequest{SourceIDs: []int64{2, 3, 2}, KeepAliases: true})
+	require.NoError(t, err)
+	assert.Equal(t, "bug", target.Name)
+	assert.Equal(t, []int64{2, 3}, todoTags.movedFrom)
+	assert.Equal(t, int64(1), todoTags.movedTo)
+	assert.NotContains(t, tags.tags, int64(2))
+	assert.NotContains(t, tags.tags, int64(3))
+	assert.Equal(t, []string{
+		string(event.TagDeleted), string(event.TagDeleted), string(event.TagUpdated),
+	}, outbox.types())
+
+	// The old names, and the aliases of the merged tags, resolve to the target
+	ids, err := resolveTagIDs(ctx, tags, 7, []string{"bugs", "defect", "defects"})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, ids)
+}
+
+func TestTagUseCase_NestsPathStyleTags(t *testing.T) {
+	tags := &memoryTagRepository{tags: map[int64]*entity.Tag{}}
+	uc := NewTagUseCase(tags, &memoryTodoTagRepository{}, nil, nil, nil, nil, &memoryOutbox{})
+	ctx := context.Background()
+
+	db, err := uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "work / backend/db"})
+	require.NoError(t, err)
+	assert.Equal(t, "work/backend/db", db.Name)
+
+	work, err := tags.FindByName(ctx, 7, "work")
+	require.NoError(t, err)
+	assert.Nil(t, work.ParentID)
+	backend, err := tags.FindByName(ctx, 7, "work/backend")
+	require.NoError(t, err)
+	assert.Equal(t, &work.ID, backend.ParentID)
+	assert.Equal(t, &backend.ID, db.ParentID)
+
+	_, err = uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "work//db"})
+	assert.ErrorIs(t, err, ErrInvalidTagPath)
+	_, err = uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "a/b/c/d/e/f"})
+	assert.ErrorIs(t, err, ErrTagPathTooDeep)
+
+	// Nested tags block deletion and follow their parent when it is renamed
+	assert.ErrorIs(t, uc.DeleteTag(ctx, work.ID, 7), ErrTagHasChildren)
+	_, err = uc.UpdateTag(ctx, work.ID, 7, &dto.UpdateTagRequest{Name: "work/backend/old"})
+	assert.ErrorIs(t, err, ErrTagMoveIntoSelf)
+
+	_, err = uc.UpdateTag(ctx, work.ID, 7, &dto.UpdateTagRequest{Name: "job"})
+	require.NoError(t, err)
+	assert.Equal(t, "job/backend", tags.tags[backend.ID].Name)
+	assert.Equal(t, "job/backend/db", tags.tags[db.ID].Name)
+
+	// Tagging a todo by path creates the missing levels too
+	ids, err := resolveTagIDs(ctx, tags, 7, []string{"job/frontend/css"})
+	require.NoError(t, err)
+	frontend, err := tags.FindByName(ctx, 7, "job/frontend")
+	require.NoError(t, err)
+	assert.Equal(t, &work.ID, frontend.ParentID)
+	assert.Equal(t, &frontend.ID, tags.tags[ids[0]].ParentID)
+}
+
+func TestToTagTree(t *testing.T) {
+	parentID := int64(1)
+	tags := []*entity.Tag{
+		{ID: 2, ParentID: &parentID, Name: "work/frontend"},
+		{ID: 3, Name: "home"},
+		{ID: 1, Name: "work"},
+		{ID: 4, ParentID: &parentID, Name: "work/backend"},
+	}
+
+	tree := dto.ToTagTree(tags, map[int64]int64{1: 3, 4: 2})
+	require.Len(t, tree, 2)
+	assert.Equal(t, "home", tree[0].Path)
+	assert.Equal(t, "work", tree[1].Path)
+	assert.Equal(t, int64(3), tree[1].TodoCount)
+	require.Len(t, tree[1].Children, 2)
+	assert.Equal(t, "backend", tree[1].Children[0].Name)
+	assert.Equal(t, "work/backend", tree[1].Children[0].Path)
+	assert.Equal(t, int64(2), tree[1].Children[0].TodoCount)
+	assert.Equal(t, "frontend", tree[1].Children[1].Name)
+}
+
+func TestTagUseCase_ValidatesMetadata(t *testing.T) {
+	tags := &memoryTagRepository{tags: map[int64]*entity.Tag{}}
+	uc := NewTagUseCase(tags, &memoryTodoTagRepository{}, nil, nil, nil, nil, &memoryOutbox{})
+	ctx := context.Background()
+
+	_, err := uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "work", Color: "blue"})
+	assert.ErrorIs(t, err, ErrInvalidTagColor)
+	_, err = uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "work", Icon: "Brief Case"})
+	assert.ErrorIs(t, err, ErrInvalidTagIcon)
+	assert.Empty(t, tags.tags)
+
+	work, err := uc.CreateTag(ctx, 7, &dto.CreateTagRequest{Name: "work", Color: "#1E90ff", Icon: "briefcase", Description: " Day job "})
+	require.NoError(t, err)
+	assert.Equal(t, "#1E90ff", work.Color)
+	assert.Equal(t, "briefcase", work.Icon)
+	assert.Equal(t, "Day job", work.Description)
+
+	// Omitted fields are kept and empty ones are cleared
+	empty := ""
+	icon := "check-circle"
+	updated, err := uc.UpdateTag(ctx, work.ID, 7, &dto.UpdateTagRequest{Name: "work", Icon: &icon, Description: &empty})
+	require.NoError(t, err)
+	assert.Equal(t, "#1E90ff", updated.Color)
+	assert.Equal(t, "check-circle", updated.Icon)
+	assert.Empty(t, updated.Description)
+
+	color := "#12345"
+	_, err = uc.UpdateTag(ctx, work.ID, 7, &dto.UpdateTagRequest{Name: "work", Color: &color})
+	assert.ErrorIs(t, err, ErrInvalidTagColor)
+	assert.Equal(t, "#1E90ff", tags.tags[work.ID].Color)
+}
+
+func TestRankTagSuggestions(t *testing.T) {
+	tags := []*entity.Tag{
+		{ID: 1, Name: "work"},
+		{ID: 2, Name: "work/backend"},
+		{ID: 3, Name: "home"},
+		{ID: 4, Name: "Workout"},
+		{ID: 5, Name: "bugs"},
+	}
+	names := func(tags []*entity.Tag) []string {
+		var result []string
+		for _, tag := range tags {
+			result = append(result, tag.Name)
+		}
+		return result
+	}
+
+	// Used tags come first, then the rest alphabetically
+	scores := map[int64]float64{4: 3.5, 1: 1.2}
+	assert.Equal(t, []string{"Workout", "work", "work/backend"}, names(rankTagSuggestions(tags, scores, "WO", 10)))
+
+	// Segments match too
+	assert.Equal(t, []string{"bugs", "work/backend"}, names(rankTagSuggestions(tags, nil, "b", 10)))
+
+	assert.Equal(t, []string{"Workout", "work"}, names(rankTagSuggestions(tags, scores, "", 2)))
+}
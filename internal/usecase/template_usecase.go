@@ -0,0 +1,378 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+// maxTemplateItems bounds the number of todos, subtasks included, in a template
+const maxTemplateItems = 100
+
+var (
+	ErrTemplateNotFound     = errors.New("template not found")
+	ErrTooManyTemplateItems = errors.New("template has too many items")
+)
+
+// TemplateUseCase implements business logic for todo templates
+type TemplateUseCase struct {
+	templateRepo repository.TemplateRepository
+	todoRepo     repository.TodoRepository
+	tagRepo      repository.TagRepository
+	todoTagRepo  repository.TodoTagRepository
+	workflowRepo repository.WorkflowRepository
+	todoCache    *cache.TodoCache
+	txManager    repository.TransactionManager
+	outboxRepo   repository.OutboxRepository
+}
+
+// NewTemplateUseCase creates a new template use case
+func NewTemplateUseCase(templateRepo repository.TemplateRepository, todoRepo repository.TodoRepository, tagRepo repository.TagRepository, todoTagRepo repository.TodoTagRepository, workflowRepo repository.WorkflowRepository, todoCache *cache.TodoCache, txManager repository.TransactionManager, outboxRepo repository.OutboxRepository) *TemplateUseCase {
+	return &TemplateUseCase{
+		templateRepo: templateRepo,
+		todoRepo:     todoRepo,
+		tagRepo:      tagRepo,
+		todoTagRepo:  todoTagRepo,
+		workflowRepo: workflowRepo,
+		todoCache:    todoCache,
+		txManager:    txManager,
+		outboxRepo:   outboxRepo,
+	}
+}
+
+// CreateTemplate creates a new template
+func (uc *TemplateUseCase) CreateTemplate(ctx context.Context, userID int64, req *dto.CreateTemplateRequest) (*dto.TemplateResponse, error) {
+	items, err := buildTemplateItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &entity.Template{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Items:       items,
+	}
+
+	if err := uc.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToTemplateResponse(template)
+	return &response, nil
+}
+
+// CreateTemplateFromTodos captures a set of the user's todos as a template.
+// Due dates become offsets from the reference date and subtask links between
+// the selected todos are kept.
+func (uc *TemplateUseCase) CreateTemplateFromTodos(ctx context.Context, userID int64, req *dto.CreateTemplateFromTodosRequest) (*dto.TemplateResponse, error) {
+	todos := make([]*entity.Todo, 0, len(req.TodoIDs))
+	selected := make(map[int64]bool, len(req.TodoIDs))
+	for _, id := range req.TodoIDs {
+		if selected[id] {
+			continue
+		}
+		todo, err := uc.todoRepo.FindByID(ctx, id)
+		if err != nil || todo.UserID != userID {
+			return nil, ErrTodoNotFound
+		}
+		todos = append(todos, todo)
+		selected[id] = true
+	}
+
+	reference := todos[0].CreatedAt
+	if req.ReferenceDate != nil {
+		reference = *req.ReferenceDate
+	} else {
+		for _, todo := range todos {
+			if todo.CreatedAt.Before(reference) {
+				reference = todo.CreatedAt
+			}
+		}
+	}
+
+	ids := make([]int64, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	tagsByTodo, err := uc.todoTagRepo.GetTagsByTodoIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group subtasks under their parents; todos whose parent was not
+	// selected become top-level items
+	children := make(map[int64][]*entity.Todo)
+	var roots []*entity.Todo
+	for _, todo := range todos {
+		if todo.ParentID != nil && selected[*todo.ParentID] {
+			children[*todo.ParentID] = append(children[*todo.ParentID], todo)
+		} else {
+			roots = append(roots, todo)
+		}
+	}
+
+	var items []entity.TemplateItem
+	var add func(todo *entity.Todo, parentPosition *int)
+	add = func(todo *entity.Todo, parentPosition *int) {
+		item := entity.TemplateItem{
+			Position:       len(items),
+			ParentPosition: parentPosition,
+			Title:          todo.Title,
+			Description:    todo.Description,
+			Priority:       todo.Priority,
+		}
+		if todo.DueDate != nil {
+			offset := int(math.Round(todo.DueDate.Sub(reference).Hours() / 24))
+			if offset < 0 {
+				offset = 0
+			}
+			item.DueOffsetDays = &offset
+		}
+
+		tags := tagsByTodo[todo.ID]
+		names := make([]string, len(tags))
+		for i, tag := range tags {
+			names[i] = tag.Name
+		}
+		item.SetTagList(names)
+
+		items = append(items, item)
+		position := item.Position
+		for _, child := range children[todo.ID] {
+			add(child, &position)
+		}
+	}
+	for _, todo := range roots {
+		add(todo, nil)
+	}
+
+	template := &entity.Template{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Items:       items,
+	}
+
+	if err := uc.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToTemplateResponse(template)
+	return &response, nil
+}
+
+// ListTemplates lists all templates owned by a user
+func (uc *TemplateUseCase) ListTemplates(ctx context.Context, userID int64) ([]dto.TemplateResponse, error) {
+	templates, err := uc.templateRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToTemplateResponseList(templates), nil
+}
+
+// GetTemplate retrieves a single template owned by a user
+func (uc *TemplateUseCase) GetTemplate(ctx context.Context, id, userID int64) (*dto.TemplateResponse, error) {
+	template, err := uc.findOwnedTemplate(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToTemplateResponse(template)
+	return &response, nil
+}
+
+// UpdateTemplate updates a template owned by a user
+func (uc *TemplateUseCase) UpdateTemplate(ctx context.Context, id, userID int64, req *dto.UpdateTemplateRequest) (*dto.TemplateResponse, error) {
+	template, err := uc.findOwnedTemplate(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		template.Name = *req.Name
+	}
+
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+
+	if req.Items != nil {
+		items, err := buildTemplateItems(req.Items)
+		if err != nil {
+			return nil, err
+		}
+		template.Items = items
+	}
+
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.templateRepo.Update(ctx, template); err != nil {
+			return err
+		}
+		if req.Items != nil {
+			return uc.templateRepo.ReplaceItems(ctx, template.ID, template.Items)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToTemplateResponse(template)
+	return &response, nil
+}
+
+// DeleteTemplate deletes a template owned by a user
+func (uc *TemplateUseCase) DeleteTemplate(ctx context.Context, id, userID int64) error {
+	if _, err := uc.findOwnedTemplate(ctx, id, userID); err != nil {
+		return err
+	}
+	return uc.templateRepo.Delete(ctx, id)
+}
+
+// InstantiateTemplate creates one todo per template item in a single
+// transaction. Due dates are the item offsets added to the start date.
+func (uc *TemplateUseCase) InstantiateTemplate(ctx context.Context, id, userID int64, req *dto.InstantiateTemplateRequest) ([]dto.TodoResponse, error) {
+	template, err := uc.findOwnedTemplate(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := loadWorkflow(ctx, uc.workflowRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	start := now
+	if req.StartDate != nil {
+		start = *req.StartDate
+	}
+
+	todos := make([]*entity.Todo, 0, len(template.Items))
+	responses := make([]dto.TodoResponse, 0, len(template.Items))
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		// Items are ordered by position, so parents are created before their subtasks
+		todoIDs := make(map[int]int64, len(template.Items))
+		for _, item := range template.Items {
+			todo := &entity.Todo{
+				UserID:      userID,
+				Title:       item.Title,
+				Description: item.Description,
+				Priority:    item.Priority,
+			}
+			todo.SetStatus(workflow.InitialState(), now)
+			if item.ParentPosition != nil {
+				if parentID, ok := todoIDs[*item.ParentPosition]; ok {
+					todo.ParentID = &parentID
+				}
+			}
+			if item.DueOffsetDays != nil {
+				dueDate := start.AddDate(0, 0, *item.DueOffsetDays)
+				todo.DueDate = &dueDate
+			}
+
+			if err := uc.todoRepo.Create(ctx, todo); err != nil {
+				return err
+			}
+			todoIDs[item.Position] = todo.ID
+
+			if names := item.TagList(); len(names) > 0 {
+				tagIDs, err := resolveTagIDs(ctx, uc.tagRepo, userID, names)
+				if err != nil {
+					return err
+				}
+				if err := uc.todoTagRepo.AddTagsToTodo(ctx, todo.ID, tagIDs); err != nil {
+					return err
+				}
+			}
+
+			tags, _ := uc.todoTagRepo.GetTagsByTodoID(ctx, todo.ID)
+			response := dto.ToTodoResponseWithTags(todo, tags)
+
+			if err := recordEvent(ctx, uc.outboxRepo, event.TodoCreated, userID, "todo", todo.ID, response); err != nil {
+				return err
+			}
+
+			todos = append(todos, todo)
+			responses = append(responses, response)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	if uc.todoCache != nil {
+		for _, todo := range todos {
+			if err := uc.todoCache.CreateTodo(ctx, todo); err != nil {
+				// Log error but don't fail the request
+				// In production, use proper logging
+			}
+		}
+	}
+
+	return responses, nil
+}
+
+// findOwnedTemplate finds a template and checks ownership
+func (uc *TemplateUseCase) findOwnedTemplate(ctx context.Context, id, userID int64) (*entity.Template, error) {
+	template, err := uc.templateRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	if template.UserID != userID {
+		return nil, ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+// buildTemplateItems flattens nested item requests into position-ordered
+// items, recording each subtask's parent position
+func buildTemplateItems(requests []dto.TemplateItemRequest) ([]entity.TemplateItem, error) {
+	var items []entity.TemplateItem
+	var add func(requests []dto.TemplateItemRequest, parentPosition *int) error
+	add = func(requests []dto.TemplateItemRequest, parentPosition *int) error {
+		for _, req := range requests {
+			if len(items) >= maxTemplateItems {
+				return ErrTooManyTemplateItems
+			}
+
+			priority, err := parsePriority(req.Priority)
+			if err != nil {
+				return err
+			}
+
+			item := entity.TemplateItem{
+				Position:       len(items),
+				ParentPosition: parentPosition,
+				Title:          req.Title,
+				Description:    req.Description,
+				Priority:       priority,
+				DueOffsetDays:  req.DueOffsetDays,
+			}
+			item.SetTagList(req.Tags)
+			items = append(items, item)
+
+			position := item.Position
+			if err := add(req.Subtasks, &position); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := add(requests, nil); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
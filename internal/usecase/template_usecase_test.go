@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTemplateRepository serves a single template
+type memoryTemplateRepository struct {
+	repository.TemplateRepository
+	template *entity.Template
+}
+
+func (r *memoryTemplateRepository) FindByID(ctx context.Context, id int64) (*entity.Template, error) {
+	if r.template == nil || r.template.ID != id {
+		return nil, ErrTemplateNotFound
+	}
+	return r.template, nil
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestBuildTemplateItems_FlattensSubtasks(t *testing.T) {
+	requests := []dto.TemplateItemRequest{
+		{Title: "Set up laptop", Subtasks: []dto.TemplateItemRequest{
+			{Title: "Install tools", Priority: "high"},
+			{Title: "Request access", DueOffsetDays: intPtr(1)},
+		}},
+		{Title: "Meet the team", Tags: []string{"onboarding", "people"}},
+	}
+
+	items, err := buildTemplateItems(requests)
+	require.NoError(t, err)
+	require.Len(t, items, 4)
+
+	assert.Equal(t, "Set up laptop", items[0].Title)
+	assert.Nil(t, items[0].ParentPosition)
+	assert.Equal(t, entity.TodoPriorityP2, items[0].Priority)
+	assert.Equal(t, intPtr(0), items[1].ParentPosition)
+	assert.Equal(t, entity.TodoPriorityP1, items[1].Priority)
+	assert.Equal(t, intPtr(0), items[2].ParentPosition)
+	assert.Nil(t, items[3].ParentPosition)
+	assert.Equal(t, []string{"onboarding", "people"}, items[3].TagList())
+
+	// The response nests the items again
+	response := dto.ToTemplateResponse(&entity.Template{Items: items})
+	require.Len(t, response.Items, 2)
+	require.Len(t, response.Items[0].Subtasks, 2)
+	assert.Equal(t, "Request access", response.Items[0].Subtasks[1].Title)
+	assert.Equal(t, intPtr(1), response.Items[0].Subtasks[1].DueOffsetDays)
+}
+
+func TestBuildTemplateItems_TooMany(t *testing.T) {
+	subtasks := make([]dto.TemplateItemRequest, maxTemplateItems)
+	for i := range subtasks {
+		subtasks[i] = dto.TemplateItemRequest{Title: "Step"}
+	}
+
+	_, err := buildTemplateItems([]dto.TemplateItemRequest{{Title: "Parent", Subtasks: subtasks}})
+	assert.ErrorIs(t, err, ErrTooManyTemplateItems)
+}
+
+func TestInstantiateTemplate(t *testing.T) {
+	templates := &memoryTemplateRepository{template: &entity.Template{
+		ID:     3,
+		UserID: 7,
+		Items: []entity.TemplateItem{
+			{Position: 0, Title: "Set up laptop", Priority: entity.TodoPriorityP2, DueOffsetDays: intPtr(2)},
+			{Position: 1, ParentPosition: intPtr(0), Title: "Install tools", Priority: entity.TodoPriorityP1},
+		},
+	}}
+	todos := &memoryTodoRepository{todos: map[int64]*entity.Todo{}}
+	outbox := &memoryOutbox{}
+	uc := NewTemplateUseCase(templates, todos, nil, &memoryTodoTagRepository{}, nil, nil, nil, outbox)
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	responses, err := uc.InstantiateTemplate(context.Background(), 3, 7, &dto.InstantiateTemplateRequest{StartDate: &start})
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+
+	parent, child := todos.todos[responses[0].ID], todos.todos[responses[1].ID]
+	assert.Equal(t, int64(7), parent.UserID)
+	assert.Equal(t, start.AddDate(0, 0, 2), *parent.DueDate)
+	assert.Nil(t, parent.ParentID)
+	assert.Equal(t, &parent.ID, child.ParentID)
+	assert.Nil(t, child.DueDate)
+	assert.Equal(t, entity.TodoPriorityP1, child.Priority)
+	assert.Equal(t, []string{"todo.created", "todo.created"}, outbox.types())
+
+	_, err = uc.InstantiateTemplate(context.Background(), 3, 8, &dto.InstantiateTemplateRequest{})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
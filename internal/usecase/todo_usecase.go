@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
 	"github.com/darron08/todolist-demo/internal/domain/repository"
 	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
 	tagRepositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
@@ -13,32 +17,63 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	// defaultMatrixLimit is the number of todos listed per matrix quadrant
+	defaultMatrixLimit = 20
+	// maxMatrixTodos caps the open todos sorted into the matrix
+	maxMatrixTodos = 1000
+	// maxMentions caps the users notified of a single text
+	maxMentions = 20
+)
+
 var (
 	ErrTodoTitleRequired      = errors.New("todo title is required")
 	ErrTodoTitleTooLong       = errors.New("todo title is too long")
 	ErrTodoDescriptionTooLong = errors.New("todo description is too long")
 	ErrInvalidStatus          = errors.New("invalid status")
+	ErrTransitionNotAllowed   = errors.New("status transition is not allowed by the workflow")
 	ErrInvalidPriority        = errors.New("invalid priority")
 	ErrTodoNotFound           = errors.New("todo not found")
 	ErrTagNotFound            = errors.New("tag not found")
 	ErrUnauthorized           = errors.New("unauthorized")
+	ErrInvalidSnoozeTime      = errors.New("snooze time must be in the future")
+	ErrParentTodoNotFound     = errors.New("parent todo not found")
+	ErrInvalidDueDateBound    = errors.New("invalid due date bound: use RFC 3339 or YYYY-MM-DD")
+	ErrStartAfterDue          = errors.New("start date must not be after the due date")
+	ErrScheduledAfterDue      = errors.New("scheduled date must not be after the due date")
+	ErrScheduledBeforeStart   = errors.New("scheduled date must not be before the start date")
+	ErrInvalidAssignee        = errors.New("assignees must be members of the organization")
 )
 
 // TodoUseCase implements business logic for todos
 type TodoUseCase struct {
-	todoRepo    repository.TodoRepository
-	tagRepo     repository.TagRepository
-	todoTagRepo repository.TodoTagRepository
-	todoCache   *cache.TodoCache
+	todoRepo     repository.TodoRepository
+	tagRepo      repository.TagRepository
+	todoTagRepo  repository.TodoTagRepository
+	assigneeRepo repository.TodoAssigneeRepository
+	workflowRepo repository.WorkflowRepository
+	userRepo     repository.UserRepository
+	todoCache    *cache.TodoCache
+	txManager    repository.TransactionManager
+	outboxRepo   repository.OutboxRepository
+	// urgentWithin is how soon a todo must be due to count as urgent in the
+	// Eisenhower matrix
+	urgentWithin time.Duration
 }
 
 // NewTodoUseCase creates a new todo use case
-func NewTodoUseCase(todoRepo repository.TodoRepository, tagRepo repository.TagRepository, todoTagRepo repository.TodoTagRepository, todoCache *cache.TodoCache) *TodoUseCase {
+func NewTodoUseCase(todoRepo repository.TodoRepository, tagRepo repository.TagRepository, todoTagRepo repository.TodoTagRepository, assigneeRepo repository.TodoAssigneeRepository, workflowRepo repository.WorkflowRepository, userRepo repository.UserRepository, todoCache *cache.TodoCache, txManager repository.TransactionManager, outboxRepo repository.OutboxRepository, urgentWithin time.Duration) *TodoUseCase {
 	return &TodoUseCase{
-		todoRepo:    todoRepo,
-		tagRepo:     tagRepo,
-		todoTagRepo: todoTagRepo,
-		todoCache:   todoCache,
+		todoRepo:     todoRepo,
+		tagRepo:      tagRepo,
+		todoTagRepo:  todoTagRepo,
+		assigneeRepo: assigneeRepo,
+		workflowRepo: workflowRepo,
+		userRepo:     userRepo,
+		todoCache:    todoCache,
+		txManager:    txManager,
+		outboxRepo:   outboxRepo,
+		urgentWithin: urgentWithin,
 	}
 }
 
@@ -58,73 +93,108 @@ func (uc *TodoUseCase) CreateTodo(ctx context.Context, userID int64, req *dto.Cr
 	}
 
 	// Set default priority if not provided
-	priority := entity.TodoPriorityMedium
-	if req.Priority != "" {
-		switch req.Priority {
-		case "low":
-			priority = entity.TodoPriorityLow
-		case "medium":
-			priority = entity.TodoPriorityMedium
-		case "high":
-			priority = entity.TodoPriorityHigh
-		default:
-			return nil, ErrInvalidPriority
+	priority, err := parsePriority(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subtasks may only be attached to the user's own todos
+	if req.ParentID != nil {
+		parent, err := uc.todoRepo.FindByID(ctx, *req.ParentID)
+		if err != nil || parent.UserID != userID {
+			return nil, ErrParentTodoNotFound
 		}
 	}
 
+	// Todos may only be assigned to members of the organization
+	assigneeIDs, err := uc.checkAssignees(ctx, req.AssigneeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// New todos start in the first state of the user's workflow
+	workflow, err := loadWorkflow(ctx, uc.workflowRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create todo entity
 	todo := &entity.Todo{
-		UserID:      userID,
-		Title:       req.Title,
-		Description: req.Description,
-		DueDate:     req.DueDate,
-		Status:      entity.TodoStatusNotStarted,
-		Priority:    priority,
+		UserID:       userID,
+		ParentID:     req.ParentID,
+		Title:        req.Title,
+		Description:  req.Description,
+		StartDate:    req.StartDate,
+		ScheduledFor: req.ScheduledFor,
+		Priority:     priority,
+		Urgent:       req.Urgent,
+		Important:    req.Important,
+		AssigneeIDs:  assigneeIDs,
 	}
+	todo.SetStatus(workflow.InitialState(), time.Now())
 
-	// Save to database
-	if err := uc.todoRepo.Create(ctx, todo); err != nil {
+	// All-day todos are due until the end of the day in the user's timezone
+	if req.DueDate != nil {
+		loc, err := loadLocation(ctx, uc.userRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+		todo.SetDueDate(*req.DueDate, req.AllDay, loc)
+	}
+	if err := validateSchedule(todo); err != nil {
 		return nil, err
 	}
 
-	// Update cache
-	if uc.todoCache != nil {
-		if err := uc.todoCache.CreateTodo(ctx, todo); err != nil {
-			// Log error but don't fail the request
-			// In production, use proper logging
+	var response dto.TodoResponse
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		// Save to database
+		if err := uc.todoRepo.Create(ctx, todo); err != nil {
+			return err
 		}
-	}
 
-	// Handle tags if provided
-	if len(req.Tags) > 0 {
-		var tagIDs []int64
-		for _, tagName := range req.Tags {
-			tag, err := uc.tagRepo.FindByName(ctx, tagName)
+		// Handle tags if provided
+		if len(req.Tags) > 0 {
+			tagIDs, err := resolveTagIDs(ctx, uc.tagRepo, todo.UserID, req.Tags)
 			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
-					// Create new tag
-					newTag := &entity.Tag{Name: tagName}
-					if err := uc.tagRepo.Create(ctx, newTag); err != nil {
-						return nil, err
-					}
-					tagIDs = append(tagIDs, newTag.ID)
-				} else {
-					return nil, err
-				}
-			} else {
-				tagIDs = append(tagIDs, tag.ID)
+				return err
+			}
+			if err := uc.todoTagRepo.AddTagsToTodo(ctx, todo.ID, tagIDs); err != nil {
+				return err
 			}
 		}
-		if err := uc.todoTagRepo.AddTagsToTodo(ctx, todo.ID, tagIDs); err != nil {
-			return nil, err
+
+		// Assign the todo
+		if err := uc.addAssignees(ctx, todo.ID, userID, assigneeIDs); err != nil {
+			return err
+		}
+
+		// Get tags for response
+		tags, _ := uc.todoTagRepo.GetTagsByTodoID(ctx, todo.ID)
+
+		// Convert to response
+		response = dto.ToTodoResponseWithTags(todo, tags)
+
+		// Record events
+		if err := recordEvent(ctx, uc.outboxRepo, event.TodoCreated, userID, "todo", todo.ID, response); err != nil {
+			return err
+		}
+		if err := uc.recordAssignments(ctx, event.TodoAssigned, userID, assigneeIDs, response); err != nil {
+			return err
 		}
+		return uc.recordMentions(ctx, userID, todo.Description, "", response)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Get tags for response
-	tags, _ := uc.todoTagRepo.GetTagsByTodoID(ctx, todo.ID)
+	// Update cache
+	if uc.todoCache != nil {
+		if err := uc.todoCache.CreateTodo(ctx, todo); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
 
-	// Convert to response
-	response := dto.ToTodoResponseWithTags(todo, tags)
 	return &response, nil
 }
 
@@ -144,8 +214,11 @@ func (uc *TodoUseCase) GetTodo(ctx context.Context, id int64, userID int64) (*dt
 		return nil, err
 	}
 
-	// Check ownership
-	if todo.UserID != userID {
+	// Owners and assignees may see the todo
+	if err := uc.loadAssignees(ctx, todo); err != nil {
+		return nil, err
+	}
+	if todo.UserID != userID && !todo.IsAssignedTo(userID) {
 		return nil, ErrUnauthorized
 	}
 
@@ -169,6 +242,9 @@ func (uc *TodoUseCase) UpdateTodo(ctx context.Context, id int64, userID int64, r
 		return nil, ErrUnauthorized
 	}
 
+	wasDone := todo.IsDone()
+	previousDescription := todo.Description
+
 	// Update fields if provided
 	if req.Title != nil {
 		if *req.Title == "" {
@@ -187,78 +263,159 @@ func (uc *TodoUseCase) UpdateTodo(ctx context.Context, id int64, userID int64, r
 		todo.Description = *req.Description
 	}
 
-	if req.DueDate != nil {
-		todo.DueDate = req.DueDate
+	if req.DueDate != nil || req.AllDay != nil {
+		if err := uc.updateDueDate(ctx, todo, req.DueDate, req.AllDay); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ClearStartDate {
+		todo.StartDate = nil
+	}
+	if req.StartDate != nil {
+		todo.StartDate = req.StartDate
+	}
+
+	if req.ClearScheduledFor {
+		todo.ScheduledFor = nil
+	}
+	if req.ScheduledFor != nil {
+		todo.ScheduledFor = req.ScheduledFor
+	}
+
+	if err := validateSchedule(todo); err != nil {
+		return nil, err
 	}
 
 	if req.Status != nil {
-		switch *req.Status {
-		case "not_started":
-			todo.Status = entity.TodoStatusNotStarted
-		case "in_progress":
-			todo.Status = entity.TodoStatusInProgress
-		case "completed":
-			todo.Status = entity.TodoStatusCompleted
-		default:
-			return nil, ErrInvalidStatus
+		if err := uc.changeStatus(ctx, todo, *req.Status); err != nil {
+			return nil, err
 		}
 	}
 
 	if req.Priority != nil {
-		switch *req.Priority {
-		case "low":
-			todo.Priority = entity.TodoPriorityLow
-		case "medium":
-			todo.Priority = entity.TodoPriorityMedium
-		case "high":
-			todo.Priority = entity.TodoPriorityHigh
-		default:
+		priority, ok := entity.ParsePriority(*req.Priority)
+		if !ok {
 			return nil, ErrInvalidPriority
 		}
+		todo.Priority = priority
+	}
+
+	if req.ResetMatrixFlags {
+		todo.Urgent = nil
+		todo.Important = nil
+	}
+	if req.Urgent != nil {
+		todo.Urgent = req.Urgent
+	}
+	if req.Important != nil {
+		todo.Important = req.Important
 	}
 
-	// Save changes
-	if err := uc.todoRepo.Update(ctx, todo); err != nil {
+	// Work out who the todo is assigned to and unassigned from
+	if err := uc.loadAssignees(ctx, todo); err != nil {
 		return nil, err
 	}
+	var assigned, unassigned []int64
+	if req.AssigneeIDs != nil {
+		assigneeIDs, err := uc.checkAssignees(ctx, req.AssigneeIDs)
+		if err != nil {
+			return nil, err
+		}
 
-	// Update cache
-	if uc.todoCache != nil {
-		if err := uc.todoCache.UpdateTodo(ctx, todo); err != nil {
-			// Log error but don't fail the request
-			// In production, use proper logging
+		kept := make([]int64, 0, len(assigneeIDs))
+		for _, assigneeID := range todo.AssigneeIDs {
+			if containsID(assigneeIDs, assigneeID) {
+				kept = append(kept, assigneeID)
+			} else {
+				unassigned = append(unassigned, assigneeID)
+			}
 		}
+		for _, assigneeID := range assigneeIDs {
+			if !containsID(kept, assigneeID) {
+				assigned = append(assigned, assigneeID)
+			}
+		}
+		todo.AssigneeIDs = append(kept, assigned...)
 	}
 
-	// Handle tags if provided
-	if req.Tags != nil {
-		var tagIDs []int64
-		for _, tagName := range req.Tags {
-			tag, err := uc.tagRepo.FindByName(ctx, tagName)
+	var response dto.TodoResponse
+	var retagged []int64
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		// Save changes
+		if err := uc.todoRepo.Update(ctx, todo); err != nil {
+			return err
+		}
+
+		// Handle assignees if provided
+		if len(unassigned) > 0 {
+			if err := uc.assigneeRepo.RemoveAssignees(ctx, todo.ID, unassigned); err != nil {
+				return err
+			}
+		}
+		if err := uc.addAssignees(ctx, todo.ID, userID, assigned); err != nil {
+			return err
+		}
+
+		// Handle tags if provided, noting the tags the todo no longer or newly
+		// counts towards
+		if req.Tags != nil {
+			tagIDs, err := resolveTagIDs(ctx, uc.tagRepo, todo.UserID, req.Tags)
 			if err != nil {
-				if err == ErrTagNotFound {
-					// Create new tag
-					newTag := &entity.Tag{Name: tagName}
-					if err := uc.tagRepo.Create(ctx, newTag); err != nil {
-						return nil, err
-					}
-					tagIDs = append(tagIDs, newTag.ID)
-				} else {
-					return nil, err
-				}
-			} else {
-				tagIDs = append(tagIDs, tag.ID)
+				return err
+			}
+			oldTagIDs, err := uc.todoTagRepo.GetTagLineageIDsByTodoID(ctx, todo.ID)
+			if err != nil {
+				return err
+			}
+			if err := uc.todoTagRepo.ReplaceTagsForTodo(ctx, todo.ID, tagIDs); err != nil {
+				return err
 			}
+			if err := uc.loadTagIDs(ctx, todo); err != nil {
+				return err
+			}
+			retagged = changedIDs(oldTagIDs, todo.TagIDs)
 		}
-		if err := uc.todoTagRepo.ReplaceTagsForTodo(ctx, todo.ID, tagIDs); err != nil {
-			return nil, err
+
+		// Get tags for response
+		tags, _ := uc.todoTagRepo.GetTagsByTodoID(ctx, todo.ID)
+
+		response = dto.ToTodoResponseWithTags(todo, tags)
+
+		// Record events
+		if err := uc.recordTodoChanged(ctx, wasDone, todo, response); err != nil {
+			return err
+		}
+		if err := uc.recordAssignments(ctx, event.TodoAssigned, userID, assigned, response); err != nil {
+			return err
+		}
+		if err := uc.recordAssignments(ctx, event.TodoUnassigned, userID, unassigned, response); err != nil {
+			return err
 		}
+		return uc.recordMentions(ctx, userID, todo.Description, previousDescription, response)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Get tags for response
-	tags, _ := uc.todoTagRepo.GetTagsByTodoID(ctx, todo.ID)
+	// Update cache, including the views of users no longer assigned
+	if uc.todoCache != nil {
+		if err := uc.todoCache.UpdateTodo(ctx, todo); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+		if err := uc.todoCache.InvalidateAssignees(ctx, todo.UserID, unassigned); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+		if len(retagged) > 0 {
+			if err := uc.todoCache.InvalidateTagged(ctx, todo.UserID, retagged); err != nil {
+				// Log error but don't fail the request
+				// In production, use proper logging
+			}
+		}
+	}
 
-	response := dto.ToTodoResponseWithTags(todo, tags)
 	return &response, nil
 }
 
@@ -275,14 +432,31 @@ func (uc *TodoUseCase) DeleteTodo(ctx context.Context, id int64, userID int64) e
 		return ErrUnauthorized
 	}
 
+	// Assignees and tags are deleted with the todo, but their cached views
+	// remain
+	if err := uc.loadAssignees(ctx, todo); err != nil {
+		return err
+	}
+	if err := uc.loadTagIDs(ctx, todo); err != nil {
+		return err
+	}
+
 	// Delete todo
-	if err := uc.todoRepo.Delete(ctx, id); err != nil {
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		// Record event
+		return recordEvent(ctx, uc.outboxRepo, event.TodoDeleted, userID, "todo", todo.ID, dto.ToTodoResponse(todo))
+	})
+	if err != nil {
 		return err
 	}
 
 	// Delete from cache
 	if uc.todoCache != nil {
-		if err := uc.todoCache.DeleteTodo(ctx, id, userID); err != nil {
+		if err := uc.todoCache.DeleteTodo(ctx, todo); err != nil {
 			// Log error but don't fail the request
 			// In production, use proper logging
 		}
@@ -291,7 +465,8 @@ func (uc *TodoUseCase) DeleteTodo(ctx context.Context, id int64, userID int64) e
 	return nil
 }
 
-// UpdateTodoStatus updates the status of a todo
+// UpdateTodoStatus updates the status of a todo, on behalf of its owner or
+// one of its assignees
 func (uc *TodoUseCase) UpdateTodoStatus(ctx context.Context, id int64, userID int64, status string) (*dto.TodoResponse, error) {
 	// Get existing todo
 	todo, err := uc.todoRepo.FindByID(ctx, id)
@@ -299,42 +474,641 @@ func (uc *TodoUseCase) UpdateTodoStatus(ctx context.Context, id int64, userID in
 		return nil, err
 	}
 
+	// Owners and assignees may move the todo through the owner's workflow
+	if err := uc.loadAssignees(ctx, todo); err != nil {
+		return nil, err
+	}
+	if todo.UserID != userID && !todo.IsAssignedTo(userID) {
+		return nil, ErrUnauthorized
+	}
+
+	wasDone := todo.IsDone()
+
+	// Update status, stamping or clearing the completion time
+	if err := uc.changeStatus(ctx, todo, status); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToTodoResponse(todo)
+
+	// Save changes and record events
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoRepo.Update(ctx, todo); err != nil {
+			return err
+		}
+		return uc.recordTodoChanged(ctx, wasDone, todo, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	if uc.todoCache != nil {
+		if err := uc.todoCache.UpdateTodoStatus(ctx, id, todo.UserID, status); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+
+	return &response, nil
+}
+
+// SnoozeTodo hides a todo from default lists until the given time
+func (uc *TodoUseCase) SnoozeTodo(ctx context.Context, id int64, userID int64, until time.Time) (*dto.TodoResponse, error) {
+	if !until.After(time.Now()) {
+		return nil, ErrInvalidSnoozeTime
+	}
+	return uc.setSnoozedUntil(ctx, id, userID, &until)
+}
+
+// UnsnoozeTodo makes a snoozed todo visible again immediately
+func (uc *TodoUseCase) UnsnoozeTodo(ctx context.Context, id int64, userID int64) (*dto.TodoResponse, error) {
+	return uc.setSnoozedUntil(ctx, id, userID, nil)
+}
+
+// setSnoozedUntil saves a todo's snooze time and refreshes its cached copy
+func (uc *TodoUseCase) setSnoozedUntil(ctx context.Context, id int64, userID int64, until *time.Time) (*dto.TodoResponse, error) {
+	// Get existing todo
+	todo, err := uc.todoRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAsTodoNotFound(err)
+	}
+
 	// Check ownership
 	if todo.UserID != userID {
 		return nil, ErrUnauthorized
 	}
 
-	// Update status
-	switch status {
-	case "not_started":
-		todo.Status = entity.TodoStatusNotStarted
-	case "in_progress":
-		todo.Status = entity.TodoStatusInProgress
-	case "completed":
-		todo.Status = entity.TodoStatusCompleted
-	default:
-		return nil, ErrInvalidStatus
-	}
+	todo.SnoozedUntil = until
+
+	var response dto.TodoResponse
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoRepo.Update(ctx, todo); err != nil {
+			return err
+		}
+
+		tags, _ := uc.todoTagRepo.GetTagsByTodoID(ctx, todo.ID)
+		response = dto.ToTodoResponseWithTags(todo, tags)
 
-	// Save changes
-	if err := uc.todoRepo.Update(ctx, todo); err != nil {
+		return recordEvent(ctx, uc.outboxRepo, event.TodoUpdated, todo.UserID, "todo", todo.ID, response)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	// Update cache
 	if uc.todoCache != nil {
-		if err := uc.todoCache.UpdateTodoStatus(ctx, id, userID, status); err != nil {
+		if err := uc.todoCache.UpdateTodo(ctx, todo); err != nil {
 			// Log error but don't fail the request
 			// In production, use proper logging
 		}
 	}
 
+	return &response, nil
+}
+
+// ArchiveTodo moves a todo to the archive, hiding it from default lists
+func (uc *TodoUseCase) ArchiveTodo(ctx context.Context, id int64, userID int64) (*dto.TodoResponse, error) {
+	// Get existing todo
+	todo, err := uc.todoRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAsTodoNotFound(err)
+	}
+
+	// Check ownership
+	if todo.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	now := time.Now()
+	todo.ArchivedAt = &now
 	response := dto.ToTodoResponse(todo)
+
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoRepo.Archive(ctx, []int64{todo.ID}, now); err != nil {
+			return err
+		}
+		return recordEvent(ctx, uc.outboxRepo, event.TodoArchived, userID, "todo", todo.ID, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	if uc.todoCache != nil {
+		if err := uc.todoCache.DeleteTodo(ctx, todo); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+
 	return &response, nil
 }
 
+// RestoreTodo moves an archived todo back to the user's active todos
+func (uc *TodoUseCase) RestoreTodo(ctx context.Context, id int64, userID int64) (*dto.TodoResponse, error) {
+	todo, err := uc.todoRepo.FindArchivedByID(ctx, id)
+	if err != nil {
+		return nil, notFoundAsTodoNotFound(err)
+	}
+
+	// Check ownership
+	if todo.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	todo.ArchivedAt = nil
+
+	var response dto.TodoResponse
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.todoRepo.Restore(ctx, todo.ID); err != nil {
+			return err
+		}
+
+		tags, _ := uc.todoTagRepo.GetTagsByTodoID(ctx, todo.ID)
+		response = dto.ToTodoResponseWithTags(todo, tags)
+
+		return recordEvent(ctx, uc.outboxRepo, event.TodoUpdated, userID, "todo", todo.ID, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	if uc.todoCache != nil {
+		if err := uc.todoCache.CreateTodo(ctx, todo); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+
+	return &response, nil
+}
+
+// ListArchivedTodos lists a user's archived todos, most recently archived first
+func (uc *TodoUseCase) ListArchivedTodos(ctx context.Context, userID int64, req *dto.ListArchivedTodosRequest) (*dto.TodoListResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	limit := req.Limit
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	todos, total, err := uc.todoRepo.FindArchivedByUserID(ctx, userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &dto.TodoListResponse{
+		Data:       dto.ToTodoResponseList(todos),
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// parsePriority parses a requested priority, defaulting to p2 when none is given
+func parsePriority(value string) (entity.TodoPriority, error) {
+	if value == "" {
+		return entity.TodoPriorityDefault, nil
+	}
+	priority, ok := entity.ParsePriority(value)
+	if !ok {
+		return "", ErrInvalidPriority
+	}
+	return priority, nil
+}
+
+// updateDueDate applies a new due date and/or all-day flag. Turning all-day
+// on alone makes the todo due on the day of its current due date in the
+// user's timezone; turning it off keeps the current due date.
+func (uc *TodoUseCase) updateDueDate(ctx context.Context, todo *entity.Todo, dueDate *time.Time, allDay *bool) error {
+	isAllDay := todo.AllDay
+	if allDay != nil {
+		isAllDay = *allDay
+	}
+
+	if dueDate == nil {
+		if todo.DueDate == nil || isAllDay == todo.AllDay {
+			todo.AllDay = isAllDay
+			return nil
+		}
+		loc, err := loadLocation(ctx, uc.userRepo, todo.UserID)
+		if err != nil {
+			return err
+		}
+		todo.SetDueDate(todo.DueDate.In(loc), isAllDay, loc)
+		return nil
+	}
+
+	loc, err := loadLocation(ctx, uc.userRepo, todo.UserID)
+	if err != nil {
+		return err
+	}
+	todo.SetDueDate(*dueDate, isAllDay, loc)
+
+	// A new due date restarts overdue detection and escalation
+	todo.OverdueAt = nil
+	todo.EscalatedAt = nil
+	return nil
+}
+
+// validateSchedule checks that a todo can be started and is planned no later
+// than it is due, and is not planned before it can be started
+func validateSchedule(todo *entity.Todo) error {
+	if todo.DueDate != nil {
+		if todo.StartDate != nil && todo.StartDate.After(*todo.DueDate) {
+			return ErrStartAfterDue
+		}
+		if todo.ScheduledFor != nil && todo.ScheduledFor.After(*todo.DueDate) {
+			return ErrScheduledAfterDue
+		}
+	}
+	if todo.StartDate != nil && todo.ScheduledFor != nil && todo.ScheduledFor.Before(*todo.StartDate) {
+		return ErrScheduledBeforeStart
+	}
+	return nil
+}
+
+// parseDueBound parses a due date bound of a list query. Dates and times
+// without an offset are read in loc; a date bounds the start of the day,
+// or its end when end is set.
+func parseDueBound(value string, loc *time.Location, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", value, loc); err == nil {
+		return &t, nil
+	}
+	day, err := entity.ParseDate(value)
+	if err != nil {
+		return nil, ErrInvalidDueDateBound
+	}
+	t := day.StartIn(loc)
+	if end {
+		t = day.EndIn(loc)
+	}
+	return &t, nil
+}
+
+// changeStatus moves a todo to a state of its owner's workflow, if the
+// workflow allows moving there from the todo's current status
+func (uc *TodoUseCase) changeStatus(ctx context.Context, todo *entity.Todo, status string) error {
+	workflow, err := loadWorkflow(ctx, uc.workflowRepo, todo.UserID)
+	if err != nil {
+		return err
+	}
+
+	state, ok := workflow.State(entity.TodoStatus(status))
+	if !ok {
+		return ErrInvalidStatus
+	}
+	if !workflow.CanTransition(todo.Status, state.Name) {
+		return ErrTransitionNotAllowed
+	}
+
+	todo.SetStatus(state, time.Now())
+	return nil
+}
+
+// recordTodoChanged records todo.updated, plus todo.completed when the todo has just entered a done state
+func (uc *TodoUseCase) recordTodoChanged(ctx context.Context, wasDone bool, todo *entity.Todo, response dto.TodoResponse) error {
+	if err := recordEvent(ctx, uc.outboxRepo, event.TodoUpdated, todo.UserID, "todo", todo.ID, response); err != nil {
+		return err
+	}
+
+	if !wasDone && todo.IsDone() {
+		return recordEvent(ctx, uc.outboxRepo, event.TodoCompleted, todo.UserID, "todo", todo.ID, response)
+	}
+	return nil
+}
+
+// checkAssignees deduplicates the users a todo is to be assigned to, checking
+// that they are members of the organization
+func (uc *TodoUseCase) checkAssignees(ctx context.Context, userIDs []int64) ([]int64, error) {
+	assigneeIDs := make([]int64, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if containsID(assigneeIDs, userID) {
+			continue
+		}
+		// Users are only found among the members of the current organization
+		if _, err := uc.userRepo.FindByID(ctx, userID); err != nil {
+			if errors.Is(err, tagRepositoryImpl.ErrUserNotFound) {
+				return nil, ErrInvalidAssignee
+			}
+			return nil, err
+		}
+		assigneeIDs = append(assigneeIDs, userID)
+	}
+	return assigneeIDs, nil
+}
+
+// loadAssignees loads the users a todo is assigned to
+func (uc *TodoUseCase) loadAssignees(ctx context.Context, todo *entity.Todo) error {
+	if uc.assigneeRepo == nil {
+		return nil
+	}
+	assigneeIDs, err := uc.assigneeRepo.FindUserIDsByTodoID(ctx, todo.ID)
+	if err != nil {
+		return err
+	}
+	todo.AssigneeIDs = assigneeIDs
+	return nil
+}
+
+// loadTagIDs loads the tags a todo counts towards
+func (uc *TodoUseCase) loadTagIDs(ctx context.Context, todo *entity.Todo) error {
+	tagIDs, err := uc.todoTagRepo.GetTagLineageIDsByTodoID(ctx, todo.ID)
+	if err != nil {
+		return err
+	}
+	todo.TagIDs = tagIDs
+	return nil
+}
+
+// addAssignees assigns a todo to users on behalf of actorID
+func (uc *TodoUseCase) addAssignees(ctx context.Context, todoID, actorID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	assignees := make([]*entity.TodoAssignee, len(userIDs))
+	for i, userID := range userIDs {
+		assignees[i] = &entity.TodoAssignee{TodoID: todoID, UserID: userID, AssignedBy: actorID}
+	}
+	return uc.assigneeRepo.AddAssignees(ctx, assignees)
+}
+
+// recordAssignments records an assignment event for each of the users, who
+// are notified of it, except for actorID who made the change
+func (uc *TodoUseCase) recordAssignments(ctx context.Context, eventType event.Type, actorID int64, userIDs []int64, response dto.TodoResponse) error {
+	for _, userID := range userIDs {
+		if userID == actorID {
+			continue
+		}
+		payload := dto.TodoAssignmentEvent{AssigneeID: userID, AssignedBy: actorID, Todo: response}
+		if err := recordEvent(ctx, uc.outboxRepo, eventType, userID, "todo", response.ID, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordMentions records a todo.mentioned event for each member of the
+// organization mentioned in text but not in previous, the text it replaces,
+// except for actorID who wrote it. Unknown usernames are not mentions.
+func (uc *TodoUseCase) recordMentions(ctx context.Context, actorID int64, text, previous string, response dto.TodoResponse) error {
+	if uc.userRepo == nil {
+		return nil
+	}
+
+	usernames := entity.NewMentions(text, previous)
+	if len(usernames) > maxMentions {
+		usernames = usernames[:maxMentions]
+	}
+
+	for _, username := range usernames {
+		// Users are only found among the members of the current organization
+		user, err := uc.userRepo.FindByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, tagRepositoryImpl.ErrUserNotFound) {
+				continue
+			}
+			return err
+		}
+		if user.ID == actorID {
+			continue
+		}
+
+		payload := dto.TodoMentionEvent{MentionedUserID: user.ID, MentionedBy: actorID, Todo: response}
+		if err := recordEvent(ctx, uc.outboxRepo, event.TodoMentioned, user.ID, "todo", response.ID, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notFoundAsTodoNotFound maps the repository's not-found error to ErrTodoNotFound
+func notFoundAsTodoNotFound(err error) error {
+	if errors.Is(err, tagRepositoryImpl.ErrTodoNotFound) {
+		return ErrTodoNotFound
+	}
+	return err
+}
+
+// resolveTagIDs looks up a user's tags by name or alias, creating the ones
+// that do not exist yet along with their parents
+func resolveTagIDs(ctx context.Context, tagRepo repository.TagRepository, userID int64, names []string) ([]int64, error) {
+	var tagIDs []int64
+	for _, tagName := range names {
+		tagName, err := normalizeTagName(tagName)
+		if err != nil {
+			return nil, err
+		}
+
+		tag, err := findTagByName(ctx, tagRepo, userID, tagName)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
+				// Create new tag
+				newTag := &entity.Tag{UserID: userID, Name: tagName}
+				if err := ensureTagParent(ctx, tagRepo, newTag); err != nil {
+					return nil, err
+				}
+				if err := tagRepo.Create(ctx, newTag); err != nil {
+					return nil, err
+				}
+				tagIDs = append(tagIDs, newTag.ID)
+			} else {
+				return nil, err
+			}
+		} else if !containsID(tagIDs, tag.ID) {
+			// Several names may resolve to the same tag
+			tagIDs = append(tagIDs, tag.ID)
+		}
+	}
+	return tagIDs, nil
+}
+
+// findTagByName looks up a user's tag by name, falling back to the former
+// names of merged and renamed tags
+func findTagByName(ctx context.Context, tagRepo repository.TagRepository, userID int64, name string) (*entity.Tag, error) {
+	tag, err := tagRepo.FindByName(ctx, userID, name)
+	if errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
+		tag, err = tagRepo.FindByAlias(ctx, userID, name)
+	}
+	return tag, err
+}
+
+// lookupTagIDs finds the IDs of a user's existing tags named in a
+// comma-separated list, without creating any. It also returns how many of
+// the names matched no tag.
+func lookupTagIDs(ctx context.Context, tagRepo repository.TagRepository, userID int64, list string) ([]int64, int, error) {
+	var tagIDs []int64
+	unknown := 0
+	for _, tagName := range strings.Split(list, ",") {
+		if strings.TrimSpace(tagName) == "" {
+			continue
+		}
+		tagName, err := normalizeTagName(tagName)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		tag, err := findTagByName(ctx, tagRepo, userID, tagName)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
+				unknown++
+				continue
+			}
+			return nil, 0, err
+		}
+		if !containsID(tagIDs, tag.ID) {
+			tagIDs = append(tagIDs, tag.ID)
+		}
+	}
+	return tagIDs, unknown, nil
+}
+
+// containsID reports whether ids contains id
+func containsID(ids []int64, id int64) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// changedIDs returns the IDs in only one of before and after
+func changedIDs(before, after []int64) []int64 {
+	var changed []int64
+	for _, id := range before {
+		if !containsID(after, id) {
+			changed = append(changed, id)
+		}
+	}
+	for _, id := range after {
+		if !containsID(before, id) {
+			changed = append(changed, id)
+		}
+	}
+	return changed
+}
+
+// GetTagsByTodoIDs retrieves the tags of several todos at once, keyed by todo ID.
+// Callers are expected to have already checked ownership of the todos.
+func (uc *TodoUseCase) GetTagsByTodoIDs(ctx context.Context, todoIDs []int64) (map[int64][]dto.TagResponse, error) {
+	tagsByTodo, err := uc.todoTagRepo.GetTagsByTodoIDs(ctx, todoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(map[int64][]dto.TagResponse, len(todoIDs))
+	for _, todoID := range todoIDs {
+		responses[todoID] = dto.ToTagResponseList(tagsByTodo[todoID])
+	}
+	return responses, nil
+}
+
 // ListTodos lists todos with pagination and filters
 func (uc *TodoUseCase) ListTodos(ctx context.Context, userID int64, req *dto.ListTodosRequest) (*dto.TodoListResponse, error) {
+	tagIDs, unknown, err := lookupTagIDs(ctx, uc.tagRepo, userID, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	matchAll := req.TagMatch == "all"
+
+	// A tag that does not exist is on no todo
+	noMatch := unknown > 0 && (matchAll || len(tagIDs) == 0)
+
+	return uc.listTodos(ctx, userID, req, tagIDs, matchAll, noMatch)
+}
+
+// ListTodosByTag lists a user's todos tagged with one of their tags or a tag
+// nested under it, applying the other list filters of the request
+func (uc *TodoUseCase) ListTodosByTag(ctx context.Context, userID, tagID int64, req *dto.ListTodosRequest) (*dto.TodoListResponse, error) {
+	tag, err := uc.tagRepo.FindByID(ctx, tagID)
+	if err != nil {
+		if errors.Is(err, tagRepositoryImpl.ErrTagNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	if tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+
+	return uc.listTodos(ctx, userID, req, []int64{tagID}, false, false)
+}
+
+// GetMatrix sorts a user's open, unsnoozed todos into the quadrants of the
+// Eisenhower matrix, listing at most limit todos per quadrant
+func (uc *TodoUseCase) GetMatrix(ctx context.Context, userID int64, limit int) (*dto.MatrixResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = defaultMatrixLimit
+	}
+
+	now := time.Now()
+	filter := repository.TodoFilter{ExcludeDone: true, VisibleAt: &now}
+	todos, _, err := uc.todoRepo.FindByUserIDAndFilters(ctx, userID, filter, "priority", "asc", 0, maxMatrixTodos)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMatrixResponse(todos, now, uc.urgentWithin, limit), nil
+}
+
+// buildMatrixResponse sorts todos into quadrants, most important and then
+// soonest due first
+func buildMatrixResponse(todos []*entity.Todo, now time.Time, urgentWithin time.Duration, limit int) *dto.MatrixResponse {
+	sorted := make([]*entity.Todo, len(todos))
+	copy(sorted, todos)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Priority.Level() != b.Priority.Level() {
+			return a.Priority.Level() < b.Priority.Level()
+		}
+		if a.DueDate == nil || b.DueDate == nil {
+			return a.DueDate != nil && b.DueDate == nil
+		}
+		return a.DueDate.Before(*b.DueDate)
+	})
+
+	quadrants := map[entity.EisenhowerQuadrant]*dto.MatrixQuadrant{}
+	response := &dto.MatrixResponse{UrgentBefore: now.Add(urgentWithin)}
+	quadrants[entity.QuadrantDo] = &response.Do
+	quadrants[entity.QuadrantSchedule] = &response.Schedule
+	quadrants[entity.QuadrantDelegate] = &response.Delegate
+	quadrants[entity.QuadrantEliminate] = &response.Eliminate
+	for _, quadrant := range quadrants {
+		quadrant.Todos = []dto.TodoResponse{}
+	}
+
+	for _, todo := range sorted {
+		quadrant := quadrants[todo.Quadrant(now, urgentWithin)]
+		quadrant.Total++
+		if len(quadrant.Todos) < limit {
+			quadrant.Todos = append(quadrant.Todos, dto.ToTodoResponse(todo))
+		}
+	}
+
+	return response
+}
+
+// listTodos lists a user's todos matching the request and the given tags.
+// When noMatch is set the tag filter cannot match any todo.
+func (uc *TodoUseCase) listTodos(ctx context.Context, userID int64, req *dto.ListTodosRequest, tagIDs []int64, matchAllTags, noMatch bool) (*dto.TodoListResponse, error) {
 	// Set default pagination values
 	page := req.Page
 	if page < 1 {
@@ -359,6 +1133,34 @@ func (uc *TodoUseCase) ListTodos(ctx context.Context, userID int64, req *dto.Lis
 		sortOrder = "asc"
 	}
 
+	if noMatch {
+		return &dto.TodoListResponse{
+			Data:  []dto.TodoResponse{},
+			Page:  page,
+			Limit: limit,
+		}, nil
+	}
+
+	excludeTagIDs, _, err := lookupTagIDs(ctx, uc.tagRepo, userID, req.ExcludeTags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Due date bounds are read in the user's timezone
+	var dueDateFrom, dueDateTo *time.Time
+	if req.DueDateFrom != "" || req.DueDateTo != "" {
+		loc, err := loadLocation(ctx, uc.userRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+		if dueDateFrom, err = parseDueBound(req.DueDateFrom, loc, false); err != nil {
+			return nil, err
+		}
+		if dueDateTo, err = parseDueBound(req.DueDateTo, loc, true); err != nil {
+			return nil, err
+		}
+	}
+
 	// Prepare filters
 	var statusFilter *string
 	if req.Status != "" {
@@ -367,27 +1169,62 @@ func (uc *TodoUseCase) ListTodos(ctx context.Context, userID int64, req *dto.Lis
 
 	var priorityFilter *string
 	if req.Priority != "" {
-		priorityFilter = &req.Priority
+		priority, ok := entity.ParsePriority(req.Priority)
+		if !ok {
+			return nil, ErrInvalidPriority
+		}
+		value := string(priority)
+		priorityFilter = &value
 	}
 
 	// Get todos with filters (with cache support)
 	var todos []*entity.Todo
 	var total int64
-	var err error
 
 	if uc.todoCache != nil {
 		// Use cache if available
 		filters := &cache.ListFilter{
-			Status:      statusFilter,
-			Priority:    priorityFilter,
-			DueDateFrom: req.DueDateFrom,
-			DueDateTo:   req.DueDateTo,
-			Search:      req.Search,
+			Status:          statusFilter,
+			Priority:        priorityFilter,
+			DueDateFrom:     dueDateFrom,
+			DueDateTo:       dueDateTo,
+			Search:          req.Search,
+			Overdue:         req.Overdue,
+			Available:       req.Available,
+			IncludeSnoozed:  req.IncludeSnoozed,
+			IncludeArchived: req.IncludeArchived,
+			TagIDs:          tagIDs,
+			MatchAllTags:    matchAllTags,
+			ExcludeTagIDs:   excludeTagIDs,
+			AssignedToMe:    req.Assignment == "assigned_to_me",
+			DelegatedByMe:   req.Assignment == "delegated_by_me",
 		}
 		todos, total, err = uc.todoCache.GetTodoList(ctx, userID, filters, sortBy, sortOrder, page, limit)
 	} else {
 		// Fallback to database query
-		todos, total, err = uc.todoRepo.FindByUserIDAndFilters(ctx, userID, statusFilter, priorityFilter, req.DueDateFrom, req.DueDateTo, sortBy, sortOrder, offset, limit)
+		filter := repository.TodoFilter{
+			Status:          statusFilter,
+			Priority:        priorityFilter,
+			DueDateFrom:     dueDateFrom,
+			DueDateTo:       dueDateTo,
+			IncludeArchived: req.IncludeArchived,
+			TagIDs:          tagIDs,
+			MatchAllTags:    matchAllTags,
+			ExcludeTagIDs:   excludeTagIDs,
+			AssignedToUser:  req.Assignment == "assigned_to_me",
+			DelegatedByUser: req.Assignment == "delegated_by_me",
+		}
+		now := time.Now()
+		if req.Overdue {
+			filter.OverdueAt = &now
+		}
+		if !req.IncludeSnoozed {
+			filter.VisibleAt = &now
+		}
+		if req.Available {
+			filter.AvailableAt = &now
+		}
+		todos, total, err = uc.todoRepo.FindByUserIDAndFilters(ctx, userID, filter, sortBy, sortOrder, offset, limit)
 	}
 
 	if err != nil {
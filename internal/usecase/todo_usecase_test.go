@@ -0,0 +1,433 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTodoRepository keeps todos in a map
+type memoryTodoRepository struct {
+	repository.TodoRepository
+	todos map[int64]*entity.Todo
+}
+
+func (r *memoryTodoRepository) Create(ctx context.Context, todo *entity.Todo) error {
+	todo.ID = int64(len(r.todos) + 1)
+	r.todos[todo.ID] = todo
+	return nil
+}
+
+func (r *memoryTodoRepository) FindByID(ctx context.Context, id int64) (*entity.Todo, error) {
+	todo, ok := r.todos[id]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	return todo, nil
+}
+
+func (r *memoryTodoRepository) Update(ctx context.Context, todo *entity.Todo) error {
+	r.todos[todo.ID] = todo
+	return nil
+}
+
+// memoryTodoTagRepository has no tags
+type memoryTodoTagRepository struct {
+	repository.TodoTagRepository
+}
+
+func (r *memoryTodoTagRepository) GetTagsByTodoID(ctx context.Context, todoID int64) ([]*entity.Tag, error) {
+	return nil, nil
+}
+
+func TestSnoozeTodo(t *testing.T) {
+	repo := &memoryTodoRepository{todos: map[int64]*entity.Todo{
+		1: {ID: 1, UserID: 7, Title: "Write report", Status: entity.TodoStatusNotStarted},
+	}}
+	outbox := &memoryOutbox{}
+	uc := NewTodoUseCase(repo, nil, &memoryTodoTagRepository{}, nil, nil, nil, nil, nil, outbox, 0)
+	ctx := context.Background()
+
+	until := time.Now().Add(2 * time.Hour)
+	response, err := uc.SnoozeTodo(ctx, 1, 7, until)
+	require.NoError(t, err)
+	assert.Equal(t, &until, response.SnoozedUntil)
+	assert.True(t, repo.todos[1].IsSnoozed(time.Now()))
+	assert.False(t, repo.todos[1].IsSnoozed(until))
+	assert.Equal(t, []string{string(event.TodoUpdated)}, outbox.types())
+
+	_, err = uc.SnoozeTodo(ctx, 1, 7, time.Now().Add(-time.Minute))
+	assert.ErrorIs(t, err, ErrInvalidSnoozeTime)
+
+	_, err = uc.SnoozeTodo(ctx, 1, 8, until)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	response, err = uc.UnsnoozeTodo(ctx, 1, 7)
+	require.NoError(t, err)
+	assert.Nil(t, response.SnoozedUntil)
+	assert.False(t, repo.todos[1].IsSnoozed(time.Now()))
+}
+
+// filteringTodoRepository records the filter of the last list query
+type filteringTodoRepository struct {
+	memoryTodoRepository
+	filter *repository.TodoFilter
+}
+
+func (r *filteringTodoRepository) FindByUserIDAndFilters(ctx context.Context, userID int64, filter repository.TodoFilter, sortBy, sortOrder string, offset, limit int) ([]*entity.Todo, int64, error) {
+	r.filter = &filter
+	return nil, 0, nil
+}
+
+func TestListTodos_FiltersByTags(t *testing.T) {
+	repo := &filteringTodoRepository{}
+	tags := &memoryTagRepository{tags: map[int64]*entity.Tag{
+		1: {ID: 1, UserID: 7, Name: "work"},
+		2: {ID: 2, UserID: 7, Name: "home"},
+		3: {ID: 3, UserID: 8, Name: "someday"},
+		4: {ID: 4, UserID: 7, Name: "someday"},
+	}}
+	tags.aliases = []*entity.TagAlias{{UserID: 7, TagID: 1, Name: "job"}}
+	uc := NewTodoUseCase(repo, tags, &memoryTodoTagRepository{}, nil, nil, nil, nil, nil, &memoryOutbox{}, 0)
+	ctx := context.Background()
+
+	_, err := uc.ListTodos(ctx, 7, &dto.ListTodosRequest{Tags: "job, home,work", ExcludeTags: "someday,unknown"})
+	require.NoError(t, err)
+	require.NotNil(t, repo.filter)
+	assert.Equal(t, []int64{1, 2}, repo.filter.TagIDs)
+	assert.False(t, repo.filter.MatchAllTags)
+	assert.Equal(t, []int64{4}, repo.filter.ExcludeTagIDs)
+
+	// Unknown tags are dropped when any tag may match
+	repo.filter = nil
+	_, err = uc.ListTodos(ctx, 7, &dto.ListTodosRequest{Tags: "work,unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, repo.filter.TagIDs)
+
+	// but match nothing when all tags must match, or when no tag is known
+	repo.filter = nil
+	response, err := uc.ListTodos(ctx, 7, &dto.ListTodosRequest{Tags: "work,unknown", TagMatch: "all"})
+	require.NoError(t, err)
+	assert.Nil(t, repo.filter)
+	assert.Empty(t, response.Data)
+	_, err = uc.ListTodos(ctx, 7, &dto.ListTodosRequest{Tags: "unknown"})
+	require.NoError(t, err)
+	assert.Nil(t, repo.filter)
+
+	_, err = uc.ListTodos(ctx, 7, &dto.ListTodosRequest{Tags: "work//db"})
+	assert.ErrorIs(t, err, ErrInvalidTagPath)
+
+	// The tag view is scoped to the caller's tags
+	_, err = uc.ListTodosByTag(ctx, 7, 3, &dto.ListTodosRequest{})
+	assert.ErrorIs(t, err, ErrTagNotFound)
+	_, err = uc.ListTodosByTag(ctx, 7, 1, &dto.ListTodosRequest{TagMatch: "all"})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, repo.filter.TagIDs)
+}
+
+func TestParsePriority(t *testing.T) {
+	for value, want := range map[string]entity.TodoPriority{
+		"p0":     entity.TodoPriorityP0,
+		" P4 ":   entity.TodoPriorityP4,
+		"high":   entity.TodoPriorityP1,
+		"Medium": entity.TodoPriorityP2,
+		"low":    entity.TodoPriorityP3,
+	} {
+		priority, ok := entity.ParsePriority(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, want, priority, value)
+	}
+
+	_, ok := entity.ParsePriority("p5")
+	assert.False(t, ok)
+	_, err := parsePriority("urgent")
+	assert.Equal(t, ErrInvalidPriority, err)
+
+	priority, err := parsePriority("")
+	require.NoError(t, err)
+	assert.Equal(t, entity.TodoPriorityP2, priority)
+	assert.Equal(t, "Medium", priority.Label())
+
+	todo := &entity.Todo{Priority: entity.TodoPriorityP1}
+	assert.True(t, todo.EscalatePriority())
+	assert.Equal(t, entity.TodoPriorityP0, todo.Priority)
+	assert.False(t, todo.EscalatePriority())
+}
+
+func TestBuildMatrixResponse(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tomorrow := now.Add(24 * time.Hour)
+	nextWeek := now.Add(7 * 24 * time.Hour)
+	yes, no := true, false
+
+	todos := []*entity.Todo{
+		{ID: 1, Priority: entity.TodoPriorityP1, DueDate: &nextWeek},
+		{ID: 2, Priority: entity.TodoPriorityP0, DueDate: &tomorrow},
+		{ID: 3, Priority: entity.TodoPriorityP1, DueDate: &tomorrow},
+		{ID: 4, Priority: entity.TodoPriorityP3, DueDate: &tomorrow},
+		{ID: 5, Priority: entity.TodoPriorityP3},
+		// Flags override the priority and the due date
+		{ID: 6, Priority: entity.TodoPriorityP4, Important: &yes, Urgent: &yes},
+		{ID: 7, Priority: entity.TodoPriorityP0, DueDate: &tomorrow, Important: &no, Urgent: &no},
+	}
+
+	matrix := buildMatrixResponse(todos, now, 48*time.Hour, 2)
+
+	assert.Equal(t, []int64{2, 3}, todoIDs(matrix.Do.Todos))
+	assert.Equal(t, 3, matrix.Do.Total)
+	assert.Equal(t, []int64{1}, todoIDs(matrix.Schedule.Todos))
+	assert.Equal(t, []int64{4}, todoIDs(matrix.Delegate.Todos))
+	assert.Equal(t, []int64{7, 5}, todoIDs(matrix.Eliminate.Todos))
+	assert.Equal(t, now.Add(48*time.Hour), matrix.UrgentBefore)
+}
+
+func todoIDs(todos []dto.TodoResponse) []int64 {
+	ids := make([]int64, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	return ids
+}
+
+// memoryUserRepository keeps users in a map
+type memoryUserRepository struct {
+	repository.UserRepository
+	users map[int64]*entity.User
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id int64) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func TestParseDueBound_AcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Clocks spring forward on 2024-03-10: the day lasts 23 hours
+	from, err := parseDueBound("2024-03-10", newYork, false)
+	require.NoError(t, err)
+	to, err := parseDueBound("2024-03-10", newYork, true)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC), from.UTC())
+	assert.Equal(t, time.Date(2024, 3, 11, 3, 59, 59, 0, time.UTC), to.UTC())
+	assert.Equal(t, 23*time.Hour-time.Second, to.Sub(*from))
+
+	// and fall back on 2024-11-03: the day lasts 25 hours
+	from, err = parseDueBound("2024-11-03", newYork, false)
+	require.NoError(t, err)
+	to, err = parseDueBound("2024-11-03", newYork, true)
+	require.NoError(t, err)
+	assert.Equal(t, 25*time.Hour-time.Second, to.Sub(*from))
+
+	// Times without an offset are wall times in the zone
+	bound, err := parseDueBound("2024-03-10T09:30:00", newYork, false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 10, 13, 30, 0, 0, time.UTC), bound.UTC())
+
+	// while RFC 3339 times are used as given
+	bound, err = parseDueBound("2024-03-10T09:30:00Z", newYork, false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC), bound.UTC())
+
+	_, err = parseDueBound("next tuesday", newYork, false)
+	assert.Equal(t, ErrInvalidDueDateBound, err)
+}
+
+func TestAllDayTodos_FollowUserTimezone(t *testing.T) {
+	repo := &memoryTodoRepository{todos: map[int64]*entity.Todo{}}
+	users := &memoryUserRepository{users: map[int64]*entity.User{
+		7: {ID: 7, Timezone: "America/New_York"},
+	}}
+	uc := NewTodoUseCase(repo, nil, &memoryTodoTagRepository{}, nil, nil, users, nil, nil, &memoryOutbox{}, 0)
+	ctx := context.Background()
+
+	// The date of the given due date is kept, whatever its offset
+	due := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	response, err := uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "File taxes", DueDate: &due, AllDay: true})
+	require.NoError(t, err)
+	assert.True(t, response.AllDay)
+	assert.Equal(t, "2024-03-10", response.DueOn)
+	assert.Equal(t, time.Date(2024, 3, 11, 3, 59, 59, 0, time.UTC), response.DueDate.UTC())
+
+	todo := repo.todos[response.ID]
+	assert.False(t, todo.IsOverdue(time.Date(2024, 3, 11, 3, 0, 0, 0, time.UTC)))
+	assert.True(t, todo.IsOverdue(time.Date(2024, 3, 11, 4, 0, 0, 0, time.UTC)))
+
+	// Moving to another zone keeps the date and moves the end of the day
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	todo.Rezone(tokyo)
+	assert.Equal(t, time.Date(2024, 3, 10, 14, 59, 59, 0, time.UTC), todo.DueDate.UTC())
+	todo.Rezone(users.users[7].Location())
+
+	// Turning all-day off keeps the due time
+	off := false
+	response, err = uc.UpdateTodo(ctx, todo.ID, 7, &dto.UpdateTodoRequest{AllDay: &off})
+	require.NoError(t, err)
+	assert.False(t, response.AllDay)
+	assert.Empty(t, response.DueOn)
+	assert.Equal(t, time.Date(2024, 3, 11, 3, 59, 59, 0, time.UTC), response.DueDate.UTC())
+
+	// and turning it on takes the day of the due time in the user's zone
+	on := true
+	response, err = uc.UpdateTodo(ctx, todo.ID, 7, &dto.UpdateTodoRequest{AllDay: &on})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-10", response.DueOn)
+}
+
+func TestStartAndScheduledDates(t *testing.T) {
+	repo := &memoryTodoRepository{todos: map[int64]*entity.Todo{}}
+	uc := NewTodoUseCase(repo, nil, &memoryTodoTagRepository{}, nil, nil, nil, nil, nil, &memoryOutbox{}, 0)
+	ctx := context.Background()
+
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	scheduled := time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC)
+
+	response, err := uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Draft slides", StartDate: &start, ScheduledFor: &scheduled, DueDate: &due})
+	require.NoError(t, err)
+	assert.Equal(t, &start, response.StartDate)
+	assert.Equal(t, &scheduled, response.ScheduledFor)
+	assert.False(t, repo.todos[response.ID].IsAvailable(start.Add(-time.Minute)))
+	assert.True(t, repo.todos[response.ID].IsAvailable(start))
+
+	_, err = uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Late start", StartDate: &scheduled, DueDate: &start})
+	assert.Equal(t, ErrStartAfterDue, err)
+	_, err = uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Late plan", ScheduledFor: &due, DueDate: &scheduled})
+	assert.Equal(t, ErrScheduledAfterDue, err)
+	_, err = uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Early plan", StartDate: &scheduled, ScheduledFor: &start})
+	assert.Equal(t, ErrScheduledBeforeStart, err)
+
+	// Updates are checked against the dates the todo keeps
+	later := due.Add(time.Hour)
+	_, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{ScheduledFor: &later})
+	assert.Equal(t, ErrScheduledAfterDue, err)
+	early := start.Add(-time.Hour)
+	_, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{DueDate: &early})
+	assert.Equal(t, ErrStartAfterDue, err)
+	_, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{ScheduledFor: &later, DueDate: &later})
+	require.NoError(t, err)
+
+	// Either date can be cleared once set
+	response, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{ClearStartDate: true})
+	require.NoError(t, err)
+	assert.Nil(t, response.StartDate)
+	assert.Equal(t, &later, response.ScheduledFor)
+	assert.True(t, repo.todos[response.ID].IsAvailable(start.Add(-time.Minute)))
+	response, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{ClearScheduledFor: true})
+	require.NoError(t, err)
+	assert.Nil(t, response.ScheduledFor)
+	assert.Nil(t, repo.todos[response.ID].ScheduledFor)
+
+	// A date sent along with its clear flag is set
+	response, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{ClearStartDate: true, StartDate: &start})
+	require.NoError(t, err)
+	assert.Equal(t, &start, response.StartDate)
+}
+
+func TestListTodos_AvailableNow(t *testing.T) {
+	repo := &filteringTodoRepository{}
+	uc := NewTodoUseCase(repo, nil, &memoryTodoTagRepository{}, nil, nil, nil, nil, nil, &memoryOutbox{}, 0)
+
+	_, err := uc.ListTodos(context.Background(), 7, &dto.ListTodosRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, repo.filter.AvailableAt)
+
+	_, err = uc.ListTodos(context.Background(), 7, &dto.ListTodosRequest{Available: true, SortBy: "scheduled_for"})
+	require.NoError(t, err)
+	require.NotNil(t, repo.filter.AvailableAt)
+	assert.WithinDuration(t, time.Now(), *repo.filter.AvailableAt, time.Minute)
+}
+
+// memoryTodoAssigneeRepository keeps assignees in a map
+type memoryTodoAssigneeRepository struct {
+	assignees map[int64][]int64
+}
+
+func (r *memoryTodoAssigneeRepository) AddAssignees(ctx context.Context, assignees []*entity.TodoAssignee) error {
+	for _, assignee := range assignees {
+		r.assignees[assignee.TodoID] = append(r.assignees[assignee.TodoID], assignee.UserID)
+	}
+	return nil
+}
+
+func (r *memoryTodoAssigneeRepository) RemoveAssignees(ctx context.Context, todoID int64, userIDs []int64) error {
+	var kept []int64
+	for _, userID := range r.assignees[todoID] {
+		if !containsID(userIDs, userID) {
+			kept = append(kept, userID)
+		}
+	}
+	r.assignees[todoID] = kept
+	return nil
+}
+
+func (r *memoryTodoAssigneeRepository) FindUserIDsByTodoID(ctx context.Context, todoID int64) ([]int64, error) {
+	return append([]int64{}, r.assignees[todoID]...), nil
+}
+
+// memberUserRepository only finds the members of the organization
+type memberUserRepository struct {
+	memoryUserRepository
+}
+
+func (r *memberUserRepository) FindByID(ctx context.Context, id int64) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repositoryImpl.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func TestTodoUseCase_Assignees(t *testing.T) {
+	repo := &memoryTodoRepository{todos: map[int64]*entity.Todo{}}
+	assignees := &memoryTodoAssigneeRepository{assignees: map[int64][]int64{}}
+	users := &memberUserRepository{memoryUserRepository{users: map[int64]*entity.User{
+		7: {ID: 7}, 8: {ID: 8}, 9: {ID: 9},
+	}}}
+	outbox := &memoryOutbox{}
+	uc := NewTodoUseCase(repo, nil, &memoryTodoTagRepository{}, assignees, nil, users, nil, nil, outbox, 0)
+	ctx := context.Background()
+
+	// Todos are only assigned to members of the organization
+	_, err := uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Plan offsite", AssigneeIDs: []int64{8, 10}})
+	assert.ErrorIs(t, err, ErrInvalidAssignee)
+
+	response, err := uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Plan offsite", AssigneeIDs: []int64{8, 8}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{8}, response.AssigneeIDs)
+	assert.Equal(t, []string{string(event.TodoCreated), string(event.TodoAssigned)}, outbox.types())
+	assert.Equal(t, int64(8), outbox.messages[1].UserID)
+
+	// Assignees see the todo and move it along, but do not edit it
+	_, err = uc.GetTodo(ctx, response.ID, 8)
+	require.NoError(t, err)
+	_, err = uc.GetTodo(ctx, response.ID, 9)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = uc.UpdateTodoStatus(ctx, response.ID, 8, "in_progress")
+	require.NoError(t, err)
+	_, err = uc.UpdateTodo(ctx, response.ID, 8, &dto.UpdateTodoRequest{AssigneeIDs: []int64{}})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// Reassigning notifies both the new and the former assignee
+	outbox.messages = nil
+	response, err = uc.UpdateTodo(ctx, response.ID, 7, &dto.UpdateTodoRequest{AssigneeIDs: []int64{9, 7}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{9, 7}, response.AssigneeIDs)
+	assert.Equal(t, []int64{9, 7}, assignees.assignees[response.ID])
+	assert.Equal(t, []string{string(event.TodoUpdated), string(event.TodoAssigned), string(event.TodoUnassigned)}, outbox.types())
+	assert.Equal(t, int64(9), outbox.messages[1].UserID)
+	assert.Equal(t, int64(8), outbox.messages[2].UserID)
+}
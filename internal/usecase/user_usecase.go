@@ -4,11 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
 	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	"github.com/darron08/todolist-demo/internal/infrastructure/mail"
 	"github.com/darron08/todolist-demo/internal/infrastructure/redis"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
 	"github.com/darron08/todolist-demo/pkg/dto"
 	"github.com/darron08/todolist-demo/pkg/utils"
 )
@@ -22,8 +30,16 @@ var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid username or password")
 	ErrInvalidToken       = errors.New("invalid or expired refresh token")
+	ErrInvalidTimezone    = errors.New("invalid timezone")
+	ErrInvalidDigest      = errors.New("invalid digest preferences: frequency must be off, daily or weekly, hour 0-23 and weekday 0-6")
+	ErrIncorrectPassword  = errors.New("current password is incorrect")
+	ErrInvalidResetToken  = errors.New("invalid or expired password reset token")
 )
 
+// passwordResetSendTimeout bounds emailing a password reset link, which
+// outlives the request asking for it
+const passwordResetSendTimeout = time.Minute
+
 // Repository errors (imported from repository layer)
 var (
 	repoErrUserNotFound = errors.New("user not found")
@@ -32,33 +48,59 @@ var (
 // UserUseCase implements business logic for users
 type UserUseCase struct {
 	userRepo          repository.UserRepository
+	orgRepo           repository.OrganizationRepository
+	todoRepo          repository.TodoRepository
+	todoCache         *cache.TodoCache
 	jwtManager        *utils.JWTManager
 	tokenStore        *redis.TokenStore
+	txManager         repository.TransactionManager
+	outboxRepo        repository.OutboxRepository
 	passwordValidator *utils.Validator
 	usernameValidator *utils.Validator
 	emailValidator    *utils.Validator
 	hashedPassword    string
+	sender            mail.Sender
+	resetURL          string
+	resetTTL          time.Duration
 }
 
-// NewUserUseCase creates a new user use case
+// NewUserUseCase creates a new user use case. Password reset links are
+// emailed through sender, point to resetURL and work for resetTTL.
 func NewUserUseCase(
 	userRepo repository.UserRepository,
+	orgRepo repository.OrganizationRepository,
+	todoRepo repository.TodoRepository,
+	todoCache *cache.TodoCache,
 	jwtManager *utils.JWTManager,
 	tokenStore *redis.TokenStore,
+	txManager repository.TransactionManager,
+	outboxRepo repository.OutboxRepository,
+	sender mail.Sender,
+	resetURL string,
+	resetTTL time.Duration,
 ) *UserUseCase {
 	validator := utils.NewValidator()
 
 	return &UserUseCase{
 		userRepo:          userRepo,
+		orgRepo:           orgRepo,
+		todoRepo:          todoRepo,
+		todoCache:         todoCache,
 		jwtManager:        jwtManager,
 		tokenStore:        tokenStore,
+		txManager:         txManager,
+		outboxRepo:        outboxRepo,
 		passwordValidator: validator,
 		usernameValidator: validator,
 		emailValidator:    validator,
+		sender:            sender,
+		resetURL:          resetURL,
+		resetTTL:          resetTTL,
 	}
 }
 
-// Register registers a new user
+// Register registers a new user, who becomes the owner of a personal
+// organization
 func (uc *UserUseCase) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.RegisterResponse, error) {
 	// Validate username
 	if err := uc.usernameValidator.ValidateUsername(req.Username); err != nil {
@@ -75,13 +117,23 @@ func (uc *UserUseCase) Register(ctx context.Context, req *dto.RegisterRequest) (
 		return nil, ErrInvalidPassword
 	}
 
-	// Check if username already exists
-	if _, err := uc.userRepo.FindByUsername(ctx, req.Username); err == nil {
+	// Validate timezone
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := loadTimezone(timezone); err != nil {
+		return nil, err
+	}
+
+	// Check if username already exists; usernames and emails are unique
+	// across organizations
+	if _, err := uc.userRepo.FindByUsername(tenant.Unscoped(ctx), req.Username); err == nil {
 		return nil, ErrUsernameExists
 	}
 
 	// Check if email already exists
-	if _, err := uc.userRepo.FindByEmail(ctx, req.Email); err == nil {
+	if _, err := uc.userRepo.FindByEmail(tenant.Unscoped(ctx), req.Email); err == nil {
 		return nil, ErrEmailExists
 	}
 
@@ -104,13 +156,25 @@ func (uc *UserUseCase) Register(ctx context.Context, req *dto.RegisterRequest) (
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		Role:         role,
+		Timezone:     timezone,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
 
-	// Save to database
-	if err := uc.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	// Save to database and record event
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		org := &entity.Organization{Name: user.Username}
+		owner := &entity.OrganizationMember{UserID: user.ID, Role: entity.OrganizationRoleOwner}
+		if err := uc.orgRepo.Create(ctx, org, owner); err != nil {
+			return fmt.Errorf("failed to create organization: %w", err)
+		}
+		return recordEvent(ctx, uc.outboxRepo, event.UserCreated, user.ID, "user", user.ID, dto.ToUserResponse(user))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return response
@@ -122,10 +186,11 @@ func (uc *UserUseCase) Register(ctx context.Context, req *dto.RegisterRequest) (
 	}, nil
 }
 
-// Login authenticates a user and returns tokens
+// Login authenticates a user and returns tokens acting in the requested
+// organization, or the user's oldest one
 func (uc *UserUseCase) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
-	// Find user by username
-	user, err := uc.userRepo.FindByUsername(ctx, req.Username)
+	// Find user by username, in whichever organization they belong to
+	user, err := uc.userRepo.FindByUsername(tenant.Unscoped(ctx), req.Username)
 	if err != nil {
 		if err != nil && err.Error() == "user not found" {
 			return nil, ErrInvalidCredentials
@@ -138,14 +203,54 @@ func (uc *UserUseCase) Login(ctx context.Context, req *dto.LoginRequest) (*dto.L
 		return nil, ErrInvalidCredentials
 	}
 
+	// Pick the organization to act in
+	tenantID := req.OrganizationID
+	if tenantID == 0 {
+		memberships, err := uc.orgRepo.FindMemberships(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find organizations: %w", err)
+		}
+		if len(memberships) == 0 {
+			return nil, ErrNoOrganization
+		}
+		tenantID = memberships[0].OrganizationID
+	}
+
+	return uc.issueTokens(ctx, user, tenantID)
+}
+
+// SwitchOrganization returns tokens acting in another organization the user
+// belongs to
+func (uc *UserUseCase) SwitchOrganization(ctx context.Context, userID, orgID int64) (*dto.LoginResponse, error) {
+	user, err := uc.userRepo.FindByID(tenant.Unscoped(ctx), userID)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return uc.issueTokens(ctx, user, orgID)
+}
+
+// issueTokens checks that a user belongs to an organization and returns a
+// new token pair acting in it
+func (uc *UserUseCase) issueTokens(ctx context.Context, user *entity.User, tenantID int64) (*dto.LoginResponse, error) {
+	if _, err := uc.orgRepo.FindMember(ctx, tenantID, user.ID); err != nil {
+		if errors.Is(err, repositoryImpl.ErrMemberNotFound) {
+			return nil, ErrNotOrganizationMember
+		}
+		return nil, fmt.Errorf("failed to find membership: %w", err)
+	}
+
 	// Generate access token (15 minutes)
-	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Username, string(user.Role))
+	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Username, string(user.Role), tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate refresh token (7 days)
-	refreshToken, tokenID, err := uc.jwtManager.GenerateRefreshToken(user.ID, user.Username, string(user.Role))
+	refreshToken, tokenID, err := uc.jwtManager.GenerateRefreshToken(user.ID, user.Username, string(user.Role), tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -164,12 +269,9 @@ func (uc *UserUseCase) Login(ctx context.Context, req *dto.LoginRequest) (*dto.L
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    expiresIn,
-		User: dto.UserResponse{
-			UserID:   user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Role:     string(user.Role),
-		},
+		User:         dto.ToUserResponse(user),
+
+		OrganizationID: tenantID,
 	}, nil
 }
 
@@ -190,14 +292,22 @@ func (uc *UserUseCase) RefreshToken(ctx context.Context, refreshToken string) (*
 		return nil, ErrInvalidToken
 	}
 
+	// Members removed from the organization lose access with their next refresh
+	if _, err := uc.orgRepo.FindMember(ctx, claims.TenantID, claims.UserID); err != nil {
+		if errors.Is(err, repositoryImpl.ErrMemberNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to find membership: %w", err)
+	}
+
 	// Generate new access token
-	accessToken, err := uc.jwtManager.GenerateAccessToken(claims.UserID, claims.Username, claims.Role)
+	accessToken, err := uc.jwtManager.GenerateAccessToken(claims.UserID, claims.Username, claims.Role, claims.TenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate new refresh token
-	newRefreshToken, newTokenID, err := uc.jwtManager.GenerateRefreshToken(claims.UserID, claims.Username, claims.Role)
+	newRefreshToken, newTokenID, err := uc.jwtManager.GenerateRefreshToken(claims.UserID, claims.Username, claims.Role, claims.TenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -222,6 +332,8 @@ func (uc *UserUseCase) RefreshToken(ctx context.Context, refreshToken string) (*
 		RefreshToken: newRefreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    expiresIn,
+
+		OrganizationID: claims.TenantID,
 	}, nil
 }
 
@@ -262,10 +374,246 @@ func (uc *UserUseCase) GetProfile(ctx context.Context, userID int64) (*dto.UserR
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return &dto.UserResponse{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Role:     string(user.Role),
-	}, nil
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// UpdateProfile updates a user's preferences. A new timezone moves the due
+// dates of the user's all-day todos, in every organization, to the end of
+// their day in that zone.
+func (uc *UserUseCase) UpdateProfile(ctx context.Context, userID int64, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err != nil && err.Error() == "user not found" {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var loc *time.Location
+	if req.Timezone != nil && *req.Timezone != user.Timezone {
+		loc, err = loadTimezone(*req.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		user.Timezone = *req.Timezone
+	}
+	if err := applyDigestPreferences(user, req); err != nil {
+		return nil, err
+	}
+
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if loc != nil && uc.todoRepo != nil {
+			return uc.todoRepo.RezoneAllDay(tenant.Unscoped(ctx), user.ID, loc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Cached todos hold the previous due dates
+	if loc != nil && uc.todoCache != nil {
+		if err := invalidateUserTodos(ctx, uc.todoCache, uc.orgRepo, user.ID); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// ChangePassword changes a user's password once they confirm the current one,
+// signing them out everywhere
+func (uc *UserUseCase) ChangePassword(ctx context.Context, userID int64, req *dto.ChangePasswordRequest) error {
+	user, err := uc.userRepo.FindByID(tenant.Unscoped(ctx), userID)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !utils.VerifyPassword(req.CurrentPassword, user.PasswordHash) {
+		return ErrIncorrectPassword
+	}
+	if err := uc.passwordValidator.ValidatePassword(req.NewPassword); err != nil {
+		return ErrInvalidPassword
+	}
+
+	return uc.setPassword(ctx, user, req.NewPassword)
+}
+
+// ForgotPassword emails a single-use password reset link to the user with
+// an email address. It succeeds whether or not there is such a user, and the
+// link is emailed in the background, so that neither the answer nor how long
+// it takes tells who has an account.
+func (uc *UserUseCase) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	user, err := uc.userRepo.FindByEmail(tenant.Unscoped(ctx), req.Email)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// The request ends before the email is sent
+	sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), passwordResetSendTimeout)
+	go func() {
+		defer cancel()
+		if err := uc.sendPasswordReset(sendCtx, user); err != nil {
+			log.Printf("Warning: failed to email password reset link to user %d: %v", user.ID, err)
+		}
+	}()
+	return nil
+}
+
+// sendPasswordReset stores a new reset token for a user, revoking the
+// previous one, and emails its link
+func (uc *UserUseCase) sendPasswordReset(ctx context.Context, user *entity.User) error {
+	token, err := redis.GeneratePasswordResetToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	if err := uc.tokenStore.StorePasswordResetToken(ctx, user.Email, token, uc.resetTTL); err != nil {
+		return err
+	}
+
+	msg, err := mail.NewPasswordResetMessage(user.Email, &mail.PasswordReset{
+		Username:  user.Username,
+		ResetURL:  uc.PasswordResetURL(token),
+		ExpiresIn: formatTTL(uc.resetTTL),
+	})
+	if err != nil {
+		return err
+	}
+	return uc.sender.Send(ctx, msg)
+}
+
+// ResetPassword sets a new password for the user a reset token was emailed
+// to, signing them out everywhere. Each token works once.
+func (uc *UserUseCase) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	// Check the password first so that a rejected one does not use up the token
+	if err := uc.passwordValidator.ValidatePassword(req.NewPassword); err != nil {
+		return ErrInvalidPassword
+	}
+
+	email, err := uc.tokenStore.ConsumePasswordResetToken(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, redis.ErrResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	// A token stops working once its email no longer belongs to anyone
+	user, err := uc.userRepo.FindByEmail(tenant.Unscoped(ctx), email)
+	if err != nil {
+		if errors.Is(err, repositoryImpl.ErrUserNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return uc.setPassword(ctx, user, req.NewPassword)
+}
+
+// PasswordResetURL returns the link a password reset token is emailed in
+func (uc *UserUseCase) PasswordResetURL(token string) string {
+	separator := "?"
+	if strings.Contains(uc.resetURL, "?") {
+		separator = "&"
+	}
+	return uc.resetURL + separator + "token=" + url.QueryEscape(token)
+}
+
+// setPassword stores a new password for a user and revokes their refresh
+// tokens, so that sessions opened with the old password end
+func (uc *UserUseCase) setPassword(ctx context.Context, user *entity.User, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.PasswordHash = hashedPassword
+	user.UpdatedAt = time.Now()
+	// Passwords are reset without acting in an organization
+	if err := uc.userRepo.Update(tenant.Unscoped(ctx), user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := uc.tokenStore.DeleteAllUserTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// formatTTL says how long a link works, such as "1 hour" or "30 minutes"
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		if ttl == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", ttl/time.Hour)
+	}
+	minutes := int64(ttl / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
+
+// applyDigestPreferences copies the digest preferences set in req to user
+func applyDigestPreferences(user *entity.User, req *dto.UpdateProfileRequest) error {
+	if req.DigestFrequency != nil {
+		switch frequency := entity.DigestFrequency(*req.DigestFrequency); frequency {
+		case entity.DigestFrequencyOff, entity.DigestFrequencyDaily, entity.DigestFrequencyWeekly:
+			user.DigestFrequency = frequency
+		default:
+			return ErrInvalidDigest
+		}
+	}
+	if req.DigestHour != nil {
+		if *req.DigestHour < 0 || *req.DigestHour > 23 {
+			return ErrInvalidDigest
+		}
+		user.DigestHour = *req.DigestHour
+	}
+	if req.DigestWeekday != nil {
+		if *req.DigestWeekday < 0 || *req.DigestWeekday > 6 {
+			return ErrInvalidDigest
+		}
+		user.DigestWeekday = time.Weekday(*req.DigestWeekday)
+	}
+	return nil
+}
+
+// loadTimezone loads an IANA timezone such as Europe/Paris
+func loadTimezone(name string) (*time.Location, error) {
+	// LoadLocation treats "" and "Local" as the server's zone
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// loadLocation returns the timezone of a user, UTC when users are not
+// available
+func loadLocation(ctx context.Context, userRepo repository.UserRepository, userID int64) (*time.Location, error) {
+	if userRepo == nil {
+		return time.UTC, nil
+	}
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.Location(), nil
 }
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	redisdb "github.com/darron08/todolist-demo/internal/infrastructure/database/redis"
+	"github.com/darron08/todolist-demo/internal/infrastructure/redis"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/darron08/todolist-demo/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emailUserRepository finds the members of the organization by email too
+type emailUserRepository struct {
+	memberUserRepository
+}
+
+func (r *emailUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repositoryImpl.ErrUserNotFound
+}
+
+func (r *emailUserRepository) Update(ctx context.Context, user *entity.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func TestUserUseCase_PasswordChecks(t *testing.T) {
+	hash, err := utils.HashPassword("Correct#Horse1")
+	require.NoError(t, err)
+	users := &emailUserRepository{memberUserRepository{memoryUserRepository{users: map[int64]*entity.User{
+		7: {ID: 7, Username: "alice", Email: "alice@example.com", PasswordHash: hash},
+	}}}}
+	sender := &recordingSender{}
+	server := miniredis.RunT(t)
+	client, err := redisdb.NewConnection(&redisdb.Config{Host: server.Host(), Port: server.Port()})
+	require.NoError(t, err)
+	defer client.Close()
+	tokenStore := redis.NewTokenStore(client)
+	uc := NewUserUseCase(users, nil, nil, nil, nil, tokenStore, nil, nil, sender, "https://app.example.com/reset?lang=en", time.Hour)
+	ctx := context.Background()
+
+	// Changing a password takes the current one and a strong new one
+	err = uc.ChangePassword(ctx, 7, &dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "Battery#Staple2"})
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+	err = uc.ChangePassword(ctx, 7, &dto.ChangePasswordRequest{CurrentPassword: "Correct#Horse1", NewPassword: "password"})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	err = uc.ChangePassword(ctx, 8, &dto.ChangePasswordRequest{CurrentPassword: "Correct#Horse1", NewPassword: "Battery#Staple2"})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.Equal(t, hash, users.users[7].PasswordHash)
+
+	// Unknown emails get the same answer as known ones, and no email
+	require.NoError(t, uc.ForgotPassword(ctx, &dto.ForgotPasswordRequest{Email: "nobody@example.com"}))
+	assert.Empty(t, sender.messages)
+
+	// Weak passwords are rejected before the reset token is used up
+	err = uc.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: "token", NewPassword: "password"})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+
+	// Changing the password stores its hash and signs the user out everywhere
+	require.NoError(t, tokenStore.StoreRefreshToken(ctx, 7, "session", "refresh"))
+	require.NoError(t, uc.ChangePassword(ctx, 7, &dto.ChangePasswordRequest{CurrentPassword: "Correct#Horse1", NewPassword: "Battery#Staple2"}))
+	assert.True(t, utils.VerifyPassword("Battery#Staple2", users.users[7].PasswordHash))
+	valid, err := tokenStore.ValidateRefreshToken(ctx, 7, "session")
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	// So does resetting it with the emailed token, which works once
+	require.NoError(t, uc.sendPasswordReset(ctx, users.users[7]))
+	require.Len(t, sender.messages, 1)
+	assert.Equal(t, "alice@example.com", sender.messages[0].To)
+	match := regexp.MustCompile(`token=([0-9a-f]{64})`).FindStringSubmatch(sender.messages[0].Text)
+	require.Len(t, match, 2)
+	require.NoError(t, tokenStore.StoreRefreshToken(ctx, 7, "session", "refresh"))
+	require.NoError(t, uc.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: match[1], NewPassword: "Tr0ub4dor&3x"}))
+	assert.True(t, utils.VerifyPassword("Tr0ub4dor&3x", users.users[7].PasswordHash))
+	valid, err = tokenStore.ValidateRefreshToken(ctx, 7, "session")
+	require.NoError(t, err)
+	assert.False(t, valid)
+	err = uc.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: match[1], NewPassword: "Correct#Horse9"})
+	assert.ErrorIs(t, err, ErrInvalidResetToken)
+	assert.True(t, utils.VerifyPassword("Tr0ub4dor&3x", users.users[7].PasswordHash))
+
+	assert.Equal(t, "https://app.example.com/reset?lang=en&token=a%2Bb", uc.PasswordResetURL("a+b"))
+	assert.Equal(t, "1 hour", formatTTL(time.Hour))
+	assert.Equal(t, "2 hours", formatTTL(2*time.Hour))
+	assert.Equal(t, "30 minutes", formatTTL(30*time.Minute))
+}
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/url"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/infrastructure/webhook"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookEvent     = errors.New("invalid webhook event type")
+	ErrWebhookURLNotPublic     = errors.New("webhook url must not point to a private, loopback or link-local address")
+)
+
+// WebhookUseCase implements business logic for webhook subscriptions
+type WebhookUseCase struct {
+	webhookRepo  repository.WebhookRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	dispatcher   *webhook.Dispatcher
+}
+
+// NewWebhookUseCase creates a new webhook use case
+func NewWebhookUseCase(webhookRepo repository.WebhookRepository, deliveryRepo repository.WebhookDeliveryRepository, dispatcher *webhook.Dispatcher) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		dispatcher:   dispatcher,
+	}
+}
+
+// CreateWebhook creates a new webhook subscription. The signing secret is only
+// returned in this response.
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, userID int64, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+	if err := uc.validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookEvents(req.Events); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := webhook.GenerateSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	hook := &entity.Webhook{
+		UserID:      userID,
+		URL:         req.URL,
+		Secret:      secret,
+		Description: req.Description,
+		Active:      true,
+	}
+	hook.SetEventList(req.Events)
+
+	if err := uc.webhookRepo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookResponse(hook)
+	response.Secret = secret
+	return &response, nil
+}
+
+// ListWebhooks lists all webhooks owned by a user
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context, userID int64) ([]dto.WebhookResponse, error) {
+	webhooks, err := uc.webhookRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToWebhookResponseList(webhooks), nil
+}
+
+// GetWebhook retrieves a single webhook owned by a user
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id, userID int64) (*dto.WebhookResponse, error) {
+	hook, err := uc.findOwnedWebhook(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookResponse(hook)
+	return &response, nil
+}
+
+// UpdateWebhook updates a webhook owned by a user
+func (uc *WebhookUseCase) UpdateWebhook(ctx context.Context, id, userID int64, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error) {
+	hook, err := uc.findOwnedWebhook(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := uc.validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = *req.URL
+	}
+
+	if req.Events != nil {
+		if err := validateWebhookEvents(req.Events); err != nil {
+			return nil, err
+		}
+		hook.SetEventList(req.Events)
+	}
+
+	if req.Description != nil {
+		hook.Description = *req.Description
+	}
+
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+
+	if err := uc.webhookRepo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookResponse(hook)
+	return &response, nil
+}
+
+// DeleteWebhook deletes a webhook owned by a user
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id, userID int64) error {
+	if _, err := uc.findOwnedWebhook(ctx, id, userID); err != nil {
+		return err
+	}
+	return uc.webhookRepo.Delete(ctx, id)
+}
+
+// ListDeliveries lists the delivery log of a webhook owned by a user
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, webhookID, userID int64, page, limit int) (*dto.WebhookDeliveryListResponse, error) {
+	if _, err := uc.findOwnedWebhook(ctx, webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	deliveries, total, err := uc.deliveryRepo.FindByWebhookID(ctx, webhookID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	return &dto.WebhookDeliveryListResponse{
+		Data:       dto.ToWebhookDeliveryResponseList(deliveries),
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Redeliver schedules a past delivery to be sent again
+func (uc *WebhookUseCase) Redeliver(ctx context.Context, webhookID, deliveryID, userID int64) (*dto.WebhookDeliveryResponse, error) {
+	if _, err := uc.findOwnedWebhook(ctx, webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := uc.deliveryRepo.FindByID(ctx, deliveryID)
+	if err != nil || delivery.WebhookID != webhookID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	if err := uc.dispatcher.Redeliver(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookDeliveryResponse(delivery)
+	return &response, nil
+}
+
+// findOwnedWebhook finds a webhook and checks ownership
+func (uc *WebhookUseCase) findOwnedWebhook(ctx context.Context, id, userID int64) (*entity.Webhook, error) {
+	hook, err := uc.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	if hook.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+
+	return hook, nil
+}
+
+// validateWebhookURL validates that a webhook URL is an absolute http(s) URL
+func (uc *WebhookUseCase) validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ErrInvalidWebhookURL
+	}
+	// Hostnames are checked again when deliveries connect, once resolved
+	if uc.dispatcher != nil && !uc.dispatcher.AllowsHost(parsed.Hostname()) {
+		return ErrWebhookURLNotPublic
+	}
+	return nil
+}
+
+// validateWebhookEvents validates that every pattern matches at least one known event type
+func validateWebhookEvents(patterns []string) error {
+	for _, pattern := range patterns {
+		matched := false
+		for _, eventType := range event.AllTypes() {
+			if eventType.Matches(pattern) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
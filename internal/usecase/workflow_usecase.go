@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	"github.com/darron08/todolist-demo/internal/domain/tenant"
+	"github.com/darron08/todolist-demo/internal/infrastructure/cache"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+)
+
+// maxWorkflowStates bounds the number of states in a workflow
+const maxWorkflowStates = 20
+
+// workflowStatePattern restricts state names to what fits in URLs and cache keys
+var workflowStatePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+var (
+	ErrWorkflowStateRequired   = errors.New("workflow needs at least one state")
+	ErrInvalidWorkflowState    = errors.New("workflow state names must be up to 20 lowercase letters, digits and underscores")
+	ErrDuplicateWorkflowState  = errors.New("workflow state names must be unique")
+	ErrTooManyWorkflowStates   = errors.New("workflow has too many states")
+	ErrInvalidStatusCategory   = errors.New("workflow state category must be open, active or done")
+	ErrUnknownTransitionTarget = errors.New("workflow transition targets an unknown state")
+	ErrInitialStateDone        = errors.New("the first workflow state cannot be a done state")
+	ErrWorkflowStateInUse      = errors.New("a removed workflow state is still used by todos")
+)
+
+// WorkflowUseCase implements business logic for status workflows
+type WorkflowUseCase struct {
+	workflowRepo repository.WorkflowRepository
+	todoRepo     repository.TodoRepository
+	todoCache    *cache.TodoCache
+	txManager    repository.TransactionManager
+	orgRepo      repository.OrganizationRepository
+}
+
+// NewWorkflowUseCase creates a new workflow use case
+func NewWorkflowUseCase(workflowRepo repository.WorkflowRepository, todoRepo repository.TodoRepository, todoCache *cache.TodoCache, txManager repository.TransactionManager, orgRepo repository.OrganizationRepository) *WorkflowUseCase {
+	return &WorkflowUseCase{
+		workflowRepo: workflowRepo,
+		todoRepo:     todoRepo,
+		todoCache:    todoCache,
+		txManager:    txManager,
+		orgRepo:      orgRepo,
+	}
+}
+
+// GetWorkflow returns a user's workflow, or the default workflow when they
+// have not defined one
+func (uc *WorkflowUseCase) GetWorkflow(ctx context.Context, userID int64) (*dto.WorkflowResponse, error) {
+	workflow, err := loadWorkflow(ctx, uc.workflowRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWorkflowResponse(workflow)
+	return &response, nil
+}
+
+// UpdateWorkflow replaces a user's workflow. States still used by todos
+// cannot be removed; todos in states that change category are moved along.
+func (uc *WorkflowUseCase) UpdateWorkflow(ctx context.Context, userID int64, req *dto.UpdateWorkflowRequest) (*dto.WorkflowResponse, error) {
+	states, err := buildWorkflowStates(req.States)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := loadWorkflow(ctx, uc.workflowRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := &entity.Workflow{ID: current.ID, UserID: userID, CreatedAt: current.CreatedAt, States: states}
+	err = uc.replaceWorkflow(ctx, current, workflow, func(ctx context.Context) error {
+		return uc.workflowRepo.Save(ctx, workflow)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWorkflowResponse(workflow)
+	return &response, nil
+}
+
+// ResetWorkflow goes back to the default workflow
+func (uc *WorkflowUseCase) ResetWorkflow(ctx context.Context, userID int64) (*dto.WorkflowResponse, error) {
+	current, err := loadWorkflow(ctx, uc.workflowRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := entity.DefaultWorkflow(userID)
+	if current.ID != 0 {
+		err = uc.replaceWorkflow(ctx, current, workflow, func(ctx context.Context) error {
+			return uc.workflowRepo.DeleteByUserID(ctx, userID)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := dto.ToWorkflowResponse(workflow)
+	return &response, nil
+}
+
+// replaceWorkflow checks that no todo is left in a state the new workflow
+// drops, then saves it and moves the todos of recategorized states. A
+// workflow applies to the user's todos in every organization.
+func (uc *WorkflowUseCase) replaceWorkflow(ctx context.Context, current, workflow *entity.Workflow, save func(ctx context.Context) error) error {
+	allTenants := tenant.Unscoped(ctx)
+
+	var removed []entity.TodoStatus
+	for _, status := range current.Statuses() {
+		if _, ok := workflow.State(status); !ok {
+			removed = append(removed, status)
+		}
+	}
+	inUse, err := uc.todoRepo.CountByStatuses(allTenants, workflow.UserID, removed)
+	if err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return ErrWorkflowStateInUse
+	}
+
+	now := time.Now()
+	err = withinTransaction(ctx, uc.txManager, func(ctx context.Context) error {
+		if err := save(ctx); err != nil {
+			return err
+		}
+		for _, state := range workflow.States {
+			if err := uc.todoRepo.UpdateStatusCategory(tenant.Unscoped(ctx), workflow.UserID, state.Name, state.Category, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cached todo lists are keyed by the statuses of the workflow
+	if uc.todoCache != nil {
+		if err := invalidateUserTodos(ctx, uc.todoCache, uc.orgRepo, workflow.UserID); err != nil {
+			// Log error but don't fail the request
+			// In production, use proper logging
+		}
+	}
+
+	return nil
+}
+
+// loadWorkflow returns a user's workflow, or the default workflow when they
+// have not defined one
+func loadWorkflow(ctx context.Context, workflowRepo repository.WorkflowRepository, userID int64) (*entity.Workflow, error) {
+	if workflowRepo == nil {
+		return entity.DefaultWorkflow(userID), nil
+	}
+
+	workflow, err := workflowRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, repositoryImpl.ErrWorkflowNotFound) {
+		return entity.DefaultWorkflow(userID), nil
+	}
+	return workflow, err
+}
+
+// buildWorkflowStates validates requested states and converts them to
+// workflow states in order
+func buildWorkflowStates(reqs []dto.WorkflowStateRequest) ([]entity.WorkflowState, error) {
+	if len(reqs) == 0 {
+		return nil, ErrWorkflowStateRequired
+	}
+	if len(reqs) > maxWorkflowStates {
+		return nil, ErrTooManyWorkflowStates
+	}
+
+	names := make(map[entity.TodoStatus]bool, len(reqs))
+	for _, req := range reqs {
+		name := entity.TodoStatus(req.Name)
+		if !workflowStatePattern.MatchString(req.Name) {
+			return nil, ErrInvalidWorkflowState
+		}
+		if names[name] {
+			return nil, ErrDuplicateWorkflowState
+		}
+		names[name] = true
+	}
+
+	states := make([]entity.WorkflowState, len(reqs))
+	for i, req := range reqs {
+		category := entity.StatusCategory(req.Category)
+		if !category.IsValid() {
+			return nil, ErrInvalidStatusCategory
+		}
+
+		transitions := make([]entity.TodoStatus, 0, len(req.Transitions))
+		for _, target := range req.Transitions {
+			status := entity.TodoStatus(target)
+			if !names[status] {
+				return nil, ErrUnknownTransitionTarget
+			}
+			if status != entity.TodoStatus(req.Name) && !containsStatus(transitions, status) {
+				transitions = append(transitions, status)
+			}
+		}
+
+		states[i] = entity.WorkflowState{
+			Position: i,
+			Name:     entity.TodoStatus(req.Name),
+			Category: category,
+		}
+		states[i].SetTransitionList(transitions)
+	}
+
+	if states[0].Category == entity.StatusCategoryDone {
+		return nil, ErrInitialStateDone
+	}
+	return states, nil
+}
+
+// containsStatus reports whether statuses contains status
+func containsStatus(statuses []entity.TodoStatus, status entity.TodoStatus) bool {
+	for _, existing := range statuses {
+		if existing == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+	"github.com/darron08/todolist-demo/internal/domain/event"
+	"github.com/darron08/todolist-demo/internal/domain/repository"
+	repositoryImpl "github.com/darron08/todolist-demo/internal/infrastructure/repository"
+	"github.com/darron08/todolist-demo/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWorkflowRepository keeps workflows by user
+type memoryWorkflowRepository struct {
+	repository.WorkflowRepository
+	workflows map[int64]*entity.Workflow
+}
+
+func (r *memoryWorkflowRepository) FindByUserID(ctx context.Context, userID int64) (*entity.Workflow, error) {
+	workflow, ok := r.workflows[userID]
+	if !ok {
+		return nil, repositoryImpl.ErrWorkflowNotFound
+	}
+	return workflow, nil
+}
+
+func TestBuildWorkflowStates(t *testing.T) {
+	states, err := buildWorkflowStates([]dto.WorkflowStateRequest{
+		{Name: "todo", Category: "open", Transitions: []string{"doing", "todo", "doing"}},
+		{Name: "doing", Category: "active", Transitions: []string{"done"}},
+		{Name: "done", Category: "done"},
+	})
+	require.NoError(t, err)
+	require.Len(t, states, 3)
+	assert.Equal(t, []entity.TodoStatus{"doing"}, states[0].TransitionList())
+	assert.Equal(t, 2, states[2].Position)
+	assert.Empty(t, states[2].TransitionList())
+
+	cases := map[string]struct {
+		states []dto.WorkflowStateRequest
+		err    error
+	}{
+		"empty":        {nil, ErrWorkflowStateRequired},
+		"bad name":     {[]dto.WorkflowStateRequest{{Name: "In Review", Category: "open"}}, ErrInvalidWorkflowState},
+		"duplicate":    {[]dto.WorkflowStateRequest{{Name: "todo", Category: "open"}, {Name: "todo", Category: "done"}}, ErrDuplicateWorkflowState},
+		"category":     {[]dto.WorkflowStateRequest{{Name: "todo", Category: "later"}}, ErrInvalidStatusCategory},
+		"unknown next": {[]dto.WorkflowStateRequest{{Name: "todo", Category: "open", Transitions: []string{"done"}}}, ErrUnknownTransitionTarget},
+		"starts done":  {[]dto.WorkflowStateRequest{{Name: "done", Category: "done"}}, ErrInitialStateDone},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := buildWorkflowStates(tc.states)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestUpdateTodoStatus_FollowsWorkflow(t *testing.T) {
+	states, err := buildWorkflowStates([]dto.WorkflowStateRequest{
+		{Name: "backlog", Category: "open", Transitions: []string{"review", "cancelled"}},
+		{Name: "review", Category: "active", Transitions: []string{"backlog"}},
+		{Name: "cancelled", Category: "done"},
+	})
+	require.NoError(t, err)
+	workflows := &memoryWorkflowRepository{workflows: map[int64]*entity.Workflow{
+		7: {ID: 1, UserID: 7, States: states},
+	}}
+
+	repo := &memoryTodoRepository{todos: map[int64]*entity.Todo{}}
+	outbox := &memoryOutbox{}
+	uc := NewTodoUseCase(repo, nil, &memoryTodoTagRepository{}, nil, workflows, nil, nil, nil, outbox, 0)
+	ctx := context.Background()
+
+	created, err := uc.CreateTodo(ctx, 7, &dto.CreateTodoRequest{Title: "Ship it"})
+	require.NoError(t, err)
+	assert.Equal(t, "backlog", created.Status)
+	assert.Equal(t, "open", created.StatusCategory)
+
+	_, err = uc.UpdateTodoStatus(ctx, created.ID, 7, "in_progress")
+	assert.Equal(t, ErrInvalidStatus, err)
+
+	_, err = uc.UpdateTodoStatus(ctx, created.ID, 7, "review")
+	require.NoError(t, err)
+	_, err = uc.UpdateTodoStatus(ctx, created.ID, 7, "cancelled")
+	assert.Equal(t, ErrTransitionNotAllowed, err)
+
+	_, err = uc.UpdateTodoStatus(ctx, created.ID, 7, "backlog")
+	require.NoError(t, err)
+	outbox.messages = nil
+	cancelled, err := uc.UpdateTodoStatus(ctx, created.ID, 7, "cancelled")
+	require.NoError(t, err)
+	assert.Equal(t, "done", cancelled.StatusCategory)
+	assert.NotNil(t, cancelled.CompletedAt)
+	assert.Equal(t, []string{string(event.TodoUpdated), string(event.TodoCompleted)}, outbox.types())
+}
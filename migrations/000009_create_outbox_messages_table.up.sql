@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    payload MEDIUMTEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(1024),
+    published_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_outbox_messages_event_id (event_id),
+    INDEX idx_outbox_aggregate (aggregate_type, aggregate_id),
+    INDEX idx_outbox_messages_published_at (published_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,7 @@
+-- Track when a todo was completed for productivity stats
+ALTER TABLE todos
+    ADD COLUMN completed_at DATETIME NULL AFTER priority,
+    ADD INDEX idx_user_completed_at (user_id, completed_at);
+
+-- Backfill already completed todos with their last update time
+UPDATE todos SET completed_at = updated_at WHERE status = 'completed' AND completed_at IS NULL;
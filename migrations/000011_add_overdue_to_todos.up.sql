@@ -0,0 +1,5 @@
+-- Track overdue detection and priority escalation
+ALTER TABLE todos
+    ADD COLUMN overdue_at DATETIME NULL AFTER completed_at,
+    ADD COLUMN escalated_at DATETIME NULL AFTER overdue_at,
+    ADD INDEX idx_status_due_date (status, due_date);
@@ -0,0 +1,4 @@
+-- Hide snoozed todos from default lists until snoozed_until passes
+ALTER TABLE todos
+    ADD COLUMN snoozed_until DATETIME NULL AFTER escalated_at,
+    ADD INDEX idx_user_snoozed_until (user_id, snoozed_until);
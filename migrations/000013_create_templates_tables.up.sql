@@ -0,0 +1,35 @@
+-- Create templates table (named sets of todo blueprints per user)
+CREATE TABLE IF NOT EXISTS templates (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(1000),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id),
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Create template_items table (one todo blueprint per row)
+CREATE TABLE IF NOT EXISTS template_items (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    template_id BIGINT NOT NULL,
+    position INT NOT NULL,
+    parent_position INT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    priority VARCHAR(20) NOT NULL DEFAULT 'medium',
+    tags VARCHAR(1024),
+    due_offset_days INT NULL,
+
+    FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_template_position (template_id, position)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Let todos created from templates keep their subtask structure
+ALTER TABLE todos
+    ADD COLUMN parent_id BIGINT NULL AFTER user_id,
+    ADD INDEX idx_parent_id (parent_id);
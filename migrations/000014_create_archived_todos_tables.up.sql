@@ -0,0 +1,39 @@
+-- Create archived_todos table (completed todos moved out of the hot todos table)
+CREATE TABLE IF NOT EXISTS archived_todos (
+    id BIGINT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    parent_id BIGINT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    due_date TIMESTAMP NULL,
+    status ENUM('not_started', 'in_progress', 'completed') NOT NULL DEFAULT 'not_started',
+    priority ENUM('low', 'medium', 'high') NOT NULL DEFAULT 'medium',
+    completed_at DATETIME NULL,
+    overdue_at DATETIME NULL,
+    escalated_at DATETIME NULL,
+    snoozed_until DATETIME NULL,
+    created_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NULL,
+    deleted_at TIMESTAMP NULL,
+    archived_at DATETIME NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_archived_at (user_id, archived_at),
+    INDEX idx_user_completed_at (user_id, completed_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Create archived_todo_tags table (tag links of archived todos)
+CREATE TABLE IF NOT EXISTS archived_todo_tags (
+    todo_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (todo_id, tag_id),
+    FOREIGN KEY (todo_id) REFERENCES archived_todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    INDEX idx_tag_id (tag_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Speed up the archiving job's scan for old completed todos
+ALTER TABLE todos
+    ADD INDEX idx_status_completed_at (status, completed_at);
@@ -0,0 +1,57 @@
+-- Scope tags to the user who owns them. Tag names used to be globally
+-- unique, so every user tagging with a shared tag gets a copy of their own.
+ALTER TABLE tags
+    ADD COLUMN user_id BIGINT NULL AFTER id;
+
+-- The first user (by id) tagging with a tag keeps the existing row
+UPDATE tags t
+JOIN (
+    SELECT tag_id, MIN(user_id) AS user_id
+    FROM (
+        SELECT tt.tag_id, td.user_id FROM todo_tags tt JOIN todos td ON td.id = tt.todo_id
+        UNION
+        SELECT att.tag_id, atd.user_id FROM archived_todo_tags att JOIN archived_todos atd ON atd.id = att.todo_id
+    ) tag_users
+    GROUP BY tag_id
+) first_user ON first_user.tag_id = t.id
+SET t.user_id = first_user.user_id;
+
+-- Every other user of the tag gets a copy
+INSERT INTO tags (user_id, name, created_at, updated_at)
+SELECT tag_users.user_id, t.name, t.created_at, t.updated_at
+FROM tags t
+JOIN (
+    SELECT tt.tag_id, td.user_id FROM todo_tags tt JOIN todos td ON td.id = tt.todo_id
+    UNION
+    SELECT att.tag_id, atd.user_id FROM archived_todo_tags att JOIN archived_todos atd ON atd.id = att.todo_id
+) tag_users ON tag_users.tag_id = t.id
+WHERE tag_users.user_id <> t.user_id;
+
+-- Point todo links at the copy owned by the todo's user
+UPDATE todo_tags tt
+JOIN todos td ON td.id = tt.todo_id
+JOIN tags shared ON shared.id = tt.tag_id
+JOIN tags own ON own.user_id = td.user_id AND own.name = shared.name
+SET tt.tag_id = own.id
+WHERE shared.user_id <> td.user_id;
+
+UPDATE archived_todo_tags att
+JOIN archived_todos atd ON atd.id = att.todo_id
+JOIN tags shared ON shared.id = att.tag_id
+JOIN tags own ON own.user_id = atd.user_id AND own.name = shared.name
+SET att.tag_id = own.id
+WHERE shared.user_id <> atd.user_id;
+
+-- Unused tags go to the first admin; without one they are dropped
+UPDATE tags
+SET user_id = (SELECT MIN(id) FROM users WHERE role = 'admin')
+WHERE user_id IS NULL;
+
+DELETE FROM tags WHERE user_id IS NULL;
+
+-- Names are now unique per user
+ALTER TABLE tags
+    MODIFY COLUMN user_id BIGINT NOT NULL,
+    DROP INDEX name,
+    ADD UNIQUE INDEX idx_user_name (user_id, name),
+    ADD FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,13 @@
+-- Nest tags: a tag named "work/backend" is a child of the tag named "work"
+ALTER TABLE tags
+    ADD COLUMN parent_id BIGINT NULL AFTER user_id,
+    ADD INDEX idx_parent_id (parent_id),
+    ADD FOREIGN KEY (parent_id) REFERENCES tags(id) ON DELETE CASCADE;
+
+-- Link existing path-style tags to their parent when it exists
+UPDATE tags child
+JOIN tags parent
+    ON parent.user_id = child.user_id
+    AND parent.name = SUBSTRING(child.name, 1, CHAR_LENGTH(child.name) - CHAR_LENGTH(SUBSTRING_INDEX(child.name, '/', -1)) - 1)
+SET child.parent_id = parent.id
+WHERE child.name LIKE '%/%';
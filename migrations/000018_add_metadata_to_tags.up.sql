@@ -0,0 +1,5 @@
+-- Add display metadata to tags
+ALTER TABLE tags
+    ADD COLUMN color VARCHAR(7) NOT NULL DEFAULT '' AFTER name,
+    ADD COLUMN icon VARCHAR(50) NOT NULL DEFAULT '' AFTER color,
+    ADD COLUMN description VARCHAR(255) NOT NULL DEFAULT '' AFTER icon;
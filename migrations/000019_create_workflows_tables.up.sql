@@ -0,0 +1,40 @@
+-- Statuses come from per-user workflows instead of a fixed ENUM
+ALTER TABLE todos
+    MODIFY COLUMN status VARCHAR(20) NOT NULL DEFAULT 'not_started',
+    ADD COLUMN status_category VARCHAR(20) NOT NULL DEFAULT 'open' AFTER status;
+
+UPDATE todos SET status_category = 'active' WHERE status = 'in_progress';
+UPDATE todos SET status_category = 'done' WHERE status = 'completed';
+
+CREATE INDEX idx_user_status_category ON todos (user_id, status_category);
+
+ALTER TABLE archived_todos
+    MODIFY COLUMN status VARCHAR(20) NOT NULL DEFAULT 'not_started',
+    ADD COLUMN status_category VARCHAR(20) NOT NULL DEFAULT 'open' AFTER status;
+
+UPDATE archived_todos SET status_category = 'active' WHERE status = 'in_progress';
+UPDATE archived_todos SET status_category = 'done' WHERE status = 'completed';
+
+-- Create workflows table (one custom workflow per user; users without one use the default)
+CREATE TABLE IF NOT EXISTS workflows (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Create workflow_states table (ordered states of a workflow and their allowed transitions)
+CREATE TABLE IF NOT EXISTS workflow_states (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    workflow_id BIGINT NOT NULL,
+    position INT NOT NULL,
+    name VARCHAR(20) NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    transitions VARCHAR(1024) NOT NULL DEFAULT '',
+
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE,
+    INDEX idx_workflow_position (workflow_id, position)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
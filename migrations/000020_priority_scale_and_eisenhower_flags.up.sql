@@ -0,0 +1,19 @@
+-- Priorities move from low/medium/high to the p0 (most important) to p4 scale
+ALTER TABLE todos
+    MODIFY COLUMN priority VARCHAR(20) NOT NULL DEFAULT 'p2',
+    ADD COLUMN urgent BOOLEAN NULL AFTER priority,
+    ADD COLUMN important BOOLEAN NULL AFTER urgent;
+
+UPDATE todos SET priority = CASE priority WHEN 'high' THEN 'p1' WHEN 'low' THEN 'p3' ELSE 'p2' END;
+
+ALTER TABLE archived_todos
+    MODIFY COLUMN priority VARCHAR(20) NOT NULL DEFAULT 'p2',
+    ADD COLUMN urgent BOOLEAN NULL AFTER priority,
+    ADD COLUMN important BOOLEAN NULL AFTER urgent;
+
+UPDATE archived_todos SET priority = CASE priority WHEN 'high' THEN 'p1' WHEN 'low' THEN 'p3' ELSE 'p2' END;
+
+ALTER TABLE template_items
+    MODIFY COLUMN priority VARCHAR(20) NOT NULL DEFAULT 'p2';
+
+UPDATE template_items SET priority = CASE priority WHEN 'high' THEN 'p1' WHEN 'low' THEN 'p3' ELSE 'p2' END;
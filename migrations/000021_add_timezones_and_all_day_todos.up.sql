@@ -0,0 +1,12 @@
+-- Users pick the timezone their due dates and days are interpreted in
+ALTER TABLE users
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC' AFTER role;
+
+-- All-day todos are due on a date; due_date is then the end of that day in the owner's timezone
+ALTER TABLE todos
+    ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT FALSE AFTER due_date,
+    ADD COLUMN due_on DATE NULL AFTER all_day;
+
+ALTER TABLE archived_todos
+    ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT FALSE AFTER due_date,
+    ADD COLUMN due_on DATE NULL AFTER all_day;
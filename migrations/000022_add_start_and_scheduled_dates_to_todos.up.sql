@@ -0,0 +1,11 @@
+-- Todos can be planned apart from when they are due: start_date is when work
+-- can begin, scheduled_for is when the owner plans to do it
+ALTER TABLE todos
+    ADD COLUMN start_date DATETIME NULL AFTER due_on,
+    ADD COLUMN scheduled_for DATETIME NULL AFTER start_date,
+    ADD INDEX idx_user_start_date (user_id, start_date),
+    ADD INDEX idx_user_scheduled_for (user_id, scheduled_for);
+
+ALTER TABLE archived_todos
+    ADD COLUMN start_date DATETIME NULL AFTER due_on,
+    ADD COLUMN scheduled_for DATETIME NULL AFTER start_date;
@@ -0,0 +1,7 @@
+-- Users opt into a daily or weekly digest email, sent at digest_hour in their timezone
+ALTER TABLE users
+    ADD COLUMN digest_frequency VARCHAR(10) NOT NULL DEFAULT 'off' AFTER timezone,
+    ADD COLUMN digest_hour TINYINT NOT NULL DEFAULT 7 AFTER digest_frequency,
+    ADD COLUMN digest_weekday TINYINT NOT NULL DEFAULT 1 AFTER digest_hour,
+    ADD COLUMN digest_sent_at DATETIME NULL AFTER digest_weekday,
+    ADD INDEX idx_users_digest_frequency (digest_frequency);
@@ -0,0 +1,66 @@
+-- Create organizations table (tenants; todos and tags belong to exactly one organization)
+CREATE TABLE IF NOT EXISTS organizations (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Create organization_members table (users' memberships and their role: owner, admin or member)
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (organization_id, user_id),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Existing users join a default organization, owned by the admins, which keeps all existing data
+INSERT INTO organizations (id, name) VALUES (1, 'Default');
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT 1, id, IF(role = 'admin', 'owner', 'member') FROM users;
+
+ALTER TABLE todos
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER user_id,
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id),
+    ADD INDEX idx_organization_user (organization_id, user_id);
+
+ALTER TABLE archived_todos
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER user_id,
+    ADD INDEX idx_organization_user (organization_id, user_id);
+
+-- Tag and alias names are unique per user within an organization
+ALTER TABLE tags
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER user_id,
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id),
+    ADD UNIQUE INDEX idx_user_organization_name (user_id, organization_id, name);
+
+ALTER TABLE tags DROP INDEX idx_user_name;
+
+ALTER TABLE tag_aliases
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER user_id,
+    ADD UNIQUE INDEX idx_user_organization_name (user_id, organization_id, name);
+
+ALTER TABLE tag_aliases DROP INDEX idx_user_name;
+
+-- Webhooks receive the events of the organization they were created in
+ALTER TABLE webhooks
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER user_id,
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id),
+    ADD INDEX idx_organization_user (organization_id, user_id);
+
+-- New rows must name their organization
+ALTER TABLE todos ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE archived_todos ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE tags ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE tag_aliases ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE webhooks ALTER COLUMN organization_id DROP DEFAULT;
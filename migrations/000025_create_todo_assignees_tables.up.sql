@@ -0,0 +1,25 @@
+-- Create todo_assignees table (todos assigned to members of their organization)
+CREATE TABLE IF NOT EXISTS todo_assignees (
+    todo_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    assigned_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (todo_id, user_id),
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Create archived_todo_assignees table (assignees of archived todos)
+CREATE TABLE IF NOT EXISTS archived_todo_assignees (
+    todo_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    assigned_by BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (todo_id, user_id),
+    FOREIGN KEY (todo_id) REFERENCES archived_todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
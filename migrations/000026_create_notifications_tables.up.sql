@@ -0,0 +1,31 @@
+-- Create notifications table (users' inboxes; one notification per user and event)
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    organization_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    event_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    actor_id BIGINT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    message VARCHAR(512) NOT NULL,
+    read_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_user_event (user_id, event_id),
+    INDEX idx_organization_user (organization_id, user_id, read_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Create notification_preferences table (per event type; defaults apply to types without a row)
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    inbox BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, event_type),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
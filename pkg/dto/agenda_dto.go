@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// AgendaRequest represents an agenda request
+type AgendaRequest struct {
+	// Range is today, or week for today and the six days after it
+	Range string `form:"range" binding:"omitempty,oneof=today week"`
+}
+
+// AgendaItem is a todo on a day of the agenda. Reason is overdue for todos
+// carried forward from an earlier due date, due for todos due that day and
+// scheduled for todos planned for that day.
+type AgendaItem struct {
+	Reason string       `json:"reason"`
+	Todo   TodoResponse `json:"todo"`
+}
+
+// AgendaDay lists the todos of a day (YYYY-MM-DD) in the user's timezone
+type AgendaDay struct {
+	Date  string       `json:"date"`
+	Items []AgendaItem `json:"items"`
+}
+
+// AgendaResponse represents a user's agenda, grouped by day in Timezone
+type AgendaResponse struct {
+	Range    string      `json:"range"`
+	Timezone string      `json:"timezone"`
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	Days     []AgendaDay `json:"days"`
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+)
+
+// ListNotificationsRequest represents a list notifications request
+type ListNotificationsRequest struct {
+	Page   int  `form:"page" binding:"omitempty,min=1"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Unread bool `form:"unread"`
+}
+
+// NotificationResponse represents a notification in the inbox
+type NotificationResponse struct {
+	ID            int64      `json:"id"`
+	EventType     string     `json:"event_type"`
+	ActorID       *int64     `json:"actor_id,omitempty"`
+	AggregateType string     `json:"aggregate_type"`
+	AggregateID   int64      `json:"aggregate_id"`
+	Message       string     `json:"message"`
+	Read          bool       `json:"read"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// NotificationListResponse represents a paginated inbox with the number of
+// unread notifications
+type NotificationListResponse struct {
+	Data        []NotificationResponse `json:"data"`
+	Page        int                    `json:"page"`
+	Limit       int                    `json:"limit"`
+	Total       int64                  `json:"total"`
+	TotalPages  int                    `json:"total_pages"`
+	UnreadCount int64                  `json:"unread_count"`
+}
+
+// MarkReadResponse represents the result of marking notifications as read
+type MarkReadResponse struct {
+	Marked      int64 `json:"marked"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// NotificationPreferenceRequest sets where events of a type are delivered
+type NotificationPreferenceRequest struct {
+	EventType string `json:"event_type" binding:"required"`
+	Inbox     *bool  `json:"inbox" binding:"required"`
+	Email     *bool  `json:"email" binding:"required"`
+}
+
+// UpdateNotificationPreferencesRequest represents an update notification
+// preferences request; event types left out keep their preference
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" binding:"required,min=1,dive"`
+}
+
+// NotificationPreferenceResponse represents where events of a type are delivered
+type NotificationPreferenceResponse struct {
+	EventType string `json:"event_type"`
+	Inbox     bool   `json:"inbox"`
+	Email     bool   `json:"email"`
+}
+
+// ToNotificationResponse converts entity.Notification to NotificationResponse
+func ToNotificationResponse(notification *entity.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:            notification.ID,
+		EventType:     notification.EventType,
+		ActorID:       notification.ActorID,
+		AggregateType: notification.AggregateType,
+		AggregateID:   notification.AggregateID,
+		Message:       notification.Message,
+		Read:          notification.IsRead(),
+		ReadAt:        notification.ReadAt,
+		CreatedAt:     notification.CreatedAt,
+	}
+}
+
+// ToNotificationResponseList converts []*entity.Notification to []NotificationResponse
+func ToNotificationResponseList(notifications []*entity.Notification) []NotificationResponse {
+	responses := make([]NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = ToNotificationResponse(notification)
+	}
+	return responses
+}
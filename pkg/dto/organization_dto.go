@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+)
+
+// CreateOrganizationRequest represents a request to create an organization.
+// The caller becomes its owner.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+}
+
+// AddMemberRequest represents a request to add a user to an organization by username
+type AddMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"omitempty,oneof=owner admin member"`
+}
+
+// UpdateMemberRequest represents a request to change a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// OrganizationResponse represents an organization the caller belongs to
+type OrganizationResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Role is the caller's role in the organization
+	Role string `json:"role"`
+	// Current is set for the organization the caller's token acts in
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemberResponse represents a member of an organization
+type MemberResponse struct {
+	UserID   int64     `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// ToOrganizationResponse converts a membership with its organization to OrganizationResponse
+func ToOrganizationResponse(member *entity.OrganizationMember, currentID int64) OrganizationResponse {
+	response := OrganizationResponse{
+		ID:      member.OrganizationID,
+		Role:    string(member.Role),
+		Current: member.OrganizationID == currentID,
+	}
+	if member.Organization != nil {
+		response.Name = member.Organization.Name
+		response.CreatedAt = member.Organization.CreatedAt
+	}
+	return response
+}
+
+// ToMemberResponse converts a membership with its user to MemberResponse
+func ToMemberResponse(member *entity.OrganizationMember) MemberResponse {
+	response := MemberResponse{
+		UserID:   member.UserID,
+		Role:     string(member.Role),
+		JoinedAt: member.CreatedAt,
+	}
+	if member.User != nil {
+		response.Username = member.User.Username
+		response.Email = member.User.Email
+	}
+	return response
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// StatsRequest represents a productivity stats request
+type StatsRequest struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=365"`
+}
+
+// DailyCompletion is the number of todos completed on a day (YYYY-MM-DD)
+type DailyCompletion struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// WeeklyCompletion is the number of todos completed in the week starting on
+// WeekStart (a Monday, YYYY-MM-DD)
+type WeeklyCompletion struct {
+	WeekStart string `json:"week_start"`
+	Count     int64  `json:"count"`
+}
+
+// TagUsageResponse represents how many of the user's todos carry a tag
+type TagUsageResponse struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// StatsResponse represents a user's productivity analytics
+type StatsResponse struct {
+	Days                 int                `json:"days"`
+	Since                time.Time          `json:"since"`
+	TotalTodos           int64              `json:"total_todos"`
+	CompletedCount       int64              `json:"completed_count"`
+	CompletedPerDay      []DailyCompletion  `json:"completed_per_day"`
+	CompletedPerWeek     []WeeklyCompletion `json:"completed_per_week"`
+	AvgLeadTimeSeconds   float64            `json:"avg_lead_time_seconds"`
+	OverdueCount         int64              `json:"overdue_count"`
+	StatusDistribution   map[string]int64   `json:"status_distribution"`
+	PriorityDistribution map[string]int64   `json:"priority_distribution"`
+	TopTags              []TagUsageResponse `json:"top_tags"`
+	GeneratedAt          time.Time          `json:"generated_at"`
+}
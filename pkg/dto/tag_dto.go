@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"sort"
 	"time"
 
 	"github.com/darron08/todolist-demo/internal/domain/entity"
@@ -9,11 +10,35 @@ import (
 // CreateTagRequest represents a create tag request
 type CreateTagRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=100"`
+	// Color is a hex color such as "#1e90ff"
+	Color       string `json:"color"`
+	Icon        string `json:"icon"`
+	Description string `json:"description"`
 }
 
 // UpdateTagRequest represents an update tag request
 type UpdateTagRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=100"`
+	// KeepAlias keeps the old name as an alias so it still resolves to the tag
+	KeepAlias bool `json:"keep_alias"`
+	// Color, Icon and Description are left unchanged when omitted and
+	// cleared when empty
+	Color       *string `json:"color"`
+	Icon        *string `json:"icon"`
+	Description *string `json:"description"`
+}
+
+// SuggestTagsRequest represents a tag autocomplete request
+type SuggestTagsRequest struct {
+	Prefix string `form:"prefix" binding:"max=100"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// MergeTagsRequest represents a request to merge tags into a target tag
+type MergeTagsRequest struct {
+	SourceIDs []int64 `json:"source_ids" binding:"required,min=1,max=50"`
+	// KeepAliases keeps the names of the merged tags as aliases of the target
+	KeepAliases bool `json:"keep_aliases"`
 }
 
 // ListTagsRequest represents a list tags request
@@ -24,11 +49,25 @@ type ListTagsRequest struct {
 
 // TagResponse represents a tag response
 type TagResponse struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	TodoCount int64     `json:"todo_count,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int64     `json:"id"`
+	ParentID    *int64    `json:"parent_id,omitempty"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color,omitempty"`
+	Icon        string    `json:"icon,omitempty"`
+	Description string    `json:"description,omitempty"`
+	TodoCount   int64     `json:"todo_count,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TagTreeNode represents a tag and the tags nested under it
+type TagTreeNode struct {
+	ID int64 `json:"id"`
+	// Name is the last segment of the path
+	Name      string        `json:"name"`
+	Path      string        `json:"path"`
+	TodoCount int64         `json:"todo_count"`
+	Children  []TagTreeNode `json:"children,omitempty"`
 }
 
 // TagListResponse represents a paginated tag list response
@@ -43,21 +82,29 @@ type TagListResponse struct {
 // ToTagResponse converts entity.Tag to TagResponse
 func ToTagResponse(tag *entity.Tag) TagResponse {
 	return TagResponse{
-		ID:        tag.ID,
-		Name:      tag.Name,
-		CreatedAt: tag.CreatedAt,
-		UpdatedAt: tag.UpdatedAt,
+		ID:          tag.ID,
+		ParentID:    tag.ParentID,
+		Name:        tag.Name,
+		Color:       tag.Color,
+		Icon:        tag.Icon,
+		Description: tag.Description,
+		CreatedAt:   tag.CreatedAt,
+		UpdatedAt:   tag.UpdatedAt,
 	}
 }
 
 // ToTagResponseWithCount converts entity.Tag to TagResponse with todo count
 func ToTagResponseWithCount(tag *entity.Tag, todoCount int64) TagResponse {
 	return TagResponse{
-		ID:        tag.ID,
-		Name:      tag.Name,
-		TodoCount: todoCount,
-		CreatedAt: tag.CreatedAt,
-		UpdatedAt: tag.UpdatedAt,
+		ID:          tag.ID,
+		ParentID:    tag.ParentID,
+		Name:        tag.Name,
+		Color:       tag.Color,
+		Icon:        tag.Icon,
+		Description: tag.Description,
+		TodoCount:   todoCount,
+		CreatedAt:   tag.CreatedAt,
+		UpdatedAt:   tag.UpdatedAt,
 	}
 }
 
@@ -69,3 +116,47 @@ func ToTagResponseList(tags []*entity.Tag) []TagResponse {
 	}
 	return responses
 }
+
+// ToTagTree nests tags under their parents, sorted by name. Tags whose
+// parent is not among tags become roots.
+func ToTagTree(tags []*entity.Tag, todoCounts map[int64]int64) []TagTreeNode {
+	sorted := make([]*entity.Tag, len(tags))
+	copy(sorted, tags)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	present := make(map[int64]bool, len(sorted))
+	for _, tag := range sorted {
+		present[tag.ID] = true
+	}
+
+	children := make(map[int64][]*entity.Tag)
+	var roots []*entity.Tag
+	for _, tag := range sorted {
+		if tag.ParentID != nil && present[*tag.ParentID] {
+			children[*tag.ParentID] = append(children[*tag.ParentID], tag)
+		} else {
+			roots = append(roots, tag)
+		}
+	}
+
+	return toTagTreeNodes(roots, children, todoCounts)
+}
+
+// toTagTreeNodes converts tags and, recursively, their nested tags
+func toTagTreeNodes(tags []*entity.Tag, children map[int64][]*entity.Tag, todoCounts map[int64]int64) []TagTreeNode {
+	nodes := make([]TagTreeNode, len(tags))
+	for i, tag := range tags {
+		nodes[i] = TagTreeNode{
+			ID:        tag.ID,
+			Name:      tag.LeafName(),
+			Path:      tag.Name,
+			TodoCount: todoCounts[tag.ID],
+		}
+		if nested := children[tag.ID]; len(nested) > 0 {
+			nodes[i].Children = toTagTreeNodes(nested, children, todoCounts)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,118 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+)
+
+// TemplateItemRequest describes a todo blueprint and its subtasks
+type TemplateItemRequest struct {
+	Title         string                `json:"title" binding:"required,min=1,max=255"`
+	Description   string                `json:"description" binding:"max=5000"`
+	Priority      string                `json:"priority" binding:"omitempty,max=10"`
+	Tags          []string              `json:"tags" binding:"omitempty,max=10"`
+	DueOffsetDays *int                  `json:"due_offset_days" binding:"omitempty,min=0,max=3650"`
+	Subtasks      []TemplateItemRequest `json:"subtasks" binding:"omitempty,max=100,dive"`
+}
+
+// CreateTemplateRequest represents a create template request
+type CreateTemplateRequest struct {
+	Name        string                `json:"name" binding:"required,min=1,max=100"`
+	Description string                `json:"description" binding:"max=1000"`
+	Items       []TemplateItemRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// CreateTemplateFromTodosRequest represents a request to capture existing todos as a template
+type CreateTemplateFromTodosRequest struct {
+	Name        string  `json:"name" binding:"required,min=1,max=100"`
+	Description string  `json:"description" binding:"max=1000"`
+	TodoIDs     []int64 `json:"todo_ids" binding:"required,min=1,max=100"`
+	// ReferenceDate is the date due-date offsets are measured from; defaults
+	// to the creation time of the oldest todo
+	ReferenceDate *time.Time `json:"reference_date"`
+}
+
+// UpdateTemplateRequest represents an update template request. Items, when
+// given, replace all items of the template.
+type UpdateTemplateRequest struct {
+	Name        *string               `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string               `json:"description" binding:"omitempty,max=1000"`
+	Items       []TemplateItemRequest `json:"items" binding:"omitempty,min=1,max=100,dive"`
+}
+
+// InstantiateTemplateRequest represents a request to create todos from a template
+type InstantiateTemplateRequest struct {
+	// StartDate is the date due-date offsets are added to; defaults to now
+	StartDate *time.Time `json:"start_date"`
+}
+
+// TemplateItemResponse represents a todo blueprint in a template response
+type TemplateItemResponse struct {
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description,omitempty"`
+	Priority      string                 `json:"priority"`
+	Tags          []string               `json:"tags,omitempty"`
+	DueOffsetDays *int                   `json:"due_offset_days,omitempty"`
+	Subtasks      []TemplateItemResponse `json:"subtasks,omitempty"`
+}
+
+// TemplateResponse represents a template response
+type TemplateResponse struct {
+	ID          int64                  `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Items       []TemplateItemResponse `json:"items"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// ToTemplateResponse converts entity.Template to TemplateResponse, nesting
+// subtasks under their parent items
+func ToTemplateResponse(template *entity.Template) TemplateResponse {
+	children := make(map[int][]entity.TemplateItem)
+	var roots []entity.TemplateItem
+	for _, item := range template.Items {
+		if item.ParentPosition == nil {
+			roots = append(roots, item)
+		} else {
+			children[*item.ParentPosition] = append(children[*item.ParentPosition], item)
+		}
+	}
+
+	return TemplateResponse{
+		ID:          template.ID,
+		Name:        template.Name,
+		Description: template.Description,
+		Items:       toTemplateItemResponses(roots, children),
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}
+}
+
+// toTemplateItemResponses converts items and, recursively, their subtasks
+func toTemplateItemResponses(items []entity.TemplateItem, children map[int][]entity.TemplateItem) []TemplateItemResponse {
+	responses := make([]TemplateItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = TemplateItemResponse{
+			Title:         item.Title,
+			Description:   item.Description,
+			Priority:      string(item.Priority),
+			Tags:          item.TagList(),
+			DueOffsetDays: item.DueOffsetDays,
+		}
+		if subtasks := children[item.Position]; len(subtasks) > 0 {
+			responses[i].Subtasks = toTemplateItemResponses(subtasks, children)
+		}
+	}
+	return responses
+}
+
+// ToTemplateResponseList converts []*entity.Template to []TemplateResponse
+func ToTemplateResponseList(templates []*entity.Template) []TemplateResponse {
+	responses := make([]TemplateResponse, len(templates))
+	for i, template := range templates {
+		responses[i] = ToTemplateResponse(template)
+	}
+	return responses
+}
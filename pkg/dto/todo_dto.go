@@ -11,8 +11,23 @@ type CreateTodoRequest struct {
 	Title       string     `json:"title" binding:"required,min=1,max=255"`
 	Description string     `json:"description" binding:"max=5000"`
 	DueDate     *time.Time `json:"due_date"`
-	Priority    string     `json:"priority" binding:"omitempty,oneof=low medium high"`
-	Tags        []string   `json:"tags" binding:"omitempty,max=10"`
+	// AllDay makes the todo due on the date of DueDate, until the end of
+	// that day in the user's timezone
+	AllDay bool `json:"all_day"`
+	// StartDate is when work can begin and ScheduledFor when it is planned;
+	// neither may be after DueDate
+	StartDate    *time.Time `json:"start_date"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
+	Priority     string     `json:"priority" binding:"omitempty,max=10"`
+	Tags         []string   `json:"tags" binding:"omitempty,max=10"`
+	// ParentID makes the new todo a subtask of another of the user's todos
+	ParentID *int64 `json:"parent_id"`
+	// Urgent and Important override what the Eisenhower matrix derives
+	// from the due date and the priority
+	Urgent    *bool `json:"urgent"`
+	Important *bool `json:"important"`
+	// AssigneeIDs assigns the todo to members of the organization
+	AssigneeIDs []int64 `json:"assignee_ids" binding:"omitempty,max=10"`
 }
 
 // UpdateTodoRequest represents an update todo request
@@ -20,41 +35,119 @@ type UpdateTodoRequest struct {
 	Title       *string    `json:"title" binding:"omitempty,min=1,max=255"`
 	Description *string    `json:"description" binding:"omitempty,max=5000"`
 	DueDate     *time.Time `json:"due_date"`
-	Status      *string    `json:"status" binding:"omitempty,oneof=not_started in_progress completed"`
-	Priority    *string    `json:"priority" binding:"omitempty,oneof=low medium high"`
-	Tags        []string   `json:"tags" binding:"omitempty,max=10"`
+	// AllDay switches the todo between an all-day due date and a due time
+	AllDay       *bool      `json:"all_day"`
+	StartDate    *time.Time `json:"start_date"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
+	Status       *string    `json:"status" binding:"omitempty,min=1,max=20"`
+	Priority     *string    `json:"priority" binding:"omitempty,max=10"`
+	Tags         []string   `json:"tags" binding:"omitempty,max=10"`
+	Urgent       *bool      `json:"urgent"`
+	Important    *bool      `json:"important"`
+	// AssigneeIDs replaces the todo's assignees; an empty list unassigns it
+	AssigneeIDs []int64 `json:"assignee_ids" binding:"omitempty,max=10"`
+	// ResetMatrixFlags clears Urgent and Important, so that the Eisenhower
+	// matrix derives them again
+	ResetMatrixFlags bool `json:"reset_matrix_flags"`
+	// ClearStartDate and ClearScheduledFor remove the start date and the
+	// scheduled time; a StartDate or ScheduledFor sent along is set instead
+	ClearStartDate    bool `json:"clear_start_date"`
+	ClearScheduledFor bool `json:"clear_scheduled_for"`
 }
 
-// UpdateTodoStatusRequest represents an update todo status request
+// UpdateTodoStatusRequest represents an update todo status request. The
+// status must be a state of the user's workflow reachable from the current one.
 type UpdateTodoStatusRequest struct {
-	Status string `json:"status" binding:"required,oneof=not_started in_progress completed"`
+	Status string `json:"status" binding:"required,max=20"`
 }
 
 // ListTodosRequest represents a list todos request with filters
 type ListTodosRequest struct {
-	Page        int        `form:"page" binding:"min=1"`
-	Limit       int        `form:"limit" binding:"min=1,max=100"`
-	Status      string     `form:"status" binding:"omitempty,oneof=not_started in_progress completed"`
-	Priority    string     `form:"priority" binding:"omitempty,oneof=low medium high"`
-	Search      string     `form:"search" binding:"max=100"`
-	DueDateFrom *time.Time `form:"due_date_from" binding:"omitempty"`
-	DueDateTo   *time.Time `form:"due_date_to" binding:"omitempty"`
-	SortBy      string     `form:"sort_by" binding:"omitempty,oneof=due_date status title"`
-	SortOrder   string     `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+	Page     int    `form:"page" binding:"min=1"`
+	Limit    int    `form:"limit" binding:"min=1,max=100"`
+	Status   string `form:"status" binding:"max=20"`
+	Priority string `form:"priority" binding:"max=10"`
+	Search   string `form:"search" binding:"max=100"`
+	// DueDateFrom and DueDateTo bound the due date. RFC 3339 times are
+	// used as given; dates (2006-01-02) and times without an offset are read
+	// in the user's timezone, a date covering the whole day.
+	DueDateFrom string `form:"due_date_from" binding:"max=35"`
+	DueDateTo   string `form:"due_date_to" binding:"max=35"`
+	Overdue     bool   `form:"overdue"`
+	// Available keeps only todos whose start date has passed
+	Available bool `form:"available"`
+	// IncludeSnoozed also lists todos whose snooze has not ended yet
+	IncludeSnoozed bool `form:"include_snoozed"`
+	// IncludeArchived also lists todos moved to the archive
+	IncludeArchived bool `form:"include_archived"`
+	// Tags keeps todos tagged with the comma-separated tag names; tags
+	// nested under a listed tag count as that tag
+	Tags string `form:"tags" binding:"max=1000"`
+	// TagMatch selects whether a todo needs any or all of Tags
+	TagMatch string `form:"tag_match" binding:"omitempty,oneof=any all"`
+	// ExcludeTags drops todos tagged with any of the comma-separated tag names
+	ExcludeTags string `form:"exclude_tags" binding:"max=1000"`
+	// Assignment lists the todos assigned to the user, whoever owns them,
+	// or the user's todos assigned to someone else
+	Assignment string `form:"assignment" binding:"omitempty,oneof=assigned_to_me delegated_by_me"`
+	SortBy     string `form:"sort_by" binding:"omitempty,oneof=due_date status title priority scheduled_for"`
+	SortOrder  string `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+}
+
+// ListArchivedTodosRequest represents a list archived todos request
+type ListArchivedTodosRequest struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// SnoozeTodoRequest represents a snooze todo request
+type SnoozeTodoRequest struct {
+	Until time.Time `json:"until" binding:"required"`
 }
 
 // TodoResponse represents a todo response
 type TodoResponse struct {
 	ID          int64      `json:"id"`
 	UserID      int64      `json:"user_id"`
+	ParentID    *int64     `json:"parent_id,omitempty"`
 	Title       string     `json:"title"`
 	Description string     `json:"description,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
-	Status      string     `json:"status"`
-	Priority    string     `json:"priority"`
-	Tags        []TagInfo  `json:"tags,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	AllDay      bool       `json:"all_day"`
+	// DueOn is the date all-day todos are due on
+	DueOn        string     `json:"due_on,omitempty"`
+	StartDate    *time.Time `json:"start_date,omitempty"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	Status       string     `json:"status"`
+	// StatusCategory is the category of the status in the user's workflow
+	StatusCategory string     `json:"status_category"`
+	Priority       string     `json:"priority"`
+	PriorityLabel  string     `json:"priority_label"`
+	Urgent         *bool      `json:"urgent,omitempty"`
+	Important      *bool      `json:"important,omitempty"`
+	Tags           []TagInfo  `json:"tags,omitempty"`
+	AssigneeIDs    []int64    `json:"assignee_ids,omitempty"`
+	IsOverdue      bool       `json:"is_overdue"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty"`
+	ArchivedAt     *time.Time `json:"archived_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TodoAssignmentEvent is the payload of the todo.assigned and
+// todo.unassigned events
+type TodoAssignmentEvent struct {
+	AssigneeID int64        `json:"assignee_id"`
+	AssignedBy int64        `json:"assigned_by"`
+	Todo       TodoResponse `json:"todo"`
+}
+
+// TodoMentionEvent is the payload of the todo.mentioned event
+type TodoMentionEvent struct {
+	MentionedUserID int64        `json:"mentioned_user_id"`
+	MentionedBy     int64        `json:"mentioned_by"`
+	Todo            TodoResponse `json:"todo"`
 }
 
 // TagInfo represents tag information in todo response
@@ -75,15 +168,29 @@ type TodoListResponse struct {
 // ToTodoResponse converts entity.Todo to TodoResponse
 func ToTodoResponse(todo *entity.Todo) TodoResponse {
 	return TodoResponse{
-		ID:          todo.ID,
-		UserID:      todo.UserID,
-		Title:       todo.Title,
-		Description: todo.Description,
-		DueDate:     todo.DueDate,
-		Status:      string(todo.Status),
-		Priority:    string(todo.Priority),
-		CreatedAt:   todo.CreatedAt,
-		UpdatedAt:   todo.UpdatedAt,
+		ID:             todo.ID,
+		UserID:         todo.UserID,
+		ParentID:       todo.ParentID,
+		Title:          todo.Title,
+		Description:    todo.Description,
+		DueDate:        todo.DueDate,
+		AllDay:         todo.AllDay,
+		DueOn:          dueOn(todo),
+		StartDate:      todo.StartDate,
+		ScheduledFor:   todo.ScheduledFor,
+		Status:         string(todo.Status),
+		StatusCategory: string(todo.StatusCategory),
+		Priority:       string(todo.Priority),
+		PriorityLabel:  todo.Priority.Label(),
+		Urgent:         todo.Urgent,
+		Important:      todo.Important,
+		AssigneeIDs:    todo.AssigneeIDs,
+		IsOverdue:      todo.IsOverdue(time.Now()),
+		CompletedAt:    todo.CompletedAt,
+		SnoozedUntil:   todo.SnoozedUntil,
+		ArchivedAt:     todo.ArchivedAt,
+		CreatedAt:      todo.CreatedAt,
+		UpdatedAt:      todo.UpdatedAt,
 	}
 }
 
@@ -98,17 +205,48 @@ func ToTodoResponseWithTags(todo *entity.Todo, tags []*entity.Tag) TodoResponse
 	}
 
 	return TodoResponse{
-		ID:          todo.ID,
-		UserID:      todo.UserID,
-		Title:       todo.Title,
-		Description: todo.Description,
-		DueDate:     todo.DueDate,
-		Status:      string(todo.Status),
-		Priority:    string(todo.Priority),
-		Tags:        tagInfos,
-		CreatedAt:   todo.CreatedAt,
-		UpdatedAt:   todo.UpdatedAt,
+		ID:             todo.ID,
+		UserID:         todo.UserID,
+		ParentID:       todo.ParentID,
+		Title:          todo.Title,
+		Description:    todo.Description,
+		DueDate:        todo.DueDate,
+		AllDay:         todo.AllDay,
+		DueOn:          dueOn(todo),
+		StartDate:      todo.StartDate,
+		ScheduledFor:   todo.ScheduledFor,
+		Status:         string(todo.Status),
+		StatusCategory: string(todo.StatusCategory),
+		Priority:       string(todo.Priority),
+		PriorityLabel:  todo.Priority.Label(),
+		Urgent:         todo.Urgent,
+		Important:      todo.Important,
+		Tags:           tagInfos,
+		AssigneeIDs:    todo.AssigneeIDs,
+		IsOverdue:      todo.IsOverdue(time.Now()),
+		CompletedAt:    todo.CompletedAt,
+		SnoozedUntil:   todo.SnoozedUntil,
+		ArchivedAt:     todo.ArchivedAt,
+		CreatedAt:      todo.CreatedAt,
+		UpdatedAt:      todo.UpdatedAt,
+	}
+}
+
+// dueOn formats the date an all-day todo is due on
+func dueOn(todo *entity.Todo) string {
+	if todo.DueOn == nil {
+		return ""
 	}
+	return todo.DueOn.String()
+}
+
+// DueBound formats a due date bound of a list request, for callers holding
+// a time rather than a query string
+func DueBound(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
 }
 
 // ToTodoResponseList converts []entity.Todo to []TodoResponse
@@ -131,3 +269,29 @@ func ToTagInfoList(tags []*entity.Tag) []TagInfo {
 	}
 	return tagInfos
 }
+
+// MatrixRequest represents an Eisenhower matrix request
+type MatrixRequest struct {
+	// Limit caps the todos listed per quadrant
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// MatrixQuadrant lists the todos of one quadrant, most important first
+type MatrixQuadrant struct {
+	Todos []TodoResponse `json:"todos"`
+	Total int            `json:"total"`
+}
+
+// MatrixResponse represents the Eisenhower matrix of a user's open todos
+type MatrixResponse struct {
+	// Do holds urgent and important todos
+	Do MatrixQuadrant `json:"do"`
+	// Schedule holds important todos that are not urgent
+	Schedule MatrixQuadrant `json:"schedule"`
+	// Delegate holds urgent todos that are not important
+	Delegate MatrixQuadrant `json:"delegate"`
+	// Eliminate holds todos that are neither
+	Eliminate MatrixQuadrant `json:"eliminate"`
+	// UrgentBefore is the due date before which todos count as urgent
+	UrgentBefore time.Time `json:"urgent_before"`
+}
@@ -10,12 +10,47 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8,max=128"`
 	Role     string `json:"role" binding:"omitempty,oneof=user admin"`
+	// Timezone is an IANA zone such as Europe/Paris; UTC when empty
+	Timezone string `json:"timezone" binding:"max=64"`
+}
+
+// UpdateProfileRequest represents an update profile request
+type UpdateProfileRequest struct {
+	// Timezone is an IANA zone such as Europe/Paris. Changing it moves the
+	// due dates of all-day todos to the end of their day in the new zone.
+	Timezone *string `json:"timezone" binding:"omitempty,min=1,max=64"`
+	// DigestFrequency opts into the digest email: off, daily or weekly
+	DigestFrequency *string `json:"digest_frequency" binding:"omitempty,oneof=off daily weekly"`
+	// DigestHour is the hour (0-23) in the user's timezone the digest is sent at
+	DigestHour *int `json:"digest_hour" binding:"omitempty,min=0,max=23"`
+	// DigestWeekday is the day weekly digests are sent on, 0 for Sunday
+	DigestWeekday *int `json:"digest_weekday" binding:"omitempty,min=0,max=6"`
 }
 
 // LoginRequest represents a login request
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// OrganizationID selects the organization the tokens act in; it
+	// defaults to the user's oldest membership
+	OrganizationID int64 `json:"organization_id" binding:"omitempty,min=1"`
+}
+
+// ChangePasswordRequest represents a change password request
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=128"`
+}
+
+// ForgotPasswordRequest represents a request for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents a password reset with an emailed token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=128"`
 }
 
 // RegisterResponse represents a registration response
@@ -33,6 +68,8 @@ type LoginResponse struct {
 	TokenType    string       `json:"token_type"`
 	ExpiresIn    int64        `json:"expires_in"`
 	User         UserResponse `json:"user"`
+	// OrganizationID is the organization the tokens act in
+	OrganizationID int64 `json:"organization_id"`
 }
 
 // RefreshTokenRequest represents a refresh token request
@@ -46,6 +83,8 @@ type RefreshTokenResponse struct {
 	RefreshToken string `json:"refresh_token"`
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int64  `json:"expires_in"`
+	// OrganizationID is the organization the tokens act in
+	OrganizationID int64 `json:"organization_id"`
 }
 
 // UserResponse represents a user response
@@ -54,6 +93,11 @@ type UserResponse struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Role     string `json:"role"`
+	Timezone string `json:"timezone"`
+	// Digest preferences; see UpdateProfileRequest
+	DigestFrequency string `json:"digest_frequency"`
+	DigestHour      int    `json:"digest_hour"`
+	DigestWeekday   int    `json:"digest_weekday"`
 }
 
 // AdminCreateUserRequest represents an admin create user request
@@ -73,16 +117,26 @@ type UserListResponse struct {
 	TotalPages int            `json:"total_pages"`
 }
 
+// ToUserResponse converts entity.User to UserResponse
+func ToUserResponse(user *entity.User) UserResponse {
+	return UserResponse{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     string(user.Role),
+		Timezone: user.Timezone,
+
+		DigestFrequency: string(user.DigestFrequency),
+		DigestHour:      user.DigestHour,
+		DigestWeekday:   int(user.DigestWeekday),
+	}
+}
+
 // ToUserResponseList converts []entity.User to []UserResponse
 func ToUserResponseList(users []*entity.User) []UserResponse {
 	responses := make([]UserResponse, len(users))
 	for i, user := range users {
-		responses[i] = UserResponse{
-			UserID:   user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Role:     string(user.Role),
-		}
+		responses[i] = ToUserResponse(user)
 	}
 	return responses
 }
@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+)
+
+// CreateWebhookRequest represents a create webhook request
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	Events      []string `json:"events" binding:"required,min=1,max=20"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=255"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// UpdateWebhookRequest represents an update webhook request
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url,max=2048"`
+	Events      []string `json:"events" binding:"omitempty,min=1,max=20"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookResponse represents a webhook response
+type WebhookResponse struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryResponse represents a webhook delivery log entry
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `json:"response_body,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookDeliveryListResponse represents a paginated webhook delivery list response
+type WebhookDeliveryListResponse struct {
+	Data       []WebhookDeliveryResponse `json:"data"`
+	Page       int                       `json:"page"`
+	Limit      int                       `json:"limit"`
+	Total      int64                     `json:"total"`
+	TotalPages int                       `json:"total_pages"`
+}
+
+// ToWebhookResponse converts entity.Webhook to WebhookResponse
+func ToWebhookResponse(webhook *entity.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		Events:      webhook.EventList(),
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+		UpdatedAt:   webhook.UpdatedAt,
+	}
+}
+
+// ToWebhookResponseList converts []*entity.Webhook to []WebhookResponse
+func ToWebhookResponseList(webhooks []*entity.Webhook) []WebhookResponse {
+	responses := make([]WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = ToWebhookResponse(webhook)
+	}
+	return responses
+}
+
+// ToWebhookDeliveryResponse converts entity.WebhookDelivery to WebhookDeliveryResponse
+func ToWebhookDeliveryResponse(delivery *entity.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+// ToWebhookDeliveryResponseList converts []*entity.WebhookDelivery to []WebhookDeliveryResponse
+func ToWebhookDeliveryResponseList(deliveries []*entity.WebhookDelivery) []WebhookDeliveryResponse {
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = ToWebhookDeliveryResponse(delivery)
+	}
+	return responses
+}
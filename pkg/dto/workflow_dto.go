@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/darron08/todolist-demo/internal/domain/entity"
+)
+
+// WorkflowStateRequest describes a state of a workflow and the states todos
+// in it may move to
+type WorkflowStateRequest struct {
+	Name        string   `json:"name" binding:"required,min=1,max=20"`
+	Category    string   `json:"category" binding:"required,oneof=open active done"`
+	Transitions []string `json:"transitions" binding:"omitempty,max=20"`
+}
+
+// UpdateWorkflowRequest represents a request to replace a user's workflow.
+// New todos start in the first state.
+type UpdateWorkflowRequest struct {
+	States []WorkflowStateRequest `json:"states" binding:"required,min=1,max=20,dive"`
+}
+
+// WorkflowStateResponse represents a state in a workflow response
+type WorkflowStateResponse struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Transitions []string `json:"transitions"`
+}
+
+// WorkflowResponse represents a workflow response
+type WorkflowResponse struct {
+	States []WorkflowStateResponse `json:"states"`
+	// IsDefault is set when the user has not defined their own workflow
+	IsDefault bool       `json:"is_default"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ToWorkflowResponse converts entity.Workflow to WorkflowResponse
+func ToWorkflowResponse(workflow *entity.Workflow) WorkflowResponse {
+	states := make([]WorkflowStateResponse, len(workflow.States))
+	for i, state := range workflow.States {
+		transitions := []string{}
+		for _, next := range state.TransitionList() {
+			transitions = append(transitions, string(next))
+		}
+		states[i] = WorkflowStateResponse{
+			Name:        string(state.Name),
+			Category:    string(state.Category),
+			Transitions: transitions,
+		}
+	}
+
+	response := WorkflowResponse{
+		States:    states,
+		IsDefault: workflow.ID == 0,
+	}
+	if !response.IsDefault {
+		updatedAt := workflow.UpdatedAt
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
@@ -22,6 +22,7 @@ type Claims struct {
 	UserID    int64  `json:"user_id"`
 	Username  string `json:"username"`
 	Role      string `json:"role"`
+	TenantID  int64  `json:"tenant_id"` // organization the token acts in
 	TokenID   string `json:"token_id,omitempty"`
 	TokenType string `json:"token_type"` // "access" or "refresh"
 	jwt.RegisteredClaims
@@ -37,26 +38,27 @@ func NewJWTManager(secret, issuer string, accessTokenExpiry, refreshTokenExpiry
 	}
 }
 
-// GenerateAccessToken generates an access token
-func (j *JWTManager) GenerateAccessToken(userID int64, username, role string) (string, error) {
-	return j.generateToken(userID, username, role, "", "access", j.accessTokenExpiry)
+// GenerateAccessToken generates an access token acting in the organization tenantID
+func (j *JWTManager) GenerateAccessToken(userID int64, username, role string, tenantID int64) (string, error) {
+	return j.generateToken(userID, username, role, tenantID, "", "access", j.accessTokenExpiry)
 }
 
-// GenerateRefreshToken generates a refresh token
-func (j *JWTManager) GenerateRefreshToken(userID int64, username, role string) (string, string, error) {
+// GenerateRefreshToken generates a refresh token acting in the organization tenantID
+func (j *JWTManager) GenerateRefreshToken(userID int64, username, role string, tenantID int64) (string, string, error) {
 	tokenID := uuid.New().String()
-	token, err := j.generateToken(userID, username, role, tokenID, "refresh", j.refreshTokenExpiry)
+	token, err := j.generateToken(userID, username, role, tenantID, tokenID, "refresh", j.refreshTokenExpiry)
 	return token, tokenID, err
 }
 
 // generateToken generates a JWT token
-func (j *JWTManager) generateToken(userID int64, username, role, tokenID, tokenType string, expiry time.Duration) (string, error) {
+func (j *JWTManager) generateToken(userID int64, username, role string, tenantID int64, tokenID, tokenType string, expiry time.Duration) (string, error) {
 	now := time.Now()
 
 	claims := &Claims{
 		UserID:    userID,
 		Username:  username,
 		Role:      role,
+		TenantID:  tenantID,
 		TokenID:   tokenID,
 		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -86,6 +88,10 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		// Tokens issued before tenants existed do not say where they act
+		if claims.TenantID == 0 {
+			return nil, errors.New("invalid token: missing tenant")
+		}
 		return claims, nil
 	}
 
@@ -127,7 +133,7 @@ func (j *JWTManager) RefreshAccessToken(refreshTokenString string) (string, stri
 		return "", "", err
 	}
 
-	newToken, newTokenID, err := j.GenerateRefreshToken(claims.UserID, claims.Username, claims.Role)
+	newToken, newTokenID, err := j.GenerateRefreshToken(claims.UserID, claims.Username, claims.Role, claims.TenantID)
 	if err != nil {
 		return "", "", err
 	}
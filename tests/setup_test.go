@@ -187,7 +187,7 @@ func CreateTestTodo(t *testing.T, userID int64) *entity.Todo {
 		Title:       "Test Todo",
 		Description: "This is a test todo",
 		Status:      entity.TodoStatusNotStarted,
-		Priority:    entity.TodoPriorityMedium,
+		Priority:    entity.TodoPriorityP2,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}